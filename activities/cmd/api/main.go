@@ -10,6 +10,7 @@ import (
 	"context"
 	"net/http"
 	"time"
+	_ "time/tzdata"
 
 	log "github.com/sirupsen/logrus"
 
@@ -20,7 +21,17 @@ func main() {
 	cfg := config.Load()
 	ctx := context.Background()
 
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		log.Fatalf("Invalid TIMEZONE %s: %v", cfg.Timezone, err)
+	}
+	time.Local = location
+
 	activitiesMongoRepo := repository.NewMongoActivitiesRepository(ctx, cfg.Mongo.URI, cfg.Mongo.DB, "activities")
+	historyMongoRepo := repository.NewMongoHistoryRepository(ctx, activitiesMongoRepo.Database(), "activities_history")
+	occurrencesMongoRepo := repository.NewMongoOccurrencesRepository(ctx, activitiesMongoRepo.Database(), "activity_occurrences")
+	roomsMongoRepo := repository.NewMongoRoomsRepository(ctx, activitiesMongoRepo.Database(), "rooms")
+	calendarTokensMongoRepo := repository.NewMongoCalendarTokensRepository(ctx, activitiesMongoRepo.Database(), "calendar_tokens")
 	rabbitClient, err := clients.NewRabbitMQClient(
 		cfg.RabbitMQ.Host,
 		cfg.RabbitMQ.Port,
@@ -33,9 +44,45 @@ func main() {
 	}
 	defer rabbitClient.Close()
 
-	activityService := services.NewActivitiesService(activitiesMongoRepo, rabbitClient)
+	notificationsClient, err := clients.NewRabbitMQClient(
+		cfg.RabbitMQ.Host,
+		cfg.RabbitMQ.Port,
+		cfg.RabbitMQ.User,
+		cfg.RabbitMQ.Pass,
+		cfg.RabbitMQ.NotificationsQueueName,
+	)
+	if err != nil {
+		log.Fatalf("Failed to initialize RabbitMQ notifications client: %v", err)
+	}
+	defer notificationsClient.Close()
+
+	// sin clave interna no se consultan los planes de los usuarios al inscribir
+	if cfg.Users.InternalAPIKey == "" {
+		log.Warn("INTERNAL_API_KEY not set: membership plans are not checked on enrollment")
+	}
+	usersClient := clients.NewUsersClient(cfg.Users.APIURL, cfg.Users.InternalAPIKey)
+
+	activityService := services.NewActivitiesService(activitiesMongoRepo, rabbitClient, historyMongoRepo, occurrencesMongoRepo, notificationsClient, roomsMongoRepo, calendarTokensMongoRepo, usersClient)
 	activityController := controllers.NewActivitiesController(activityService)
 
+	// los usuarios eliminados en users-api se quitan de todas las actividades
+	usersEventsClient, err := clients.NewRabbitMQClient(
+		cfg.RabbitMQ.Host,
+		cfg.RabbitMQ.Port,
+		cfg.RabbitMQ.User,
+		cfg.RabbitMQ.Pass,
+		cfg.RabbitMQ.UsersQueueName,
+	)
+	if err != nil {
+		log.Fatalf("Failed to initialize RabbitMQ users client: %v", err)
+	}
+	defer usersEventsClient.Close()
+	go func() {
+		if err := usersEventsClient.ConsumeUserEvents(ctx, activityService.HandleUserEvent); err != nil {
+			log.Errorf("users events consumer stopped: %v", err)
+		}
+	}()
+
 	router := gin.Default()
 	router.Use(middleware.CORSMiddleware)
 
@@ -47,6 +94,21 @@ func main() {
 	// GET /activities - listar todos los activities (✅ implementado)
 	router.GET("/activities", activityController.GetActivities)
 
+	// GET /activities/admin - listar activities incluyendo las dadas de baja (protegido - solo admin)
+	router.GET("/activities/admin", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetActivitiesAdmin)
+
+	// GET /activities/schedule?week= - grilla semanal de clases con lugares disponibles (público, cacheable)
+	router.GET("/activities/schedule", activityController.GetSchedule)
+
+	// GET /activities/schedule.ics - grilla semanal como calendario iCalendar (público, cacheable)
+	router.GET("/activities/schedule.ics", activityController.GetScheduleCalendar)
+
+	// POST /activities/import?format=csv|json&dry_run=true - importar catálogo con upsert por clave_externa (protegido - solo admin)
+	router.POST("/activities/import", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.ImportActivities)
+
+	// GET /activities/export?format=csv|json - exportar catálogo de actividades activas (protegido - solo admin)
+	router.GET("/activities/export", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.ExportActivities)
+
 	// GET /activities/many?ids=id1,id2,id3 - obtener multiples activities por IDs (público)
 	router.GET("/activities/many", activityController.GetManyActivities)
 
@@ -56,27 +118,111 @@ func main() {
 	// GET /activities/:id - obtener activity por ID (devuelve DTO admin o público según rol)
 	router.GET("/activities/:id", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetActivityByID)
 
-	// PUT /activities/:id - actualizar activity existente (protegido)
+	// PUT /activities/:id - reemplazar activity existente (protegido)
 	router.PUT("/activities/:id", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.UpdateActivity)
 
-	// DELETE /activities/:id - eliminar activity (protegido)
+	// PATCH /activities/:id - actualizar parcialmente activity con JSON Merge Patch (protegido)
+	router.PATCH("/activities/:id", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.PatchActivity)
+
+	// DELETE /activities/:id - dar de baja activity; con ?purge=true la elimina definitivamente (protegido)
 	router.DELETE("/activities/:id", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.DeleteActivity)
 
-	// POST /activities/:id/inscribir - inscribir usuario (protegido)
+	// POST /activities/:id/desactivar - dar de baja activity (protegido - solo admin)
+	router.POST("/activities/:id/desactivar", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.DeactivateActivity)
+
+	// POST /activities/:id/activar - reactivar activity dada de baja (protegido - solo admin)
+	router.POST("/activities/:id/activar", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.ReactivateActivity)
+
+	// POST /activities/:id/inscribir - inscribir usuario a todas las clases de la serie (protegido)
 	router.POST("/activities/:id/inscribir", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.Inscribir)
 
-	// POST /activities/:id/desinscribir - desinscribir usuario (protegido)
+	// POST /activities/:id/desinscribir - desinscribir usuario de la serie (protegido)
 	router.POST("/activities/:id/desinscribir", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.Desinscribir)
 
+	// GET /activities/:id/roster?fecha=&format=json|csv - inscriptos con sus datos (protegido - solo admin)
+	router.GET("/activities/:id/roster", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetRoster)
+
+	// POST /activities/:id/roster - inscribir usuarios a la serie (protegido - solo admin)
+	router.POST("/activities/:id/roster", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.AddToRoster)
+
+	// DELETE /activities/:id/roster/:userId - quitar a un usuario de la serie (protegido - solo admin)
+	router.DELETE("/activities/:id/roster/:userId", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.RemoveFromRoster)
+
+	// POST /activities/:id/roster/move - pasar a un usuario a otra actividad (protegido - solo admin)
+	router.POST("/activities/:id/roster/move", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.MoveEnrollment)
+
+	// POST /activities/:id/roster/import?format=csv|json&replace=true - cargar inscriptos desde un archivo (protegido - solo admin)
+	router.POST("/activities/:id/roster/import", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.ImportRoster)
+
+	// GET /activities/:id/occurrences?desde=&hasta= - clases (fechas) de la actividad (público)
+	router.GET("/activities/:id/occurrences", activityController.GetOccurrences)
+
+	// POST /activities/:id/occurrences/:fecha/inscribir - inscribir usuario solo a una fecha (protegido)
+	router.POST("/activities/:id/occurrences/:fecha/inscribir", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.InscribirOccurrence)
+
+	// POST /activities/:id/occurrences/:fecha/desinscribir - desinscribir usuario de una fecha (protegido)
+	router.POST("/activities/:id/occurrences/:fecha/desinscribir", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.DesinscribirOccurrence)
+
+	// POST /activities/:id/occurrences/:fecha/cancelar - cancelar la clase de una fecha (protegido - solo admin)
+	router.POST("/activities/:id/occurrences/:fecha/cancelar", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.CancelOccurrence)
+
+	// POST /activities/:id/occurrences/:fecha/reprogramar - mover la clase de una fecha (protegido - solo admin)
+	router.POST("/activities/:id/occurrences/:fecha/reprogramar", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.RescheduleOccurrence)
+
+	// PUT /activities/:id/occurrences/:fecha/asistencia - tomar asistencia de una clase (protegido - solo admin)
+	router.PUT("/activities/:id/occurrences/:fecha/asistencia", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.RecordAttendance)
+
+	// GET /occurrences/upcoming?desde=&hasta= - clases próximas con sus asistentes, para recordatorios (protegido - admin o servicios)
+	router.GET("/occurrences/upcoming", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetUpcomingOccurrences)
+
+	// GET /rooms - listar salas con su capacidad y equipamiento (público)
+	router.GET("/rooms", activityController.GetRooms)
+
+	// GET /rooms/:id - obtener sala por ID (público)
+	router.GET("/rooms/:id", activityController.GetRoom)
+
+	// POST /rooms - crear sala (protegido - solo admin)
+	router.POST("/rooms", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.CreateRoom)
+
+	// PUT /rooms/:id - reemplazar sala (protegido - solo admin)
+	router.PUT("/rooms/:id", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.UpdateRoom)
+
+	// DELETE /rooms/:id - eliminar sala sin actividades asignadas (protegido - solo admin)
+	router.DELETE("/rooms/:id", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.DeleteRoom)
+
 	// GET /inscriptions/:userId - obtener actividades inscritas por usuario (protegido)
 	router.GET("/inscriptions/:userId", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetInscripcionesByUserID)
 
 	// GET /inscriptions/data/:userId - obtener datos completos de actividades inscritas por usuario (protegido)
 	router.GET("/inscriptions/data/:userId", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetInscribedActivities)
 
+	// GET /inscriptions/history/:userId - inscripciones actuales y pasadas y asistencia del usuario (protegido)
+	router.GET("/inscriptions/history/:userId", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetEnrollmentHistory)
+
+	// GET /inscriptions/calendar/:userId - URL del calendario .ics del usuario (protegido)
+	router.GET("/inscriptions/calendar/:userId", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetCalendarToken)
+
+	// POST /inscriptions/calendar/:userId - generar una URL nueva e invalidar la anterior (protegido)
+	router.POST("/inscriptions/calendar/:userId", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.RotateCalendarToken)
+
+	// GET /calendar/:token - calendario .ics para suscribirse (público, autenticado por el token)
+	router.GET("/calendar/:token", activityController.GetCalendar)
+
 	// GET /activities/statistics - obtener estadísticas de actividades (protegido - solo admin)
 	router.GET("/activities/statistics", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetStatistics)
 
+	// GET /activities/analytics/occupancy?format=json|csv - ocupación por día y hora (protegido - solo admin)
+	router.GET("/activities/analytics/occupancy", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetOccupancyHeatmap)
+
+	// GET /activities/analytics/no-shows?from=&to=&format=json|csv - ausentismo por actividad (protegido - solo admin)
+	router.GET("/activities/analytics/no-shows", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetNoShowRates)
+
+	// GET /activities/:id/history - historial de cambios de una actividad (protegido - solo admin)
+	router.GET("/activities/:id/history", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetActivityHistory)
+
+	// GET /history/users/:userId - historial de cambios hechos por un usuario o que lo afectan (protegido - solo admin)
+	router.GET("/history/users/:userId", middleware.AuthMiddleware(cfg.JwtSecret, "http://users-api:8080/auth"), activityController.GetUserHistory)
+
 	srv := &http.Server{
 		Addr:              ":" + cfg.Port,
 		Handler:           router,
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"activities/internal/clients"
+	"activities/internal/config"
+	"activities/internal/dto"
+	"activities/internal/repository"
+	"activities/internal/services"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const usage = `Uso:
+  catalog import [-dry-run] [-format csv|json] <archivo>
+  catalog export [-format csv|json] [-o archivo]
+
+import crea o actualiza actividades por clave_externa (ver README). Si alguna fila es inválida no
+se importa ninguna. export escribe el catálogo de actividades activas (por defecto en la salida
+estándar). Usa las mismas variables de entorno que la API.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// la CLI no valida tokens, pero la configuración exige la variable
+	if os.Getenv("JWT_SECRET") == "" {
+		os.Setenv("JWT_SECRET", "catalog-cli")
+	}
+
+	switch os.Args[1] {
+	case "import":
+		os.Exit(runImport(os.Args[2:]))
+	case "export":
+		os.Exit(runExport(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "solo informar qué se haría, sin importar")
+	format := flags.String("format", "", "formato del archivo (csv o json); por defecto según la extensión")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	catalogFormat, err := services.ParseCatalogFormat(*format)
+	if err != nil {
+		log.Error(err)
+		return 2
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Errorf("No se pudo abrir %s: %v", path, err)
+		return 1
+	}
+	defer file.Close()
+
+	rows, err := services.ParseActivities(catalogFormat, file)
+	if err != nil {
+		log.Errorf("Archivo inválido: %v", err)
+		return 1
+	}
+
+	ctx := services.WithActor(context.Background(), dto.Actor{Username: "catalog-cli", IsAdmin: true})
+	service, closeService := newService(ctx, !*dryRun)
+	defer closeService()
+
+	report, err := service.ImportActivities(ctx, rows, *dryRun)
+	if err != nil {
+		log.Errorf("Error al importar: %v", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	log.Info("=== Import Summary ===")
+	log.Infof("Rows: %d (dry run: %t, applied: %t)", report.Total, report.DryRun, report.Aplicado)
+	log.Infof("Created: %d, updated: %d, unchanged: %d, with errors: %d", report.Creadas, report.Actualizadas, report.SinCambios, report.ConErrores)
+	log.Info("======================")
+
+	if report.ConErrores > 0 {
+		return 1
+	}
+	return 0
+}
+
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "", "formato (csv o json); por defecto según la extensión de -o, o json")
+	output := flags.String("o", "", "archivo de salida; por defecto la salida estándar")
+	flags.Parse(args)
+
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*output), ".")
+	}
+	if *format == "" {
+		*format = dto.CatalogFormatJSON
+	}
+	catalogFormat, err := services.ParseCatalogFormat(*format)
+	if err != nil {
+		log.Error(err)
+		return 2
+	}
+
+	ctx := context.Background()
+	service, closeService := newService(ctx, false)
+	defer closeService()
+
+	activities, err := service.ExportActivities(ctx)
+	if err != nil {
+		log.Errorf("Error al exportar: %v", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Errorf("No se pudo crear %s: %v", *output, err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := services.WriteActivities(catalogFormat, w, activities); err != nil {
+		log.Errorf("Error al escribir el catálogo: %v", err)
+		return 1
+	}
+
+	log.Infof("Exported %d activities", len(activities))
+	return 0
+}
+
+// newService arma el servicio de actividades como la API. RabbitMQ solo se conecta si se van a
+// aplicar cambios (las altas y modificaciones publican eventos para el buscador).
+func newService(ctx context.Context, withRabbit bool) (*services.ActivitiesServiceImpl, func()) {
+	cfg := config.Load()
+
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		log.Fatalf("Invalid TIMEZONE %s: %v", cfg.Timezone, err)
+	}
+	time.Local = location
+
+	log.Info("Connecting to MongoDB...")
+	activitiesRepo := repository.NewMongoActivitiesRepository(ctx, cfg.Mongo.URI, cfg.Mongo.DB, "activities")
+	if activitiesRepo == nil {
+		log.Fatal("Failed to initialize MongoDB repository")
+	}
+	historyRepo := repository.NewMongoHistoryRepository(ctx, activitiesRepo.Database(), "activities_history")
+	occurrencesRepo := repository.NewMongoOccurrencesRepository(ctx, activitiesRepo.Database(), "activity_occurrences")
+	roomsRepo := repository.NewMongoRoomsRepository(ctx, activitiesRepo.Database(), "rooms")
+
+	var publisher services.RabbitMQPublisher
+	closeService := func() {}
+	if withRabbit {
+		log.Info("Connecting to RabbitMQ...")
+		rabbitClient, err := clients.NewRabbitMQClient(
+			cfg.RabbitMQ.Host,
+			cfg.RabbitMQ.Port,
+			cfg.RabbitMQ.User,
+			cfg.RabbitMQ.Pass,
+			cfg.RabbitMQ.QueueName,
+		)
+		if err != nil {
+			log.Fatalf("Failed to initialize RabbitMQ client: %v", err)
+		}
+		publisher = rabbitClient
+		closeService = func() { rabbitClient.Close() }
+	}
+
+	return services.NewActivitiesService(activitiesRepo, publisher, historyRepo, occurrencesRepo, nil, roomsRepo, nil, nil), closeService
+}
@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"activities/internal/dto"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -75,6 +76,66 @@ func (r *RabbitMQClient) Publish(ctx context.Context, action, id string) error {
 	})
 }
 
+// PublishEvent publica un evento de clase (cancelación, reprogramación) para las notificaciones
+func (r *RabbitMQClient) PublishEvent(ctx context.Context, event dto.OccurrenceEvent) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	pubCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return r.channel.PublishWithContext(pubCtx, "", r.queueName, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		Type:         event.Type,
+		Body:         b,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    event.Timestamp,
+	})
+}
+
+// ConsumeUserEvents invoca al handler con cada evento de la cola de usuarios. Si el handler falla
+// el mensaje se reencola una sola vez; los mensajes que no se pueden deserializar se descartan.
+func (r *RabbitMQClient) ConsumeUserEvents(ctx context.Context, handler func(context.Context, dto.UserEvent) error) error {
+	msgs, err := r.channel.Consume(r.queueName, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to register consumer: %w", err)
+	}
+	log.Infof("Consumer registered for queue %s", r.queueName)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case msg, ok := <-msgs:
+			if !ok {
+				return fmt.Errorf("consumer channel closed")
+			}
+
+			var event dto.UserEvent
+			if err := json.Unmarshal(msg.Body, &event); err != nil {
+				log.Errorf("Error unmarshalling user event: %v", err)
+				if err := msg.Nack(false, false); err != nil {
+					log.Errorf("Error rejecting message: %v", err)
+				}
+				continue
+			}
+
+			if err := handler(ctx, event); err != nil {
+				log.Errorf("Error handling %s event for user %d: %v", event.Type, event.UserID, err)
+				if err := msg.Nack(false, !msg.Redelivered); err != nil {
+					log.Errorf("Error rejecting message: %v", err)
+				}
+				continue
+			}
+
+			if err := msg.Ack(false); err != nil {
+				log.Errorf("Error acknowledging message: %v", err)
+			}
+		}
+	}
+}
+
 // Close cierra canal y conexión
 func (r *RabbitMQClient) Close() error {
 	if r.channel != nil {
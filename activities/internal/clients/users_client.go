@@ -0,0 +1,169 @@
+package clients
+
+import (
+	"activities/internal/dto"
+	"activities/internal/errors"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// internalKeyHeader es el header con el que users-api autentica los endpoints internos
+const internalKeyHeader = "X-Internal-Key"
+
+// usersCacheTTL es cuánto se guarda un usuario encontrado en users-api. Los eliminados se descartan
+// antes con Forget al recibir user.deleted.
+const usersCacheTTL = 5 * time.Minute
+
+type cachedUser struct {
+	user    dto.User
+	expires time.Time
+}
+
+// UsersClient consulta users-api. GetUser guarda los usuarios encontrados durante usersCacheTTL
+// para no consultar en cada inscripción.
+type UsersClient struct {
+	baseURL     string
+	internalKey string
+	client      *http.Client
+
+	mu    sync.Mutex
+	cache map[int]cachedUser
+}
+
+func NewUsersClient(baseURL, internalKey string) *UsersClient {
+	return &UsersClient{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		internalKey: internalKey,
+		client:      &http.Client{Timeout: 5 * time.Second},
+		cache:       map[int]cachedUser{},
+	}
+}
+
+// GetUser busca un usuario por su ID, primero entre los guardados
+func (c *UsersClient) GetUser(ctx context.Context, userID int) (dto.User, error) {
+	c.mu.Lock()
+	cached, ok := c.cache[userID]
+	if ok && time.Now().After(cached.expires) {
+		delete(c.cache, userID)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		return cached.user, nil
+	}
+
+	user, err := c.fetchUser(ctx, userID)
+	if err != nil {
+		return dto.User{}, err
+	}
+
+	c.mu.Lock()
+	c.cache[userID] = cachedUser{user: user, expires: time.Now().Add(usersCacheTTL)}
+	c.mu.Unlock()
+	return user, nil
+}
+
+// Forget descarta el usuario guardado, para que la próxima consulta vaya a users-api
+func (c *UsersClient) Forget(userID int) {
+	c.mu.Lock()
+	delete(c.cache, userID)
+	c.mu.Unlock()
+}
+
+func (c *UsersClient) fetchUser(ctx context.Context, userID int) (dto.User, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/users/%d", c.baseURL, userID), nil)
+	if err != nil {
+		return dto.User{}, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return dto.User{}, fmt.Errorf("%w: %v", errors.ErrUsersUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return dto.User{}, errors.ErrUserNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return dto.User{}, fmt.Errorf("%w: users-api returned status %d", errors.ErrUsersUnavailable, resp.StatusCode)
+	}
+
+	var user dto.User
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return dto.User{}, fmt.Errorf("%w: error decoding user: %v", errors.ErrUsersUnavailable, err)
+	}
+	return user, nil
+}
+
+// GetUsers busca varios usuarios en una sola llamada a POST /users/many (sin límite de largo de la
+// URL) y omite los que no existen. La respuesta no incluye is_admin.
+func (c *UsersClient) GetUsers(ctx context.Context, userIDs []int) ([]dto.User, error) {
+	body, err := json.Marshal(map[string][]int{"ids": userIDs})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/users/many", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrUsersUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: users-api returned status %d", errors.ErrUsersUnavailable, resp.StatusCode)
+	}
+
+	var result struct {
+		Users []dto.User `json:"users"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: error decoding users: %v", errors.ErrUsersUnavailable, err)
+	}
+	return result.Users, nil
+}
+
+// Entitlement consulta si el usuario tiene un plan vigente en la fecha y su cuota semanal. Sin
+// clave interna devuelve ErrMembershipChecksDisabled.
+func (c *UsersClient) Entitlement(ctx context.Context, userID, fecha string) (dto.Entitlement, error) {
+	if c.internalKey == "" {
+		return dto.Entitlement{}, errors.ErrMembershipChecksDisabled
+	}
+	endpoint := fmt.Sprintf("%s/internal/users/%s/entitlement?fecha=%s", c.baseURL, url.PathEscape(userID), url.QueryEscape(fecha))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return dto.Entitlement{}, err
+	}
+	req.Header.Set(internalKeyHeader, c.internalKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return dto.Entitlement{}, fmt.Errorf("%w: %v", errors.ErrUsersUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return dto.Entitlement{}, errors.ErrUserNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return dto.Entitlement{}, fmt.Errorf("%w: users-api returned status %d", errors.ErrUsersUnavailable, resp.StatusCode)
+	}
+
+	var entitlement dto.Entitlement
+	if err := json.NewDecoder(resp.Body).Decode(&entitlement); err != nil {
+		return dto.Entitlement{}, fmt.Errorf("%w: error decoding entitlement: %v", errors.ErrUsersUnavailable, err)
+	}
+	return entitlement, nil
+}
@@ -11,6 +11,14 @@ type Config struct {
 	Mongo     MongoConfig
 	RabbitMQ  RabbitMQConfig
 	JwtSecret string
+	Timezone  string
+	Users     UsersConfig
+}
+
+type UsersConfig struct {
+	APIURL string
+	// clave para los endpoints internos de users-api; sin clave no se verifican los planes
+	InternalAPIKey string
 }
 
 type MongoConfig struct {
@@ -24,6 +32,10 @@ type RabbitMQConfig struct {
 	User      string
 	Pass      string
 	QueueName string
+	// cola de eventos de clases (cancelaciones, reprogramaciones) para el servicio de notificaciones
+	NotificationsQueueName string
+	// cola de eventos de usuarios (user.deleted) que publica users-api
+	UsersQueueName string
 }
 
 func Load() Config {
@@ -52,10 +64,19 @@ func Load() Config {
 			User:      getEnv("RABBITMQ_USER", "admin"),
 			Pass:      getEnv("RABBITMQ_PASS", "admin"),
 			QueueName: getEnv("RABBITMQ_QUEUE_NAME", "items"),
+
+			NotificationsQueueName: getEnv("RABBITMQ_NOTIFICATIONS_QUEUE", "notifications"),
+			UsersQueueName:         getEnv("RABBITMQ_USERS_QUEUE", "users"),
 		},
 		// Solr indexing is handled by the search service; activities service
 		// does not need Solr configuration anymore.
 		JwtSecret: secret,
+		// zona horaria en la que se interpretan las fechas y horarios de las clases
+		Timezone: getEnv("TIMEZONE", "America/Argentina/Buenos_Aires"),
+		Users: UsersConfig{
+			APIURL:         getEnv("USERS_API_URL", "http://users-api:8080"),
+			InternalAPIKey: getEnv("INTERNAL_API_KEY", ""),
+		},
 	}
 
 	log.Infoln("=== variables de entorno ===")
@@ -65,7 +86,12 @@ func Load() Config {
 	log.Infoln("RABBITMQ_HOST:", cfg.RabbitMQ.Host)
 	log.Infoln("RABBITMQ_PORT:", cfg.RabbitMQ.Port)
 	log.Infoln("RABBITMQ_QUEUE:", cfg.RabbitMQ.QueueName)
+	log.Infoln("RABBITMQ_NOTIFICATIONS_QUEUE:", cfg.RabbitMQ.NotificationsQueueName)
+	log.Infoln("RABBITMQ_USERS_QUEUE:", cfg.RabbitMQ.UsersQueueName)
 	log.Infoln("JWT_SECRET:", cfg.JwtSecret)
+	log.Infoln("TIMEZONE:", cfg.Timezone)
+	log.Infoln("USERS_API_URL:", cfg.Users.APIURL)
+	log.Infoln("INTERNAL_API_KEY:", cfg.Users.InternalAPIKey)
 	log.Infoln("==================================")
 	return cfg
 }
@@ -8,7 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
@@ -17,16 +19,48 @@ import (
 
 type ActivitiesService interface {
 	List(ctx context.Context) ([]dto.Activity, error)
+	ListPage(ctx context.Context, filters dto.ActivityListFilters) (dto.ActivitiesPage, error)
 	GetMany(ctx context.Context, ids []string) ([]dto.Activity, error)
 	Create(ctx context.Context, actividad dto.ActivityAdministration) (dto.ActivityAdministration, error)
 	GetByID(ctx context.Context, id string) (dto.ActivityAdministration, error)
 	Update(ctx context.Context, id string, actividad dto.ActivityAdministration) (dto.ActivityAdministration, error)
+	Patch(ctx context.Context, id string, patch []byte, version int64) (dto.ActivityAdministration, error)
 	Delete(ctx context.Context, id string) error
+	Deactivate(ctx context.Context, id string) (dto.ActivityAdministration, error)
+	Reactivate(ctx context.Context, id string) (dto.ActivityAdministration, error)
+	Purge(ctx context.Context, id string) error
 	Inscribir(ctx context.Context, id string, userID string) (string, error)
 	Desinscribir(ctx context.Context, id string, userID string) (string, error)
 	GetInscripcionesByUserID(ctx context.Context, userID string) ([]string, error)
 	GetActivitiesByUserID(ctx context.Context, userID string) (dto.Activities, error)
-	GetStatistics(ctx context.Context) (dto.ActivityStatistics, error)
+	GetStatistics(ctx context.Context, filters dto.StatisticsFilters) (dto.ActivityStatistics, error)
+	OccupancyHeatmap(ctx context.Context) (dto.OccupancyHeatmap, error)
+	NoShowRates(ctx context.Context, filters dto.StatisticsFilters) (dto.NoShowReport, error)
+	ListHistory(ctx context.Context, filters dto.HistoryFilters) (dto.HistoryPage, error)
+	ListOccurrences(ctx context.Context, activityID, desde, hasta string) ([]dto.Occurrence, error)
+	InscribirOccurrence(ctx context.Context, activityID, fecha, userID string) error
+	DesinscribirOccurrence(ctx context.Context, activityID, fecha, userID string) error
+	CancelOccurrence(ctx context.Context, activityID, fecha, motivo string) (dto.Occurrence, error)
+	RescheduleOccurrence(ctx context.Context, activityID, fecha string, req dto.OccurrenceReschedule) (dto.Occurrence, error)
+	UpcomingOccurrences(ctx context.Context, from, to time.Time) ([]dto.Occurrence, error)
+	ListRooms(ctx context.Context) ([]dto.Room, error)
+	GetRoom(ctx context.Context, id string) (dto.Room, error)
+	CreateRoom(ctx context.Context, room dto.Room) (dto.Room, error)
+	UpdateRoom(ctx context.Context, id string, room dto.Room) (dto.Room, error)
+	DeleteRoom(ctx context.Context, id string) error
+	RecordAttendance(ctx context.Context, activityID, fecha string, presentes []int) (dto.Attendance, error)
+	GetEnrollmentHistory(ctx context.Context, userID string) (dto.EnrollmentHistory, error)
+	CalendarToken(ctx context.Context, userID string, rotate bool) (string, error)
+	Calendar(ctx context.Context, token string) (string, error)
+	WeeklySchedule(ctx context.Context, week string) (dto.WeeklySchedule, error)
+	ScheduleCalendar(ctx context.Context) (string, error)
+	ImportActivities(ctx context.Context, rows []dto.ImportRow, dryRun bool) (dto.ImportReport, error)
+	ExportActivities(ctx context.Context) ([]dto.ActivityAdministration, error)
+	GetRoster(ctx context.Context, id, fecha string) (dto.ActivityRoster, error)
+	AddToRoster(ctx context.Context, id string, userIDs []int) (dto.RosterResult, error)
+	RemoveFromRoster(ctx context.Context, id string, userID int) (dto.RosterResult, error)
+	ImportRoster(ctx context.Context, id string, userIDs []int, replace bool) (dto.RosterResult, error)
+	MoveEnrollment(ctx context.Context, from, to string, userID int) error
 }
 
 type ActivitiesController struct {
@@ -83,6 +117,49 @@ func isAdminFromClaims(claims jwt.MapClaims) bool {
 	return false
 }
 
+// trustedServices son los servicios que pueden usar los endpoints internos con un token de servicio
+var trustedServices = map[string]bool{
+	"notifications": true,
+}
+
+// isServiceFromClaims indica si el token es de uno de los trustedServices, firmado con el mismo
+// JWT_SECRET y con su nombre en el claim "service"
+func isServiceFromClaims(claims jwt.MapClaims) bool {
+	if claims == nil {
+		return false
+	}
+	service, _ := claims["service"].(string)
+	return trustedServices[service]
+}
+
+// actorFromClaims arma el actor que se registra en el historial a partir de los claims del token
+func actorFromClaims(claims jwt.MapClaims) dto.Actor {
+	id, _ := getUserIDFromClaims(claims)
+	username, _ := claims["username"].(string)
+	return dto.Actor{ID: id, Username: username, IsAdmin: isAdminFromClaims(claims)}
+}
+
+// requestContext devuelve el contexto del request con el actor para el historial
+func requestContext(ctx *gin.Context, claims jwt.MapClaims) context.Context {
+	return services.WithActor(ctx.Request.Context(), actorFromClaims(claims))
+}
+
+// etag arma el valor del header ETag a partir de la versión de la actividad
+func etag(version int64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// parseIfMatch obtiene la versión esperada del header If-Match (acepta ETags débiles)
+func parseIfMatch(header string) (int64, error) {
+	value := strings.TrimPrefix(strings.TrimSpace(header), "W/")
+	value = strings.Trim(value, `"`)
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("If-Match must be the ETag returned by GET /activities/:id")
+	}
+	return version, nil
+}
+
 func splitAndTrim(s string, sep string) []string {
 	var result []string
 	parts := strings.Split(s, sep)
@@ -98,17 +175,130 @@ func splitAndTrim(s string, sep string) []string {
 
 // Authentication is handled by middleware; handlers can read claims from context if needed
 
+func parseOptionalBool(ctx *gin.Context, key string) (*bool, error) {
+	raw := ctx.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be true or false", key)
+	}
+	return &v, nil
+}
+
+func parseOptionalInt(ctx *gin.Context, key string) (int, error) {
+	raw := ctx.Query(key)
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", key)
+	}
+	return v, nil
+}
+
 // GetActivities maneja GET /activities
+//
+// Por defecto devuelve una página (page, limit) de actividades activas con filtros (dia,
+// instructor, hora_desde, hora_hasta, disponible) y orden (sort=campo1,-campo2). Con all=true devuelve el
+// listado completo sin paginar, como antes, para compatibilidad con clientes existentes.
 func (c *ActivitiesController) GetActivities(ctx *gin.Context) {
-	activities, err := c.service.List(ctx.Request.Context())
+	if all, _ := strconv.ParseBool(ctx.Query("all")); all {
+		activities, err := c.service.List(ctx.Request.Context())
+		if err != nil {
+			log.Errorf("error al obtener todas las actividades: %v", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activities", "details": err.Error()})
+			return
+		}
+
+		log.Infof("actividades obtenidas exitosamente")
+		ctx.JSON(http.StatusOK, gin.H{"activities": activities, "count": len(activities)})
+		return
+	}
+
+	filters, err := parseListFilters(ctx)
+	if err != nil {
+		log.Warnf("parametros invalidos al listar actividades: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+	// el listado público solo muestra actividades activas
+	activa := true
+	filters.Activa = &activa
+
+	c.listPage(ctx, filters)
+}
+
+// GetActivitiesAdmin maneja GET /activities/admin (solo admin)
+//
+// Acepta los mismos parámetros que GetActivities y además activa=true|false; sin ese filtro
+// incluye tanto las actividades activas como las dadas de baja.
+func (c *ActivitiesController) GetActivitiesAdmin(ctx *gin.Context) {
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return
+	}
+	if !isAdminFromClaims(claims) {
+		log.Warnf("operacion sin privilegios para el usuario: %s@%s", claims["username"], ctx.RemoteIP())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only admin users can list inactive activities"})
+		return
+	}
+
+	filters, err := parseListFilters(ctx)
+	if err == nil {
+		filters.Activa, err = parseOptionalBool(ctx, "activa")
+	}
+	if err != nil {
+		log.Warnf("parametros invalidos al listar actividades: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	c.listPage(ctx, filters)
+}
+
+func parseListFilters(ctx *gin.Context) (dto.ActivityListFilters, error) {
+	filters := dto.ActivityListFilters{
+		Dia:        ctx.Query("dia"),
+		Instructor: ctx.Query("instructor"),
+		HoraDesde:  ctx.Query("hora_desde"),
+		HoraHasta:  ctx.Query("hora_hasta"),
+		Sort:       splitAndTrim(ctx.Query("sort"), ","),
+	}
+
+	var err error
+	var parseErrs []error
+	if filters.Page, err = parseOptionalInt(ctx, "page"); err != nil {
+		parseErrs = append(parseErrs, err)
+	}
+	if filters.Limit, err = parseOptionalInt(ctx, "limit"); err != nil {
+		parseErrs = append(parseErrs, err)
+	}
+	if filters.Disponible, err = parseOptionalBool(ctx, "disponible"); err != nil {
+		parseErrs = append(parseErrs, err)
+	}
+	return filters, errors.Join(parseErrs...)
+}
+
+func (c *ActivitiesController) listPage(ctx *gin.Context, filters dto.ActivityListFilters) {
+	page, err := c.service.ListPage(ctx.Request.Context(), filters)
 	if err != nil {
-		log.Errorf("error al obtener todas las actividades: %v", err)
+		if errors.Is(err, services.ErrValidation) {
+			log.Warnf("parametros invalidos al listar actividades: %v", err)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+		log.Errorf("error al obtener actividades: %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activities", "details": err.Error()})
 		return
 	}
 
-	log.Infof("actividades obtenidas exitosamente")
-	ctx.JSON(http.StatusOK, gin.H{"activities": activities, "count": len(activities)})
+	log.Infof("pagina %d de actividades obtenida exitosamente: %d de %d", page.Page, page.Count, page.Total)
+	ctx.JSON(http.StatusOK, page)
 }
 
 // GetManyActivities maneja GET /activities/many?ids=id1,id2,id3
@@ -165,8 +355,13 @@ func (c *ActivitiesController) CreateActivity(ctx *gin.Context) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": "only admin users can create activities"})
 		return
 	}
-	created, err := c.service.Create(ctx.Request.Context(), newAct)
+	created, err := c.service.Create(requestContext(ctx, claims), newAct)
 	if err != nil {
+		if errors.Is(err, services.ErrRoomConflict) {
+			log.Warnf("sala ocupada al crear actividad: %v", err)
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Room is already booked at that time", "details": err.Error()})
+			return
+		}
 		if errors.Is(err, services.ErrValidation) {
 			log.Warnf("error de validación al crear actividad: %v", err)
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
@@ -209,6 +404,8 @@ func (c *ActivitiesController) GetActivityByID(ctx *gin.Context) {
 		return
 	}
 
+	ctx.Header("ETag", etag(actAdmin.Version))
+
 	// If requester is admin, return the administration DTO (with users_inscritos, fecha)
 	if isAdminFromClaims(claims) {
 		log.Infof("actividad %s (admin view) obtenida exitosamente por usuario: %s", id, claims["username"])
@@ -216,6 +413,13 @@ func (c *ActivitiesController) GetActivityByID(ctx *gin.Context) {
 		return
 	}
 
+	// Las actividades dadas de baja solo son visibles para admins
+	if !actAdmin.Activa {
+		log.Warnf("actividad inactiva solicitada por usuario no admin: %s", id)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		return
+	}
+
 	// Non-admin users: return the public DTO (sin datos sensibles como usuarios inscritos)
 	public := dto.Activity{
 		ID:                 actAdmin.ID,
@@ -228,6 +432,9 @@ func (c *ActivitiesController) GetActivityByID(ctx *gin.Context) {
 		CapacidadMax:       actAdmin.CapacidadMax,
 		LugaresDisponibles: actAdmin.LugaresDisponibles,
 		FotoUrl:            actAdmin.FotoUrl,
+		Recurrencia:        actAdmin.Recurrencia,
+		SalaID:             actAdmin.SalaID,
+		Recurso:            actAdmin.Recurso,
 	}
 
 	log.Infof("actividad %s (public view) obtenida exitosamente por usuario: %s", id, claims["username"])
@@ -263,7 +470,7 @@ func (c *ActivitiesController) Inscribir(ctx *gin.Context) {
 		return
 	}
 
-	_, err := c.service.Inscribir(ctx.Request.Context(), activityID, uid)
+	_, err := c.service.Inscribir(requestContext(ctx, claims), activityID, uid)
 	if err != nil {
 		if errors.Is(err, repository.ErrActivityNotFound) {
 			log.Warnf("actividad no encontrada para inscribir: %s", activityID)
@@ -271,6 +478,16 @@ func (c *ActivitiesController) Inscribir(ctx *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, repository.ErrActivityInactive) {
+			log.Warnf("intento de inscripcion en actividad inactiva: %s", activityID)
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Activity is not active"})
+			return
+		}
+
+		if respondEnrollmentWindowError(ctx, err, activityID) || respondEntitlementError(ctx, err, uid, activityID) {
+			return
+		}
+
 		if errors.Is(err, repository.ErrActivityFull) {
 			log.Warnf("actividad llena: %s", activityID)
 			ctx.JSON(http.StatusConflict, gin.H{"error": "Activity is full"})
@@ -321,7 +538,7 @@ func (c *ActivitiesController) Desinscribir(ctx *gin.Context) {
 		return
 	}
 
-	_, err := c.service.Desinscribir(ctx.Request.Context(), activityID, uid)
+	_, err := c.service.Desinscribir(requestContext(ctx, claims), activityID, uid)
 	if err != nil {
 		if errors.Is(err, repository.ErrActivityNotFound) {
 			log.Warnf("actividad no encontrada para desinscribir: %s", activityID)
@@ -345,6 +562,9 @@ func (c *ActivitiesController) Desinscribir(ctx *gin.Context) {
 }
 
 // UpdateActivity maneja PUT /activities/:id
+//
+// Requiere el header If-Match con el ETag obtenido en GET /activities/:id. Si la actividad
+// cambió desde entonces responde 412 y no aplica la modificación.
 func (c *ActivitiesController) UpdateActivity(ctx *gin.Context) {
 	var toUpdate dto.ActivityAdministration
 	if err := ctx.ShouldBindJSON(&toUpdate); err != nil {
@@ -372,13 +592,99 @@ func (c *ActivitiesController) UpdateActivity(ctx *gin.Context) {
 		return
 	}
 
-	updated, err := c.service.Update(ctx.Request.Context(), id, toUpdate)
+	version, ok := requireIfMatch(ctx, id)
+	if !ok {
+		return
+	}
+	toUpdate.Version = version
+
+	updated, err := c.service.Update(requestContext(ctx, claims), id, toUpdate)
+	c.respondUpdate(ctx, id, claims, updated, err)
+}
+
+// PatchActivity maneja PATCH /activities/:id
+//
+// El body es un JSON Merge Patch (RFC 7386): solo se modifican los campos enviados y un null
+// borra el campo. La validación se aplica sobre la actividad resultante. Igual que PUT,
+// requiere If-Match con el ETag de la actividad.
+func (c *ActivitiesController) PatchActivity(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		log.Warnf("peticion de actualizacion sin ID de actividad")
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID parameter is required"})
+		return
+	}
+
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return
+	}
+	if !isAdminFromClaims(claims) {
+		log.Warnf("operacion sin privilegios para el usuario: %s@%s", claims["username"], ctx.RemoteIP())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only admin users can update activities"})
+		return
+	}
+
+	contentType := ctx.ContentType()
+	if contentType != "application/merge-patch+json" && contentType != "application/json" {
+		log.Warnf("content type no soportado en PATCH de actividad %s: %s", id, contentType)
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be application/merge-patch+json"})
+		return
+	}
+
+	version, ok := requireIfMatch(ctx, id)
+	if !ok {
+		return
+	}
+
+	patch, err := ctx.GetRawData()
+	if err != nil {
+		log.Warnf("error al leer body del PATCH: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	updated, err := c.service.Patch(requestContext(ctx, claims), id, patch, version)
+	c.respondUpdate(ctx, id, claims, updated, err)
+}
+
+// requireIfMatch obtiene la versión del header If-Match; si falta o es inválido responde y devuelve false
+func requireIfMatch(ctx *gin.Context, id string) (int64, bool) {
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" {
+		log.Warnf("actualizacion de actividad %s sin If-Match", id)
+		ctx.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+		return 0, false
+	}
+	version, err := parseIfMatch(ifMatch)
+	if err != nil {
+		log.Warnf("If-Match invalido al actualizar actividad %s: %s", id, ifMatch)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header", "details": err.Error()})
+		return 0, false
+	}
+	return version, true
+}
+
+// respondUpdate arma la respuesta de PUT y PATCH
+func (c *ActivitiesController) respondUpdate(ctx *gin.Context, id string, claims jwt.MapClaims, updated dto.ActivityAdministration, err error) {
 	if err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			log.Warnf("conflicto de version al actualizar actividad %s: %v", id, err)
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "Activity was modified by another request, reload it and try again"})
+			return
+		}
 		if errors.Is(err, repository.ErrActivityNotFound) {
 			log.Warnf("actividad no encontrada para actualizar: %s", id)
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
 			return
 		}
+		if errors.Is(err, services.ErrRoomConflict) {
+			log.Warnf("sala ocupada al actualizar actividad %s: %v", id, err)
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Room is already booked at that time", "details": err.Error()})
+			return
+		}
 		if errors.Is(err, services.ErrValidation) {
 			log.Warnf("error de validación al actualizar actividad %s: %v", id, err)
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
@@ -390,10 +696,14 @@ func (c *ActivitiesController) UpdateActivity(ctx *gin.Context) {
 	}
 
 	log.Infof("actividad %s actualizada exitosamente por usuario: %s", id, claims["username"])
+	ctx.Header("ETag", etag(updated.Version))
 	ctx.JSON(http.StatusOK, gin.H{"activity": updated})
 }
 
 // DeleteActivity maneja DELETE /activities/:id
+//
+// Por defecto da de baja la actividad (soft delete). Con purge=true la elimina definitivamente
+// junto con sus inscripciones.
 func (c *ActivitiesController) DeleteActivity(ctx *gin.Context) {
 	id := ctx.Param("id")
 	if id == "" {
@@ -414,7 +724,19 @@ func (c *ActivitiesController) DeleteActivity(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.service.Delete(ctx.Request.Context(), id); err != nil {
+	purge, err := strconv.ParseBool(ctx.DefaultQuery("purge", "false"))
+	if err != nil {
+		log.Warnf("parametro purge invalido: %s", ctx.Query("purge"))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "purge must be true or false"})
+		return
+	}
+
+	if purge {
+		err = c.service.Purge(requestContext(ctx, claims), id)
+	} else {
+		err = c.service.Delete(requestContext(ctx, claims), id)
+	}
+	if err != nil {
 		if errors.Is(err, repository.ErrActivityNotFound) {
 			log.Warnf("actividad no encontrada para eliminar: %s", id)
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
@@ -425,10 +747,73 @@ func (c *ActivitiesController) DeleteActivity(ctx *gin.Context) {
 		return
 	}
 
-	log.Infof("actividad %s eliminada exitosamente por usuario: %s", id, claims["username"])
+	if purge {
+		log.Infof("actividad %s eliminada definitivamente por usuario: %s", id, claims["username"])
+	} else {
+		log.Infof("actividad %s dada de baja por usuario: %s", id, claims["username"])
+	}
 	ctx.Status(http.StatusNoContent)
 }
 
+// DeactivateActivity maneja POST /activities/:id/desactivar
+func (c *ActivitiesController) DeactivateActivity(ctx *gin.Context) {
+	c.setActiva(ctx, false)
+}
+
+// ReactivateActivity maneja POST /activities/:id/activar
+func (c *ActivitiesController) ReactivateActivity(ctx *gin.Context) {
+	c.setActiva(ctx, true)
+}
+
+func (c *ActivitiesController) setActiva(ctx *gin.Context, activa bool) {
+	id := ctx.Param("id")
+	if id == "" {
+		log.Warnf("peticion de cambio de estado sin ID de actividad")
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID parameter is required"})
+		return
+	}
+
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return
+	}
+	if !isAdminFromClaims(claims) {
+		log.Warnf("operacion sin privilegios para el usuario: %s@%s", claims["username"], ctx.RemoteIP())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only admin users can activate or deactivate activities"})
+		return
+	}
+
+	var (
+		updated dto.ActivityAdministration
+		err     error
+	)
+	if activa {
+		updated, err = c.service.Reactivate(requestContext(ctx, claims), id)
+	} else {
+		updated, err = c.service.Deactivate(requestContext(ctx, claims), id)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrActivityNotFound) {
+			log.Warnf("actividad no encontrada para cambiar de estado: %s", id)
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		if errors.Is(err, services.ErrRoomConflict) || errors.Is(err, services.ErrValidation) {
+			log.Warnf("no se puede reactivar la actividad %s en su sala: %v", id, err)
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Activity room is not available", "details": err.Error()})
+			return
+		}
+		log.Errorf("error al cambiar estado de actividad %s: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update activity status", "details": err.Error()})
+		return
+	}
+
+	log.Infof("actividad %s marcada activa=%t por usuario: %s", id, activa, claims["username"])
+	ctx.JSON(http.StatusOK, gin.H{"activity": updated})
+}
+
 // GetInscripcionesByUserID maneja GET /inscriptions/:userId
 func (c *ActivitiesController) GetInscripcionesByUserID(ctx *gin.Context) {
 	userID := ctx.Param("userId")
@@ -521,7 +906,8 @@ func (c *ActivitiesController) GetInscribedActivities(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"activities": activities, "count": len(activities)})
 }
 
-// GetStatistics obtiene estadísticas de actividades (solo admin)
+// GetStatistics obtiene estadísticas de actividades (solo admin). from y to (YYYY-MM-DD) limitan
+// el período de la tendencia de inscripciones; limit es el largo de los rankings de popularidad.
 func (c *ActivitiesController) GetStatistics(ctx *gin.Context) {
 	claims, ok := getClaimsFromContext(ctx)
 	if !ok {
@@ -536,8 +922,22 @@ func (c *ActivitiesController) GetStatistics(ctx *gin.Context) {
 		return
 	}
 
-	stats, err := c.service.GetStatistics(ctx.Request.Context())
+	filters := dto.StatisticsFilters{Desde: ctx.Query("from"), Hasta: ctx.Query("to")}
+	limit, err := parseOptionalInt(ctx, "limit")
+	if err != nil {
+		log.Warnf("parametros invalidos para estadísticas: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+	filters.Limit = limit
+
+	stats, err := c.service.GetStatistics(ctx.Request.Context(), filters)
 	if err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			log.Warnf("parametros invalidos para estadísticas: %v", err)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
 		log.WithError(err).Error("error al obtener estadísticas")
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch statistics", "details": err.Error()})
 		return
@@ -546,3 +946,71 @@ func (c *ActivitiesController) GetStatistics(ctx *gin.Context) {
 	log.Info("estadísticas obtenidas exitosamente")
 	ctx.JSON(http.StatusOK, stats)
 }
+
+// GetActivityHistory maneja GET /activities/:id/history (solo admin)
+func (c *ActivitiesController) GetActivityHistory(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		log.Warnf("peticion de historial sin ID de actividad")
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID parameter is required"})
+		return
+	}
+
+	c.listHistory(ctx, dto.HistoryFilters{ActivityID: id})
+}
+
+// GetUserHistory maneja GET /history/users/:userId (solo admin)
+func (c *ActivitiesController) GetUserHistory(ctx *gin.Context) {
+	userID := ctx.Param("userId")
+	if userID == "" {
+		log.Warnf("peticion de historial sin userId")
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "userId parameter is required"})
+		return
+	}
+
+	c.listHistory(ctx, dto.HistoryFilters{UserID: userID})
+}
+
+func (c *ActivitiesController) listHistory(ctx *gin.Context, filters dto.HistoryFilters) {
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return
+	}
+	if !isAdminFromClaims(claims) {
+		log.Warnf("operacion sin privilegios para el usuario: %s@%s", claims["username"], ctx.RemoteIP())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "forbidden: admin access required"})
+		return
+	}
+
+	var err error
+	var parseErrs []error
+	if filters.Page, err = parseOptionalInt(ctx, "page"); err != nil {
+		parseErrs = append(parseErrs, err)
+	}
+	if filters.Limit, err = parseOptionalInt(ctx, "limit"); err != nil {
+		parseErrs = append(parseErrs, err)
+	}
+	if len(parseErrs) > 0 {
+		err := errors.Join(parseErrs...)
+		log.Warnf("parametros invalidos al consultar historial: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+		return
+	}
+
+	page, err := c.service.ListHistory(ctx.Request.Context(), filters)
+	if err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			log.Warnf("parametros invalidos al consultar historial: %v", err)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+		log.Errorf("error al obtener historial: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch history", "details": err.Error()})
+		return
+	}
+
+	log.Infof("historial obtenido exitosamente: %d de %d registros", page.Count, page.Total)
+	ctx.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"activities/internal/dto"
+	"activities/internal/services"
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetOccupancyHeatmap maneja GET /activities/analytics/occupancy?format=json|csv (solo admin):
+// ocupación por día de la semana y hora
+func (c *ActivitiesController) GetOccupancyHeatmap(ctx *gin.Context) {
+	if _, ok := c.requireAnalyticsAdmin(ctx); !ok {
+		return
+	}
+	format, ok := analyticsFormat(ctx)
+	if !ok {
+		return
+	}
+
+	heatmap, err := c.service.OccupancyHeatmap(ctx.Request.Context())
+	if err != nil {
+		log.Errorf("error al calcular la ocupacion: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute occupancy", "details": err.Error()})
+		return
+	}
+
+	if format == dto.CatalogFormatJSON {
+		ctx.JSON(http.StatusOK, heatmap)
+		return
+	}
+	var buf bytes.Buffer
+	if err := services.WriteOccupancyCSV(&buf, heatmap); err != nil {
+		log.Errorf("error al escribir la ocupacion: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute occupancy", "details": err.Error()})
+		return
+	}
+	sendCSV(ctx, "ocupacion.csv", buf.Bytes())
+}
+
+// GetNoShowRates maneja GET /activities/analytics/no-shows?from=&to=&format=json|csv (solo admin):
+// ausentismo por actividad en las clases con asistencia tomada
+func (c *ActivitiesController) GetNoShowRates(ctx *gin.Context) {
+	if _, ok := c.requireAnalyticsAdmin(ctx); !ok {
+		return
+	}
+	format, ok := analyticsFormat(ctx)
+	if !ok {
+		return
+	}
+
+	filters := dto.StatisticsFilters{Desde: ctx.Query("from"), Hasta: ctx.Query("to")}
+	report, err := c.service.NoShowRates(ctx.Request.Context(), filters)
+	if err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			log.Warnf("parametros invalidos para ausentismo: %v", err)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+		log.Errorf("error al calcular el ausentismo: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute no-show rates", "details": err.Error()})
+		return
+	}
+
+	if format == dto.CatalogFormatJSON {
+		ctx.JSON(http.StatusOK, report)
+		return
+	}
+	var buf bytes.Buffer
+	if err := services.WriteNoShowsCSV(&buf, report); err != nil {
+		log.Errorf("error al escribir el ausentismo: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute no-show rates", "details": err.Error()})
+		return
+	}
+	sendCSV(ctx, "ausentismo_"+report.Desde+"_"+report.Hasta+".csv", buf.Bytes())
+}
+
+func analyticsFormat(ctx *gin.Context) (string, bool) {
+	format, err := services.ParseCatalogFormat(ctx.DefaultQuery("format", dto.CatalogFormatJSON))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format", "details": err.Error()})
+		return "", false
+	}
+	return format, true
+}
+
+func sendCSV(ctx *gin.Context, filename string, data []byte) {
+	ctx.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+func (c *ActivitiesController) requireAnalyticsAdmin(ctx *gin.Context) (jwt.MapClaims, bool) {
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return nil, false
+	}
+	if !isAdminFromClaims(claims) {
+		log.Warnf("consulta de analiticas sin privilegios para el usuario: %s@%s", claims["username"], ctx.RemoteIP())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only admin users can view analytics"})
+		return nil, false
+	}
+	return claims, true
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"activities/internal/dto"
+	"activities/internal/services"
+	"bytes"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+const maxImportSize = 5 << 20 // 5 MB
+
+// ImportActivities maneja POST /activities/import?format=csv|json&dry_run=true. El archivo va
+// como cuerpo de la petición o en el campo "file" de un formulario multipart. Responde el
+// informe de la importación: 400 si hay filas inválidas (no se importa ninguna).
+func (c *ActivitiesController) ImportActivities(ctx *gin.Context) {
+	claims, ok := c.requireCatalogAdmin(ctx)
+	if !ok {
+		return
+	}
+
+	dryRun := false
+	if value := ctx.Query("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize)
+	body, format, err := importFile(ctx)
+	if err != nil {
+		log.Warnf("archivo de importacion invalido: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import file", "details": err.Error()})
+		return
+	}
+	if format, err = services.ParseCatalogFormat(format); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format", "details": err.Error()})
+		return
+	}
+
+	rows, err := services.ParseActivities(format, body)
+	if err != nil {
+		log.Warnf("archivo de importacion invalido: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import file", "details": err.Error()})
+		return
+	}
+
+	report, err := c.service.ImportActivities(requestContext(ctx, claims), rows, dryRun)
+	if err != nil {
+		log.Errorf("error al importar actividades: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import activities", "details": err.Error()})
+		return
+	}
+
+	if !dryRun && !report.Aplicado {
+		log.Warnf("importacion rechazada: %d filas invalidas", report.ConErrores)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Import has invalid rows", "report": report})
+		return
+	}
+	log.Infof("importacion de %d filas (dry_run=%t) por usuario: %s", report.Total, dryRun, claims["username"])
+	ctx.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// importFile obtiene el archivo a importar y su formato: el parámetro format o, si no se indica,
+// el tipo de contenido o la extensión del archivo subido
+func importFile(ctx *gin.Context) (io.Reader, string, error) {
+	format := ctx.Query("format")
+
+	if strings.HasPrefix(ctx.ContentType(), "multipart/form-data") {
+		header, err := ctx.FormFile("file")
+		if err != nil {
+			return nil, "", err
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, "", err
+		}
+		if format == "" {
+			format = strings.TrimPrefix(filepath.Ext(header.Filename), ".")
+		}
+		return bytes.NewReader(data), format, nil
+	}
+
+	if format == "" {
+		switch ctx.ContentType() {
+		case "text/csv":
+			format = dto.CatalogFormatCSV
+		case "application/json":
+			format = dto.CatalogFormatJSON
+		}
+	}
+	data, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(data), format, nil
+}
+
+// ExportActivities maneja GET /activities/export?format=csv|json (por defecto json): catálogo de
+// actividades activas en el formato que acepta la importación
+func (c *ActivitiesController) ExportActivities(ctx *gin.Context) {
+	if _, ok := c.requireCatalogAdmin(ctx); !ok {
+		return
+	}
+
+	format, err := services.ParseCatalogFormat(ctx.DefaultQuery("format", dto.CatalogFormatJSON))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format", "details": err.Error()})
+		return
+	}
+
+	activities, err := c.service.ExportActivities(ctx.Request.Context())
+	if err != nil {
+		log.Errorf("error al exportar actividades: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export activities", "details": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteActivities(format, &buf, activities); err != nil {
+		log.Errorf("error al escribir el catalogo: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export activities", "details": err.Error()})
+		return
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if format == dto.CatalogFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	ctx.Header("Content-Disposition", `attachment; filename="actividades.`+format+`"`)
+	ctx.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+func (c *ActivitiesController) requireCatalogAdmin(ctx *gin.Context) (jwt.MapClaims, bool) {
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return nil, false
+	}
+	if !isAdminFromClaims(claims) {
+		log.Warnf("importacion/exportacion sin privilegios para el usuario: %s@%s", claims["username"], ctx.RemoteIP())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only admin users can import or export activities"})
+		return nil, false
+	}
+	return claims, true
+}
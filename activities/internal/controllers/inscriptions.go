@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"activities/internal/repository"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetEnrollmentHistory maneja GET /inscriptions/history/:userId: inscripciones actuales y pasadas
+// del usuario y su asistencia a las clases
+func (c *ActivitiesController) GetEnrollmentHistory(ctx *gin.Context) {
+	userID := ctx.Param("userId")
+	if _, ok := requireSelfOrAdmin(ctx, userID); !ok {
+		return
+	}
+
+	history, err := c.service.GetEnrollmentHistory(ctx.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidUserID) {
+			log.Warnf("userId invalido al obtener historial de inscripciones: %s", userID)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id format"})
+			return
+		}
+		log.Errorf("error al obtener historial de inscripciones de usuario %s: %v", userID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch enrollment history", "details": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
+
+// GetCalendarToken maneja GET /inscriptions/calendar/:userId: devuelve la URL del calendario
+// (.ics) del usuario, generando el token la primera vez
+func (c *ActivitiesController) GetCalendarToken(ctx *gin.Context) {
+	c.calendarToken(ctx, false)
+}
+
+// RotateCalendarToken maneja POST /inscriptions/calendar/:userId: genera un token nuevo e
+// invalida la URL anterior
+func (c *ActivitiesController) RotateCalendarToken(ctx *gin.Context) {
+	c.calendarToken(ctx, true)
+}
+
+func (c *ActivitiesController) calendarToken(ctx *gin.Context, rotate bool) {
+	userID := ctx.Param("userId")
+	if _, ok := requireSelfOrAdmin(ctx, userID); !ok {
+		return
+	}
+
+	token, err := c.service.CalendarToken(ctx.Request.Context(), userID, rotate)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidUserID) {
+			log.Warnf("userId invalido al obtener token de calendario: %s", userID)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id format"})
+			return
+		}
+		log.Errorf("error al obtener token de calendario de usuario %s: %v", userID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get calendar token", "details": err.Error()})
+		return
+	}
+
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"id_usuario": userID,
+		"token":      token,
+		"url":        scheme + "://" + ctx.Request.Host + "/calendar/" + token + ".ics",
+	})
+}
+
+// GetCalendar maneja GET /calendar/:token: calendario iCalendar del usuario para suscribirse
+// desde una aplicación de calendario. El token reemplaza al JWT (las aplicaciones no lo envían).
+func (c *ActivitiesController) GetCalendar(ctx *gin.Context) {
+	token := strings.TrimSuffix(ctx.Param("token"), ".ics")
+
+	calendar, err := c.service.Calendar(ctx.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, repository.ErrCalendarTokenNotFound) {
+			log.Warnf("token de calendario invalido desde %s", ctx.RemoteIP())
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+			return
+		}
+		log.Errorf("error al generar calendario: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build calendar", "details": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", `inline; filename="clases.ics"`)
+	ctx.Header("Cache-Control", "private, max-age=300")
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(calendar))
+}
+
+// requireSelfOrAdmin permite la operación solo al propio usuario o a un admin
+func requireSelfOrAdmin(ctx *gin.Context, userID string) (jwt.MapClaims, bool) {
+	if userID == "" {
+		log.Warnf("peticion sin userId")
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "userId parameter is required"})
+		return nil, false
+	}
+
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return nil, false
+	}
+
+	requesterID, ok := getUserIDFromClaims(claims)
+	if !ok {
+		log.Warnf("id de usuario invalido en claims del token")
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id in token claims"})
+		return nil, false
+	}
+
+	if requesterID != userID && !isAdminFromClaims(claims) {
+		log.Warnf("usuario %s intento acceder a datos de usuario %s sin permisos", requesterID, userID)
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "cannot access other user's inscriptions"})
+		return nil, false
+	}
+	return claims, true
+}
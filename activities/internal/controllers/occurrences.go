@@ -0,0 +1,395 @@
+package controllers
+
+import (
+	"activities/internal/dto"
+	"activities/internal/repository"
+	"activities/internal/services"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetOccurrences maneja GET /activities/:id/occurrences?desde=YYYY-MM-DD&hasta=YYYY-MM-DD
+func (c *ActivitiesController) GetOccurrences(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if id == "" {
+		log.Warnf("peticion de ocurrencias sin ID de actividad")
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID parameter is required"})
+		return
+	}
+
+	occurrences, err := c.service.ListOccurrences(ctx.Request.Context(), id, ctx.Query("desde"), ctx.Query("hasta"))
+	if err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			log.Warnf("parametros invalidos al listar ocurrencias de %s: %v", id, err)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrActivityNotFound) || errors.Is(err, repository.ErrActivityInactive) {
+			log.Warnf("actividad no encontrada para listar ocurrencias: %s", id)
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+			return
+		}
+		log.Errorf("error al obtener ocurrencias de actividad %s: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch occurrences", "details": err.Error()})
+		return
+	}
+
+	// el listado es público: no se exponen los usuarios inscriptos
+	for i := range occurrences {
+		occurrences[i].UsersInscribed = nil
+	}
+
+	log.Infof("%d ocurrencias de actividad %s obtenidas exitosamente", len(occurrences), id)
+	ctx.JSON(http.StatusOK, gin.H{"occurrences": occurrences, "count": len(occurrences)})
+}
+
+// InscribirOccurrence maneja POST /activities/:id/occurrences/:fecha/inscribir
+func (c *ActivitiesController) InscribirOccurrence(ctx *gin.Context) {
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return
+	}
+
+	uid, ok := getUserIDFromClaims(claims)
+	if !ok {
+		log.Warnf("id de usuario invalido en claims del token")
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id in token claims"})
+		return
+	}
+
+	if isAdminFromClaims(claims) {
+		log.Warnf("intento de inscripcion por usuario admin: %s", claims["username"])
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "admin users cannot inscribe"})
+		return
+	}
+
+	activityID := ctx.Param("id")
+	fecha := ctx.Param("fecha")
+
+	if err := c.service.InscribirOccurrence(requestContext(ctx, claims), activityID, fecha, uid); err != nil {
+		if respondOccurrenceError(ctx, err, activityID, fecha) {
+			return
+		}
+		if errors.Is(err, services.ErrOccurrenceCancelled) {
+			log.Warnf("clase cancelada: %s %s", activityID, fecha)
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Class is cancelled"})
+			return
+		}
+		if respondEnrollmentWindowError(ctx, err, activityID) || respondEntitlementError(ctx, err, uid, activityID) {
+			return
+		}
+		if errors.Is(err, repository.ErrActivityFull) {
+			log.Warnf("clase llena: %s %s", activityID, fecha)
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Class is full"})
+			return
+		}
+		if errors.Is(err, repository.ErrUserAlreadyInscribed) {
+			log.Warnf("usuario %s ya inscrito en actividad %s %s", uid, activityID, fecha)
+			ctx.JSON(http.StatusConflict, gin.H{"error": "User already inscribed in this class"})
+			return
+		}
+
+		log.Errorf("fallo al inscribir usuario %s en actividad %s %s: %v", uid, activityID, fecha, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to inscribe", "details": err.Error()})
+		return
+	}
+
+	log.Infof("usuario %s inscrito exitosamente en actividad %s el %s", uid, activityID, fecha)
+	ctx.JSON(http.StatusOK, gin.H{"status": "inscribed", "activity_id": activityID, "fecha": fecha, "user_id": uid})
+}
+
+// DesinscribirOccurrence maneja POST /activities/:id/occurrences/:fecha/desinscribir
+func (c *ActivitiesController) DesinscribirOccurrence(ctx *gin.Context) {
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return
+	}
+
+	uid, ok := getUserIDFromClaims(claims)
+	if !ok {
+		log.Warnf("id de usuario invalido en claims del token")
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id in token claims"})
+		return
+	}
+
+	if isAdminFromClaims(claims) {
+		log.Warnf("intento de desinscripcion por usuario admin: %s", claims["username"])
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "admin users cannot desinscribe"})
+		return
+	}
+
+	activityID := ctx.Param("id")
+	fecha := ctx.Param("fecha")
+
+	if err := c.service.DesinscribirOccurrence(requestContext(ctx, claims), activityID, fecha, uid); err != nil {
+		if respondOccurrenceError(ctx, err, activityID, fecha) {
+			return
+		}
+		if errors.Is(err, repository.ErrUserNotInscribed) {
+			log.Warnf("usuario %s no inscrito en actividad %s %s", uid, activityID, fecha)
+			ctx.JSON(http.StatusConflict, gin.H{"error": "User not inscribed in this class"})
+			return
+		}
+
+		log.Errorf("fallo al desinscribir usuario %s de actividad %s %s: %v", uid, activityID, fecha, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to desinscribe", "details": err.Error()})
+		return
+	}
+
+	log.Infof("usuario %s desinscrito exitosamente de actividad %s el %s", uid, activityID, fecha)
+	ctx.JSON(http.StatusOK, gin.H{"status": "unsubscribed", "activity_id": activityID, "fecha": fecha, "user_id": uid})
+}
+
+// CancelOccurrence maneja POST /activities/:id/occurrences/:fecha/cancelar con body {"motivo": "..."}
+func (c *ActivitiesController) CancelOccurrence(ctx *gin.Context) {
+	claims, ok := c.requireAdmin(ctx)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Motivo string `json:"motivo"`
+	}
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		log.Warnf("JSON invalido al cancelar clase: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format", "details": err.Error()})
+		return
+	}
+
+	activityID := ctx.Param("id")
+	fecha := ctx.Param("fecha")
+
+	occurrence, err := c.service.CancelOccurrence(requestContext(ctx, claims), activityID, fecha, body.Motivo)
+	if err != nil {
+		if respondOccurrenceError(ctx, err, activityID, fecha) {
+			return
+		}
+		if errors.Is(err, services.ErrOccurrenceCancelled) {
+			log.Warnf("clase ya cancelada: %s %s", activityID, fecha)
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Class is already cancelled"})
+			return
+		}
+		log.Errorf("error al cancelar clase %s %s: %v", activityID, fecha, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel class", "details": err.Error()})
+		return
+	}
+
+	log.Infof("clase %s %s cancelada por usuario: %s", activityID, fecha, claims["username"])
+	ctx.JSON(http.StatusOK, gin.H{"occurrence": occurrence})
+}
+
+// RescheduleOccurrence maneja POST /activities/:id/occurrences/:fecha/reprogramar con body
+// {"nueva_fecha": "YYYY-MM-DD", "hora_inicio": "HH:MM", "hora_fin": "HH:MM", "motivo": "..."}
+func (c *ActivitiesController) RescheduleOccurrence(ctx *gin.Context) {
+	claims, ok := c.requireAdmin(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.OccurrenceReschedule
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Warnf("JSON invalido al reprogramar clase: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format", "details": err.Error()})
+		return
+	}
+
+	activityID := ctx.Param("id")
+	fecha := ctx.Param("fecha")
+
+	occurrence, err := c.service.RescheduleOccurrence(requestContext(ctx, claims), activityID, fecha, req)
+	if err != nil {
+		if respondOccurrenceError(ctx, err, activityID, fecha) {
+			return
+		}
+		if errors.Is(err, services.ErrRoomConflict) {
+			log.Warnf("sala ocupada al reprogramar clase %s %s: %v", activityID, fecha, err)
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Room is already booked at that time", "details": err.Error()})
+			return
+		}
+		log.Errorf("error al reprogramar clase %s %s: %v", activityID, fecha, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule class", "details": err.Error()})
+		return
+	}
+
+	log.Infof("clase %s %s reprogramada por usuario: %s", activityID, fecha, claims["username"])
+	ctx.JSON(http.StatusOK, gin.H{"occurrence": occurrence})
+}
+
+// GetUpcomingOccurrences maneja GET /occurrences/upcoming?desde=RFC3339&hasta=RFC3339: clases que
+// empiezan en ese rango con todos sus asistentes (solo admin o servicios internos)
+func (c *ActivitiesController) GetUpcomingOccurrences(ctx *gin.Context) {
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return
+	}
+	if !isAdminFromClaims(claims) && !isServiceFromClaims(claims) {
+		log.Warnf("operacion sin privilegios para el usuario: %s@%s", claims["username"], ctx.RemoteIP())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only admin users or services can list upcoming classes"})
+		return
+	}
+
+	from, errFrom := time.Parse(time.RFC3339, ctx.Query("desde"))
+	to, errTo := time.Parse(time.RFC3339, ctx.Query("hasta"))
+	if errFrom != nil || errTo != nil {
+		log.Warnf("rango invalido para clases proximas: %s - %s", ctx.Query("desde"), ctx.Query("hasta"))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "desde and hasta must be RFC3339 timestamps"})
+		return
+	}
+
+	occurrences, err := c.service.UpcomingOccurrences(ctx.Request.Context(), from, to)
+	if err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters", "details": err.Error()})
+			return
+		}
+		log.Errorf("error al obtener clases proximas: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch upcoming classes", "details": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"occurrences": occurrences, "count": len(occurrences)})
+}
+
+// requireAdmin devuelve los claims del token si el usuario es admin; si no, responde el error
+func (c *ActivitiesController) requireAdmin(ctx *gin.Context) (jwt.MapClaims, bool) {
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return nil, false
+	}
+	if !isAdminFromClaims(claims) {
+		log.Warnf("operacion sin privilegios para el usuario: %s@%s", claims["username"], ctx.RemoteIP())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only admin users can change classes"})
+		return nil, false
+	}
+	return claims, true
+}
+
+// respondOccurrenceError responde los errores comunes al operar sobre una fecha de una actividad.
+// Devuelve false si el error no es uno de ellos.
+func respondOccurrenceError(ctx *gin.Context, err error, activityID, fecha string) bool {
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		log.Warnf("fecha invalida para actividad %s: %s", activityID, fecha)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+	case errors.Is(err, repository.ErrActivityNotFound), errors.Is(err, repository.ErrInvalidIDFormat):
+		log.Warnf("actividad no encontrada: %s", activityID)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+	case errors.Is(err, services.ErrOccurrenceNotFound):
+		log.Warnf("actividad %s sin clase el %s", activityID, fecha)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Activity has no class on that date"})
+	case errors.Is(err, repository.ErrActivityInactive):
+		log.Warnf("actividad inactiva: %s", activityID)
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Activity is not active"})
+	case errors.Is(err, services.ErrOccurrencePast):
+		log.Warnf("clase ya iniciada: %s %s", activityID, fecha)
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Class has already started"})
+	default:
+		return false
+	}
+	return true
+}
+
+// respondEnrollmentWindowError responde 409 con un código estable cuando la inscripción está fuera
+// de la ventana de la política de la actividad. Devuelve false si el error no es de ventana.
+func respondEnrollmentWindowError(ctx *gin.Context, err error, activityID string) bool {
+	var message, code string
+	switch {
+	case errors.Is(err, services.ErrEnrollmentNotOpen):
+		message, code = "Enrollment is not open yet", "enrollment_not_open"
+	case errors.Is(err, services.ErrEnrollmentClosed):
+		message, code = "Enrollment is closed", "enrollment_closed"
+	default:
+		return false
+	}
+	log.Warnf("inscripcion fuera de ventana en actividad %s: %v", activityID, err)
+	ctx.JSON(http.StatusConflict, gin.H{"error": message, "code": code, "details": err.Error()})
+	return true
+}
+
+// respondEntitlementError responde cuando el plan del usuario no le permite inscribirse (403 con un
+// código estable), el usuario no existe (404) o no se pudo consultar users-api (503). Devuelve false
+// si el error no es de plan ni de usuario.
+func respondEntitlementError(ctx *gin.Context, err error, uid, activityID string) bool {
+	var message, code string
+	switch {
+	case errors.Is(err, services.ErrMembershipRequired):
+		message, code = "A membership plan is required to enroll", "membership_required"
+	case errors.Is(err, services.ErrMembershipNotValid):
+		message, code = "Membership plan is not valid on the class date", "membership_not_valid"
+	case errors.Is(err, services.ErrWeeklyQuotaExceeded):
+		message, code = "Weekly class quota exceeded", "weekly_quota_exceeded"
+	case errors.Is(err, services.ErrUserNotFound):
+		log.Warnf("usuario %s no encontrado en users-api al inscribir en %s", uid, activityID)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return true
+	case errors.Is(err, services.ErrUsersUnavailable):
+		log.Errorf("no se pudo verificar al usuario %s en users-api: %v", uid, err)
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "Could not verify the user", "details": err.Error()})
+		return true
+	default:
+		return false
+	}
+	log.Warnf("usuario %s sin plan para inscribirse en %s: %v", uid, activityID, err)
+	ctx.JSON(http.StatusForbidden, gin.H{"error": message, "code": code, "details": err.Error()})
+	return true
+}
+
+// RecordAttendance maneja PUT /activities/:id/occurrences/:fecha/asistencia con body
+// {"usuarios": [1, 2]}: los usuarios presentes; el resto de los inscriptos queda ausente
+func (c *ActivitiesController) RecordAttendance(ctx *gin.Context) {
+	claims, ok := c.requireAdmin(ctx)
+	if !ok {
+		return
+	}
+
+	var body dto.AttendanceRequest
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		log.Warnf("JSON invalido al tomar asistencia: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format", "details": err.Error()})
+		return
+	}
+
+	activityID := ctx.Param("id")
+	fecha := ctx.Param("fecha")
+
+	attendance, err := c.service.RecordAttendance(requestContext(ctx, claims), activityID, fecha, body.Usuarios)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrValidation):
+			log.Warnf("asistencia invalida para %s %s: %v", activityID, fecha, err)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+		case errors.Is(err, repository.ErrActivityNotFound), errors.Is(err, repository.ErrInvalidIDFormat):
+			log.Warnf("actividad no encontrada: %s", activityID)
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+		case errors.Is(err, services.ErrOccurrenceNotFound):
+			log.Warnf("actividad %s sin clase el %s", activityID, fecha)
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Activity has no class on that date"})
+		case errors.Is(err, services.ErrOccurrenceCancelled):
+			log.Warnf("asistencia de clase cancelada: %s %s", activityID, fecha)
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Class is cancelled"})
+		case errors.Is(err, services.ErrOccurrenceNotStarted):
+			log.Warnf("asistencia de clase no iniciada: %s %s", activityID, fecha)
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Class has not started yet"})
+		default:
+			log.Errorf("error al tomar asistencia %s %s: %v", activityID, fecha, err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record attendance", "details": err.Error()})
+		}
+		return
+	}
+
+	log.Infof("asistencia de %s %s tomada por usuario: %s", activityID, fecha, claims["username"])
+	ctx.JSON(http.StatusOK, gin.H{"attendance": attendance})
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"activities/internal/dto"
+	"activities/internal/repository"
+	"activities/internal/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetRooms maneja GET /rooms
+func (c *ActivitiesController) GetRooms(ctx *gin.Context) {
+	rooms, err := c.service.ListRooms(ctx.Request.Context())
+	if err != nil {
+		log.Errorf("error al listar salas: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rooms", "details": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"rooms": rooms, "count": len(rooms)})
+}
+
+// GetRoom maneja GET /rooms/:id
+func (c *ActivitiesController) GetRoom(ctx *gin.Context) {
+	id := ctx.Param("id")
+	room, err := c.service.GetRoom(ctx.Request.Context(), id)
+	if err != nil {
+		if respondRoomError(ctx, err, id) {
+			return
+		}
+		log.Errorf("error al obtener sala %s: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch room", "details": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"room": room})
+}
+
+// CreateRoom maneja POST /rooms con body {"nombre": "...", "capacidad": 20, "recursos": {"bicicleta": 15}}
+func (c *ActivitiesController) CreateRoom(ctx *gin.Context) {
+	claims, ok := c.requireRoomAdmin(ctx)
+	if !ok {
+		return
+	}
+
+	var room dto.Room
+	if err := ctx.ShouldBindJSON(&room); err != nil {
+		log.Warnf("JSON invalido al crear sala: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format", "details": err.Error()})
+		return
+	}
+
+	created, err := c.service.CreateRoom(ctx.Request.Context(), room)
+	if err != nil {
+		if respondRoomError(ctx, err, "") {
+			return
+		}
+		log.Errorf("error al crear sala: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create room", "details": err.Error()})
+		return
+	}
+
+	log.Infof("sala %s creada por usuario: %s", created.ID, claims["username"])
+	ctx.JSON(http.StatusCreated, gin.H{"room": created})
+}
+
+// UpdateRoom maneja PUT /rooms/:id
+func (c *ActivitiesController) UpdateRoom(ctx *gin.Context) {
+	claims, ok := c.requireRoomAdmin(ctx)
+	if !ok {
+		return
+	}
+
+	id := ctx.Param("id")
+	var room dto.Room
+	if err := ctx.ShouldBindJSON(&room); err != nil {
+		log.Warnf("JSON invalido al actualizar sala %s: %v", id, err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format", "details": err.Error()})
+		return
+	}
+
+	updated, err := c.service.UpdateRoom(ctx.Request.Context(), id, room)
+	if err != nil {
+		if respondRoomError(ctx, err, id) {
+			return
+		}
+		log.Errorf("error al actualizar sala %s: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update room", "details": err.Error()})
+		return
+	}
+
+	log.Infof("sala %s actualizada por usuario: %s", id, claims["username"])
+	ctx.JSON(http.StatusOK, gin.H{"room": updated})
+}
+
+// DeleteRoom maneja DELETE /rooms/:id
+func (c *ActivitiesController) DeleteRoom(ctx *gin.Context) {
+	claims, ok := c.requireRoomAdmin(ctx)
+	if !ok {
+		return
+	}
+
+	id := ctx.Param("id")
+	if err := c.service.DeleteRoom(ctx.Request.Context(), id); err != nil {
+		if respondRoomError(ctx, err, id) {
+			return
+		}
+		log.Errorf("error al eliminar sala %s: %v", id, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete room", "details": err.Error()})
+		return
+	}
+
+	log.Infof("sala %s eliminada por usuario: %s", id, claims["username"])
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *ActivitiesController) requireRoomAdmin(ctx *gin.Context) (jwt.MapClaims, bool) {
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return nil, false
+	}
+	if !isAdminFromClaims(claims) {
+		log.Warnf("operacion sobre salas sin privilegios para el usuario: %s@%s", claims["username"], ctx.RemoteIP())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only admin users can manage rooms"})
+		return nil, false
+	}
+	return claims, true
+}
+
+// respondRoomError responde los errores comunes de la administración de salas.
+// Devuelve false si el error no es uno de ellos.
+func respondRoomError(ctx *gin.Context, err error, id string) bool {
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		log.Warnf("datos de sala invalidos %s: %v", id, err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+	case errors.Is(err, repository.ErrRoomNotFound), errors.Is(err, repository.ErrInvalidIDFormat):
+		log.Warnf("sala no encontrada: %s", id)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Room not found"})
+	case errors.Is(err, repository.ErrRoomAlreadyExists):
+		log.Warnf("sala duplicada: %v", err)
+		ctx.JSON(http.StatusConflict, gin.H{"error": "A room with that name already exists"})
+	case errors.Is(err, services.ErrRoomInUse):
+		log.Warnf("sala %s con actividades asignadas: %v", id, err)
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Room has activities assigned", "details": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"activities/internal/dto"
+	"activities/internal/repository"
+	"activities/internal/services"
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetRoster maneja GET /activities/:id/roster?fecha=&format=json|csv (solo admin): la actividad con
+// los datos de sus inscriptos. En CSV es la planilla de asistencia para imprimir.
+func (c *ActivitiesController) GetRoster(ctx *gin.Context) {
+	claims, ok := c.requireRosterAdmin(ctx)
+	if !ok {
+		return
+	}
+	format, ok := analyticsFormat(ctx)
+	if !ok {
+		return
+	}
+	activityID := ctx.Param("id")
+	fecha := ctx.Query("fecha")
+
+	roster, err := c.service.GetRoster(requestContext(ctx, claims), activityID, fecha)
+	if err != nil {
+		respondRosterError(ctx, err, activityID)
+		return
+	}
+
+	if format == dto.CatalogFormatJSON {
+		ctx.JSON(http.StatusOK, roster)
+		return
+	}
+	var buf bytes.Buffer
+	if err := services.WriteRosterCSV(&buf, roster); err != nil {
+		log.Errorf("error al escribir los inscriptos de %s: %v", activityID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get the roster", "details": err.Error()})
+		return
+	}
+	filename := "inscriptos_" + activityID
+	if fecha != "" {
+		filename += "_" + fecha
+	}
+	sendCSV(ctx, filename+".csv", buf.Bytes())
+}
+
+// AddToRoster maneja POST /activities/:id/roster con body {"usuarios": [1, 2]}: un admin inscribe
+// usuarios a la serie
+func (c *ActivitiesController) AddToRoster(ctx *gin.Context) {
+	claims, ok := c.requireRosterAdmin(ctx)
+	if !ok {
+		return
+	}
+	activityID := ctx.Param("id")
+
+	var req dto.RosterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Warnf("body invalido al inscribir usuarios en %s: %v", activityID, err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	result, err := c.service.AddToRoster(requestContext(ctx, claims), activityID, req.Usuarios)
+	if err != nil {
+		respondRosterError(ctx, err, activityID)
+		return
+	}
+
+	log.Infof("usuarios %v inscritos en actividad %s por admin %s", result.Agregados, activityID, claims["username"])
+	ctx.JSON(http.StatusOK, result)
+}
+
+// RemoveFromRoster maneja DELETE /activities/:id/roster/:userId: un admin quita a un usuario de la serie
+func (c *ActivitiesController) RemoveFromRoster(ctx *gin.Context) {
+	claims, ok := c.requireRosterAdmin(ctx)
+	if !ok {
+		return
+	}
+	activityID := ctx.Param("id")
+
+	userID, err := strconv.Atoi(ctx.Param("userId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	result, err := c.service.RemoveFromRoster(requestContext(ctx, claims), activityID, userID)
+	if err != nil {
+		respondRosterError(ctx, err, activityID)
+		return
+	}
+
+	log.Infof("usuario %d quitado de actividad %s por admin %s", userID, activityID, claims["username"])
+	ctx.JSON(http.StatusOK, result)
+}
+
+// MoveEnrollment maneja POST /activities/:id/roster/move con body {"id_usuario": 5, "destino": "..."}:
+// pasa al usuario de la serie de esta actividad a la de destino
+func (c *ActivitiesController) MoveEnrollment(ctx *gin.Context) {
+	claims, ok := c.requireRosterAdmin(ctx)
+	if !ok {
+		return
+	}
+	activityID := ctx.Param("id")
+
+	var req dto.RosterMove
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Warnf("body invalido al mover usuario de %s: %v", activityID, err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	if err := c.service.MoveEnrollment(requestContext(ctx, claims), activityID, req.Destino, req.UserID); err != nil {
+		respondRosterError(ctx, err, activityID)
+		return
+	}
+
+	log.Infof("usuario %d movido de %s a %s por admin %s", req.UserID, activityID, req.Destino, claims["username"])
+	ctx.JSON(http.StatusOK, gin.H{"status": "moved", "id_usuario": req.UserID, "origen": activityID, "destino": req.Destino})
+}
+
+// ImportRoster maneja POST /activities/:id/roster/import?format=csv|json&replace=true: carga la
+// lista de inscriptos de la serie desde un archivo (columna id_usuario o {"usuarios": [...]})
+func (c *ActivitiesController) ImportRoster(ctx *gin.Context) {
+	claims, ok := c.requireRosterAdmin(ctx)
+	if !ok {
+		return
+	}
+	activityID := ctx.Param("id")
+
+	replace := false
+	if value := ctx.Query("replace"); value != "" {
+		var err error
+		if replace, err = strconv.ParseBool(value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "replace must be true or false"})
+			return
+		}
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize)
+	body, format, err := importFile(ctx)
+	if err != nil {
+		log.Warnf("archivo de inscriptos invalido: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import file", "details": err.Error()})
+		return
+	}
+	if format, err = services.ParseCatalogFormat(format); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format", "details": err.Error()})
+		return
+	}
+	userIDs, err := services.ParseRoster(format, body)
+	if err != nil {
+		log.Warnf("archivo de inscriptos invalido: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import file", "details": err.Error()})
+		return
+	}
+
+	result, err := c.service.ImportRoster(requestContext(ctx, claims), activityID, userIDs, replace)
+	if err != nil {
+		respondRosterError(ctx, err, activityID)
+		return
+	}
+
+	log.Infof("inscriptos de actividad %s importados por admin %s: %d agregados, %d quitados", activityID, claims["username"], len(result.Agregados), len(result.Quitados))
+	ctx.JSON(http.StatusOK, result)
+}
+
+func respondRosterError(ctx *gin.Context, err error, activityID string) {
+	switch {
+	case errors.Is(err, services.ErrValidation):
+		log.Warnf("cambio de inscriptos invalido en actividad %s: %v", activityID, err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+	case errors.Is(err, repository.ErrActivityNotFound), errors.Is(err, repository.ErrInvalidIDFormat):
+		log.Warnf("actividad no encontrada: %s", activityID)
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+	case errors.Is(err, services.ErrOccurrenceNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Activity has no class on that date"})
+	case errors.Is(err, repository.ErrUserNotInscribed):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User is not inscribed in this activity"})
+	case errors.Is(err, repository.ErrActivityInactive):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Activity is not active"})
+	case errors.Is(err, repository.ErrActivityFull):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Activity does not have enough places", "details": err.Error()})
+	case errors.Is(err, repository.ErrUserAlreadyInscribed):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "User already inscribed in this activity", "details": err.Error()})
+	case errors.Is(err, services.ErrUsersUnavailable):
+		log.Errorf("no se pudo verificar a los usuarios en users-api: %v", err)
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "Could not verify the users", "details": err.Error()})
+	default:
+		log.Errorf("fallo al cambiar inscriptos de actividad %s: %v", activityID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the roster", "details": err.Error()})
+	}
+}
+
+func (c *ActivitiesController) requireRosterAdmin(ctx *gin.Context) (jwt.MapClaims, bool) {
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return nil, false
+	}
+	if !isAdminFromClaims(claims) {
+		log.Warnf("cambio de inscriptos sin privilegios para el usuario: %s@%s", claims["username"], ctx.RemoteIP())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only admin users can manage rosters"})
+		return nil, false
+	}
+	return claims, true
+}
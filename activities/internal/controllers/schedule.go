@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"activities/internal/services"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// los lugares disponibles cambian con cada inscripción: la grilla se cachea poco tiempo
+	scheduleMaxAge         = 60
+	scheduleCalendarMaxAge = 900
+)
+
+// GetSchedule maneja GET /activities/schedule?week=2026-W10 (o una fecha YYYY-MM-DD de la
+// semana; por defecto la actual): grilla semanal para embeber en el sitio
+func (c *ActivitiesController) GetSchedule(ctx *gin.Context) {
+	week := ctx.Query("week")
+
+	schedule, err := c.service.WeeklySchedule(ctx.Request.Context(), week)
+	if err != nil {
+		if errors.Is(err, services.ErrValidation) {
+			log.Warnf("semana invalida para la grilla: %s", week)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid week", "details": err.Error()})
+			return
+		}
+		log.Errorf("error al armar la grilla semanal: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build schedule", "details": err.Error()})
+		return
+	}
+
+	body, err := json.Marshal(schedule)
+	if err != nil {
+		log.Errorf("error al serializar la grilla semanal: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build schedule"})
+		return
+	}
+	respondCached(ctx, "application/json; charset=utf-8", body, scheduleMaxAge)
+}
+
+// GetScheduleCalendar maneja GET /activities/schedule.ics: todas las actividades activas como
+// eventos semanales para suscribirse desde una aplicación de calendario
+func (c *ActivitiesController) GetScheduleCalendar(ctx *gin.Context) {
+	calendar, err := c.service.ScheduleCalendar(ctx.Request.Context())
+	if err != nil {
+		log.Errorf("error al generar el calendario de horarios: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build calendar", "details": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", `inline; filename="horarios.ics"`)
+	respondCached(ctx, "text/calendar; charset=utf-8", []byte(calendar), scheduleCalendarMaxAge)
+}
+
+// respondCached responde body con Cache-Control público y un ETag del contenido. Si el cliente ya
+// tiene esa versión (If-None-Match) responde 304 sin cuerpo.
+func respondCached(ctx *gin.Context, contentType string, body []byte, maxAge int) {
+	sum := sha256.Sum256(body)
+	tag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	ctx.Header("ETag", tag)
+	ctx.Header("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+
+	for _, candidate := range strings.Split(ctx.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == tag || candidate == "*" {
+			ctx.Status(http.StatusNotModified)
+			return
+		}
+	}
+	ctx.Data(http.StatusOK, contentType, body)
+}
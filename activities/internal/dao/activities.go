@@ -10,18 +10,25 @@ import (
 // ActivityDAO es el modelo usado solamente para la capa de persistencia (MongoDB)
 // Tiene etiquetas `bson` y usa primitive.ObjectID para el campo ID.
 type ActivityDAO struct {
-	ID                primitive.ObjectID `bson:"_id,omitempty"`
-	Nombre            string             `bson:"nombre"`
-	Descripcion       string             `bson:"descripcion"`
-	Profesor          string             `bson:"profesor_id"`
-	DiaSemana         string             `bson:"dia_semana"`
-	HoraInicio        string             `bson:"hora_inicio"` // capaz cambiar a time.Time
-	HoraFin           string             `bson:"hora_fin"`    // capaz cambiar a time.Time
-	UsuariosInscritos []int              `bson:"usuarios_inscritos"`
-	CapacidadMax      int                `bson:"capacidad_max"`
-	Activa            bool               `bson:"activa"`
-	FechaCreacion     time.Time          `bson:"fecha_creacion"`
-	FotoUrl           string             `bson:"foto_url"`
+	ID                 primitive.ObjectID `bson:"_id,omitempty"`
+	Nombre             string             `bson:"nombre"`
+	Descripcion        string             `bson:"descripcion"`
+	Profesor           string             `bson:"profesor_id"`
+	DiaSemana          string             `bson:"dia_semana"`
+	HoraInicio         string             `bson:"hora_inicio"` // capaz cambiar a time.Time
+	HoraFin            string             `bson:"hora_fin"`    // capaz cambiar a time.Time
+	UsuariosInscritos  []int              `bson:"usuarios_inscritos"`
+	CapacidadMax       int                `bson:"capacidad_max"`
+	Activa             bool               `bson:"activa"`
+	FechaCreacion      time.Time          `bson:"fecha_creacion"`
+	FechaActualizacion time.Time          `bson:"fecha_actualizacion"`
+	Version            int64              `bson:"version"` // control de concurrencia optimista
+	FotoUrl            string             `bson:"foto_url"`
+	Recurrencia        *dto.Recurrence    `bson:"recurrencia"`
+	Politica           *dto.Policy        `bson:"politica,omitempty"`
+	SalaID             string             `bson:"id_sala,omitempty"`
+	Recurso            string             `bson:"recurso,omitempty"`
+	ClaveExterna       string             `bson:"clave_externa,omitempty"` // clave del sistema de origen en las importaciones
 }
 
 // ToDomain convierte ActivityDAO a Activity (DTO/Domain)
@@ -38,23 +45,35 @@ func (dao ActivityDAO) ToDomain() dto.Activity {
 		FotoUrl:            dao.FotoUrl,
 		CapacidadMax:       dao.CapacidadMax,
 		LugaresDisponibles: lugaresDisponibles,
+		Recurrencia:        dao.Recurrencia,
+		Politica:           dao.Politica,
+		SalaID:             dao.SalaID,
+		Recurso:            dao.Recurso,
 	}
 }
 
 func FromDomainDAO(a dto.ActivityAdministration) ActivityDAO {
+	now := time.Now().UTC()
 	return ActivityDAO{
 		// ID se asigna automáticamente en Create si es vacío
-		Nombre:            a.Nombre,
-		Descripcion:       a.Descripcion,
-		Profesor:          a.Profesor,
-		DiaSemana:         a.DiaSemana,
-		HoraInicio:        a.HoraInicio,
-		HoraFin:           a.HoraFin,
-		UsuariosInscritos: a.UsersInscribed,
-		CapacidadMax:      a.CapacidadMax,
-		FotoUrl:           a.FotoUrl,
-		Activa:            true, // Por defecto al crear es activa
-		FechaCreacion:     time.Now().UTC(),
+		Nombre:             a.Nombre,
+		Descripcion:        a.Descripcion,
+		Profesor:           a.Profesor,
+		DiaSemana:          a.DiaSemana,
+		HoraInicio:         a.HoraInicio,
+		HoraFin:            a.HoraFin,
+		UsuariosInscritos:  a.UsersInscribed,
+		CapacidadMax:       a.CapacidadMax,
+		FotoUrl:            a.FotoUrl,
+		Recurrencia:        a.Recurrencia,
+		Politica:           a.Politica,
+		SalaID:             a.SalaID,
+		Recurso:            a.Recurso,
+		ClaveExterna:       a.ClaveExterna,
+		Activa:             true, // Por defecto al crear es activa
+		FechaCreacion:      now,
+		FechaActualizacion: now,
+		Version:            1,
 	}
 }
 
@@ -72,8 +91,16 @@ func ToDomainAdministration(dao ActivityDAO) dto.ActivityAdministration {
 			FotoUrl:            dao.FotoUrl,
 			CapacidadMax:       dao.CapacidadMax,
 			LugaresDisponibles: lugaresDisponibles,
+			Recurrencia:        dao.Recurrencia,
+			Politica:           dao.Politica,
+			SalaID:             dao.SalaID,
+			Recurso:            dao.Recurso,
 		},
-		UsersInscribed: dao.UsuariosInscritos,
-		FechaCreacion:  dao.FechaCreacion,
+		UsersInscribed:     dao.UsuariosInscritos,
+		ClaveExterna:       dao.ClaveExterna,
+		Activa:             dao.Activa,
+		FechaCreacion:      dao.FechaCreacion,
+		FechaActualizacion: dao.FechaActualizacion,
+		Version:            dao.Version,
 	}
 }
@@ -0,0 +1,11 @@
+package dao
+
+import "time"
+
+// CalendarTokenDAO es el token con el que un usuario se suscribe a su calendario de clases. Hay
+// uno por usuario; generar uno nuevo invalida el anterior.
+type CalendarTokenDAO struct {
+	UserID        string    `bson:"id_usuario"`
+	Token         string    `bson:"token"`
+	FechaCreacion time.Time `bson:"fecha_creacion"`
+}
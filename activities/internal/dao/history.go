@@ -0,0 +1,46 @@
+package dao
+
+import (
+	"activities/internal/dto"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HistoryEntryDAO es un registro de la colección de historial. Los registros solo se insertan,
+// nunca se modifican ni se eliminan.
+type HistoryEntryDAO struct {
+	ID               primitive.ObjectID         `bson:"_id,omitempty"`
+	ActivityID       string                     `bson:"id_actividad"`
+	Action           string                     `bson:"accion"`
+	Actor            dto.Actor                  `bson:"actor"`
+	UserID           string                     `bson:"id_usuario,omitempty"`
+	Changes          map[string]dto.FieldChange `bson:"cambios,omitempty"`
+	LateCancellation bool                       `bson:"cancelacion_tardia,omitempty"`
+	Timestamp        time.Time                  `bson:"fecha"`
+}
+
+func (dao HistoryEntryDAO) ToDomain() dto.HistoryEntry {
+	return dto.HistoryEntry{
+		ID:               dao.ID.Hex(),
+		ActivityID:       dao.ActivityID,
+		Action:           dao.Action,
+		Actor:            dao.Actor,
+		UserID:           dao.UserID,
+		Changes:          dao.Changes,
+		LateCancellation: dao.LateCancellation,
+		Timestamp:        dao.Timestamp,
+	}
+}
+
+func HistoryEntryFromDomain(e dto.HistoryEntry) HistoryEntryDAO {
+	return HistoryEntryDAO{
+		ActivityID:       e.ActivityID,
+		Action:           e.Action,
+		Actor:            e.Actor,
+		UserID:           e.UserID,
+		Changes:          e.Changes,
+		LateCancellation: e.LateCancellation,
+		Timestamp:        e.Timestamp,
+	}
+}
@@ -0,0 +1,41 @@
+package dao
+
+import (
+	"activities/internal/dto"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OccurrenceDAO guarda los datos propios de una fecha de una actividad. El horario y el cupo
+// salen de la actividad; el documento se crea recién cuando alguien se inscribe a esa fecha o
+// cuando la clase se cancela o reprograma.
+type OccurrenceDAO struct {
+	ID                   primitive.ObjectID `bson:"_id,omitempty"`
+	ActivityID           string             `bson:"id_actividad"`
+	Fecha                string             `bson:"fecha"` // YYYY-MM-DD
+	Estado               string             `bson:"estado,omitempty"`
+	Motivo               string             `bson:"motivo,omitempty"`
+	NuevaFecha           string             `bson:"nueva_fecha,omitempty"` // YYYY-MM-DD si se reprogramó
+	HoraInicio           string             `bson:"hora_inicio,omitempty"`
+	HoraFin              string             `bson:"hora_fin,omitempty"`
+	UsuariosInscritos    []int              `bson:"usuarios_inscritos"`
+	Asistentes           []int              `bson:"asistentes,omitempty"`
+	Ausentes             []int              `bson:"ausentes,omitempty"`
+	CancelacionesTardias []int              `bson:"cancelaciones_tardias,omitempty"` // se desinscribieron fuera de la ventana de cancelación
+}
+
+func (dao OccurrenceDAO) ToDomain() dto.OccurrenceRecord {
+	return dto.OccurrenceRecord{
+		ActivityID:        dao.ActivityID,
+		Fecha:             dao.Fecha,
+		Estado:            dao.Estado,
+		Motivo:            dao.Motivo,
+		NuevaFecha:        dao.NuevaFecha,
+		HoraInicio:        dao.HoraInicio,
+		HoraFin:           dao.HoraFin,
+		UsersInscribed:    dao.UsuariosInscritos,
+		Asistentes:        dao.Asistentes,
+		Ausentes:          dao.Ausentes,
+		LateCancellations: dao.CancelacionesTardias,
+	}
+}
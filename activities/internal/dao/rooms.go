@@ -0,0 +1,36 @@
+package dao
+
+import (
+	"activities/internal/dto"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RoomDAO es una sala en la colección rooms
+type RoomDAO struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	Nombre        string             `bson:"nombre"`
+	Capacidad     int                `bson:"capacidad"`
+	Recursos      map[string]int     `bson:"recursos,omitempty"`
+	FechaCreacion time.Time          `bson:"fecha_creacion"`
+}
+
+func (dao RoomDAO) ToDomain() dto.Room {
+	return dto.Room{
+		ID:            dao.ID.Hex(),
+		Nombre:        dao.Nombre,
+		Capacidad:     dao.Capacidad,
+		Recursos:      dao.Recursos,
+		FechaCreacion: dao.FechaCreacion,
+	}
+}
+
+func FromDomainRoomDAO(room dto.Room) RoomDAO {
+	return RoomDAO{
+		Nombre:        room.Nombre,
+		Capacidad:     room.Capacidad,
+		Recursos:      room.Recursos,
+		FechaCreacion: time.Now().UTC(),
+	}
+}
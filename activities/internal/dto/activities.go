@@ -3,41 +3,99 @@ package dto
 import "time"
 
 type Activity struct {
-	ID                 string `json:"id_actividad"`
-	Nombre             string `json:"titulo"`
-	Descripcion        string `json:"descripcion"`
-	Profesor           string `json:"instructor"`
-	DiaSemana          string `json:"dia"`
-	HoraInicio         string `json:"hora_inicio"`
-	HoraFin            string `json:"hora_fin"`
-	CapacidadMax       int    `json:"cupo"`
-	LugaresDisponibles int    `json:"lugares_disponibles"`
-	FotoUrl            string `json:"foto_url"`
+	ID                 string      `json:"id_actividad"`
+	Nombre             string      `json:"titulo"`
+	Descripcion        string      `json:"descripcion"`
+	Profesor           string      `json:"instructor"`
+	DiaSemana          string      `json:"dia"`
+	HoraInicio         string      `json:"hora_inicio"`
+	HoraFin            string      `json:"hora_fin"`
+	CapacidadMax       int         `json:"cupo"`
+	LugaresDisponibles int         `json:"lugares_disponibles"`
+	FotoUrl            string      `json:"foto_url"`
+	Recurrencia        *Recurrence `json:"recurrencia,omitempty"` // sin regla: todas las semanas el día DiaSemana
+	SalaID             string      `json:"id_sala,omitempty"`     // sala donde se dicta (opcional)
+	Recurso            string      `json:"recurso,omitempty"`     // equipamiento de la sala que usa cada asistente (ej. bicicleta)
+	Politica           *Policy     `json:"politica,omitempty"`    // ventanas de inscripción y cancelación; sin política no hay límites
 }
 
 type Activities []Activity
 
+// DiasSemana lista los días válidos en orden de calendario
+var DiasSemana = []string{"Lunes", "Martes", "Miércoles", "Jueves", "Viernes", "Sábado", "Domingo"}
+
+// ActivityListFilters son los filtros, orden y paginación de GET /activities
+type ActivityListFilters struct {
+	Page       int
+	Limit      int
+	Dia        string
+	Instructor string
+	HoraDesde  string // hora_inicio >= HoraDesde
+	HoraHasta  string // hora_fin <= HoraHasta
+	Activa     *bool
+	Disponible *bool    // true: con lugares disponibles, false: llenas
+	Sort       []string // campos de orden, con prefijo "-" para descendente
+}
+
+type ActivitiesPage struct {
+	Activities Activities `json:"activities"`
+	Count      int        `json:"count"`
+	Total      int        `json:"total"`
+	Page       int        `json:"page"`
+	Limit      int        `json:"limit"`
+	TotalPages int        `json:"total_pages"`
+	HasNext    bool       `json:"has_next"`
+}
+
 type ActivityAdministration struct {
 	Activity
-	UsersInscribed []int `json:"usuarios_inscritos,omitempty"` // Array de User IDs (JSON: usuarios_inscritos)
-	FechaCreacion  time.Time
+	UsersInscribed     []int     `json:"usuarios_inscritos,omitempty"` // Array de User IDs (JSON: usuarios_inscritos)
+	ClaveExterna       string    `json:"clave_externa,omitempty"`      // clave única del sistema de origen, para importar catálogos
+	Activa             bool      `json:"activa"`                       // false: dada de baja (soft delete), oculta para el público
+	FechaCreacion      time.Time `json:"fecha_creacion"`               // no cambia después de crear la actividad
+	FechaActualizacion time.Time `json:"fecha_actualizacion"`
+	Version            int64     `json:"version"` // se incrementa con cada cambio; se expone como ETag
 }
 
 type ActivitiesAdministrations []ActivityAdministration
 
-type DayDistribution struct {
-	Dia   string `json:"dia"`
-	Count int    `json:"count"`
-}
-
-type ActivityStatistics struct {
-	TotalActivities       int                `json:"total_actividades"`
-	TotalEnrollments      int                `json:"total_inscripciones"`
-	AverageEnrollmentRate float64            `json:"tasa_promedio_inscripcion"`
-	TotalCapacity         int                `json:"capacidad_total"`
-	CapacityUtilization   float64            `json:"utilizacion_capacidad"`
-	ActivitiesByDay       []DayDistribution  `json:"actividades_por_dia"`
-	MostPopularActivity   *Activity          `json:"actividad_mas_popular"`
-	FullActivitiesCount   int                `json:"actividades_llenas"`
-	AvailableActivities   int                `json:"actividades_disponibles"`
+// Actor es quien realiza una operación, tomado de los claims del JWT
+type Actor struct {
+	ID       string `json:"id_usuario" bson:"id_usuario"`
+	Username string `json:"username,omitempty" bson:"username,omitempty"`
+	IsAdmin  bool   `json:"is_admin" bson:"is_admin"`
+}
+
+// FieldChange es el valor de un campo antes y después de una operación
+type FieldChange struct {
+	Before any `json:"antes" bson:"antes"`
+	After  any `json:"despues" bson:"despues"`
+}
+
+// HistoryEntry es un registro del historial de cambios (append-only) de una actividad
+type HistoryEntry struct {
+	ID               string                 `json:"id"`
+	ActivityID       string                 `json:"id_actividad"`
+	Action           string                 `json:"accion"`
+	Actor            Actor                  `json:"actor"`
+	UserID           string                 `json:"id_usuario,omitempty"` // usuario afectado en inscripciones/desinscripciones
+	Changes          map[string]FieldChange `json:"cambios,omitempty"`
+	LateCancellation bool                   `json:"cancelacion_tardia,omitempty"` // desinscripción fuera de la ventana de cancelación
+	Timestamp        time.Time              `json:"fecha"`
+}
+
+type HistoryFilters struct {
+	ActivityID string
+	UserID     string // entradas hechas por el usuario o que lo afectan
+	Page       int
+	Limit      int
+}
+
+type HistoryPage struct {
+	Entries []HistoryEntry `json:"entries"`
+	Count   int            `json:"count"`
+	Total   int            `json:"total"`
+	Page    int            `json:"page"`
+	Limit   int            `json:"limit"`
+	HasNext bool           `json:"has_next"`
 }
@@ -0,0 +1,65 @@
+package dto
+
+// OccupancyCell es la ocupación de una franja de una hora en un día de la semana: cuántas clases
+// se dictan (total o parcialmente) en esa hora, sus inscriptos a la serie y su cupo
+type OccupancyCell struct {
+	Hora       int     `json:"hora"`
+	Clases     int     `json:"clases"`
+	Inscriptos int     `json:"inscriptos"`
+	Capacidad  int     `json:"capacidad"`
+	Ocupacion  float64 `json:"ocupacion"` // porcentaje del cupo ocupado
+	Llenas     int     `json:"llenas"`
+}
+
+type OccupancyDay struct {
+	Dia   string          `json:"dia"`
+	Horas []OccupancyCell `json:"horas"` // 0 a 23
+}
+
+// OccupancySlot es una franja con sobredemanda (ocupación mayor o igual al umbral)
+type OccupancySlot struct {
+	Dia       string  `json:"dia"`
+	Hora      int     `json:"hora"`
+	Ocupacion float64 `json:"ocupacion"`
+	Llenas    int     `json:"llenas"`
+}
+
+// OccupancyHeatmap es la matriz día de la semana × hora de la ocupación de las actividades activas
+type OccupancyHeatmap struct {
+	Dias         []OccupancyDay  `json:"dias"`
+	Umbral       float64         `json:"umbral_sobredemanda"`
+	SobreDemanda []OccupancySlot `json:"sobredemanda"` // de mayor a menor ocupación
+}
+
+// ActivityAttendance es la asistencia registrada de las clases de una actividad en un período
+type ActivityAttendance struct {
+	ActivityID           string
+	Clases               int
+	Presentes            int
+	Ausentes             int
+	CancelacionesTardias int
+}
+
+// NoShowRate es el ausentismo de una actividad: inscriptos que no asistieron sobre el total de
+// inscriptos de las clases con asistencia tomada. Las cancelaciones tardías se informan aparte.
+type NoShowRate struct {
+	ActivityID           string  `json:"id_actividad"`
+	Titulo               string  `json:"titulo"`
+	Instructor           string  `json:"instructor"`
+	Clases               int     `json:"clases"`
+	Presentes            int     `json:"presentes"`
+	Ausentes             int     `json:"ausentes"`
+	TasaAusentismo       float64 `json:"tasa_ausentismo"` // porcentaje
+	CancelacionesTardias int     `json:"cancelaciones_tardias"`
+}
+
+type NoShowReport struct {
+	Desde                string       `json:"desde"`
+	Hasta                string       `json:"hasta"`
+	Clases               int          `json:"clases"`
+	Presentes            int          `json:"presentes"`
+	Ausentes             int          `json:"ausentes"`
+	TasaAusentismo       float64      `json:"tasa_ausentismo"`
+	CancelacionesTardias int          `json:"cancelaciones_tardias"`
+	Actividades          []NoShowRate `json:"actividades"` // de mayor a menor ausentismo
+}
@@ -0,0 +1,45 @@
+package dto
+
+// Formatos de importación y exportación del catálogo de actividades
+const (
+	CatalogFormatCSV  = "csv"
+	CatalogFormatJSON = "json"
+)
+
+// Acción que la importación aplica (o aplicaría, en un dry run) a cada fila
+const (
+	ImportActionCreate    = "create"
+	ImportActionUpdate    = "update"
+	ImportActionUnchanged = "unchanged"
+)
+
+// ImportRow es una fila leída del archivo a importar. Errores son los problemas de formato de la
+// fila (ej. un cupo que no es un número); la validación de la actividad se hace al importar.
+type ImportRow struct {
+	Fila     int
+	Activity ActivityAdministration
+	Errores  []string
+}
+
+// ImportRowResult es el resultado de una fila de la importación
+type ImportRowResult struct {
+	Fila         int      `json:"fila"`
+	ClaveExterna string   `json:"clave_externa"`
+	Accion       string   `json:"accion,omitempty"`
+	ActivityID   string   `json:"id_actividad,omitempty"`
+	Cambios      []string `json:"cambios,omitempty"` // campos que cambian en las actualizaciones
+	Errores      []string `json:"errores,omitempty"`
+}
+
+// ImportReport es el informe de una importación. Si alguna fila es inválida no se aplica ninguna
+// (Aplicado queda en false); en un dry run nunca se aplica.
+type ImportReport struct {
+	DryRun       bool              `json:"dry_run"`
+	Aplicado     bool              `json:"aplicado"`
+	Total        int               `json:"total"`
+	Creadas      int               `json:"creadas"`
+	Actualizadas int               `json:"actualizadas"`
+	SinCambios   int               `json:"sin_cambios"`
+	ConErrores   int               `json:"con_errores"`
+	Filas        []ImportRowResult `json:"filas"`
+}
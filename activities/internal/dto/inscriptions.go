@@ -0,0 +1,58 @@
+package dto
+
+import "time"
+
+// Asistencia de un usuario a una clase
+const (
+	AttendancePresent = "presente"
+	AttendanceAbsent  = "ausente"
+)
+
+// AttendanceRequest es la asistencia de una clase: los usuarios presentes. El resto de los
+// inscriptos queda como ausente.
+type AttendanceRequest struct {
+	Usuarios []int `json:"usuarios"`
+}
+
+// Attendance es la asistencia guardada de una clase
+type Attendance struct {
+	ActivityID string `json:"id_actividad"`
+	Fecha      string `json:"fecha"`
+	Asistentes []int  `json:"asistentes"`
+	Ausentes   []int  `json:"ausentes"`
+}
+
+// EnrollmentPeriod es un período en el que el usuario estuvo inscripto a una actividad (a la
+// serie o, si tiene Fecha, a una sola clase). Hasta es nil mientras siga inscripto.
+type EnrollmentPeriod struct {
+	ActivityID string     `json:"id_actividad"`
+	Titulo     string     `json:"titulo"`
+	Fecha      string     `json:"fecha,omitempty"`
+	Desde      time.Time  `json:"desde"`
+	Hasta      *time.Time `json:"hasta,omitempty"`
+}
+
+// AttendanceEntry es la asistencia del usuario a una clase
+type AttendanceEntry struct {
+	ActivityID string `json:"id_actividad"`
+	Titulo     string `json:"titulo"`
+	Fecha      string `json:"fecha"`
+	Asistencia string `json:"asistencia"` // presente | ausente
+}
+
+// EnrollmentHistory es el historial de inscripciones y asistencia de un usuario, más reciente primero
+type EnrollmentHistory struct {
+	UserID        string             `json:"id_usuario"`
+	Inscripciones []EnrollmentPeriod `json:"inscripciones"`
+	Asistencias   []AttendanceEntry  `json:"asistencias"`
+	Presentes     int                `json:"presentes"`
+	Ausentes      int                `json:"ausentes"`
+}
+
+// Policy son las ventanas de inscripción y cancelación de las clases de una actividad. Cada valor
+// en cero no limita.
+type Policy struct {
+	AperturaDias     int `json:"apertura_dias,omitempty" bson:"apertura_dias,omitempty"`         // la inscripción abre X días antes de la clase
+	CierreMinutos    int `json:"cierre_minutos,omitempty" bson:"cierre_minutos,omitempty"`       // y cierra Y minutos antes del inicio
+	CancelacionHoras int `json:"cancelacion_horas,omitempty" bson:"cancelacion_horas,omitempty"` // cancelación sin cargo hasta Z horas antes
+}
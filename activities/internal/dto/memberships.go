@@ -0,0 +1,15 @@
+package dto
+
+// Motivo de users-api cuando el usuario no tiene plan
+const EntitlementNoPlan = "sin_plan"
+
+// Entitlement es lo que users-api informa del plan de un usuario para una fecha
+type Entitlement struct {
+	UserID          int    `json:"id_usuario"`
+	Fecha           string `json:"fecha"`
+	Vigente         bool   `json:"vigente"`
+	Motivo          string `json:"motivo,omitempty"` // sin_plan, plan_no_iniciado o plan_vencido
+	Desde           string `json:"desde,omitempty"`
+	Hasta           string `json:"hasta,omitempty"`
+	ClasesSemanales int    `json:"clases_semanales"` // 0 = ilimitado
+}
@@ -0,0 +1,95 @@
+package dto
+
+import "time"
+
+// FechaLayout es el formato de las fechas de recurrencias y ocurrencias (YYYY-MM-DD)
+const FechaLayout = "2006-01-02"
+
+// Recurrence es la regla con la que se generan las clases (ocurrencias) de una actividad.
+// Sin FechaInicio/FechaFin la actividad se repite sin límite.
+type Recurrence struct {
+	Dias        []string `json:"dias" bson:"dias"`                                     // días de la semana (Lunes ... Domingo)
+	FechaInicio string   `json:"fecha_inicio,omitempty" bson:"fecha_inicio,omitempty"` // YYYY-MM-DD, inclusive
+	FechaFin    string   `json:"fecha_fin,omitempty" bson:"fecha_fin,omitempty"`       // YYYY-MM-DD, inclusive
+	Excepciones []string `json:"excepciones,omitempty" bson:"excepciones,omitempty"`   // fechas YYYY-MM-DD sin clase
+}
+
+// Estados de una ocurrencia
+const (
+	OccurrenceScheduled   = "programada"
+	OccurrenceCancelled   = "cancelada"
+	OccurrenceRescheduled = "reprogramada"
+)
+
+// Occurrence es una clase concreta (fecha) de una actividad. Los inscriptos a la serie completa
+// ocupan lugar en todas las ocurrencias; UsersInscribed son los inscriptos solo a esta fecha.
+// Fecha identifica a la clase aunque se haya reprogramado: en ese caso NuevaFecha, DiaSemana y
+// el horario corresponden a cuándo se dicta.
+type Occurrence struct {
+	ActivityID         string `json:"id_actividad"`
+	Titulo             string `json:"titulo"`
+	Fecha              string `json:"fecha"`
+	NuevaFecha         string `json:"nueva_fecha,omitempty"`
+	DiaSemana          string `json:"dia"`
+	HoraInicio         string `json:"hora_inicio"`
+	HoraFin            string `json:"hora_fin"`
+	CapacidadMax       int    `json:"cupo"`
+	LugaresDisponibles int    `json:"lugares_disponibles"`
+	Estado             string `json:"estado"`
+	Motivo             string `json:"motivo,omitempty"`
+	UsersInscribed     []int  `json:"usuarios_inscritos,omitempty"`
+}
+
+// OccurrenceRecord es lo que se guarda de una ocurrencia: solo existe una vez que alguien se
+// inscribe a esa fecha o cuando se cancela o reprograma. Los campos vacíos toman el valor de la
+// actividad.
+type OccurrenceRecord struct {
+	ActivityID        string
+	Fecha             string
+	Estado            string
+	Motivo            string
+	NuevaFecha        string
+	HoraInicio        string
+	HoraFin           string
+	UsersInscribed    []int
+	Asistentes        []int // presentes, una vez tomada la asistencia
+	Ausentes          []int // inscriptos que no asistieron
+	LateCancellations []int // se desinscribieron fuera de la ventana de cancelación
+}
+
+// OccurrenceReschedule es el pedido de reprogramación de una clase; los campos vacíos mantienen
+// la fecha u horario original
+type OccurrenceReschedule struct {
+	NuevaFecha string `json:"nueva_fecha"`
+	HoraInicio string `json:"hora_inicio"`
+	HoraFin    string `json:"hora_fin"`
+	Motivo     string `json:"motivo"`
+}
+
+// Eventos de clases e inscripciones que se publican para el servicio de notificaciones
+const (
+	EventActivityCancelled   = "activity.cancelled"
+	EventActivityRescheduled = "activity.rescheduled"
+	EventEnrollmentCreated   = "enrollment.created"
+	EventEnrollmentCancelled = "enrollment.cancelled"
+)
+
+// OccurrenceEvent avisa a los usuarios afectados que una clase se canceló o se reprogramó (a los
+// inscriptos de la serie y de la fecha) o que se inscribieron o desinscribieron. En las
+// inscripciones a la serie Fecha queda vacío y Dia indica el día de la semana.
+type OccurrenceEvent struct {
+	Type            string    `json:"type"`
+	ActivityID      string    `json:"id_actividad"`
+	Titulo          string    `json:"titulo"`
+	Dia             string    `json:"dia,omitempty"`
+	Fecha           string    `json:"fecha,omitempty"`
+	HoraInicio      string    `json:"hora_inicio"`
+	HoraFin         string    `json:"hora_fin"`
+	NuevaFecha      string    `json:"nueva_fecha,omitempty"`
+	NuevaHoraInicio string    `json:"nueva_hora_inicio,omitempty"`
+	NuevaHoraFin    string    `json:"nueva_hora_fin,omitempty"`
+	Motivo          string    `json:"motivo"`
+	Usuarios        []int     `json:"usuarios"`
+	Actor           Actor     `json:"actor"`
+	Timestamp       time.Time `json:"timestamp"`
+}
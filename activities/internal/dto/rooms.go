@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// Room es una sala del gimnasio. Recursos es el equipamiento disponible (ej. "bicicleta": 20) que
+// limita el cupo de las actividades que lo usan.
+type Room struct {
+	ID            string         `json:"id_sala"`
+	Nombre        string         `json:"nombre"`
+	Capacidad     int            `json:"capacidad"`
+	Recursos      map[string]int `json:"recursos,omitempty"`
+	FechaCreacion time.Time      `json:"fecha_creacion"`
+}
+
+type Rooms []Room
@@ -0,0 +1,62 @@
+package dto
+
+import "time"
+
+// User son los datos de un usuario de users-api
+type User struct {
+	ID       int    `json:"id_usuario"`
+	Nombre   string `json:"nombre"`
+	Apellido string `json:"apellido"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	IsAdmin  bool   `json:"is_admin"`
+}
+
+// RosterRequest son los usuarios a inscribir a la serie de una actividad
+type RosterRequest struct {
+	Usuarios []int `json:"usuarios"`
+}
+
+// RosterMove pasa a un usuario de la serie de una actividad a la de otra
+type RosterMove struct {
+	UserID  int    `json:"id_usuario"`
+	Destino string `json:"destino"`
+}
+
+// RosterResult es el resultado de un cambio de inscriptos hecho por un admin
+type RosterResult struct {
+	ActivityID string `json:"id_actividad"`
+	Agregados  []int  `json:"agregados"`
+	Quitados   []int  `json:"quitados"`
+	SinCambios []int  `json:"sin_cambios"`
+	Inscriptos int    `json:"inscriptos"`
+	Cupo       int    `json:"cupo"`
+}
+
+// UserPublic son los datos de un inscripto que se muestran en el detalle de una clase
+type UserPublic struct {
+	ID       int    `json:"id_usuario"`
+	Nombre   string `json:"nombre"`
+	Apellido string `json:"apellido"`
+	Email    string `json:"email"`
+}
+
+// ActivityRoster es el detalle de una actividad con sus inscriptos (ClaseDetalleDTO). Con Fecha
+// incluye también a los inscriptos solo a esa clase. Desconocidos son los IDs inscriptos que
+// users-api ya no tiene.
+type ActivityRoster struct {
+	Activity
+	Fecha        string       `json:"fecha,omitempty"`
+	Inscritos    []UserPublic `json:"inscritos"`
+	Desconocidos []int        `json:"desconocidos,omitempty"`
+}
+
+// EventUserDeleted lo publica users-api al eliminar un usuario
+const EventUserDeleted = "user.deleted"
+
+// UserEvent es un evento de la cola de usuarios de users-api
+type UserEvent struct {
+	Type      string    `json:"type"`
+	UserID    int       `json:"id_usuario"`
+	Timestamp time.Time `json:"timestamp"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+// ScheduleClass es una clase de la grilla semanal pública (sin los usuarios inscriptos)
+type ScheduleClass struct {
+	Occurrence
+	Instructor string `json:"instructor"`
+	SalaID     string `json:"id_sala,omitempty"`
+}
+
+// ScheduleDay son las clases de un día de la grilla, ordenadas por horario
+type ScheduleDay struct {
+	Dia    string          `json:"dia"`
+	Fecha  string          `json:"fecha"`
+	Clases []ScheduleClass `json:"clases"`
+}
+
+// WeeklySchedule es la grilla de una semana (lunes a domingo) con todas las actividades activas
+type WeeklySchedule struct {
+	Semana string        `json:"semana"` // semana ISO, ej. 2026-W10
+	Desde  string        `json:"desde"`
+	Hasta  string        `json:"hasta"`
+	Dias   []ScheduleDay `json:"dias"`
+}
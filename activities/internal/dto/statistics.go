@@ -0,0 +1,78 @@
+package dto
+
+type DayDistribution struct {
+	Dia   string `json:"dia"`
+	Count int    `json:"count"`
+}
+
+// StatisticsBreakdown son los totales de las actividades activas de un instructor, un día o una
+// franja horaria (hora de inicio)
+type StatisticsBreakdown struct {
+	Clave         string  `json:"clave"`
+	Actividades   int     `json:"actividades"`
+	Inscripciones int     `json:"inscripciones"`
+	Capacidad     int     `json:"capacidad"`
+	Utilizacion   float64 `json:"utilizacion"` // porcentaje de la capacidad ocupada
+}
+
+// EnrollmentTrend son las inscripciones y desinscripciones de una semana ISO, según el historial
+type EnrollmentTrend struct {
+	Semana           string `json:"semana"` // YYYY-Www
+	Desde            string `json:"desde"`  // lunes de la semana
+	Inscripciones    int    `json:"inscripciones"`
+	Desinscripciones int    `json:"desinscripciones"`
+	Neto             int    `json:"neto"`
+}
+
+// HistoryWeekCount es la cantidad de registros del historial con una acción en una semana ISO
+type HistoryWeekCount struct {
+	Year   int
+	Week   int
+	Action string
+	Count  int
+}
+
+// RankedActivity es una actividad en el ranking de popularidad según sus inscriptos a la serie
+type RankedActivity struct {
+	Posicion   int     `json:"posicion"`
+	ActivityID string  `json:"id_actividad"`
+	Titulo     string  `json:"titulo"`
+	Instructor string  `json:"instructor"`
+	DiaSemana  string  `json:"dia"`
+	HoraInicio string  `json:"hora_inicio"`
+	Inscriptos int     `json:"inscriptos"`
+	Cupo       int     `json:"cupo"`
+	Ocupacion  float64 `json:"ocupacion"`
+}
+
+// StatisticsFilters es el período (YYYY-MM-DD, inclusive) de las tendencias de las estadísticas y
+// el largo de los rankings de popularidad
+type StatisticsFilters struct {
+	Desde string
+	Hasta string
+	Limit int
+}
+
+type ActivityStatistics struct {
+	TotalActivities       int                   `json:"total_actividades"`
+	TotalEnrollments      int                   `json:"total_inscripciones"`
+	AverageEnrollmentRate float64               `json:"tasa_promedio_inscripcion"`
+	TotalCapacity         int                   `json:"capacidad_total"`
+	CapacityUtilization   float64               `json:"utilizacion_capacidad"`
+	ActivitiesByDay       []DayDistribution     `json:"actividades_por_dia"`
+	MostPopularActivity   *Activity             `json:"actividad_mas_popular"` // nil si ninguna tiene inscriptos
+	FullActivitiesCount   int                   `json:"actividades_llenas"`
+	AvailableActivities   int                   `json:"actividades_disponibles"`
+	ByInstructor          []StatisticsBreakdown `json:"por_instructor"`
+	ByDay                 []StatisticsBreakdown `json:"por_dia"`
+	ByTimeSlot            []StatisticsBreakdown `json:"por_franja"`
+	MostPopular           []RankedActivity      `json:"mas_populares"`   // solo actividades con inscriptos
+	LeastPopular          []RankedActivity      `json:"menos_populares"` // de menos a más inscriptos
+
+	// Período de las tendencias, tomadas del historial de inscripciones
+	Desde                  string            `json:"desde"`
+	Hasta                  string            `json:"hasta"`
+	PeriodEnrollments      int               `json:"inscripciones_periodo"`
+	PeriodUnenrollments    int               `json:"desinscripciones_periodo"`
+	WeeklyEnrollmentsTrend []EnrollmentTrend `json:"tendencia_semanal"`
+}
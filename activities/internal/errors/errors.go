@@ -12,6 +12,11 @@ var (
 	ErrInvalidUserID         = errors.New("invalid user id format")
 	ErrInvalidIDFormat       = errors.New("invalid ID format")
 	ErrActivityAlreadyExists = errors.New("activity with the same ID already exists")
+	ErrActivityInactive      = errors.New("activity is not active")
+	ErrRoomNotFound          = errors.New("room not found")
+	ErrRoomAlreadyExists     = errors.New("room with the same name already exists")
+	ErrVersionConflict       = errors.New("activity was modified by another request")
+	ErrCalendarTokenNotFound = errors.New("calendar token not found")
 )
 
 // Service validation errors
@@ -27,6 +32,52 @@ var (
 	ErrActivityDoesNotExist      = errors.New("activity does not exist")
 	ErrCapacityLessThanInscribed = errors.New("cupo cannot be less than the number of inscribed users")
 	ErrInscritosExceedCapacity   = errors.New("number of inscritos cannot exceed capacity")
+	ErrInvalidSort               = errors.New("invalid sort field")
+	ErrInvalidTimeFormat         = errors.New("time must use the HH:MM format")
+	ErrInvalidPagination         = errors.New("page and limit must be positive integers")
+	ErrInvalidPatch              = errors.New("body must be a JSON merge patch object")
+	ErrReadOnlyField             = errors.New("fields cannot be modified")
+	ErrRosterNotEditable         = errors.New("usuarios_inscritos can only be changed through the roster endpoints")
+	ErrInvalidDate               = errors.New("dates must use the YYYY-MM-DD format")
+	ErrInvalidDateRange          = errors.New("invalid date range")
+	ErrInvalidRecurrence         = errors.New("invalid recurrence")
+	ErrOccurrenceNotFound        = errors.New("activity has no class on that date")
+	ErrOccurrencePast            = errors.New("class has already started")
+	ErrOccurrenceCancelled       = errors.New("class is cancelled")
+	ErrReasonRequired            = errors.New("motivo is required and cannot be empty")
+	ErrRoomNameRequired          = errors.New("nombre is required and cannot be empty")
+	ErrRoomCapacityRequired      = errors.New("capacidad must be greater than zero")
+	ErrInvalidResource           = errors.New("recursos must have a name and a positive quantity")
+	ErrUnknownRoom               = errors.New("id_sala does not match an existing room")
+	ErrResourceNotInRoom         = errors.New("recurso is not available in the room")
+	ErrCapacityExceedsRoom       = errors.New("cupo cannot exceed the capacity of the room")
+	ErrRoomCapacityTooSmall      = errors.New("capacidad cannot be less than the cupo of the activities in the room")
+	ErrRoomConflict              = errors.New("room is already booked at that time")
+	ErrRoomInUse                 = errors.New("room is used by activities")
+	ErrOccurrenceNotStarted      = errors.New("class has not started yet")
+	ErrInvalidAttendance         = errors.New("usuarios must be inscribed in the class")
+	ErrInvalidWeek               = errors.New("week must use the YYYY-Www or YYYY-MM-DD format")
+	ErrInvalidImport             = errors.New("invalid import file")
+	ErrInvalidFormat             = errors.New("format must be csv or json")
+	ErrExternalKeyRequired       = errors.New("clave_externa is required and cannot be empty")
+	ErrDuplicateExternalKey      = errors.New("clave_externa is repeated in the file")
+	ErrInvalidPolicy             = errors.New("politica values cannot be negative")
+	ErrEnrollmentNotOpen         = errors.New("enrollment is not open yet")
+	ErrEnrollmentClosed          = errors.New("enrollment is closed")
+	ErrInvalidRoster             = errors.New("usuarios must be a list of positive user ids")
+	ErrInvalidRosterUsers        = errors.New("some users cannot be enrolled")
+	ErrSameActivity              = errors.New("destino must be a different activity")
+)
+
+// Users API errors
+var (
+	ErrUserNotFound        = errors.New("user not found")
+	ErrUsersUnavailable    = errors.New("users service is unavailable")
+	ErrMembershipRequired  = errors.New("user has no membership plan")
+	ErrMembershipNotValid  = errors.New("membership plan is not valid on the class date")
+	ErrWeeklyQuotaExceeded = errors.New("weekly class quota exceeded")
+	// sin clave interna users-api no informa los planes: no se verifican
+	ErrMembershipChecksDisabled = errors.New("membership checks are disabled")
 )
 
 // Service operation errors
@@ -7,8 +7,9 @@ import (
 
 func CORSMiddleware(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
-	ctx.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	ctx.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	ctx.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+	ctx.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match")
+	ctx.Header("Access-Control-Expose-Headers", "ETag")
 
 	if ctx.Request.Method == http.MethodOptions {
 		ctx.Status(http.StatusNoContent)
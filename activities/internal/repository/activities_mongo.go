@@ -6,7 +6,9 @@ import (
 	"context"
 	"errors"
 	"log"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -37,17 +39,81 @@ func NewMongoActivitiesRepository(ctx context.Context, uri, dbName, collectionNa
 		return nil
 	}
 
-	return &MongoActivitiesRepository{
+	repo := &MongoActivitiesRepository{
 		col: client.Database(dbName).Collection(collectionName), // Conecta con la colección "activities"
 	}
+	repo.ensureIndexes(ctx)
+	repo.backfillFields(ctx)
+
+	return repo
+}
+
+// activeFilter matchea las actividades activas. Los documentos creados antes de que existiera
+// el campo activa se consideran activos.
+var activeFilter = bson.M{"$ne": false}
+
+// backfillFields completa los campos agregados después de que existieran actividades guardadas:
+// las actividades sin activa se marcan como activas, las que no tienen fecha_actualizacion
+// toman su fecha_creacion y las que no tienen version arrancan en 1
+func (r *MongoActivitiesRepository) backfillFields(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := r.col.UpdateMany(ctx, bson.M{"activa": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"activa": true}})
+	if err != nil {
+		log.Printf("Error backfilling activa field: %v", err)
+	} else if result.ModifiedCount > 0 {
+		log.Printf("%d activities marked as active", result.ModifiedCount)
+	}
+
+	result, err = r.col.UpdateMany(ctx,
+		bson.M{"fecha_actualizacion": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"fecha_actualizacion": "$fecha_creacion"}}}},
+	)
+	if err != nil {
+		log.Printf("Error backfilling fecha_actualizacion field: %v", err)
+	} else if result.ModifiedCount > 0 {
+		log.Printf("%d activities got fecha_actualizacion", result.ModifiedCount)
+	}
+
+	result, err = r.col.UpdateMany(ctx, bson.M{"version": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"version": int64(1)}})
+	if err != nil {
+		log.Printf("Error backfilling version field: %v", err)
+	} else if result.ModifiedCount > 0 {
+		log.Printf("%d activities got version 1", result.ModifiedCount)
+	}
+}
+
+// ensureIndexes crea los índices que usan los filtros de List y las búsquedas por usuario
+func (r *MongoActivitiesRepository) ensureIndexes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "profesor_id", Value: 1}}},
+		{Keys: bson.D{{Key: "dia_semana", Value: 1}, {Key: "hora_inicio", Value: 1}}},
+		{Keys: bson.D{{Key: "activa", Value: 1}}},
+		{Keys: bson.D{{Key: "usuarios_inscritos", Value: 1}}},
+		{Keys: bson.D{{Key: "id_sala", Value: 1}}},
+		{Keys: bson.D{{Key: "clave_externa", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+	})
+	if err != nil {
+		log.Printf("Error creating indexes: %v", err)
+	}
 }
 
-// List obtiene todos los activities de DB
+// Database devuelve la base de datos de la colección de activities, para que otros
+// repositorios compartan la misma conexión
+func (r *MongoActivitiesRepository) Database() *mongo.Database {
+	return r.col.Database()
+}
+
+// List obtiene todos los activities activos de DB
 func (r *MongoActivitiesRepository) List(ctx context.Context) ([]dto.Activity, error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	cur, err := r.col.Find(ctx, bson.M{})
+	cur, err := r.col.Find(ctx, bson.M{"activa": activeFilter})
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +132,127 @@ func (r *MongoActivitiesRepository) List(ctx context.Context) ([]dto.Activity, e
 	return dtoActivities, nil
 }
 
-// GetMany obtiene multiples activities por IDs (ignora IDs no encontrados)
+// listSortFields traduce los campos de orden de la API a los campos del documento
+var listSortFields = map[string]string{
+	"titulo":              "nombre",
+	"instructor":          "profesor_id",
+	"dia":                 "dia_orden",
+	"hora_inicio":         "hora_inicio",
+	"hora_fin":            "hora_fin",
+	"cupo":                "capacidad_max",
+	"lugares_disponibles": "lugares_disponibles",
+	"fecha_creacion":      "fecha_creacion",
+}
+
+// ListFiltered obtiene una página de activities aplicando filtros y orden con un pipeline de agregación
+func (r *MongoActivitiesRepository) ListFiltered(ctx context.Context, filters dto.ActivityListFilters) (dto.ActivitiesPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	match := bson.M{}
+	if filters.Dia != "" {
+		match["dia_semana"] = filters.Dia
+	}
+	if filters.Instructor != "" {
+		match["profesor_id"] = bson.M{"$regex": regexp.QuoteMeta(filters.Instructor), "$options": "i"}
+	}
+	if filters.HoraDesde != "" {
+		match["hora_inicio"] = bson.M{"$gte": filters.HoraDesde}
+	}
+	if filters.HoraHasta != "" {
+		match["hora_fin"] = bson.M{"$lte": filters.HoraHasta}
+	}
+	if filters.Activa != nil {
+		if *filters.Activa {
+			match["activa"] = activeFilter
+		} else {
+			match["activa"] = false
+		}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{
+			"dia_orden": bson.M{"$indexOfArray": bson.A{dto.DiasSemana, "$dia_semana"}},
+			"lugares_disponibles": bson.M{"$subtract": bson.A{
+				"$capacidad_max",
+				bson.M{"$size": bson.M{"$ifNull": bson.A{"$usuarios_inscritos", bson.A{}}}},
+			}},
+		}}},
+	}
+
+	if filters.Disponible != nil {
+		if *filters.Disponible {
+			pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"lugares_disponibles": bson.M{"$gt": 0}}}})
+		} else {
+			pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"lugares_disponibles": bson.M{"$lte": 0}}}})
+		}
+	}
+
+	sort := bson.D{}
+	for _, field := range filters.Sort {
+		direction := 1
+		if strings.HasPrefix(field, "-") {
+			direction = -1
+			field = strings.TrimPrefix(field, "-")
+		}
+		if docField, ok := listSortFields[field]; ok {
+			sort = append(sort, bson.E{Key: docField, Value: direction})
+		}
+	}
+	// _id como desempate para que el orden sea estable entre páginas
+	sort = append(sort, bson.E{Key: "_id", Value: 1})
+
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: sort}},
+		bson.D{{Key: "$facet", Value: bson.M{
+			"items": bson.A{
+				bson.M{"$skip": int64((filters.Page - 1) * filters.Limit)},
+				bson.M{"$limit": int64(filters.Limit)},
+			},
+			"total": bson.A{bson.M{"$count": "count"}},
+		}}},
+	)
+
+	cur, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return dto.ActivitiesPage{}, err
+	}
+	defer cur.Close(ctx)
+
+	var results []struct {
+		Items []dao.ActivityDAO `bson:"items"`
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+	}
+	if err := cur.All(ctx, &results); err != nil {
+		return dto.ActivitiesPage{}, err
+	}
+
+	page := dto.ActivitiesPage{
+		Activities: dto.Activities{},
+		Page:       filters.Page,
+		Limit:      filters.Limit,
+	}
+	if len(results) == 0 {
+		return page, nil
+	}
+
+	for _, daoAct := range results[0].Items {
+		page.Activities = append(page.Activities, daoAct.ToDomain())
+	}
+	if len(results[0].Total) > 0 {
+		page.Total = results[0].Total[0].Count
+	}
+	page.Count = len(page.Activities)
+	page.TotalPages = (page.Total + filters.Limit - 1) / filters.Limit
+	page.HasNext = filters.Page < page.TotalPages
+
+	return page, nil
+}
+
+// GetMany obtiene multiples activities activos por IDs (ignora IDs no encontrados e inactivos)
 func (r *MongoActivitiesRepository) GetMany(ctx context.Context, ids []string) ([]dto.Activity, error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
@@ -84,7 +270,7 @@ func (r *MongoActivitiesRepository) GetMany(ctx context.Context, ids []string) (
 		return []dto.Activity{}, nil
 	}
 
-	filter := bson.M{"_id": bson.M{"$in": objectIDs}}
+	filter := bson.M{"_id": bson.M{"$in": objectIDs}, "activa": activeFilter}
 	cur, err := r.col.Find(ctx, filter)
 	if err != nil {
 		return nil, err
@@ -109,7 +295,6 @@ func (r *MongoActivitiesRepository) Create(ctx context.Context, activity dto.Act
 	activityDAO := dao.FromDomainDAO(activity)
 
 	activityDAO.ID = primitive.NewObjectID()
-	activityDAO.FechaCreacion = time.Now().UTC()
 
 	_, err := r.col.InsertOne(ctx, activityDAO)
 	if err != nil {
@@ -122,52 +307,86 @@ func (r *MongoActivitiesRepository) Create(ctx context.Context, activity dto.Act
 	return dao.ToDomainAdministration(activityDAO), nil
 }
 
-// Update actualiza un activity existente
+// GetByExternalKey obtiene una actividad (activa o no) por su clave externa
+func (r *MongoActivitiesRepository) GetByExternalKey(ctx context.Context, key string) (dto.ActivityAdministration, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var activityDAO dao.ActivityDAO
+	err := r.col.FindOne(ctx, bson.M{"clave_externa": key}).Decode(&activityDAO)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return dto.ActivityAdministration{}, ErrActivityNotFound
+	}
+	if err != nil {
+		return dto.ActivityAdministration{}, err
+	}
+	return dao.ToDomainAdministration(activityDAO), nil
+}
+
+// Update reemplaza los campos editables de un activity existente. Si activity.Version es mayor a cero solo se actualiza
+// si la versión guardada coincide; si no, devuelve ErrVersionConflict.
 func (r *MongoActivitiesRepository) Update(ctx context.Context, id string, activity dto.ActivityAdministration) (dto.ActivityAdministration, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return dto.ActivityAdministration{}, ErrInvalidIDFormat
 	}
 
-	// Preparar los campos a actualizar
-	// Construir update dinámico a partir de campos no vacíos
-	set := bson.M{}
-	if activity.Nombre != "" {
-		set["nombre"] = activity.Nombre
+	// Reemplazo completo de los campos editables: un valor vacío borra el campo
+	set := bson.M{
+		"nombre":        activity.Nombre,
+		"descripcion":   activity.Descripcion,
+		"profesor_id":   activity.Profesor,
+		"dia_semana":    activity.DiaSemana,
+		"hora_inicio":   activity.HoraInicio,
+		"hora_fin":      activity.HoraFin,
+		"foto_url":      activity.FotoUrl,
+		"capacidad_max": activity.CapacidadMax,
+		"recurrencia":   activity.Recurrencia,
+		"politica":      activity.Politica,
+		"id_sala":       activity.SalaID,
+		"recurso":       activity.Recurso,
 	}
-	if activity.Descripcion != "" {
-		set["descripcion"] = activity.Descripcion
+	// La clave externa la asignan las importaciones; los formularios no la envían
+	if activity.ClaveExterna != "" {
+		set["clave_externa"] = activity.ClaveExterna
 	}
-	if activity.Profesor != "" {
-		set["profesor_id"] = activity.Profesor
-	}
-	if activity.DiaSemana != "" {
-		set["dia_semana"] = activity.DiaSemana
-	}
-	if activity.HoraInicio != "" {
-		set["hora_inicio"] = activity.HoraInicio
-	}
-	if activity.HoraFin != "" {
-		set["hora_fin"] = activity.HoraFin
-	}
-	if activity.FotoUrl != "" {
-		set["foto_url"] = activity.FotoUrl
-	}
-	if activity.CapacidadMax > 0 {
-		set["capacidad_max"] = activity.CapacidadMax
+	// fecha_creacion no se modifica nunca
+	set["fecha_actualizacion"] = time.Now().UTC()
+
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+
+	filter := bson.M{"_id": objID}
+	if activity.Version > 0 {
+		filter["version"] = activity.Version
 	}
-	// If admin provided explicit users list, convert to integer slice and set it
-	if activity.UsersInscribed != nil {
-		set["usuarios_inscritos"] = activity.UsersInscribed
+
+	result, err := r.col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return dto.ActivityAdministration{}, err
 	}
-	if len(set) == 0 {
-		return dto.ActivityAdministration{}, ErrNoFieldsToUpdate
+	if result.MatchedCount == 0 {
+		if activity.Version > 0 {
+			if _, err := r.GetByID(ctx, id); err == nil {
+				return dto.ActivityAdministration{}, ErrVersionConflict
+			}
+		}
+		return dto.ActivityAdministration{}, ErrActivityNotFound
 	}
-	set["fecha_creacion"] = time.Now().UTC()
 
-	update := bson.M{"$set": set}
+	return r.GetByID(ctx, id)
+}
 
-	result, err := r.col.UpdateByID(ctx, objID, update)
+// SetActiva activa o da de baja (soft delete) un activity sin tocar sus inscripciones
+func (r *MongoActivitiesRepository) SetActiva(ctx context.Context, id string, activa bool) (dto.ActivityAdministration, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return dto.ActivityAdministration{}, ErrInvalidIDFormat
+	}
+
+	result, err := r.col.UpdateByID(ctx, objID, bson.M{
+		"$set": bson.M{"activa": activa, "fecha_actualizacion": time.Now().UTC()},
+		"$inc": bson.M{"version": 1},
+	})
 	if err != nil {
 		return dto.ActivityAdministration{}, err
 	}
@@ -178,7 +397,7 @@ func (r *MongoActivitiesRepository) Update(ctx context.Context, id string, activ
 	return r.GetByID(ctx, id)
 }
 
-// Delete elimina un activity por ID
+// Delete elimina definitivamente un activity por ID, incluidas sus inscripciones
 func (r *MongoActivitiesRepository) Delete(ctx context.Context, id string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -196,6 +415,30 @@ func (r *MongoActivitiesRepository) Delete(ctx context.Context, id string) error
 	return nil
 }
 
+// Restore vuelve a insertar un activity eliminado con su ID, estado, fechas, versión e
+// inscripciones originales (rollback de Purge)
+func (r *MongoActivitiesRepository) Restore(ctx context.Context, activity dto.ActivityAdministration) error {
+	objID, err := primitive.ObjectIDFromHex(activity.ID)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	activityDAO := dao.FromDomainDAO(activity)
+	activityDAO.ID = objID
+	activityDAO.Activa = activity.Activa
+	activityDAO.FechaCreacion = activity.FechaCreacion
+	activityDAO.FechaActualizacion = activity.FechaActualizacion
+	activityDAO.Version = activity.Version
+
+	if _, err := r.col.InsertOne(ctx, activityDAO); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrActivityAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
 // GetByID busca un activity por su ID
 func (r *MongoActivitiesRepository) GetByID(ctx context.Context, id string) (dto.ActivityAdministration, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -232,6 +475,10 @@ func (r *MongoActivitiesRepository) Inscribir(ctx context.Context, id string, us
 		return "", ErrActivityNotFound
 	}
 
+	if !act.Activa {
+		return "", ErrActivityInactive
+	}
+
 	if len(act.UsersInscribed) >= (act.CapacidadMax) {
 		return "", ErrActivityFull
 	}
@@ -243,8 +490,10 @@ func (r *MongoActivitiesRepository) Inscribir(ctx context.Context, id string, us
 		}
 	}
 
-	update := bson.M{"$push": bson.M{"usuarios_inscritos": idint}}
-	result, err := r.col.UpdateByID(ctx, objID, update)
+	// el filtro sobre activa evita inscribir si la actividad se dio de baja mientras tanto
+	// la versión también cambia: un admin que leyó la actividad antes ve el conflicto
+	update := bson.M{"$push": bson.M{"usuarios_inscritos": idint}, "$inc": bson.M{"version": 1}}
+	result, err := r.col.UpdateOne(ctx, bson.M{"_id": objID, "activa": activeFilter}, update)
 	if err != nil {
 		return "", err
 	}
@@ -281,7 +530,7 @@ func (r *MongoActivitiesRepository) Desinscribir(ctx context.Context, id string,
 		return "", ErrUserNotInscribed
 	}
 
-	update := bson.M{"$pull": bson.M{"usuarios_inscritos": idint}}
+	update := bson.M{"$pull": bson.M{"usuarios_inscritos": idint}, "$inc": bson.M{"version": 1}}
 	result, err := r.col.UpdateByID(ctx, objID, update)
 	if err != nil {
 		return "", err
@@ -371,3 +620,167 @@ func (r *MongoActivitiesRepository) ListAllForAdmin(ctx context.Context) ([]dto.
 
 	return dtoActivities, nil
 }
+
+// Statistics calcula con un pipeline de agregación los totales de las actividades activas, sus
+// desgloses por instructor, día y franja horaria (hora de inicio) y los rankings de las limit
+// actividades más y menos populares. Los empates se desempatan por ocupación, título e ID. La
+// utilización de los desgloses se calcula en el servicio.
+func (r *MongoActivitiesRepository) Statistics(ctx context.Context, limit int) (dto.ActivityStatistics, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	group := func(key any) bson.M {
+		return bson.M{
+			"_id":           key,
+			"actividades":   bson.M{"$sum": 1},
+			"inscripciones": bson.M{"$sum": "$inscriptos"},
+			"capacidad":     bson.M{"$sum": "$capacidad_max"},
+		}
+	}
+	byDay := group("$dia_semana")
+	byDay["orden"] = bson.M{"$first": "$dia_orden"}
+	totals := group(nil)
+	totals["llenas"] = bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gte": bson.A{"$inscriptos", "$capacidad_max"}}, 1, 0}}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"activa": activeFilter}}},
+		{{Key: "$addFields", Value: bson.M{
+			"inscriptos": bson.M{"$size": bson.M{"$ifNull": bson.A{"$usuarios_inscritos", bson.A{}}}},
+			"dia_orden":  bson.M{"$indexOfArray": bson.A{dto.DiasSemana, "$dia_semana"}},
+			"franja":     bson.M{"$concat": bson.A{bson.M{"$substrCP": bson.A{"$hora_inicio", 0, 2}}, ":00"}},
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"ocupacion": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{"$capacidad_max", 0}},
+				bson.M{"$multiply": bson.A{bson.M{"$divide": bson.A{"$inscriptos", "$capacidad_max"}}, 100}},
+				0,
+			}},
+		}}},
+		{{Key: "$facet", Value: bson.M{
+			"totales": bson.A{bson.M{"$group": totals}},
+			"por_dia": bson.A{
+				bson.M{"$group": byDay},
+				bson.M{"$sort": bson.D{{Key: "orden", Value: 1}, {Key: "_id", Value: 1}}},
+			},
+			"por_instructor": bson.A{
+				bson.M{"$group": group("$profesor_id")},
+				bson.M{"$sort": bson.D{{Key: "inscripciones", Value: -1}, {Key: "_id", Value: 1}}},
+			},
+			"por_franja": bson.A{
+				bson.M{"$group": group("$franja")},
+				bson.M{"$sort": bson.D{{Key: "_id", Value: 1}}},
+			},
+			"mas_populares": bson.A{
+				bson.M{"$match": bson.M{"inscriptos": bson.M{"$gt": 0}}},
+				bson.M{"$sort": bson.D{{Key: "inscriptos", Value: -1}, {Key: "ocupacion", Value: -1}, {Key: "nombre", Value: 1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": limit},
+			},
+			"menos_populares": bson.A{
+				bson.M{"$sort": bson.D{{Key: "inscriptos", Value: 1}, {Key: "ocupacion", Value: 1}, {Key: "nombre", Value: 1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": limit},
+			},
+		}}},
+	}
+
+	cur, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return dto.ActivityStatistics{}, err
+	}
+	defer cur.Close(ctx)
+
+	type breakdown struct {
+		Key           string `bson:"_id"`
+		Actividades   int    `bson:"actividades"`
+		Inscripciones int    `bson:"inscripciones"`
+		Capacidad     int    `bson:"capacidad"`
+		Llenas        int    `bson:"llenas"`
+	}
+	type ranked struct {
+		dao.ActivityDAO `bson:",inline"`
+		Inscriptos      int     `bson:"inscriptos"`
+		Ocupacion       float64 `bson:"ocupacion"`
+	}
+	var results []struct {
+		Totales        []breakdown `bson:"totales"`
+		PorDia         []breakdown `bson:"por_dia"`
+		PorInstructor  []breakdown `bson:"por_instructor"`
+		PorFranja      []breakdown `bson:"por_franja"`
+		MasPopulares   []ranked    `bson:"mas_populares"`
+		MenosPopulares []ranked    `bson:"menos_populares"`
+	}
+	if err := cur.All(ctx, &results); err != nil {
+		return dto.ActivityStatistics{}, err
+	}
+
+	toDomain := func(groups []breakdown) []dto.StatisticsBreakdown {
+		breakdowns := make([]dto.StatisticsBreakdown, len(groups))
+		for i, g := range groups {
+			breakdowns[i] = dto.StatisticsBreakdown{Clave: g.Key, Actividades: g.Actividades, Inscripciones: g.Inscripciones, Capacidad: g.Capacidad}
+		}
+		return breakdowns
+	}
+
+	toRanking := func(activities []ranked) []dto.RankedActivity {
+		ranking := make([]dto.RankedActivity, len(activities))
+		for i, a := range activities {
+			ranking[i] = dto.RankedActivity{
+				Posicion:   i + 1,
+				ActivityID: a.ID.Hex(),
+				Titulo:     a.Nombre,
+				Instructor: a.Profesor,
+				DiaSemana:  a.DiaSemana,
+				HoraInicio: a.HoraInicio,
+				Inscriptos: a.Inscriptos,
+				Cupo:       a.CapacidadMax,
+				Ocupacion:  a.Ocupacion,
+			}
+		}
+		return ranking
+	}
+
+	stats := dto.ActivityStatistics{}
+	if len(results) == 0 {
+		return stats, nil
+	}
+	result := results[0]
+	if len(result.Totales) > 0 {
+		stats.TotalActivities = result.Totales[0].Actividades
+		stats.TotalEnrollments = result.Totales[0].Inscripciones
+		stats.TotalCapacity = result.Totales[0].Capacidad
+		stats.FullActivitiesCount = result.Totales[0].Llenas
+	}
+	stats.ByDay = toDomain(result.PorDia)
+	stats.ByInstructor = toDomain(result.PorInstructor)
+	stats.ByTimeSlot = toDomain(result.PorFranja)
+	stats.MostPopular = toRanking(result.MasPopulares)
+	stats.LeastPopular = toRanking(result.MenosPopulares)
+	if len(result.MasPopulares) > 0 {
+		mostPopular := result.MasPopulares[0].ToDomain()
+		stats.MostPopularActivity = &mostPopular
+	}
+	return stats, nil
+}
+
+// ListByRoom devuelve las actividades (activas o no) asignadas a una sala
+func (r *MongoActivitiesRepository) ListByRoom(ctx context.Context, salaID string) ([]dto.ActivityAdministration, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cur, err := r.col.Find(ctx, bson.M{"id_sala": salaID})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var daoActivities []dao.ActivityDAO
+	if err := cur.All(ctx, &daoActivities); err != nil {
+		return nil, err
+	}
+
+	dtoActivities := make([]dto.ActivityAdministration, len(daoActivities))
+	for i, daoAct := range daoActivities {
+		dtoActivities[i] = dao.ToDomainAdministration(daoAct)
+	}
+
+	return dtoActivities, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"activities/internal/dao"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoCalendarTokensRepository guarda los tokens de los calendarios (.ics) de los usuarios
+type MongoCalendarTokensRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoCalendarTokensRepository(ctx context.Context, db *mongo.Database, collectionName string) *MongoCalendarTokensRepository {
+	repo := &MongoCalendarTokensRepository{
+		col: db.Collection(collectionName),
+	}
+	repo.ensureIndexes(ctx)
+
+	return repo
+}
+
+func (r *MongoCalendarTokensRepository) ensureIndexes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id_usuario", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "token", Value: 1}}, Options: options.Index().SetUnique(true)},
+	})
+	if err != nil {
+		log.Printf("Error creating calendar tokens indexes: %v", err)
+	}
+}
+
+// GetByUser devuelve el token del usuario
+func (r *MongoCalendarTokensRepository) GetByUser(ctx context.Context, userID string) (string, error) {
+	return r.findOne(ctx, bson.M{"id_usuario": userID}, func(t dao.CalendarTokenDAO) string { return t.Token })
+}
+
+// GetUserID devuelve el usuario dueño del token
+func (r *MongoCalendarTokensRepository) GetUserID(ctx context.Context, token string) (string, error) {
+	return r.findOne(ctx, bson.M{"token": token}, func(t dao.CalendarTokenDAO) string { return t.UserID })
+}
+
+func (r *MongoCalendarTokensRepository) findOne(ctx context.Context, filter bson.M, field func(dao.CalendarTokenDAO) string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var tokenDAO dao.CalendarTokenDAO
+	err := r.col.FindOne(ctx, filter).Decode(&tokenDAO)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", ErrCalendarTokenNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return field(tokenDAO), nil
+}
+
+// Set guarda el token del usuario, reemplazando el anterior
+func (r *MongoCalendarTokensRepository) Set(ctx context.Context, userID, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": dao.CalendarTokenDAO{UserID: userID, Token: token, FechaCreacion: time.Now().UTC()}}
+	_, err := r.col.UpdateOne(ctx, bson.M{"id_usuario": userID}, update, options.Update().SetUpsert(true))
+	return err
+}
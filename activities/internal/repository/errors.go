@@ -14,4 +14,9 @@ var (
 	ErrInvalidUserID         = errors.ErrInvalidUserID
 	ErrInvalidIDFormat       = errors.ErrInvalidIDFormat
 	ErrActivityAlreadyExists = errors.ErrActivityAlreadyExists
+	ErrActivityInactive      = errors.ErrActivityInactive
+	ErrVersionConflict       = errors.ErrVersionConflict
+	ErrRoomNotFound          = errors.ErrRoomNotFound
+	ErrRoomAlreadyExists     = errors.ErrRoomAlreadyExists
+	ErrCalendarTokenNotFound = errors.ErrCalendarTokenNotFound
 )
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"activities/internal/dao"
+	"activities/internal/dto"
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoHistoryRepository guarda el historial de cambios de las actividades en una colección
+// append-only: solo expone operaciones de inserción y consulta
+type MongoHistoryRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoHistoryRepository(ctx context.Context, db *mongo.Database, collectionName string) *MongoHistoryRepository {
+	repo := &MongoHistoryRepository{
+		col: db.Collection(collectionName),
+	}
+	repo.ensureIndexes(ctx)
+
+	return repo
+}
+
+func (r *MongoHistoryRepository) ensureIndexes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id_actividad", Value: 1}, {Key: "fecha", Value: -1}}},
+		{Keys: bson.D{{Key: "actor.id_usuario", Value: 1}, {Key: "fecha", Value: -1}}},
+		{Keys: bson.D{{Key: "id_usuario", Value: 1}, {Key: "fecha", Value: -1}}},
+		{Keys: bson.D{{Key: "accion", Value: 1}, {Key: "fecha", Value: 1}}},
+	})
+	if err != nil {
+		log.Printf("Error creating history indexes: %v", err)
+	}
+}
+
+// Append agrega un registro al historial
+func (r *MongoHistoryRepository) Append(ctx context.Context, entry dto.HistoryEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	entryDAO := dao.HistoryEntryFromDomain(entry)
+	entryDAO.ID = primitive.NewObjectID()
+
+	_, err := r.col.InsertOne(ctx, entryDAO)
+	return err
+}
+
+// List devuelve los registros más recientes primero, filtrando por actividad y/o usuario
+func (r *MongoHistoryRepository) List(ctx context.Context, filters dto.HistoryFilters) (dto.HistoryPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if filters.ActivityID != "" {
+		filter["id_actividad"] = filters.ActivityID
+	}
+	if filters.UserID != "" {
+		filter["$or"] = bson.A{
+			bson.M{"actor.id_usuario": filters.UserID},
+			bson.M{"id_usuario": filters.UserID},
+		}
+	}
+
+	total, err := r.col.CountDocuments(ctx, filter)
+	if err != nil {
+		return dto.HistoryPage{}, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "fecha", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((filters.Page - 1) * filters.Limit)).
+		SetLimit(int64(filters.Limit))
+
+	cur, err := r.col.Find(ctx, filter, opts)
+	if err != nil {
+		return dto.HistoryPage{}, err
+	}
+	defer cur.Close(ctx)
+
+	var entriesDAO []dao.HistoryEntryDAO
+	if err := cur.All(ctx, &entriesDAO); err != nil {
+		return dto.HistoryPage{}, err
+	}
+
+	page := dto.HistoryPage{
+		Entries: make([]dto.HistoryEntry, len(entriesDAO)),
+		Total:   int(total),
+		Page:    filters.Page,
+		Limit:   filters.Limit,
+	}
+	for i, entryDAO := range entriesDAO {
+		page.Entries[i] = entryDAO.ToDomain()
+	}
+	page.Count = len(page.Entries)
+	page.HasNext = filters.Page*filters.Limit < page.Total
+
+	return page, nil
+}
+
+// CountByWeek cuenta los registros con alguna de las acciones entre from y to (to excluido),
+// agrupados por semana ISO en la zona horaria local y por acción
+func (r *MongoHistoryRepository) CountByWeek(ctx context.Context, actions []string, from, to time.Time) ([]dto.HistoryWeekCount, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	timezone := time.Local.String()
+	if timezone == "Local" {
+		timezone = "UTC"
+	}
+	date := bson.M{"date": "$fecha", "timezone": timezone}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"accion": bson.M{"$in": actions},
+			"fecha":  bson.M{"$gte": from.UTC(), "$lt": to.UTC()},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"anio":   bson.M{"$isoWeekYear": date},
+				"semana": bson.M{"$isoWeek": date},
+				"accion": "$accion",
+			},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.anio", Value: 1}, {Key: "_id.semana", Value: 1}, {Key: "_id.accion", Value: 1}}}},
+	}
+
+	cur, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var results []struct {
+		ID struct {
+			Anio   int    `bson:"anio"`
+			Semana int    `bson:"semana"`
+			Accion string `bson:"accion"`
+		} `bson:"_id"`
+		Count int `bson:"count"`
+	}
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make([]dto.HistoryWeekCount, len(results))
+	for i, result := range results {
+		counts[i] = dto.HistoryWeekCount{Year: result.ID.Anio, Week: result.ID.Semana, Action: result.ID.Accion, Count: result.Count}
+	}
+	return counts, nil
+}
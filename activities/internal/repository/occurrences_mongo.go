@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"activities/internal/dao"
+	"activities/internal/dto"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoOccurrencesRepository guarda las inscripciones a fechas puntuales de las actividades
+type MongoOccurrencesRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoOccurrencesRepository(ctx context.Context, db *mongo.Database, collectionName string) *MongoOccurrencesRepository {
+	repo := &MongoOccurrencesRepository{
+		col: db.Collection(collectionName),
+	}
+	repo.ensureIndexes(ctx)
+
+	return repo
+}
+
+func (r *MongoOccurrencesRepository) ensureIndexes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := r.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "id_actividad", Value: 1}, {Key: "fecha", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "usuarios_inscritos", Value: 1}, {Key: "fecha", Value: 1}}},
+		{Keys: bson.D{{Key: "asistentes", Value: 1}}},
+		{Keys: bson.D{{Key: "ausentes", Value: 1}}},
+		{Keys: bson.D{{Key: "fecha", Value: 1}}},
+	})
+	if err != nil {
+		log.Printf("Error creating occurrences indexes: %v", err)
+	}
+}
+
+// List devuelve las ocurrencias guardadas de una actividad entre from y to (YYYY-MM-DD, inclusive),
+// incluyendo las reprogramadas a una fecha dentro del rango
+func (r *MongoOccurrencesRepository) List(ctx context.Context, activityID, from, to string) ([]dto.OccurrenceRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	dateRange := bson.M{"$gte": from, "$lte": to}
+	filter := bson.M{
+		"id_actividad": activityID,
+		"$or":          bson.A{bson.M{"fecha": dateRange}, bson.M{"nueva_fecha": dateRange}},
+	}
+	cur, err := r.col.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "fecha", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var occurrencesDAO []dao.OccurrenceDAO
+	if err := cur.All(ctx, &occurrencesDAO); err != nil {
+		return nil, err
+	}
+
+	records := make([]dto.OccurrenceRecord, len(occurrencesDAO))
+	for i, occDAO := range occurrencesDAO {
+		records[i] = occDAO.ToDomain()
+	}
+	return records, nil
+}
+
+// MaxEnrolled devuelve la mayor cantidad de inscriptos individuales en las ocurrencias de la
+// actividad desde la fecha indicada
+func (r *MongoOccurrencesRepository) MaxEnrolled(ctx context.Context, activityID, from string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"id_actividad": activityID, "fecha": bson.M{"$gte": from}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": nil,
+			"max": bson.M{"$max": bson.M{"$size": bson.M{"$ifNull": bson.A{"$usuarios_inscritos", bson.A{}}}}},
+		}}},
+	}
+
+	cur, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+
+	var results []struct {
+		Max int `bson:"max"`
+	}
+	if err := cur.All(ctx, &results); err != nil {
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	return results[0].Max, nil
+}
+
+// Inscribir agrega al usuario a la ocurrencia, creándola si todavía no existe. maxEnrolled es
+// la cantidad máxima de inscriptos individuales que admite (cupo menos inscriptos a la serie);
+// el control se hace en la misma operación para no superar el cupo con inscripciones simultáneas.
+func (r *MongoOccurrencesRepository) Inscribir(ctx context.Context, activityID, fecha, userID string, maxEnrolled int) error {
+	idint, err := strconv.Atoi(userID)
+	if err != nil {
+		return ErrInvalidUserID
+	}
+	if maxEnrolled <= 0 {
+		return ErrActivityFull
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// "usuarios_inscritos.N" no existe si la lista tiene N elementos o menos
+	filter := bson.M{
+		"id_actividad":       activityID,
+		"fecha":              fecha,
+		"usuarios_inscritos": bson.M{"$ne": idint},
+		fmt.Sprintf("usuarios_inscritos.%d", maxEnrolled-1): bson.M{"$exists": false},
+	}
+	update := bson.M{"$push": bson.M{"usuarios_inscritos": idint}}
+
+	_, err = r.col.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err == nil {
+		return nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	// la ocurrencia existe pero no cumple el filtro: ya inscripto o sin lugar
+	var occDAO dao.OccurrenceDAO
+	if err := r.col.FindOne(ctx, bson.M{"id_actividad": activityID, "fecha": fecha}).Decode(&occDAO); err != nil {
+		return err
+	}
+	for _, uid := range occDAO.UsuariosInscritos {
+		if uid == idint {
+			return ErrUserAlreadyInscribed
+		}
+	}
+	if len(occDAO.UsuariosInscritos) >= maxEnrolled {
+		return ErrActivityFull
+	}
+
+	// otra inscripción creó la ocurrencia al mismo tiempo: se reintenta sin upsert
+	result, err := r.col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrActivityFull
+	}
+	return nil
+}
+
+// Desinscribir quita al usuario de la ocurrencia
+func (r *MongoOccurrencesRepository) Desinscribir(ctx context.Context, activityID, fecha, userID string) error {
+	idint, err := strconv.Atoi(userID)
+	if err != nil {
+		return ErrInvalidUserID
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"id_actividad": activityID, "fecha": fecha, "usuarios_inscritos": idint}
+	result, err := r.col.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"usuarios_inscritos": idint}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotInscribed
+	}
+	return nil
+}
+
+// SetEstado guarda el estado, motivo y reprogramación de la ocurrencia (creándola si no existe)
+// sin tocar sus inscriptos. Devuelve la ocurrencia como estaba antes del cambio.
+func (r *MongoOccurrencesRepository) SetEstado(ctx context.Context, record dto.OccurrenceRecord) (dto.OccurrenceRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"id_actividad": record.ActivityID, "fecha": record.Fecha}
+	update := bson.M{
+		"$set": bson.M{
+			"estado":      record.Estado,
+			"motivo":      record.Motivo,
+			"nueva_fecha": record.NuevaFecha,
+			"hora_inicio": record.HoraInicio,
+			"hora_fin":    record.HoraFin,
+		},
+		"$setOnInsert": bson.M{"usuarios_inscritos": bson.A{}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var previous dao.OccurrenceDAO
+	err := r.col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// no existía: el estado anterior es el de la actividad
+		return dto.OccurrenceRecord{ActivityID: record.ActivityID, Fecha: record.Fecha}, nil
+	}
+	if err != nil {
+		return dto.OccurrenceRecord{}, err
+	}
+	return previous.ToDomain(), nil
+}
+
+// SetAsistencia guarda los presentes y ausentes de la clase (creándola si no existe) sin tocar sus
+// inscriptos
+func (r *MongoOccurrencesRepository) SetAsistencia(ctx context.Context, activityID, fecha string, asistentes, ausentes []int) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"id_actividad": activityID, "fecha": fecha}
+	update := bson.M{
+		"$set":         bson.M{"asistentes": asistentes, "ausentes": ausentes},
+		"$setOnInsert": bson.M{"usuarios_inscritos": bson.A{}},
+	}
+	_, err := r.col.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// AddLateCancellation registra que el usuario se desinscribió de la clase fuera de la ventana de
+// cancelación, creando la ocurrencia si todavía no existe
+func (r *MongoOccurrencesRepository) AddLateCancellation(ctx context.Context, activityID, fecha, userID string) error {
+	idint, err := strconv.Atoi(userID)
+	if err != nil {
+		return ErrInvalidUserID
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"id_actividad": activityID, "fecha": fecha}
+	update := bson.M{
+		"$addToSet":    bson.M{"cancelaciones_tardias": idint},
+		"$setOnInsert": bson.M{"usuarios_inscritos": bson.A{}},
+	}
+	_, err = r.col.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// ListByUser devuelve las ocurrencias en las que el usuario se inscribió a la fecha o en las que
+// figura en la asistencia, ordenadas por fecha
+func (r *MongoOccurrencesRepository) ListByUser(ctx context.Context, userID string) ([]dto.OccurrenceRecord, error) {
+	idint, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil, ErrInvalidUserID
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"usuarios_inscritos": idint},
+		bson.M{"asistentes": idint},
+		bson.M{"ausentes": idint},
+	}}
+	cur, err := r.col.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "fecha", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var occurrencesDAO []dao.OccurrenceDAO
+	if err := cur.All(ctx, &occurrencesDAO); err != nil {
+		return nil, err
+	}
+
+	records := make([]dto.OccurrenceRecord, len(occurrencesDAO))
+	for i, occDAO := range occurrencesDAO {
+		records[i] = occDAO.ToDomain()
+	}
+	return records, nil
+}
+
+// AttendanceByActivity suma por actividad los presentes y ausentes de las clases entre from y to
+// (YYYY-MM-DD, inclusive) que tienen la asistencia tomada, y las cancelaciones tardías del período
+func (r *MongoOccurrencesRepository) AttendanceByActivity(ctx context.Context, from, to string) ([]dto.ActivityAttendance, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	size := func(field string) bson.M {
+		return bson.M{"$size": bson.M{"$ifNull": bson.A{field, bson.A{}}}}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"fecha": bson.M{"$gte": from, "$lte": to},
+			"$or": bson.A{
+				bson.M{"asistentes": bson.M{"$exists": true}},
+				bson.M{"ausentes": bson.M{"$exists": true}},
+				bson.M{"cancelaciones_tardias": bson.M{"$exists": true}},
+			},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": "$id_actividad",
+			"clases": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$add": bson.A{size("$asistentes"), size("$ausentes")}}, 0}}, 1, 0,
+			}}},
+			"presentes":             bson.M{"$sum": size("$asistentes")},
+			"ausentes":              bson.M{"$sum": size("$ausentes")},
+			"cancelaciones_tardias": bson.M{"$sum": size("$cancelaciones_tardias")},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cur, err := r.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var results []struct {
+		ActivityID           string `bson:"_id"`
+		Clases               int    `bson:"clases"`
+		Presentes            int    `bson:"presentes"`
+		Ausentes             int    `bson:"ausentes"`
+		CancelacionesTardias int    `bson:"cancelaciones_tardias"`
+	}
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	attendance := make([]dto.ActivityAttendance, len(results))
+	for i, result := range results {
+		attendance[i] = dto.ActivityAttendance{
+			ActivityID:           result.ActivityID,
+			Clases:               result.Clases,
+			Presentes:            result.Presentes,
+			Ausentes:             result.Ausentes,
+			CancelacionesTardias: result.CancelacionesTardias,
+		}
+	}
+	return attendance, nil
+}
+
+// DeleteByActivity elimina todas las ocurrencias guardadas de una actividad
+func (r *MongoOccurrencesRepository) DeleteByActivity(ctx context.Context, activityID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := r.col.DeleteMany(ctx, bson.M{"id_actividad": activityID})
+	return err
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"activities/internal/dao"
+	"activities/internal/dto"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoRoomsRepository guarda las salas y su equipamiento
+type MongoRoomsRepository struct {
+	col *mongo.Collection
+}
+
+func NewMongoRoomsRepository(ctx context.Context, db *mongo.Database, collectionName string) *MongoRoomsRepository {
+	repo := &MongoRoomsRepository{
+		col: db.Collection(collectionName),
+	}
+	repo.ensureIndexes(ctx)
+
+	return repo
+}
+
+func (r *MongoRoomsRepository) ensureIndexes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := r.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "nombre", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("Error creating rooms indexes: %v", err)
+	}
+}
+
+// List devuelve todas las salas ordenadas por nombre
+func (r *MongoRoomsRepository) List(ctx context.Context) ([]dto.Room, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cur, err := r.col.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "nombre", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var roomsDAO []dao.RoomDAO
+	if err := cur.All(ctx, &roomsDAO); err != nil {
+		return nil, err
+	}
+
+	rooms := make([]dto.Room, len(roomsDAO))
+	for i, roomDAO := range roomsDAO {
+		rooms[i] = roomDAO.ToDomain()
+	}
+	return rooms, nil
+}
+
+func (r *MongoRoomsRepository) GetByID(ctx context.Context, id string) (dto.Room, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return dto.Room{}, ErrInvalidIDFormat
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var roomDAO dao.RoomDAO
+	if err := r.col.FindOne(ctx, bson.M{"_id": objID}).Decode(&roomDAO); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dto.Room{}, ErrRoomNotFound
+		}
+		return dto.Room{}, err
+	}
+	return roomDAO.ToDomain(), nil
+}
+
+func (r *MongoRoomsRepository) Create(ctx context.Context, room dto.Room) (dto.Room, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	roomDAO := dao.FromDomainRoomDAO(room)
+	roomDAO.ID = primitive.NewObjectID()
+
+	if _, err := r.col.InsertOne(ctx, roomDAO); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return dto.Room{}, ErrRoomAlreadyExists
+		}
+		return dto.Room{}, err
+	}
+	return roomDAO.ToDomain(), nil
+}
+
+// Update reemplaza el nombre, la capacidad y el equipamiento de la sala
+func (r *MongoRoomsRepository) Update(ctx context.Context, id string, room dto.Room) (dto.Room, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return dto.Room{}, ErrInvalidIDFormat
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{
+		"nombre":    room.Nombre,
+		"capacidad": room.Capacidad,
+		"recursos":  room.Recursos,
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var roomDAO dao.RoomDAO
+	if err := r.col.FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, opts).Decode(&roomDAO); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dto.Room{}, ErrRoomNotFound
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return dto.Room{}, ErrRoomAlreadyExists
+		}
+		return dto.Room{}, err
+	}
+	return roomDAO.ToDomain(), nil
+}
+
+func (r *MongoRoomsRepository) Delete(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidIDFormat
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := r.col.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrRoomNotFound
+	}
+	return nil
+}
@@ -5,52 +5,104 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
-	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type ActivitiesRepository interface {
 	List(ctx context.Context) ([]dto.Activity, error)
+	ListFiltered(ctx context.Context, filters dto.ActivityListFilters) (dto.ActivitiesPage, error)
 	GetMany(ctx context.Context, ids []string) ([]dto.Activity, error)
 	Create(ctx context.Context, activity dto.ActivityAdministration) (dto.ActivityAdministration, error)
 	GetByID(ctx context.Context, id string) (dto.ActivityAdministration, error)
 	Update(ctx context.Context, id string, activity dto.ActivityAdministration) (dto.ActivityAdministration, error)
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, activity dto.ActivityAdministration) error
+	SetActiva(ctx context.Context, id string, activa bool) (dto.ActivityAdministration, error)
 	Inscribir(ctx context.Context, id string, userID string) (string, error)
 	Desinscribir(ctx context.Context, id string, userID string) (string, error)
 	GetInscripcionesByUserID(ctx context.Context, userID string) ([]string, error)
 	GetActivitiesByUserID(ctx context.Context, userID string) (dto.Activities, error)
 	ListAllForAdmin(ctx context.Context) ([]dto.ActivityAdministration, error)
+	ListByRoom(ctx context.Context, salaID string) ([]dto.ActivityAdministration, error)
+	GetByExternalKey(ctx context.Context, key string) (dto.ActivityAdministration, error)
+	Statistics(ctx context.Context, limit int) (dto.ActivityStatistics, error)
 }
 
 type ActivitiesService interface {
 	List(ctx context.Context) ([]dto.Activity, error)
+	ListPage(ctx context.Context, filters dto.ActivityListFilters) (dto.ActivitiesPage, error)
 	GetMany(ctx context.Context, ids []string) ([]dto.Activity, error)
 	Create(ctx context.Context, activity dto.ActivityAdministration) (dto.ActivityAdministration, error)
 	GetByID(ctx context.Context, id string) (dto.ActivityAdministration, error)
 	Update(ctx context.Context, id string, activity dto.ActivityAdministration) (dto.ActivityAdministration, error)
+	Patch(ctx context.Context, id string, patch []byte, version int64) (dto.ActivityAdministration, error)
 	Delete(ctx context.Context, id string) error
+	Deactivate(ctx context.Context, id string) (dto.ActivityAdministration, error)
+	Reactivate(ctx context.Context, id string) (dto.ActivityAdministration, error)
+	Purge(ctx context.Context, id string) error
 	Inscribir(ctx context.Context, id string, userID string) (string, error)
 	Desinscribir(ctx context.Context, id string, userID string) (string, error)
 	GetInscripcionesByUserID(ctx context.Context, userID string) ([]string, error)
-	GetStatistics(ctx context.Context) (dto.ActivityStatistics, error)
+	GetStatistics(ctx context.Context, filters dto.StatisticsFilters) (dto.ActivityStatistics, error)
+	OccupancyHeatmap(ctx context.Context) (dto.OccupancyHeatmap, error)
+	NoShowRates(ctx context.Context, filters dto.StatisticsFilters) (dto.NoShowReport, error)
+	ListHistory(ctx context.Context, filters dto.HistoryFilters) (dto.HistoryPage, error)
+	ListOccurrences(ctx context.Context, activityID, desde, hasta string) ([]dto.Occurrence, error)
+	InscribirOccurrence(ctx context.Context, activityID, fecha, userID string) error
+	DesinscribirOccurrence(ctx context.Context, activityID, fecha, userID string) error
+	CancelOccurrence(ctx context.Context, activityID, fecha, motivo string) (dto.Occurrence, error)
+	RescheduleOccurrence(ctx context.Context, activityID, fecha string, req dto.OccurrenceReschedule) (dto.Occurrence, error)
+	UpcomingOccurrences(ctx context.Context, from, to time.Time) ([]dto.Occurrence, error)
+	ListRooms(ctx context.Context) ([]dto.Room, error)
+	GetRoom(ctx context.Context, id string) (dto.Room, error)
+	CreateRoom(ctx context.Context, room dto.Room) (dto.Room, error)
+	UpdateRoom(ctx context.Context, id string, room dto.Room) (dto.Room, error)
+	DeleteRoom(ctx context.Context, id string) error
+	RecordAttendance(ctx context.Context, activityID, fecha string, presentes []int) (dto.Attendance, error)
+	GetEnrollmentHistory(ctx context.Context, userID string) (dto.EnrollmentHistory, error)
+	CalendarToken(ctx context.Context, userID string, rotate bool) (string, error)
+	Calendar(ctx context.Context, token string) (string, error)
+	WeeklySchedule(ctx context.Context, week string) (dto.WeeklySchedule, error)
+	ScheduleCalendar(ctx context.Context) (string, error)
+	ImportActivities(ctx context.Context, rows []dto.ImportRow, dryRun bool) (dto.ImportReport, error)
+	ExportActivities(ctx context.Context) ([]dto.ActivityAdministration, error)
 }
 
 type RabbitMQPublisher interface {
 	Publish(ctx context.Context, action string, id string) error
 }
 
+// NotificationPublisher publica los eventos de clases que consume el servicio de notificaciones
+type NotificationPublisher interface {
+	PublishEvent(ctx context.Context, event dto.OccurrenceEvent) error
+}
+
 type ActivitiesServiceImpl struct {
 	repository      ActivitiesRepository
 	rabbitPublisher RabbitMQPublisher
+	history         HistoryRepository
+	occurrences     OccurrencesRepository
+	notifier        NotificationPublisher
+	rooms           RoomsRepository
+	calendarTokens  CalendarTokensRepository
+	users           UsersDirectory
 }
 
-func NewActivitiesService(repo ActivitiesRepository, rabbit RabbitMQPublisher) *ActivitiesServiceImpl {
+func NewActivitiesService(repo ActivitiesRepository, rabbit RabbitMQPublisher, history HistoryRepository, occurrences OccurrencesRepository, notifier NotificationPublisher, rooms RoomsRepository, calendarTokens CalendarTokensRepository, users UsersDirectory) *ActivitiesServiceImpl {
 	return &ActivitiesServiceImpl{
 		repository:      repo,
 		rabbitPublisher: rabbit,
+		history:         history,
+		occurrences:     occurrences,
+		notifier:        notifier,
+		rooms:           rooms,
+		calendarTokens:  calendarTokens,
+		users:           users,
 	}
 }
 
@@ -85,6 +137,12 @@ func (s *ActivitiesServiceImpl) validateActivity(a dto.ActivityAdministration) e
 	if !validDays[a.DiaSemana] {
 		return ErrInvalidDay
 	}
+	if err := validateRecurrence(a.Recurrencia); err != nil {
+		return err
+	}
+	if err := validatePolicy(a.Politica); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -94,6 +152,63 @@ func (s *ActivitiesServiceImpl) List(ctx context.Context) ([]dto.Activity, error
 	return s.repository.List(ctx)
 }
 
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+var (
+	timeFormat = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+	validSortFields = map[string]bool{
+		"titulo":              true,
+		"instructor":          true,
+		"dia":                 true,
+		"hora_inicio":         true,
+		"hora_fin":            true,
+		"cupo":                true,
+		"lugares_disponibles": true,
+		"fecha_creacion":      true,
+	}
+)
+
+// ListPage obtiene una página de actividades aplicando filtros y orden.
+// Page y Limit en cero toman los valores por defecto; Limit se acota a maxListLimit.
+func (s *ActivitiesServiceImpl) ListPage(ctx context.Context, filters dto.ActivityListFilters) (dto.ActivitiesPage, error) {
+	if filters.Page < 0 || filters.Limit < 0 {
+		return dto.ActivitiesPage{}, errors.Join(ErrValidation, ErrInvalidPagination)
+	}
+	if filters.Page == 0 {
+		filters.Page = 1
+	}
+	if filters.Limit == 0 {
+		filters.Limit = defaultListLimit
+	}
+	if filters.Limit > maxListLimit {
+		filters.Limit = maxListLimit
+	}
+
+	if filters.Dia != "" && !slices.Contains(dto.DiasSemana, filters.Dia) {
+		return dto.ActivitiesPage{}, errors.Join(ErrValidation, ErrInvalidDay)
+	}
+	for _, hora := range []string{filters.HoraDesde, filters.HoraHasta} {
+		if hora != "" && !timeFormat.MatchString(hora) {
+			return dto.ActivitiesPage{}, errors.Join(ErrValidation, fmt.Errorf("%w: %s", ErrInvalidTimeFormat, hora))
+		}
+	}
+
+	if len(filters.Sort) == 0 {
+		filters.Sort = []string{"dia", "hora_inicio"}
+	}
+	for _, field := range filters.Sort {
+		if !validSortFields[strings.TrimPrefix(field, "-")] {
+			return dto.ActivitiesPage{}, errors.Join(ErrValidation, fmt.Errorf("%w: %s", ErrInvalidSort, field))
+		}
+	}
+
+	return s.repository.ListFiltered(ctx, filters)
+}
+
 // GetMany obtiene multiples actividades por IDs (ignora IDs no encontrados)
 func (s *ActivitiesServiceImpl) GetMany(ctx context.Context, ids []string) ([]dto.Activity, error) {
 	return s.repository.GetMany(ctx, ids)
@@ -101,9 +216,13 @@ func (s *ActivitiesServiceImpl) GetMany(ctx context.Context, ids []string) ([]dt
 
 // Create valida y crea una nueva actividad
 func (s *ActivitiesServiceImpl) Create(ctx context.Context, activity dto.ActivityAdministration) (dto.ActivityAdministration, error) {
+	normalizeRecurrence(&activity)
 	if err := s.validateActivity(activity); err != nil {
 		return dto.ActivityAdministration{}, errors.Join(ErrValidation, err)
 	}
+	if err := s.checkRoom(ctx, activity.Activity, ""); err != nil {
+		return dto.ActivityAdministration{}, err
+	}
 
 	created, err := s.repository.Create(ctx, activity)
 	if err != nil {
@@ -124,6 +243,11 @@ func (s *ActivitiesServiceImpl) Create(ctx context.Context, activity dto.Activit
 	}
 
 	log.Infof("Activity %s created and event published successfully", created.ID)
+	s.recordHistory(ctx, dto.HistoryEntry{
+		ActivityID: created.ID,
+		Action:     HistoryActionCreate,
+		Changes:    diffActivities(dto.ActivityAdministration{}, created),
+	})
 	return created, nil
 }
 
@@ -136,16 +260,31 @@ func (s *ActivitiesServiceImpl) GetByID(ctx context.Context, id string) (dto.Act
 	return act, nil
 }
 
-// Update actualiza una actividad existente
+// Update reemplaza una actividad existente (PUT): todos los campos editables se validan y se guardan
 func (s *ActivitiesServiceImpl) Update(ctx context.Context, id string, activity dto.ActivityAdministration) (dto.ActivityAdministration, error) {
 	currentActivity, err := s.repository.GetByID(ctx, id)
 	if err != nil {
 		return dto.ActivityAdministration{}, errors.Join(ErrActivityDoesNotExist, err)
 	}
 
+	// activity.Version es la versión que el cliente leyó; si ya cambió no se pisa el cambio ajeno
+	if activity.Version > 0 && activity.Version != currentActivity.Version {
+		return dto.ActivityAdministration{}, fmt.Errorf("%w: expected version %d, current is %d", ErrVersionConflict, activity.Version, currentActivity.Version)
+	}
+
+	// los inscriptos solo se cambian con los endpoints de roster, que validan cupo y usuarios
+	if activity.UsersInscribed != nil {
+		return dto.ActivityAdministration{}, errors.Join(ErrValidation, ErrRosterNotEditable)
+	}
+
+	normalizeRecurrence(&activity)
+
 	if err := s.validateActivity(activity); err != nil {
 		return dto.ActivityAdministration{}, errors.Join(ErrValidation, err)
 	}
+	if err := s.checkRoom(ctx, activity.Activity, id); err != nil {
+		return dto.ActivityAdministration{}, err
+	}
 
 	// Validar que la nueva capacidad no sea menor a la cantidad de inscritos
 	if activity.CapacidadMax > 0 {
@@ -155,22 +294,6 @@ func (s *ActivitiesServiceImpl) Update(ctx context.Context, id string, activity
 		}
 	}
 
-	// If admin provided an explicit users list, validate it against capacity
-	if activity.UsersInscribed != nil {
-		// determine capacity to compare: prefer updated capacity if provided, otherwise current
-		capToCheck := 0
-		if activity.CapacidadMax > 0 {
-			capToCheck = activity.CapacidadMax
-		} else {
-			capToCheck = currentActivity.CapacidadMax
-		}
-		if capToCheck > 0 {
-			if len(activity.UsersInscribed) > capToCheck {
-				return dto.ActivityAdministration{}, fmt.Errorf("%w: number of inscritos (%d) cannot exceed capacity (%d)", ErrValidation, len(activity.UsersInscribed), capToCheck)
-			}
-		}
-	}
-
 	updated, err := s.repository.Update(ctx, id, activity)
 	if err != nil {
 		return dto.ActivityAdministration{}, err
@@ -179,8 +302,10 @@ func (s *ActivitiesServiceImpl) Update(ctx context.Context, id string, activity
 	if err := s.rabbitPublisher.Publish(ctx, "update", updated.ID); err != nil {
 		log.Errorf("Failed to publish update event for activity %s: %v", id, err)
 
-		// Rollback: restore the original activity in MongoDB
-		if _, restoreErr := s.repository.Update(ctx, id, currentActivity); restoreErr != nil {
+		// Rollback: restore the original activity in MongoDB, only if nobody changed it since
+		restore := currentActivity
+		restore.Version = updated.Version
+		if _, restoreErr := s.repository.Update(ctx, id, restore); restoreErr != nil {
 			log.Errorf("CRITICAL: Failed to rollback activity %s after RabbitMQ publish failure: %v", id, restoreErr)
 			return dto.ActivityAdministration{}, errors.Join(ErrPublishEventFailed, ErrRollbackFailed, err, restoreErr)
 		}
@@ -190,11 +315,86 @@ func (s *ActivitiesServiceImpl) Update(ctx context.Context, id string, activity
 	}
 
 	log.Infof("Activity %s updated and event published successfully", id)
+	s.recordHistory(ctx, dto.HistoryEntry{
+		ActivityID: id,
+		Action:     HistoryActionUpdate,
+		Changes:    diffActivities(currentActivity, updated),
+	})
 	return updated, nil
 }
 
-// Delete elimina una actividad por ID
+// Delete da de baja una actividad (soft delete): deja de listarse y de aceptar inscripciones,
+// pero conserva sus datos e inscripciones. Para borrarla definitivamente se usa Purge.
 func (s *ActivitiesServiceImpl) Delete(ctx context.Context, id string) error {
+	_, err := s.Deactivate(ctx, id)
+	return err
+}
+
+// Deactivate da de baja una actividad y la quita del buscador
+func (s *ActivitiesServiceImpl) Deactivate(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+	return s.setActiva(ctx, id, false)
+}
+
+// Reactivate vuelve a activar una actividad dada de baja y la vuelve a indexar
+func (s *ActivitiesServiceImpl) Reactivate(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+	return s.setActiva(ctx, id, true)
+}
+
+func (s *ActivitiesServiceImpl) setActiva(ctx context.Context, id string, activa bool) (dto.ActivityAdministration, error) {
+	current, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return dto.ActivityAdministration{}, errors.Join(ErrActivityDoesNotExist, err)
+	}
+	if current.Activa == activa {
+		// nada que cambiar, no se publica ningún evento
+		return current, nil
+	}
+	if activa {
+		// mientras estuvo de baja la sala pudo haberse asignado a otra actividad
+		if err := s.checkRoom(ctx, current.Activity, id); err != nil {
+			return dto.ActivityAdministration{}, err
+		}
+	}
+
+	updated, err := s.repository.SetActiva(ctx, id, activa)
+	if err != nil {
+		return dto.ActivityAdministration{}, err
+	}
+
+	// para el buscador una baja equivale a eliminar el documento y una reactivación a crearlo
+	action := "delete"
+	if activa {
+		action = "create"
+	}
+
+	if err := s.rabbitPublisher.Publish(ctx, action, id); err != nil {
+		log.Errorf("Failed to publish %s event for activity %s: %v", action, id, err)
+
+		// Rollback: restore the previous state in MongoDB
+		if _, restoreErr := s.repository.SetActiva(ctx, id, !activa); restoreErr != nil {
+			log.Errorf("CRITICAL: Failed to rollback activity %s after RabbitMQ publish failure: %v", id, restoreErr)
+			return dto.ActivityAdministration{}, errors.Join(ErrPublishEventFailed, ErrRollbackFailed, err, restoreErr)
+		}
+
+		log.Warnf("Successfully rolled back activity %s after RabbitMQ publish failure", id)
+		return dto.ActivityAdministration{}, errors.Join(ErrPublishEventFailed, err)
+	}
+
+	log.Infof("Activity %s set activa=%t and event published successfully", id, activa)
+	historyAction := HistoryActionDeactivate
+	if activa {
+		historyAction = HistoryActionReactivate
+	}
+	s.recordHistory(ctx, dto.HistoryEntry{
+		ActivityID: id,
+		Action:     historyAction,
+		Changes:    diffActivities(current, updated),
+	})
+	return updated, nil
+}
+
+// Purge elimina definitivamente una actividad junto con sus inscripciones
+func (s *ActivitiesServiceImpl) Purge(ctx context.Context, id string) error {
 	activityToDelete, err := s.repository.GetByID(ctx, id)
 	if err != nil {
 		return errors.Join(ErrActivityDoesNotExist, err)
@@ -207,8 +407,9 @@ func (s *ActivitiesServiceImpl) Delete(ctx context.Context, id string) error {
 	if err := s.rabbitPublisher.Publish(ctx, "delete", activityToDelete.ID); err != nil {
 		log.Errorf("Failed to publish delete event for activity %s: %v", id, err)
 
-		// Rollback: restore the deleted activity in MongoDB
-		if _, restoreErr := s.repository.Create(ctx, activityToDelete); restoreErr != nil {
+		// Rollback: restore the deleted activity in MongoDB with its original ID, so links,
+		// search documents, history and occurrences keep pointing to it
+		if restoreErr := s.repository.Restore(ctx, activityToDelete); restoreErr != nil {
 			log.Errorf("CRITICAL: Failed to rollback activity %s after RabbitMQ publish failure: %v", id, restoreErr)
 			return errors.Join(ErrPublishEventFailed, ErrRollbackFailed, err, restoreErr)
 		}
@@ -217,166 +418,102 @@ func (s *ActivitiesServiceImpl) Delete(ctx context.Context, id string) error {
 		return errors.Join(ErrPublishEventFailed, err)
 	}
 
-	log.Infof("Activity %s deleted and event published successfully", id)
+	// las clases se borran recién con el evento publicado: si hubo rollback siguen intactas
+	if err := s.occurrences.DeleteByActivity(ctx, id); err != nil {
+		log.Errorf("Failed to delete occurrences of purged activity %s: %v", id, err)
+	}
+
+	log.Infof("Activity %s purged and event published successfully", id)
+	s.recordHistory(ctx, dto.HistoryEntry{
+		ActivityID: id,
+		Action:     HistoryActionPurge,
+		Changes:    diffActivities(activityToDelete, dto.ActivityAdministration{}),
+	})
 	return nil
 }
 
-// Inscribir registra al usuario en la actividad
+// Inscribir registra al usuario en todas las clases de la actividad (la serie completa). Con
+// política de inscripción, la serie abre apertura_dias antes de su primera clase y la inscripción
+// cierra cierre_minutos antes de cada clase.
 func (s *ActivitiesServiceImpl) Inscribir(ctx context.Context, id string, userID string) (string, error) {
-	return s.repository.Inscribir(ctx, id, userID)
-}
+	activity, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if err := s.checkUser(ctx, userID); err != nil {
+		return "", err
+	}
+	if err := s.checkSeriesEnrollmentWindow(ctx, activity); err != nil {
+		return "", err
+	}
+	if err := s.checkSeriesEntitlement(ctx, activity, userID); err != nil {
+		return "", err
+	}
 
-// Desinscribir quita al usuario de la actividad
-func (s *ActivitiesServiceImpl) Desinscribir(ctx context.Context, id string, userID string) (string, error) {
-	return s.repository.Desinscribir(ctx, id, userID)
-}
+	// quien se inscribe a la serie ocupa lugar en todas las fechas: no puede haber ninguna
+	// fecha futura que ya esté llena con inscripciones individuales
+	maxOccurrence, err := s.occurrences.MaxEnrolled(ctx, id, today().Format(dto.FechaLayout))
+	if err != nil {
+		return "", err
+	}
+	if maxOccurrence > 0 && len(activity.UsersInscribed)+maxOccurrence >= activity.CapacidadMax {
+		return "", ErrActivityFull
+	}
 
-// GetInscripcionesByUserID obtiene las actividades inscritas por un usuario
-func (s *ActivitiesServiceImpl) GetInscripcionesByUserID(ctx context.Context, userID string) ([]string, error) {
-	return s.repository.GetInscripcionesByUserID(ctx, userID)
-}
+	result, err := s.repository.Inscribir(ctx, id, userID)
+	if err != nil {
+		return "", err
+	}
 
-func (s *ActivitiesServiceImpl) GetActivitiesByUserID(ctx context.Context, userID string) (dto.Activities, error) {
-	return s.repository.GetActivitiesByUserID(ctx, userID)
+	s.recordHistory(ctx, dto.HistoryEntry{ActivityID: id, Action: HistoryActionEnroll, UserID: userID})
+	s.notifySeriesEnrollment(ctx, dto.EventEnrollmentCreated, id, userID)
+	return result, nil
 }
 
-// GetStatistics calcula estadísticas de actividades usando concurrencia
-func (s *ActivitiesServiceImpl) GetStatistics(ctx context.Context) (dto.ActivityStatistics, error) {
-	activities, err := s.repository.ListAllForAdmin(ctx)
+// Desinscribir quita al usuario de la actividad. Si la próxima clase está dentro de la ventana de
+// cancelación se permite igual, pero queda registrada como cancelación tardía de esa clase.
+func (s *ActivitiesServiceImpl) Desinscribir(ctx context.Context, id string, userID string) (string, error) {
+	result, err := s.repository.Desinscribir(ctx, id, userID)
 	if err != nil {
-		log.WithError(err).Error("Error fetching activities for statistics")
-		return dto.ActivityStatistics{}, err
+		return "", err
 	}
 
-	totalEnrollmentsChan := make(chan int)
-	capacityUtilizationChan := make(chan float64)
-	dayDistributionChan := make(chan []dto.DayDistribution)
-	mostPopularChan := make(chan *dto.Activity)
-	fullActivitiesChan := make(chan int)
-
-	var wg sync.WaitGroup
-
-	// Goroutine 1: Calcular total de inscripciones y capacidad total
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		totalEnrollments := 0
-		totalCapacity := 0
-
-		for _, act := range activities {
-			totalEnrollments += len(act.UsersInscribed)
-			totalCapacity += act.CapacidadMax
-		}
-
-		// Calcular utilización de capacidad
-		var utilization float64
-		if totalCapacity > 0 {
-			utilization = (float64(totalEnrollments) / float64(totalCapacity)) * 100
-		}
-
-		totalEnrollmentsChan <- totalEnrollments
-		capacityUtilizationChan <- utilization
-	}()
-
-	// Goroutine 2: Calcular distribución por día de la semana
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		dayCount := make(map[string]int)
-
-		for _, act := range activities {
-			dayCount[act.DiaSemana]++
-		}
-
-		distribution := []dto.DayDistribution{}
-		for day, count := range dayCount {
-			distribution = append(distribution, dto.DayDistribution{
-				Dia:   day,
-				Count: count,
-			})
-		}
-
-		dayDistributionChan <- distribution
-	}()
-
-	// Goroutine 3: Encontrar actividad más popular
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		var mostPopular *dto.Activity
-		maxEnrollments := -1
-
-		for _, act := range activities {
-			if len(act.UsersInscribed) > maxEnrollments {
-				maxEnrollments = len(act.UsersInscribed)
-				activity := act.Activity // Copy the embedded Activity struct
-				mostPopular = &activity
-			}
+	late := false
+	if activity, err := s.repository.GetByID(ctx, id); err != nil {
+		log.Warnf("Failed to load activity %s to check the cancellation window: %v", id, err)
+	} else if activity.Politica != nil && activity.Politica.CancelacionHoras > 0 {
+		next, start, ok, err := s.nextOccurrence(ctx, activity)
+		if err != nil {
+			log.Warnf("Failed to find the next class of activity %s to check the cancellation window: %v", id, err)
+		} else if ok && isLateCancellation(activity.Politica, start, time.Now()) {
+			late = true
+			s.recordLateCancellation(ctx, id, next.Fecha, userID)
 		}
+	}
 
-		mostPopularChan <- mostPopular
-	}()
+	s.recordHistory(ctx, dto.HistoryEntry{ActivityID: id, Action: HistoryActionUnenroll, UserID: userID, LateCancellation: late})
+	s.notifySeriesEnrollment(ctx, dto.EventEnrollmentCancelled, id, userID)
+	return result, nil
+}
 
-	// Goroutine 4: Contar actividades llenas vs disponibles
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		fullCount := 0
+// notifySeriesEnrollment avisa al usuario que se inscribió o desinscribió de la serie
+func (s *ActivitiesServiceImpl) notifySeriesEnrollment(ctx context.Context, eventType, id, userID string) {
+	if s.notifier == nil {
+		return
+	}
+	activity, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		log.Warnf("Failed to load activity %s to notify %s: %v", id, eventType, err)
+		return
+	}
+	s.notifyEnrollment(ctx, eventType, activity, dto.Occurrence{}, userID)
+}
 
-		for _, act := range activities {
-			if len(act.UsersInscribed) >= act.CapacidadMax {
-				fullCount++
-			}
-		}
+// GetInscripcionesByUserID obtiene las actividades inscritas por un usuario
+func (s *ActivitiesServiceImpl) GetInscripcionesByUserID(ctx context.Context, userID string) ([]string, error) {
+	return s.repository.GetInscripcionesByUserID(ctx, userID)
+}
 
-		fullActivitiesChan <- fullCount
-	}()
-
-	// Goroutine para cerrar channels después de que todas las goroutines terminen
-	go func() {
-		wg.Wait()
-		close(totalEnrollmentsChan)
-		close(capacityUtilizationChan)
-		close(dayDistributionChan)
-		close(mostPopularChan)
-		close(fullActivitiesChan)
-	}()
-
-	totalEnrollments := <-totalEnrollmentsChan
-	capacityUtilization := <-capacityUtilizationChan
-	dayDistribution := <-dayDistributionChan
-	mostPopular := <-mostPopularChan
-	fullActivities := <-fullActivitiesChan
-
-	// Calcular tasa promedio de inscripción
-	var avgEnrollmentRate float64
-	if len(activities) > 0 {
-		avgEnrollmentRate = float64(totalEnrollments) / float64(len(activities))
-	}
-
-	// Calculate total capacity
-	var totalCapacity int
-	for _, act := range activities {
-		totalCapacity += act.CapacidadMax
-	}
-
-	stats := dto.ActivityStatistics{
-		TotalActivities:       len(activities),
-		TotalEnrollments:      totalEnrollments,
-		AverageEnrollmentRate: avgEnrollmentRate,
-		TotalCapacity:         totalCapacity,
-		CapacityUtilization:   capacityUtilization,
-		ActivitiesByDay:       dayDistribution,
-		MostPopularActivity:   mostPopular,
-		FullActivitiesCount:   fullActivities,
-		AvailableActivities:   len(activities) - fullActivities,
-	}
-
-	log.WithFields(log.Fields{
-		"total_activities":  stats.TotalActivities,
-		"total_enrollments": stats.TotalEnrollments,
-		"full_activities":   stats.FullActivitiesCount,
-	}).Info("Statistics calculated successfully")
-
-	return stats, nil
+func (s *ActivitiesServiceImpl) GetActivitiesByUserID(ctx context.Context, userID string) (dto.Activities, error) {
+	return s.repository.GetActivitiesByUserID(ctx, userID)
 }
@@ -4,7 +4,11 @@ import (
 	"activities/internal/dto"
 	"context"
 	"errors"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Mock implementations
@@ -15,10 +19,16 @@ type mockRepo struct {
 	getByIDFunc                  func(ctx context.Context, id string) (dto.ActivityAdministration, error)
 	updateFunc                   func(ctx context.Context, id string, activity dto.ActivityAdministration) (dto.ActivityAdministration, error)
 	deleteFunc                   func(ctx context.Context, id string) error
+	restoreFunc                  func(ctx context.Context, activity dto.ActivityAdministration) error
 	inscribirFunc                func(ctx context.Context, id string, userID string) (string, error)
 	desinscribirFunc             func(ctx context.Context, id string, userID string) (string, error)
 	getInscripcionesByUserIDFunc func(ctx context.Context, userID string) ([]string, error)
 	listAllForAdminFunc          func(ctx context.Context) ([]dto.ActivityAdministration, error)
+	listFilteredFunc             func(ctx context.Context, filters dto.ActivityListFilters) (dto.ActivitiesPage, error)
+	setActivaFunc                func(ctx context.Context, id string, activa bool) (dto.ActivityAdministration, error)
+	listByRoomFunc               func(ctx context.Context, salaID string) ([]dto.ActivityAdministration, error)
+	getByExternalKeyFunc         func(ctx context.Context, key string) (dto.ActivityAdministration, error)
+	statisticsFunc               func(ctx context.Context, limit int) (dto.ActivityStatistics, error)
 }
 
 func (m *mockRepo) List(ctx context.Context) ([]dto.Activity, error) {
@@ -28,6 +38,13 @@ func (m *mockRepo) List(ctx context.Context) ([]dto.Activity, error) {
 	return nil, nil
 }
 
+func (m *mockRepo) ListFiltered(ctx context.Context, filters dto.ActivityListFilters) (dto.ActivitiesPage, error) {
+	if m.listFilteredFunc != nil {
+		return m.listFilteredFunc(ctx, filters)
+	}
+	return dto.ActivitiesPage{}, nil
+}
+
 func (m *mockRepo) GetMany(ctx context.Context, ids []string) ([]dto.Activity, error) {
 	if m.getManyFunc != nil {
 		return m.getManyFunc(ctx, ids)
@@ -63,6 +80,20 @@ func (m *mockRepo) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *mockRepo) Restore(ctx context.Context, activity dto.ActivityAdministration) error {
+	if m.restoreFunc != nil {
+		return m.restoreFunc(ctx, activity)
+	}
+	return nil
+}
+
+func (m *mockRepo) SetActiva(ctx context.Context, id string, activa bool) (dto.ActivityAdministration, error) {
+	if m.setActivaFunc != nil {
+		return m.setActivaFunc(ctx, id, activa)
+	}
+	return dto.ActivityAdministration{}, nil
+}
+
 func (m *mockRepo) Inscribir(ctx context.Context, id string, userID string) (string, error) {
 	if m.inscribirFunc != nil {
 		return m.inscribirFunc(ctx, id, userID)
@@ -95,6 +126,27 @@ func (m *mockRepo) ListAllForAdmin(ctx context.Context) ([]dto.ActivityAdministr
 	return nil, nil
 }
 
+func (m *mockRepo) ListByRoom(ctx context.Context, salaID string) ([]dto.ActivityAdministration, error) {
+	if m.listByRoomFunc != nil {
+		return m.listByRoomFunc(ctx, salaID)
+	}
+	return nil, nil
+}
+
+func (m *mockRepo) GetByExternalKey(ctx context.Context, key string) (dto.ActivityAdministration, error) {
+	if m.getByExternalKeyFunc != nil {
+		return m.getByExternalKeyFunc(ctx, key)
+	}
+	return dto.ActivityAdministration{}, ErrActivityNotFound
+}
+
+func (m *mockRepo) Statistics(ctx context.Context, limit int) (dto.ActivityStatistics, error) {
+	if m.statisticsFunc != nil {
+		return m.statisticsFunc(ctx, limit)
+	}
+	return dto.ActivityStatistics{}, nil
+}
+
 type mockRabbit struct {
 	publishFunc func(ctx context.Context, action string, id string) error
 }
@@ -106,6 +158,210 @@ func (m *mockRabbit) Publish(ctx context.Context, action string, id string) erro
 	return nil
 }
 
+type mockHistory struct {
+	entries    []dto.HistoryEntry
+	weekCounts []dto.HistoryWeekCount
+	from, to   time.Time
+}
+
+func (m *mockHistory) Append(ctx context.Context, entry dto.HistoryEntry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *mockHistory) List(ctx context.Context, filters dto.HistoryFilters) (dto.HistoryPage, error) {
+	return dto.HistoryPage{Entries: m.entries, Page: filters.Page, Limit: filters.Limit}, nil
+}
+
+func (m *mockHistory) CountByWeek(ctx context.Context, actions []string, from, to time.Time) ([]dto.HistoryWeekCount, error) {
+	m.from, m.to = from, to
+	return m.weekCounts, nil
+}
+
+type mockOccurrences struct {
+	records         []dto.OccurrenceRecord
+	listFunc        func(activityID string) []dto.OccurrenceRecord
+	maxEnrolled     int
+	inscribirFunc   func(ctx context.Context, activityID, fecha, userID string, maxEnrolled int) error
+	deletedActivity string
+	attendance      []dto.ActivityAttendance
+	lateCancelled   []string
+	unenrolled      []string
+}
+
+func (m *mockOccurrences) List(ctx context.Context, activityID, from, to string) ([]dto.OccurrenceRecord, error) {
+	if m.listFunc != nil {
+		return m.listFunc(activityID), nil
+	}
+	return m.records, nil
+}
+
+func (m *mockOccurrences) MaxEnrolled(ctx context.Context, activityID, from string) (int, error) {
+	return m.maxEnrolled, nil
+}
+
+func (m *mockOccurrences) Inscribir(ctx context.Context, activityID, fecha, userID string, maxEnrolled int) error {
+	if m.inscribirFunc != nil {
+		return m.inscribirFunc(ctx, activityID, fecha, userID, maxEnrolled)
+	}
+	return nil
+}
+
+func (m *mockOccurrences) Desinscribir(ctx context.Context, activityID, fecha, userID string) error {
+	m.unenrolled = append(m.unenrolled, activityID+"/"+fecha+"/"+userID)
+	return nil
+}
+
+func (m *mockOccurrences) SetEstado(ctx context.Context, record dto.OccurrenceRecord) (dto.OccurrenceRecord, error) {
+	for i, r := range m.records {
+		if r.ActivityID == record.ActivityID && r.Fecha == record.Fecha {
+			m.records[i] = record
+			return r, nil
+		}
+	}
+	m.records = append(m.records, record)
+	return dto.OccurrenceRecord{ActivityID: record.ActivityID, Fecha: record.Fecha}, nil
+}
+
+func (m *mockOccurrences) SetAsistencia(ctx context.Context, activityID, fecha string, asistentes, ausentes []int) error {
+	for i, r := range m.records {
+		if r.ActivityID == activityID && r.Fecha == fecha {
+			m.records[i].Asistentes, m.records[i].Ausentes = asistentes, ausentes
+			return nil
+		}
+	}
+	m.records = append(m.records, dto.OccurrenceRecord{ActivityID: activityID, Fecha: fecha, Asistentes: asistentes, Ausentes: ausentes})
+	return nil
+}
+
+func (m *mockOccurrences) ListByUser(ctx context.Context, userID string) ([]dto.OccurrenceRecord, error) {
+	uid, _ := strconv.Atoi(userID)
+	var records []dto.OccurrenceRecord
+	for _, r := range m.records {
+		if slices.Contains(r.UsersInscribed, uid) || slices.Contains(r.Asistentes, uid) || slices.Contains(r.Ausentes, uid) {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+func (m *mockOccurrences) AttendanceByActivity(ctx context.Context, from, to string) ([]dto.ActivityAttendance, error) {
+	return m.attendance, nil
+}
+
+func (m *mockOccurrences) AddLateCancellation(ctx context.Context, activityID, fecha, userID string) error {
+	m.lateCancelled = append(m.lateCancelled, fecha+":"+userID)
+	return nil
+}
+
+func (m *mockOccurrences) DeleteByActivity(ctx context.Context, activityID string) error {
+	m.deletedActivity = activityID
+	return nil
+}
+
+type mockUsers struct {
+	users       map[int]dto.User // sin usuarios cargados todos existen
+	batches     int
+	forgotten   []int
+	entitlement dto.Entitlement
+	err         error
+	fechas      []string
+}
+
+func (m *mockUsers) GetUsers(ctx context.Context, userIDs []int) ([]dto.User, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.batches++
+	var users []dto.User
+	for _, id := range userIDs {
+		if user, ok := m.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (m *mockUsers) GetUser(ctx context.Context, userID int) (dto.User, error) {
+	if m.err != nil {
+		return dto.User{}, m.err
+	}
+	if m.users == nil {
+		return dto.User{ID: userID}, nil
+	}
+	user, ok := m.users[userID]
+	if !ok {
+		return dto.User{}, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (m *mockUsers) Forget(userID int) {
+	m.forgotten = append(m.forgotten, userID)
+}
+
+func (m *mockUsers) Entitlement(ctx context.Context, userID, fecha string) (dto.Entitlement, error) {
+	m.fechas = append(m.fechas, fecha)
+	return m.entitlement, m.err
+}
+
+type mockNotifier struct {
+	events []dto.OccurrenceEvent
+	err    error
+}
+
+func (m *mockNotifier) PublishEvent(ctx context.Context, event dto.OccurrenceEvent) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.events = append(m.events, event)
+	return nil
+}
+
+// testDeps are the dependencies of newTestService. The ones left nil are not set in the
+// service, except the repository, RabbitMQ and the occurrences, which default to empty mocks.
+type testDeps struct {
+	repo        *mockRepo
+	rabbit      *mockRabbit
+	history     *mockHistory
+	occurrences *mockOccurrences
+	notifier    *mockNotifier
+	rooms       *mockRooms
+	tokens      *mockCalendarTokens
+	users       *mockUsers
+}
+
+// newTestService builds the service with the mocks in deps. Nil mocks are not passed as
+// interfaces so the service sees them as missing dependencies.
+func newTestService(deps testDeps) *ActivitiesServiceImpl {
+	if deps.repo == nil {
+		deps.repo = &mockRepo{}
+	}
+	if deps.rabbit == nil {
+		deps.rabbit = &mockRabbit{}
+	}
+	if deps.occurrences == nil {
+		deps.occurrences = &mockOccurrences{}
+	}
+	service := NewActivitiesService(deps.repo, deps.rabbit, nil, deps.occurrences, nil, nil, nil, nil)
+	if deps.history != nil {
+		service.history = deps.history
+	}
+	if deps.notifier != nil {
+		service.notifier = deps.notifier
+	}
+	if deps.rooms != nil {
+		service.rooms = deps.rooms
+	}
+	if deps.tokens != nil {
+		service.calendarTokens = deps.tokens
+	}
+	if deps.users != nil {
+		service.users = deps.users
+	}
+	return service
+}
+
 // TestList tests the List method
 func TestList(t *testing.T) {
 	ctx := context.Background()
@@ -121,7 +377,7 @@ func TestList(t *testing.T) {
 			},
 		}
 		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		result, err := service.List(ctx)
 
@@ -141,7 +397,7 @@ func TestList(t *testing.T) {
 			},
 		}
 		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		_, err := service.List(ctx)
 
@@ -151,6 +407,71 @@ func TestList(t *testing.T) {
 	})
 }
 
+// TestListPage tests the ListPage method
+func TestListPage(t *testing.T) {
+	ctx := context.Background()
+
+	// Defaults applied before reaching the repository
+	t.Run("defaults", func(t *testing.T) {
+		var received dto.ActivityListFilters
+		mockRepo := &mockRepo{
+			listFilteredFunc: func(ctx context.Context, filters dto.ActivityListFilters) (dto.ActivitiesPage, error) {
+				received = filters
+				return dto.ActivitiesPage{Page: filters.Page, Limit: filters.Limit}, nil
+			},
+		}
+		service := newTestService(testDeps{repo: mockRepo})
+
+		_, err := service.ListPage(ctx, dto.ActivityListFilters{Limit: 500})
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if received.Page != 1 {
+			t.Errorf("expected page 1, got %d", received.Page)
+		}
+		if received.Limit != maxListLimit {
+			t.Errorf("expected limit %d, got %d", maxListLimit, received.Limit)
+		}
+		if len(received.Sort) != 2 || received.Sort[0] != "dia" || received.Sort[1] != "hora_inicio" {
+			t.Errorf("expected default sort [dia hora_inicio], got %v", received.Sort)
+		}
+	})
+
+	// Invalid sort field
+	t.Run("invalid sort", func(t *testing.T) {
+		service := newTestService(testDeps{repo: &mockRepo{}})
+
+		_, err := service.ListPage(ctx, dto.ActivityListFilters{Sort: []string{"-password"}})
+
+		if !errors.Is(err, ErrValidation) || !errors.Is(err, ErrInvalidSort) {
+			t.Errorf("expected ErrInvalidSort validation error, got %v", err)
+		}
+	})
+
+	// Invalid time format
+	t.Run("invalid time", func(t *testing.T) {
+		service := newTestService(testDeps{repo: &mockRepo{}})
+
+		_, err := service.ListPage(ctx, dto.ActivityListFilters{HoraDesde: "25:00"})
+
+		if !errors.Is(err, ErrValidation) || !errors.Is(err, ErrInvalidTimeFormat) {
+			t.Errorf("expected ErrInvalidTimeFormat validation error, got %v", err)
+		}
+	})
+
+	// Invalid day
+	t.Run("invalid day", func(t *testing.T) {
+		service := newTestService(testDeps{repo: &mockRepo{}})
+
+		_, err := service.ListPage(ctx, dto.ActivityListFilters{Dia: "Domingo de ramos"})
+
+		if !errors.Is(err, ErrInvalidDay) {
+			t.Errorf("expected ErrInvalidDay, got %v", err)
+		}
+	})
+}
+
 // TestCreate tests the Create method
 func TestCreate(t *testing.T) {
 	ctx := context.Background()
@@ -179,7 +500,7 @@ func TestCreate(t *testing.T) {
 				return nil
 			},
 		}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		result, err := service.Create(ctx, validActivity)
 
@@ -198,7 +519,7 @@ func TestCreate(t *testing.T) {
 
 		mockRepo := &mockRepo{}
 		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		_, err := service.Create(ctx, invalidActivity)
 
@@ -215,7 +536,7 @@ func TestCreate(t *testing.T) {
 			},
 		}
 		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		_, err := service.Create(ctx, validActivity)
 
@@ -240,7 +561,7 @@ func TestCreate(t *testing.T) {
 				return errors.New("rabbitmq error")
 			},
 		}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		_, err := service.Create(ctx, validActivity)
 
@@ -294,7 +615,7 @@ func TestUpdate(t *testing.T) {
 				return nil
 			},
 		}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		result, err := service.Update(ctx, "1", validUpdate)
 
@@ -314,7 +635,7 @@ func TestUpdate(t *testing.T) {
 			},
 		}
 		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		_, err := service.Update(ctx, "999", validUpdate)
 
@@ -334,7 +655,7 @@ func TestUpdate(t *testing.T) {
 			},
 		}
 		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		_, err := service.Update(ctx, "1", invalidUpdate)
 
@@ -343,6 +664,26 @@ func TestUpdate(t *testing.T) {
 		}
 	})
 
+	// The roster is only changed through the roster endpoints
+	t.Run("roster in body", func(t *testing.T) {
+		invalidUpdate := validUpdate
+		invalidUpdate.UsersInscribed = []int{1, 2, 3, 4}
+
+		mockRepo := &mockRepo{
+			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+				return existingActivity, nil
+			},
+		}
+		mockRabbit := &mockRabbit{}
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
+
+		_, err := service.Update(ctx, "1", invalidUpdate)
+
+		if !errors.Is(err, ErrValidation) || !errors.Is(err, ErrRosterNotEditable) {
+			t.Errorf("expected ErrRosterNotEditable validation error, got %v", err)
+		}
+	})
+
 	// Validation error
 	t.Run("validation error", func(t *testing.T) {
 		invalidUpdate := validUpdate
@@ -354,7 +695,7 @@ func TestUpdate(t *testing.T) {
 			},
 		}
 		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		_, err := service.Update(ctx, "1", invalidUpdate)
 
@@ -374,7 +715,7 @@ func TestUpdate(t *testing.T) {
 			},
 		}
 		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		_, err := service.Update(ctx, "1", validUpdate)
 
@@ -399,7 +740,7 @@ func TestUpdate(t *testing.T) {
 				return errors.New("rabbitmq error")
 			},
 		}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		_, err := service.Update(ctx, "1", validUpdate)
 
@@ -409,11 +750,11 @@ func TestUpdate(t *testing.T) {
 	})
 }
 
-// TestDelete tests the Delete method
-func TestDelete(t *testing.T) {
+// TestUpdateVersionConflict tests that stale versions are rejected
+func TestUpdateVersionConflict(t *testing.T) {
 	ctx := context.Background()
 
-	existingActivity := dto.ActivityAdministration{
+	current := dto.ActivityAdministration{
 		Activity: dto.Activity{
 			ID:           "1",
 			Nombre:       "Yoga",
@@ -423,80 +764,237 @@ func TestDelete(t *testing.T) {
 			CapacidadMax: 20,
 			DiaSemana:    "Lunes",
 		},
+		Version: 3,
 	}
+	mockRepo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return current, nil
+		},
+		updateFunc: func(ctx context.Context, id string, activity dto.ActivityAdministration) (dto.ActivityAdministration, error) {
+			t.Error("repository update must not be called on version conflict")
+			return activity, nil
+		},
+	}
+	service := newTestService(testDeps{repo: mockRepo})
 
-	// Happy path
+	stale := current
+	stale.Version = 2
+	_, err := service.Update(ctx, "1", stale)
+
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+}
+
+// TestPatch tests the Patch method (JSON Merge Patch)
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+
+	current := dto.ActivityAdministration{
+		Activity: dto.Activity{
+			ID:           "1",
+			Nombre:       "Yoga",
+			Descripcion:  "Clase suave",
+			Profesor:     "Juan Perez",
+			HoraInicio:   "10:00",
+			HoraFin:      "11:00",
+			CapacidadMax: 20,
+			DiaSemana:    "Lunes",
+			FotoUrl:      "http://fotos/yoga.png",
+		},
+		UsersInscribed: []int{5},
+		Version:        2,
+	}
+	newRepo := func(saved *dto.ActivityAdministration) *mockRepo {
+		return &mockRepo{
+			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+				return current, nil
+			},
+			updateFunc: func(ctx context.Context, id string, activity dto.ActivityAdministration) (dto.ActivityAdministration, error) {
+				*saved = activity
+				return activity, nil
+			},
+		}
+	}
+
+	// Null clears the field, omitted fields are kept
+	t.Run("null clears field", func(t *testing.T) {
+		var saved dto.ActivityAdministration
+		service := newTestService(testDeps{repo: newRepo(&saved)})
+
+		_, err := service.Patch(ctx, "1", []byte(`{"descripcion": null, "cupo": 25}`), 2)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if saved.Descripcion != "" {
+			t.Errorf("expected descripcion to be cleared, got %q", saved.Descripcion)
+		}
+		if saved.CapacidadMax != 25 || saved.Nombre != "Yoga" || saved.FotoUrl != current.FotoUrl {
+			t.Errorf("unexpected merged activity %+v", saved)
+		}
+		if saved.UsersInscribed != nil {
+			t.Errorf("expected enrollments to be left untouched, got %v", saved.UsersInscribed)
+		}
+		if saved.Version != 2 {
+			t.Errorf("expected version 2, got %d", saved.Version)
+		}
+	})
+
+	// Validation runs on the merged result
+	t.Run("clearing required field", func(t *testing.T) {
+		var saved dto.ActivityAdministration
+		service := newTestService(testDeps{repo: newRepo(&saved)})
+
+		_, err := service.Patch(ctx, "1", []byte(`{"titulo": null}`), 2)
+
+		if !errors.Is(err, ErrValidation) || !errors.Is(err, ErrTitleRequired) {
+			t.Errorf("expected ErrTitleRequired validation error, got %v", err)
+		}
+	})
+
+	// Read-only fields are rejected
+	t.Run("read-only field", func(t *testing.T) {
+		var saved dto.ActivityAdministration
+		service := newTestService(testDeps{repo: newRepo(&saved)})
+
+		_, err := service.Patch(ctx, "1", []byte(`{"version": 10}`), 2)
+
+		if !errors.Is(err, ErrReadOnlyField) {
+			t.Errorf("expected ErrReadOnlyField, got %v", err)
+		}
+	})
+
+	// The roster cannot be replaced or cleared with PATCH
+	t.Run("roster is read-only", func(t *testing.T) {
+		var saved dto.ActivityAdministration
+		service := newTestService(testDeps{repo: newRepo(&saved)})
+
+		for _, patch := range []string{`{"usuarios_inscritos": [1, 2]}`, `{"usuarios_inscritos": null}`} {
+			if _, err := service.Patch(ctx, "1", []byte(patch), 2); !errors.Is(err, ErrReadOnlyField) {
+				t.Errorf("expected ErrReadOnlyField for %s, got %v", patch, err)
+			}
+		}
+	})
+
+	// The external key is only assigned by catalog imports
+	t.Run("external key is read-only", func(t *testing.T) {
+		var saved dto.ActivityAdministration
+		service := newTestService(testDeps{repo: newRepo(&saved)})
+
+		_, err := service.Patch(ctx, "1", []byte(`{"clave_externa": null}`), 2)
+
+		if !errors.Is(err, ErrReadOnlyField) {
+			t.Errorf("expected ErrReadOnlyField, got %v", err)
+		}
+	})
+
+	// Not an object
+	t.Run("invalid patch", func(t *testing.T) {
+		var saved dto.ActivityAdministration
+		service := newTestService(testDeps{repo: newRepo(&saved)})
+
+		_, err := service.Patch(ctx, "1", []byte(`["titulo"]`), 2)
+
+		if !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("expected ErrInvalidPatch, got %v", err)
+		}
+	})
+}
+
+// TestDelete tests the Delete method (soft delete)
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+
+	activeActivity := dto.ActivityAdministration{
+		Activity: dto.Activity{ID: "1", Nombre: "Yoga"},
+		Activa:   true,
+	}
+
+	// Happy path: the activity is deactivated and removed from search
 	t.Run("success", func(t *testing.T) {
+		var setTo *bool
+		var published string
 		mockRepo := &mockRepo{
 			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
-				return existingActivity, nil
+				return activeActivity, nil
+			},
+			setActivaFunc: func(ctx context.Context, id string, activa bool) (dto.ActivityAdministration, error) {
+				setTo = &activa
+				deactivated := activeActivity
+				deactivated.Activa = activa
+				return deactivated, nil
 			},
 			deleteFunc: func(ctx context.Context, id string) error {
+				t.Error("soft delete must not remove the document")
 				return nil
 			},
 		}
 		mockRabbit := &mockRabbit{
 			publishFunc: func(ctx context.Context, action, id string) error {
+				published = action
 				return nil
 			},
 		}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		err := service.Delete(ctx, "1")
 
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
 		}
+		if setTo == nil || *setTo {
+			t.Error("expected activity to be set as inactive")
+		}
+		if published != "delete" {
+			t.Errorf("expected delete event, got %q", published)
+		}
 	})
 
-	// Activity not found
-	t.Run("not found", func(t *testing.T) {
+	// Already inactive: nothing changes and no event is published
+	t.Run("already inactive", func(t *testing.T) {
 		mockRepo := &mockRepo{
 			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
-				return dto.ActivityAdministration{}, errors.New("not found")
+				return dto.ActivityAdministration{Activity: dto.Activity{ID: "1"}}, nil
 			},
 		}
-		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
-
-		err := service.Delete(ctx, "999")
+		mockRabbit := &mockRabbit{
+			publishFunc: func(ctx context.Context, action, id string) error {
+				t.Error("expected no event to be published")
+				return nil
+			},
+		}
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
-		if err == nil {
-			t.Error("expected error, got nil")
+		if err := service.Delete(ctx, "1"); err != nil {
+			t.Errorf("expected no error, got %v", err)
 		}
 	})
 
-	// Repository delete error
-	t.Run("repository error", func(t *testing.T) {
+	// Activity not found
+	t.Run("not found", func(t *testing.T) {
 		mockRepo := &mockRepo{
 			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
-				return existingActivity, nil
-			},
-			deleteFunc: func(ctx context.Context, id string) error {
-				return errors.New("db error")
+				return dto.ActivityAdministration{}, errors.New("not found")
 			},
 		}
-		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
-
-		err := service.Delete(ctx, "1")
+		service := newTestService(testDeps{repo: mockRepo})
 
-		if err == nil {
+		if err := service.Delete(ctx, "999"); err == nil {
 			t.Error("expected error, got nil")
 		}
 	})
 
-	// RabbitMQ error with rollback
+	// RabbitMQ error: the activity is reactivated
 	t.Run("rabbitmq error with rollback", func(t *testing.T) {
+		var calls []bool
 		mockRepo := &mockRepo{
 			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
-				return existingActivity, nil
+				return activeActivity, nil
 			},
-			deleteFunc: func(ctx context.Context, id string) error {
-				return nil
-			},
-			createFunc: func(ctx context.Context, activity dto.ActivityAdministration) (dto.ActivityAdministration, error) {
-				return activity, nil
+			setActivaFunc: func(ctx context.Context, id string, activa bool) (dto.ActivityAdministration, error) {
+				calls = append(calls, activa)
+				return activeActivity, nil
 			},
 		}
 		mockRabbit := &mockRabbit{
@@ -504,12 +1002,171 @@ func TestDelete(t *testing.T) {
 				return errors.New("rabbitmq error")
 			},
 		}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		err := service.Delete(ctx, "1")
 
-		if err == nil {
-			t.Error("expected error, got nil")
+		if !errors.Is(err, ErrPublishEventFailed) {
+			t.Errorf("expected ErrPublishEventFailed, got %v", err)
+		}
+		if len(calls) != 2 || calls[0] || !calls[1] {
+			t.Errorf("expected deactivate then reactivate, got %v", calls)
+		}
+	})
+}
+
+// TestReactivate tests the Reactivate method
+func TestReactivate(t *testing.T) {
+	ctx := context.Background()
+
+	var published string
+	mockRepo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return dto.ActivityAdministration{Activity: dto.Activity{ID: id}}, nil
+		},
+		setActivaFunc: func(ctx context.Context, id string, activa bool) (dto.ActivityAdministration, error) {
+			return dto.ActivityAdministration{Activity: dto.Activity{ID: id}, Activa: activa}, nil
+		},
+	}
+	mockRabbit := &mockRabbit{
+		publishFunc: func(ctx context.Context, action, id string) error {
+			published = action
+			return nil
+		},
+	}
+	service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
+
+	result, err := service.Reactivate(ctx, "1")
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !result.Activa {
+		t.Error("expected activity to be active")
+	}
+	if published != "create" {
+		t.Errorf("expected create event, got %q", published)
+	}
+}
+
+// TestPurge tests the Purge method
+func TestPurge(t *testing.T) {
+	ctx := context.Background()
+
+	existingActivity := dto.ActivityAdministration{
+		Activity: dto.Activity{
+			ID:           "1",
+			Nombre:       "Yoga",
+			Profesor:     "Juan Perez",
+			HoraInicio:   "10:00",
+			HoraFin:      "11:00",
+			CapacidadMax: 20,
+			DiaSemana:    "Lunes",
+		},
+	}
+
+	// Happy path
+	t.Run("success", func(t *testing.T) {
+		mockRepo := &mockRepo{
+			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+				return existingActivity, nil
+			},
+			deleteFunc: func(ctx context.Context, id string) error {
+				return nil
+			},
+		}
+		mockRabbit := &mockRabbit{
+			publishFunc: func(ctx context.Context, action, id string) error {
+				return nil
+			},
+		}
+		occurrences := &mockOccurrences{}
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit, occurrences: occurrences})
+
+		err := service.Purge(ctx, "1")
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if occurrences.deletedActivity != "1" {
+			t.Errorf("expected occurrences of activity 1 to be deleted, got %q", occurrences.deletedActivity)
+		}
+	})
+
+	// Activity not found
+	t.Run("not found", func(t *testing.T) {
+		mockRepo := &mockRepo{
+			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+				return dto.ActivityAdministration{}, errors.New("not found")
+			},
+		}
+		mockRabbit := &mockRabbit{}
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
+
+		err := service.Purge(ctx, "999")
+
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	// Repository delete error
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := &mockRepo{
+			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+				return existingActivity, nil
+			},
+			deleteFunc: func(ctx context.Context, id string) error {
+				return errors.New("db error")
+			},
+		}
+		mockRabbit := &mockRabbit{}
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
+
+		err := service.Purge(ctx, "1")
+
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	// RabbitMQ error with rollback: the activity keeps its ID and its occurrences
+	t.Run("rabbitmq error with rollback", func(t *testing.T) {
+		var restored *dto.ActivityAdministration
+		mockRepo := &mockRepo{
+			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+				return existingActivity, nil
+			},
+			deleteFunc: func(ctx context.Context, id string) error {
+				return nil
+			},
+			createFunc: func(ctx context.Context, activity dto.ActivityAdministration) (dto.ActivityAdministration, error) {
+				t.Error("expected the rollback not to create a new activity")
+				return activity, nil
+			},
+			restoreFunc: func(ctx context.Context, activity dto.ActivityAdministration) error {
+				restored = &activity
+				return nil
+			},
+		}
+		mockRabbit := &mockRabbit{
+			publishFunc: func(ctx context.Context, action, id string) error {
+				return errors.New("rabbitmq error")
+			},
+		}
+		occurrences := &mockOccurrences{}
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit, occurrences: occurrences})
+
+		err := service.Purge(ctx, "1")
+
+		if !errors.Is(err, ErrPublishEventFailed) {
+			t.Errorf("expected ErrPublishEventFailed, got %v", err)
+		}
+		if restored == nil || restored.ID != "1" {
+			t.Errorf("expected the activity restored with its ID, got %+v", restored)
+		}
+		if occurrences.deletedActivity != "" {
+			t.Errorf("expected occurrences to be kept, deleted for %q", occurrences.deletedActivity)
 		}
 	})
 }
@@ -526,7 +1183,7 @@ func TestInscribir(t *testing.T) {
 			},
 		}
 		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		result, err := service.Inscribir(ctx, "1", "100")
 
@@ -546,7 +1203,7 @@ func TestInscribir(t *testing.T) {
 			},
 		}
 		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		_, err := service.Inscribir(ctx, "1", "100")
 
@@ -568,7 +1225,7 @@ func TestGetInscripcionesByUserID(t *testing.T) {
 			},
 		}
 		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		result, err := service.GetInscripcionesByUserID(ctx, "100")
 
@@ -588,7 +1245,7 @@ func TestGetInscripcionesByUserID(t *testing.T) {
 			},
 		}
 		mockRabbit := &mockRabbit{}
-		service := NewActivitiesService(mockRepo, mockRabbit)
+		service := newTestService(testDeps{repo: mockRepo, rabbit: mockRabbit})
 
 		_, err := service.GetInscripcionesByUserID(ctx, "100")
 
@@ -597,3 +1254,2036 @@ func TestGetInscripcionesByUserID(t *testing.T) {
 		}
 	})
 }
+
+// TestHistory tests that operations are recorded in the history
+func TestHistory(t *testing.T) {
+	actor := dto.Actor{ID: "7", Username: "admin", IsAdmin: true}
+	ctx := WithActor(context.Background(), actor)
+
+	current := dto.ActivityAdministration{
+		Activity: dto.Activity{
+			ID:           "1",
+			Nombre:       "Yoga",
+			Descripcion:  "Clase suave",
+			Profesor:     "Juan Perez",
+			HoraInicio:   "10:00",
+			HoraFin:      "11:00",
+			CapacidadMax: 20,
+			DiaSemana:    "Lunes",
+		},
+		Activa: true,
+	}
+
+	// Update records only the changed fields with the actor
+	t.Run("update diff", func(t *testing.T) {
+		history := &mockHistory{}
+		updated := current
+		updated.Nombre = "Yoga avanzado"
+		mockRepo := &mockRepo{
+			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+				return current, nil
+			},
+			updateFunc: func(ctx context.Context, id string, activity dto.ActivityAdministration) (dto.ActivityAdministration, error) {
+				return updated, nil
+			},
+		}
+		service := newTestService(testDeps{repo: mockRepo, history: history})
+
+		if _, err := service.Update(ctx, "1", updated); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(history.entries) != 1 {
+			t.Fatalf("expected 1 history entry, got %d", len(history.entries))
+		}
+		entry := history.entries[0]
+		if entry.Action != HistoryActionUpdate || entry.ActivityID != "1" {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+		if entry.Actor != actor {
+			t.Errorf("expected actor %+v, got %+v", actor, entry.Actor)
+		}
+		if len(entry.Changes) != 1 {
+			t.Fatalf("expected 1 changed field, got %v", entry.Changes)
+		}
+		change, ok := entry.Changes["titulo"]
+		if !ok || change.Before != "Yoga" || change.After != "Yoga avanzado" {
+			t.Errorf("unexpected titulo change %+v", change)
+		}
+	})
+
+	// Failed operations are not recorded
+	t.Run("failed enroll", func(t *testing.T) {
+		history := &mockHistory{}
+		mockRepo := &mockRepo{
+			inscribirFunc: func(ctx context.Context, id, userID string) (string, error) {
+				return "", errors.New("activity full")
+			},
+		}
+		service := newTestService(testDeps{repo: mockRepo, history: history})
+
+		service.Inscribir(ctx, "1", "100")
+
+		if len(history.entries) != 0 {
+			t.Errorf("expected no history entries, got %d", len(history.entries))
+		}
+	})
+
+	// Enroll records the affected user
+	t.Run("enroll", func(t *testing.T) {
+		history := &mockHistory{}
+		mockRepo := &mockRepo{
+			inscribirFunc: func(ctx context.Context, id, userID string) (string, error) {
+				return id, nil
+			},
+		}
+		service := newTestService(testDeps{repo: mockRepo, history: history})
+
+		if _, err := service.Inscribir(ctx, "1", "100"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(history.entries) != 1 || history.entries[0].Action != HistoryActionEnroll || history.entries[0].UserID != "100" {
+			t.Errorf("unexpected history entries %+v", history.entries)
+		}
+	})
+}
+
+// TestOccurrenceDates tests the generation of class dates from a recurrence rule
+func TestOccurrenceDates(t *testing.T) {
+	mustParse := func(fecha string) time.Time {
+		t.Helper()
+		date, err := parseFecha(fecha)
+		if err != nil {
+			t.Fatalf("invalid date %s: %v", fecha, err)
+		}
+		return date
+	}
+	format := func(dates []time.Time) []string {
+		out := make([]string, 0, len(dates))
+		for _, date := range dates {
+			out = append(out, date.Format(dto.FechaLayout))
+		}
+		return out
+	}
+
+	// 2026-03-02 es lunes
+	from, to := mustParse("2026-03-02"), mustParse("2026-03-15")
+
+	t.Run("multiple days", func(t *testing.T) {
+		rec := dto.Recurrence{Dias: []string{"Lunes", "Jueves"}}
+		got := format(occurrenceDates(rec, from, to))
+		want := []string{"2026-03-02", "2026-03-05", "2026-03-09", "2026-03-12"}
+		if !slices.Equal(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("date range and exceptions", func(t *testing.T) {
+		rec := dto.Recurrence{
+			Dias:        []string{"Lunes", "Jueves"},
+			FechaInicio: "2026-03-04",
+			FechaFin:    "2026-03-12",
+			Excepciones: []string{"2026-03-09"},
+		}
+		got := format(occurrenceDates(rec, from, to))
+		want := []string{"2026-03-05", "2026-03-12"}
+		if !slices.Equal(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+}
+
+// TestValidateRecurrence tests the validation of recurrence rules
+func TestValidateRecurrence(t *testing.T) {
+	tests := []struct {
+		name    string
+		rec     *dto.Recurrence
+		wantErr error
+	}{
+		{name: "no rule", rec: nil},
+		{name: "valid", rec: &dto.Recurrence{Dias: []string{"Martes"}, FechaInicio: "2026-03-01", FechaFin: "2026-06-30"}},
+		{name: "no days", rec: &dto.Recurrence{}, wantErr: ErrInvalidRecurrence},
+		{name: "invalid day", rec: &dto.Recurrence{Dias: []string{"Funday"}}, wantErr: ErrInvalidDay},
+		{name: "invalid date", rec: &dto.Recurrence{Dias: []string{"Martes"}, FechaInicio: "01/03/2026"}, wantErr: ErrInvalidDate},
+		{name: "end before start", rec: &dto.Recurrence{Dias: []string{"Martes"}, FechaInicio: "2026-06-30", FechaFin: "2026-03-01"}, wantErr: ErrInvalidRecurrence},
+		{name: "invalid exception", rec: &dto.Recurrence{Dias: []string{"Martes"}, Excepciones: []string{"2026-02-30"}}, wantErr: ErrInvalidDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRecurrence(tt.rec)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestInscribirOccurrence tests the enrollment in a single class
+func TestInscribirOccurrence(t *testing.T) {
+	ctx := context.Background()
+	fecha := today().AddDate(0, 0, 7)
+	activity := dto.ActivityAdministration{
+		Activity: dto.Activity{
+			ID:           "1",
+			DiaSemana:    dto.DiasSemana[(int(fecha.Weekday())+6)%7],
+			HoraInicio:   "10:00",
+			HoraFin:      "11:00",
+			CapacidadMax: 10,
+		},
+		UsersInscribed: []int{100},
+		Activa:         true,
+	}
+	mockRepo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return activity, nil
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var gotMax int
+		occurrences := &mockOccurrences{
+			inscribirFunc: func(ctx context.Context, activityID, f, userID string, maxEnrolled int) error {
+				gotMax = maxEnrolled
+				return nil
+			},
+		}
+		service := newTestService(testDeps{repo: mockRepo, occurrences: occurrences})
+
+		if err := service.InscribirOccurrence(ctx, "1", fecha.Format(dto.FechaLayout), "200"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if gotMax != 9 {
+			t.Errorf("expected 9 places for the class, got %d", gotMax)
+		}
+	})
+
+	t.Run("already in the series", func(t *testing.T) {
+		service := newTestService(testDeps{repo: mockRepo})
+
+		err := service.InscribirOccurrence(ctx, "1", fecha.Format(dto.FechaLayout), "100")
+		if !errors.Is(err, ErrUserAlreadyInscribed) {
+			t.Errorf("expected ErrUserAlreadyInscribed, got %v", err)
+		}
+	})
+
+	t.Run("no class that day", func(t *testing.T) {
+		service := newTestService(testDeps{repo: mockRepo})
+
+		err := service.InscribirOccurrence(ctx, "1", fecha.AddDate(0, 0, 1).Format(dto.FechaLayout), "200")
+		if !errors.Is(err, ErrOccurrenceNotFound) {
+			t.Errorf("expected ErrOccurrenceNotFound, got %v", err)
+		}
+	})
+
+	t.Run("past class", func(t *testing.T) {
+		service := newTestService(testDeps{repo: mockRepo})
+
+		err := service.InscribirOccurrence(ctx, "1", fecha.AddDate(0, 0, -14).Format(dto.FechaLayout), "200")
+		if !errors.Is(err, ErrOccurrencePast) {
+			t.Errorf("expected ErrOccurrencePast, got %v", err)
+		}
+	})
+}
+
+// TestEnrollmentPolicy tests the enrollment and cancellation windows of an activity
+func TestEnrollmentPolicy(t *testing.T) {
+	ctx := context.Background()
+	fecha := today().AddDate(0, 0, 7)
+	newRepo := func(policy *dto.Policy, rec *dto.Recurrence) *mockRepo {
+		activity := dto.ActivityAdministration{
+			Activity: dto.Activity{
+				ID:           "1",
+				DiaSemana:    dto.DiasSemana[(int(fecha.Weekday())+6)%7],
+				HoraInicio:   "10:00",
+				HoraFin:      "11:00",
+				CapacidadMax: 10,
+				Recurrencia:  rec,
+				Politica:     policy,
+			},
+			Activa: true,
+		}
+		return &mockRepo{
+			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+				return activity, nil
+			},
+			inscribirFunc: func(ctx context.Context, id string, userID string) (string, error) {
+				return id, nil
+			},
+			desinscribirFunc: func(ctx context.Context, id string, userID string) (string, error) {
+				return id, nil
+			},
+		}
+	}
+
+	t.Run("class not open yet", func(t *testing.T) {
+		service := newTestService(testDeps{repo: newRepo(&dto.Policy{AperturaDias: 7}, nil)})
+
+		err := service.InscribirOccurrence(ctx, "1", fecha.AddDate(0, 0, 14).Format(dto.FechaLayout), "200")
+		if !errors.Is(err, ErrEnrollmentNotOpen) {
+			t.Errorf("expected ErrEnrollmentNotOpen, got %v", err)
+		}
+	})
+
+	t.Run("class open", func(t *testing.T) {
+		service := newTestService(testDeps{repo: newRepo(&dto.Policy{AperturaDias: 14, CierreMinutos: 60}, nil)})
+
+		if err := service.InscribirOccurrence(ctx, "1", fecha.Format(dto.FechaLayout), "200"); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("class closed", func(t *testing.T) {
+		service := newTestService(testDeps{repo: newRepo(&dto.Policy{CierreMinutos: 8 * 24 * 60}, nil)})
+
+		err := service.InscribirOccurrence(ctx, "1", fecha.Format(dto.FechaLayout), "200")
+		if !errors.Is(err, ErrEnrollmentClosed) {
+			t.Errorf("expected ErrEnrollmentClosed, got %v", err)
+		}
+	})
+
+	t.Run("series closed before the next class", func(t *testing.T) {
+		service := newTestService(testDeps{repo: newRepo(&dto.Policy{CierreMinutos: 8 * 24 * 60}, nil)})
+
+		if _, err := service.Inscribir(ctx, "1", "200"); !errors.Is(err, ErrEnrollmentClosed) {
+			t.Errorf("expected ErrEnrollmentClosed, got %v", err)
+		}
+	})
+
+	t.Run("series not open before the first class", func(t *testing.T) {
+		dia := dto.DiasSemana[(int(fecha.Weekday())+6)%7]
+		rec := &dto.Recurrence{Dias: []string{dia}, FechaInicio: fecha.AddDate(0, 0, 14).Format(dto.FechaLayout)}
+		service := newTestService(testDeps{repo: newRepo(&dto.Policy{AperturaDias: 7}, rec)})
+
+		if _, err := service.Inscribir(ctx, "1", "200"); !errors.Is(err, ErrEnrollmentNotOpen) {
+			t.Errorf("expected ErrEnrollmentNotOpen, got %v", err)
+		}
+	})
+
+	t.Run("late class cancellation is recorded", func(t *testing.T) {
+		occurrences, history := &mockOccurrences{}, &mockHistory{}
+		service := newTestService(testDeps{repo: newRepo(&dto.Policy{CancelacionHoras: 8 * 24}, nil), occurrences: occurrences, history: history})
+
+		if err := service.DesinscribirOccurrence(ctx, "1", fecha.Format(dto.FechaLayout), "200"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if want := []string{fecha.Format(dto.FechaLayout) + ":200"}; !slices.Equal(occurrences.lateCancelled, want) {
+			t.Errorf("expected late cancellation %v, got %v", want, occurrences.lateCancelled)
+		}
+		if len(history.entries) != 1 || !history.entries[0].LateCancellation {
+			t.Errorf("expected a late cancellation in history, got %+v", history.entries)
+		}
+	})
+
+	t.Run("cancellation in time", func(t *testing.T) {
+		occurrences, history := &mockOccurrences{}, &mockHistory{}
+		service := newTestService(testDeps{repo: newRepo(&dto.Policy{CancelacionHoras: 24}, nil), occurrences: occurrences, history: history})
+
+		if err := service.DesinscribirOccurrence(ctx, "1", fecha.Format(dto.FechaLayout), "200"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(occurrences.lateCancelled) != 0 || len(history.entries) != 1 || history.entries[0].LateCancellation {
+			t.Errorf("expected a cancellation in time, got %v %+v", occurrences.lateCancelled, history.entries)
+		}
+	})
+
+	t.Run("late series cancellation is recorded on the next class", func(t *testing.T) {
+		occurrences, history := &mockOccurrences{}, &mockHistory{}
+		service := newTestService(testDeps{repo: newRepo(&dto.Policy{CancelacionHoras: 8 * 24}, nil), occurrences: occurrences, history: history})
+
+		if _, err := service.Desinscribir(ctx, "1", "200"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(occurrences.lateCancelled) != 1 {
+			t.Errorf("expected one late cancellation, got %v", occurrences.lateCancelled)
+		}
+		if len(history.entries) != 1 || !history.entries[0].LateCancellation {
+			t.Errorf("expected a late cancellation in history, got %+v", history.entries)
+		}
+	})
+
+	t.Run("negative values", func(t *testing.T) {
+		if err := validatePolicy(&dto.Policy{CierreMinutos: -1}); !errors.Is(err, ErrInvalidPolicy) {
+			t.Errorf("expected ErrInvalidPolicy, got %v", err)
+		}
+		if err := validatePolicy(nil); err != nil {
+			t.Errorf("expected no error without policy, got %v", err)
+		}
+	})
+}
+
+// TestMembershipEntitlement tests the membership plan checks on enrollment
+func TestMembershipEntitlement(t *testing.T) {
+	ctx := context.Background()
+	fecha := today().AddDate(0, 0, 7)
+	dia := dto.DiasSemana[(int(fecha.Weekday())+6)%7]
+	activities := map[string]dto.ActivityAdministration{}
+	for _, id := range []string{"1", "2", "3"} {
+		activities[id] = dto.ActivityAdministration{
+			Activity: dto.Activity{ID: id, DiaSemana: dia, HoraInicio: "10:00", HoraFin: "11:00", CapacidadMax: 10},
+			Activa:   true,
+		}
+	}
+	repo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return activities[id], nil
+		},
+		getInscripcionesByUserIDFunc: func(ctx context.Context, userID string) ([]string, error) {
+			return []string{"2"}, nil
+		},
+		inscribirFunc: func(ctx context.Context, id string, userID string) (string, error) {
+			return id, nil
+		},
+	}
+	// el usuario 200 va a la serie de la actividad 2 y a la clase de la 3 de esa fecha
+	records := []dto.OccurrenceRecord{{ActivityID: "3", Fecha: fecha.Format(dto.FechaLayout), UsersInscribed: []int{200}}}
+	newOccurrences := func() *mockOccurrences {
+		return &mockOccurrences{
+			records: records,
+			listFunc: func(activityID string) []dto.OccurrenceRecord {
+				var list []dto.OccurrenceRecord
+				for _, r := range records {
+					if r.ActivityID == activityID {
+						list = append(list, r)
+					}
+				}
+				return list
+			},
+		}
+	}
+	enroll := func(service *ActivitiesServiceImpl) error {
+		return service.InscribirOccurrence(ctx, "1", fecha.Format(dto.FechaLayout), "200")
+	}
+
+	t.Run("within quota", func(t *testing.T) {
+		users := &mockUsers{entitlement: dto.Entitlement{Vigente: true, ClasesSemanales: 3}}
+		if err := enroll(newTestService(testDeps{repo: repo, occurrences: newOccurrences(), users: users})); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(users.fechas) != 1 || users.fechas[0] != fecha.Format(dto.FechaLayout) {
+			t.Errorf("expected the plan checked on the class date, got %v", users.fechas)
+		}
+	})
+
+	t.Run("unlimited plan", func(t *testing.T) {
+		users := &mockUsers{entitlement: dto.Entitlement{Vigente: true}}
+		if err := enroll(newTestService(testDeps{repo: repo, occurrences: newOccurrences(), users: users})); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("over quota", func(t *testing.T) {
+		users := &mockUsers{entitlement: dto.Entitlement{Vigente: true, ClasesSemanales: 2}}
+		err := enroll(newTestService(testDeps{repo: repo, occurrences: newOccurrences(), users: users}))
+		if !errors.Is(err, ErrWeeklyQuotaExceeded) {
+			t.Errorf("expected ErrWeeklyQuotaExceeded, got %v", err)
+		}
+	})
+
+	t.Run("series over quota", func(t *testing.T) {
+		users := &mockUsers{entitlement: dto.Entitlement{Vigente: true, ClasesSemanales: 1}}
+		_, err := newTestService(testDeps{repo: repo, occurrences: newOccurrences(), users: users}).Inscribir(ctx, "1", "200")
+		if !errors.Is(err, ErrWeeklyQuotaExceeded) {
+			t.Errorf("expected ErrWeeklyQuotaExceeded, got %v", err)
+		}
+	})
+
+	t.Run("expired plan", func(t *testing.T) {
+		users := &mockUsers{entitlement: dto.Entitlement{Motivo: "plan_vencido", Desde: "2026-01-01", Hasta: "2026-01-31"}}
+		if err := enroll(newTestService(testDeps{repo: repo, occurrences: newOccurrences(), users: users})); !errors.Is(err, ErrMembershipNotValid) {
+			t.Errorf("expected ErrMembershipNotValid, got %v", err)
+		}
+	})
+
+	t.Run("no plan", func(t *testing.T) {
+		users := &mockUsers{entitlement: dto.Entitlement{Motivo: dto.EntitlementNoPlan}}
+		if _, err := newTestService(testDeps{repo: repo, occurrences: newOccurrences(), users: users}).Inscribir(ctx, "1", "200"); !errors.Is(err, ErrMembershipRequired) {
+			t.Errorf("expected ErrMembershipRequired, got %v", err)
+		}
+	})
+
+	t.Run("users service down", func(t *testing.T) {
+		users := &mockUsers{err: errors.Join(ErrUsersUnavailable, errors.New("connection refused"))}
+		if err := enroll(newTestService(testDeps{repo: repo, occurrences: newOccurrences(), users: users})); !errors.Is(err, ErrUsersUnavailable) {
+			t.Errorf("expected ErrUsersUnavailable, got %v", err)
+		}
+	})
+}
+
+// TestCancelOccurrence tests the cancellation of a single class
+func TestCancelOccurrence(t *testing.T) {
+	ctx := context.Background()
+	fecha := today().AddDate(0, 0, 7).Format(dto.FechaLayout)
+	date, _ := parseFecha(fecha)
+	mockRepo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return dto.ActivityAdministration{
+				Activity: dto.Activity{
+					ID:           "1",
+					Nombre:       "Yoga",
+					DiaSemana:    dto.DiasSemana[(int(date.Weekday())+6)%7],
+					HoraInicio:   "10:00",
+					HoraFin:      "11:00",
+					CapacidadMax: 10,
+				},
+				UsersInscribed: []int{100},
+				Activa:         true,
+			}, nil
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		occurrences := &mockOccurrences{records: []dto.OccurrenceRecord{{ActivityID: "1", Fecha: fecha, UsersInscribed: []int{200}}}}
+		notifier := &mockNotifier{}
+		history := &mockHistory{}
+		service := newTestService(testDeps{repo: mockRepo, history: history, occurrences: occurrences, notifier: notifier})
+
+		occurrence, err := service.CancelOccurrence(ctx, "1", fecha, "Instructor enfermo")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if occurrence.Estado != dto.OccurrenceCancelled || occurrence.Motivo != "Instructor enfermo" {
+			t.Errorf("unexpected occurrence %+v", occurrence)
+		}
+		if !slices.Equal(occurrences.records[0].UsersInscribed, []int{200}) {
+			t.Errorf("expected enrollments to be kept, got %v", occurrences.records[0].UsersInscribed)
+		}
+
+		if len(notifier.events) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(notifier.events))
+		}
+		event := notifier.events[0]
+		if event.Type != dto.EventActivityCancelled || event.Fecha != fecha || event.Titulo != "Yoga" {
+			t.Errorf("unexpected event %+v", event)
+		}
+		if !slices.Equal(event.Usuarios, []int{100, 200}) {
+			t.Errorf("expected series and class users to be notified, got %v", event.Usuarios)
+		}
+		if len(history.entries) != 1 || history.entries[0].Action != HistoryActionCancel {
+			t.Errorf("unexpected history entries %+v", history.entries)
+		}
+	})
+
+	t.Run("reason required", func(t *testing.T) {
+		service := newTestService(testDeps{repo: mockRepo, notifier: &mockNotifier{}})
+
+		_, err := service.CancelOccurrence(ctx, "1", fecha, "  ")
+		if !errors.Is(err, ErrReasonRequired) {
+			t.Errorf("expected ErrReasonRequired, got %v", err)
+		}
+	})
+
+	t.Run("already cancelled", func(t *testing.T) {
+		occurrences := &mockOccurrences{records: []dto.OccurrenceRecord{{ActivityID: "1", Fecha: fecha, Estado: dto.OccurrenceCancelled}}}
+		service := newTestService(testDeps{repo: mockRepo, occurrences: occurrences, notifier: &mockNotifier{}})
+
+		_, err := service.CancelOccurrence(ctx, "1", fecha, "Feriado")
+		if !errors.Is(err, ErrOccurrenceCancelled) {
+			t.Errorf("expected ErrOccurrenceCancelled, got %v", err)
+		}
+	})
+
+	t.Run("enroll in cancelled class", func(t *testing.T) {
+		occurrences := &mockOccurrences{records: []dto.OccurrenceRecord{{ActivityID: "1", Fecha: fecha, Estado: dto.OccurrenceCancelled}}}
+		service := newTestService(testDeps{repo: mockRepo, occurrences: occurrences, notifier: &mockNotifier{}})
+
+		err := service.InscribirOccurrence(ctx, "1", fecha, "200")
+		if !errors.Is(err, ErrOccurrenceCancelled) {
+			t.Errorf("expected ErrOccurrenceCancelled, got %v", err)
+		}
+	})
+
+	t.Run("publish failure rolls back", func(t *testing.T) {
+		occurrences := &mockOccurrences{}
+		service := newTestService(testDeps{repo: mockRepo, occurrences: occurrences, notifier: &mockNotifier{err: errors.New("rabbit down")}})
+
+		_, err := service.CancelOccurrence(ctx, "1", fecha, "Feriado")
+		if !errors.Is(err, ErrPublishEventFailed) {
+			t.Fatalf("expected ErrPublishEventFailed, got %v", err)
+		}
+		if len(occurrences.records) != 1 || occurrences.records[0].Estado != "" {
+			t.Errorf("expected occurrence state to be restored, got %+v", occurrences.records)
+		}
+	})
+}
+
+// TestRescheduleOccurrence tests moving a single class
+func TestRescheduleOccurrence(t *testing.T) {
+	ctx := context.Background()
+	fecha := today().AddDate(0, 0, 7).Format(dto.FechaLayout)
+	nuevaFecha := today().AddDate(0, 0, 8).Format(dto.FechaLayout)
+	date, _ := parseFecha(fecha)
+	mockRepo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return dto.ActivityAdministration{
+				Activity: dto.Activity{
+					ID:           "1",
+					DiaSemana:    dto.DiasSemana[(int(date.Weekday())+6)%7],
+					HoraInicio:   "10:00",
+					HoraFin:      "11:00",
+					CapacidadMax: 10,
+				},
+				UsersInscribed: []int{100},
+				Activa:         true,
+			}, nil
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		notifier := &mockNotifier{}
+		service := newTestService(testDeps{repo: mockRepo, notifier: notifier})
+
+		occurrence, err := service.RescheduleOccurrence(ctx, "1", fecha, dto.OccurrenceReschedule{
+			NuevaFecha: nuevaFecha,
+			HoraInicio: "18:00",
+			HoraFin:    "19:00",
+			Motivo:     "Sala ocupada",
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if occurrence.Estado != dto.OccurrenceRescheduled || occurrence.Fecha != fecha || occurrence.NuevaFecha != nuevaFecha || occurrence.HoraInicio != "18:00" {
+			t.Errorf("unexpected occurrence %+v", occurrence)
+		}
+
+		if len(notifier.events) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(notifier.events))
+		}
+		event := notifier.events[0]
+		if event.Type != dto.EventActivityRescheduled || event.Fecha != fecha || event.HoraInicio != "10:00" ||
+			event.NuevaFecha != nuevaFecha || event.NuevaHoraInicio != "18:00" || event.NuevaHoraFin != "19:00" {
+			t.Errorf("unexpected event %+v", event)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		service := newTestService(testDeps{repo: mockRepo, notifier: &mockNotifier{}})
+
+		tests := []dto.OccurrenceReschedule{
+			{NuevaFecha: nuevaFecha},
+			{Motivo: "sin cambios"},
+			{NuevaFecha: "mañana", Motivo: "Sala ocupada"},
+			{HoraInicio: "25:00", Motivo: "Sala ocupada"},
+			{HoraInicio: "12:00", Motivo: "hora_fin antes de hora_inicio"},
+			{NuevaFecha: today().AddDate(0, 0, -1).Format(dto.FechaLayout), Motivo: "al pasado"},
+		}
+		for _, req := range tests {
+			if _, err := service.RescheduleOccurrence(ctx, "1", fecha, req); !errors.Is(err, ErrValidation) {
+				t.Errorf("expected validation error for %+v, got %v", req, err)
+			}
+		}
+	})
+
+	t.Run("room conflict on the new date", func(t *testing.T) {
+		newDate, _ := parseFecha(nuevaFecha)
+		activity, _ := mockRepo.getByIDFunc(ctx, "1")
+		activity.SalaID = "s1"
+		pilates := dto.ActivityAdministration{
+			Activity: dto.Activity{ID: "2", Nombre: "Pilates", SalaID: "s1", DiaSemana: dto.DiasSemana[(int(newDate.Weekday())+6)%7], HoraInicio: "18:00", HoraFin: "19:00"},
+			Activa:   true,
+		}
+		repo := *mockRepo
+		repo.getByIDFunc = func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return activity, nil
+		}
+		repo.listByRoomFunc = func(ctx context.Context, salaID string) ([]dto.ActivityAdministration, error) {
+			return []dto.ActivityAdministration{activity, pilates}, nil
+		}
+		var pilatesRecords []dto.OccurrenceRecord
+		occurrences := &mockOccurrences{listFunc: func(activityID string) []dto.OccurrenceRecord {
+			if activityID == "2" {
+				return pilatesRecords
+			}
+			return nil
+		}}
+		notifier := &mockNotifier{}
+		service := newTestService(testDeps{repo: &repo, occurrences: occurrences, notifier: notifier})
+		req := dto.OccurrenceReschedule{NuevaFecha: nuevaFecha, HoraInicio: "18:30", HoraFin: "19:30", Motivo: "Feriado"}
+
+		if _, err := service.RescheduleOccurrence(ctx, "1", fecha, req); !errors.Is(err, ErrRoomConflict) {
+			t.Fatalf("expected ErrRoomConflict, got %v", err)
+		}
+		if len(notifier.events) != 0 {
+			t.Errorf("expected no events, got %+v", notifier.events)
+		}
+
+		// a free time on the same day, or the other class being cancelled, leaves the room free
+		if _, err := service.RescheduleOccurrence(ctx, "1", fecha, dto.OccurrenceReschedule{NuevaFecha: nuevaFecha, HoraInicio: "19:00", HoraFin: "20:00", Motivo: "Feriado"}); err != nil {
+			t.Errorf("expected no error for a free time, got %v", err)
+		}
+		pilatesRecords = []dto.OccurrenceRecord{{ActivityID: "2", Fecha: nuevaFecha, Estado: dto.OccurrenceCancelled}}
+		if _, err := service.RescheduleOccurrence(ctx, "1", fecha, req); err != nil {
+			t.Errorf("expected no error with the other class cancelled, got %v", err)
+		}
+	})
+}
+
+// TestEnrollmentNotification tests the enrollment events for the notifications service
+func TestEnrollmentNotification(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := &mockRepo{
+		inscribirFunc: func(ctx context.Context, id, userID string) (string, error) {
+			return id, nil
+		},
+		getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return dto.ActivityAdministration{
+				Activity: dto.Activity{ID: id, Nombre: "Yoga", DiaSemana: "Lunes", HoraInicio: "10:00", HoraFin: "11:00", CapacidadMax: 10},
+				Activa:   true,
+			}, nil
+		},
+	}
+
+	t.Run("series", func(t *testing.T) {
+		notifier := &mockNotifier{}
+		service := newTestService(testDeps{repo: mockRepo, notifier: notifier})
+
+		if _, err := service.Inscribir(ctx, "1", "100"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(notifier.events) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(notifier.events))
+		}
+		event := notifier.events[0]
+		if event.Type != dto.EventEnrollmentCreated || event.Fecha != "" || event.Dia != "Lunes" || !slices.Equal(event.Usuarios, []int{100}) {
+			t.Errorf("unexpected event %+v", event)
+		}
+	})
+
+	t.Run("publish failure does not fail the enrollment", func(t *testing.T) {
+		service := newTestService(testDeps{repo: mockRepo, notifier: &mockNotifier{err: errors.New("rabbit down")}})
+
+		if _, err := service.Inscribir(ctx, "1", "100"); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
+
+// TestUpcomingOccurrences tests the classes returned for reminders
+func TestUpcomingOccurrences(t *testing.T) {
+	ctx := context.Background()
+	day := today().AddDate(0, 0, 1)
+	dia := dto.DiasSemana[(int(day.Weekday())+6)%7]
+	fecha := day.Format(dto.FechaLayout)
+	mockRepo := &mockRepo{
+		listAllForAdminFunc: func(ctx context.Context) ([]dto.ActivityAdministration, error) {
+			return []dto.ActivityAdministration{
+				{Activity: dto.Activity{ID: "1", DiaSemana: dia, HoraInicio: "10:00", HoraFin: "11:00"}, UsersInscribed: []int{100}, Activa: true},
+				{Activity: dto.Activity{ID: "2", DiaSemana: dia, HoraInicio: "10:30", HoraFin: "11:30"}, Activa: true},
+				{Activity: dto.Activity{ID: "3", DiaSemana: dia, HoraInicio: "18:00", HoraFin: "19:00"}, Activa: true},
+				{Activity: dto.Activity{ID: "4", DiaSemana: dia, HoraInicio: "10:00", HoraFin: "11:00"}, Activa: false},
+			}, nil
+		},
+	}
+	occurrences := &mockOccurrences{records: []dto.OccurrenceRecord{
+		{ActivityID: "1", Fecha: fecha, UsersInscribed: []int{200}},
+		{ActivityID: "2", Fecha: fecha, Estado: dto.OccurrenceCancelled},
+	}}
+	occurrences.listFunc = func(activityID string) []dto.OccurrenceRecord {
+		var records []dto.OccurrenceRecord
+		for _, r := range occurrences.records {
+			if r.ActivityID == activityID {
+				records = append(records, r)
+			}
+		}
+		return records
+	}
+	service := newTestService(testDeps{repo: mockRepo, occurrences: occurrences})
+
+	from := time.Date(day.Year(), day.Month(), day.Day(), 9, 0, 0, 0, time.Local)
+	upcoming, err := service.UpcomingOccurrences(ctx, from, from.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// la 2 está cancelada, la 3 empieza fuera del rango y la 4 está dada de baja
+	if len(upcoming) != 1 || upcoming[0].ActivityID != "1" {
+		t.Fatalf("expected only activity 1, got %+v", upcoming)
+	}
+	if !slices.Equal(upcoming[0].UsersInscribed, []int{100, 200}) {
+		t.Errorf("expected series and class users, got %v", upcoming[0].UsersInscribed)
+	}
+
+	if _, err := service.UpcomingOccurrences(ctx, from, from.AddDate(0, 0, 8)); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected validation error for a range over 7 days, got %v", err)
+	}
+}
+
+type mockRooms struct {
+	rooms   map[string]dto.Room
+	updated *dto.Room
+	deleted string
+}
+
+func (m *mockRooms) List(ctx context.Context) ([]dto.Room, error) {
+	var rooms []dto.Room
+	for _, r := range m.rooms {
+		rooms = append(rooms, r)
+	}
+	return rooms, nil
+}
+
+func (m *mockRooms) GetByID(ctx context.Context, id string) (dto.Room, error) {
+	room, ok := m.rooms[id]
+	if !ok {
+		return dto.Room{}, ErrRoomNotFound
+	}
+	return room, nil
+}
+
+func (m *mockRooms) Create(ctx context.Context, room dto.Room) (dto.Room, error) {
+	room.ID = "new"
+	return room, nil
+}
+
+func (m *mockRooms) Update(ctx context.Context, id string, room dto.Room) (dto.Room, error) {
+	room.ID = id
+	m.updated = &room
+	return room, nil
+}
+
+func (m *mockRooms) Delete(ctx context.Context, id string) error {
+	m.deleted = id
+	return nil
+}
+
+// TestRoomAssignment tests the room capacity, equipment and schedule checks
+func TestRoomAssignment(t *testing.T) {
+	ctx := context.Background()
+	rooms := &mockRooms{rooms: map[string]dto.Room{
+		"spinning": {ID: "spinning", Nombre: "Sala Spinning", Capacidad: 20, Recursos: map[string]int{"bicicleta": 15}},
+	}}
+	booked := dto.ActivityAdministration{
+		Activity: dto.Activity{ID: "1", Nombre: "Spinning AM", DiaSemana: "Lunes", HoraInicio: "08:00", HoraFin: "09:00", CapacidadMax: 15, SalaID: "spinning", Recurso: "bicicleta"},
+		Activa:   true,
+	}
+	newRepo := func() *mockRepo {
+		return &mockRepo{
+			listByRoomFunc: func(ctx context.Context, salaID string) ([]dto.ActivityAdministration, error) {
+				if salaID == "spinning" {
+					return []dto.ActivityAdministration{booked}, nil
+				}
+				return nil, nil
+			},
+			createFunc: func(ctx context.Context, activity dto.ActivityAdministration) (dto.ActivityAdministration, error) {
+				activity.ID = "2"
+				return activity, nil
+			},
+		}
+	}
+	activity := func(dia, inicio, fin string, cupo int, recurso string) dto.ActivityAdministration {
+		return dto.ActivityAdministration{Activity: dto.Activity{
+			Nombre: "Spinning", Profesor: "Ana", DiaSemana: dia, HoraInicio: inicio, HoraFin: fin,
+			CapacidadMax: cupo, SalaID: "spinning", Recurso: recurso,
+		}}
+	}
+
+	t.Run("capacity exceeds room", func(t *testing.T) {
+		service := newTestService(testDeps{repo: newRepo(), rooms: rooms})
+		_, err := service.Create(ctx, activity("Martes", "08:00", "09:00", 25, ""))
+		if !errors.Is(err, ErrValidation) || !errors.Is(err, ErrCapacityExceedsRoom) {
+			t.Errorf("expected capacity exceeds room error, got %v", err)
+		}
+	})
+
+	t.Run("resource limits cupo", func(t *testing.T) {
+		service := newTestService(testDeps{repo: newRepo(), rooms: rooms})
+		if _, err := service.Create(ctx, activity("Martes", "08:00", "09:00", 18, "bicicleta")); !errors.Is(err, ErrCapacityExceedsRoom) {
+			t.Errorf("expected 15 bikes to limit cupo, got %v", err)
+		}
+		if _, err := service.Create(ctx, activity("Martes", "08:00", "09:00", 10, "colchoneta")); !errors.Is(err, ErrResourceNotInRoom) {
+			t.Errorf("expected resource not in room, got %v", err)
+		}
+		if _, err := service.Create(ctx, activity("Martes", "08:00", "09:00", 15, "bicicleta")); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("unknown room", func(t *testing.T) {
+		service := newTestService(testDeps{repo: newRepo(), rooms: rooms})
+		a := activity("Martes", "08:00", "09:00", 10, "")
+		a.SalaID = "missing"
+		if _, err := service.Create(ctx, a); !errors.Is(err, ErrValidation) || !errors.Is(err, ErrUnknownRoom) {
+			t.Errorf("expected unknown room error, got %v", err)
+		}
+	})
+
+	t.Run("schedule conflict", func(t *testing.T) {
+		service := newTestService(testDeps{repo: newRepo(), rooms: rooms})
+		_, err := service.Create(ctx, activity("Lunes", "08:30", "09:30", 10, ""))
+		if !errors.Is(err, ErrRoomConflict) || errors.Is(err, ErrValidation) {
+			t.Errorf("expected room conflict, got %v", err)
+		}
+		// la clase siguiente empieza cuando termina la anterior
+		if _, err := service.Create(ctx, activity("Lunes", "09:00", "10:00", 10, "")); err != nil {
+			t.Errorf("expected back-to-back classes to be allowed, got %v", err)
+		}
+	})
+
+	t.Run("recurrence periods", func(t *testing.T) {
+		a := booked.Activity
+		a.Recurrencia = &dto.Recurrence{Dias: []string{"Lunes", "Miércoles"}, FechaFin: "2026-03-31"}
+		b := booked.Activity
+		b.Recurrencia = &dto.Recurrence{Dias: []string{"Miércoles"}, FechaInicio: "2026-04-01"}
+		if schedulesOverlap(a, b) {
+			t.Error("expected no overlap between consecutive periods")
+		}
+		b.Recurrencia.FechaInicio = "2026-03-15"
+		if !schedulesOverlap(a, b) {
+			t.Error("expected overlap on Wednesdays in March")
+		}
+	})
+
+	t.Run("update excludes itself", func(t *testing.T) {
+		repo := newRepo()
+		repo.getByIDFunc = func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return booked, nil
+		}
+		repo.updateFunc = func(ctx context.Context, id string, activity dto.ActivityAdministration) (dto.ActivityAdministration, error) {
+			return activity, nil
+		}
+		service := newTestService(testDeps{repo: repo, rooms: rooms})
+		update := booked
+		update.Profesor = "Ana"
+		update.HoraFin = "09:15"
+		if _, err := service.Update(ctx, "1", update); err != nil {
+			t.Errorf("expected no conflict with itself, got %v", err)
+		}
+	})
+}
+
+// TestRoomManagement tests the room CRUD and its checks against assigned activities
+func TestRoomManagement(t *testing.T) {
+	ctx := context.Background()
+	booked := dto.ActivityAdministration{
+		Activity: dto.Activity{ID: "1", Nombre: "Spinning AM", CapacidadMax: 15, SalaID: "spinning", Recurso: "bicicleta"},
+		Activa:   true,
+	}
+	repo := &mockRepo{
+		listByRoomFunc: func(ctx context.Context, salaID string) ([]dto.ActivityAdministration, error) {
+			if salaID == "spinning" {
+				return []dto.ActivityAdministration{booked}, nil
+			}
+			return nil, nil
+		},
+	}
+	newRooms := func() *mockRooms {
+		return &mockRooms{rooms: map[string]dto.Room{
+			"spinning": {ID: "spinning", Nombre: "Sala Spinning", Capacidad: 20, Recursos: map[string]int{"bicicleta": 15}},
+			"yoga":     {ID: "yoga", Nombre: "Sala Yoga", Capacidad: 25},
+		}}
+	}
+
+	t.Run("invalid room", func(t *testing.T) {
+		service := newTestService(testDeps{repo: repo, rooms: newRooms()})
+		if _, err := service.CreateRoom(ctx, dto.Room{Nombre: " ", Capacidad: 10}); !errors.Is(err, ErrRoomNameRequired) {
+			t.Errorf("expected name required, got %v", err)
+		}
+		if _, err := service.CreateRoom(ctx, dto.Room{Nombre: "Sala", Capacidad: 10, Recursos: map[string]int{"bicicleta": 0}}); !errors.Is(err, ErrInvalidResource) {
+			t.Errorf("expected invalid resource, got %v", err)
+		}
+	})
+
+	t.Run("cannot shrink below assigned cupo", func(t *testing.T) {
+		rooms := newRooms()
+		service := newTestService(testDeps{repo: repo, rooms: rooms})
+		_, err := service.UpdateRoom(ctx, "spinning", dto.Room{Nombre: "Sala Spinning", Capacidad: 20, Recursos: map[string]int{"bicicleta": 10}})
+		if !errors.Is(err, ErrValidation) || !errors.Is(err, ErrRoomCapacityTooSmall) {
+			t.Errorf("expected room capacity too small, got %v", err)
+		}
+		if rooms.updated != nil {
+			t.Error("expected room not to be updated")
+		}
+	})
+
+	t.Run("cannot delete room in use", func(t *testing.T) {
+		rooms := newRooms()
+		service := newTestService(testDeps{repo: repo, rooms: rooms})
+		if err := service.DeleteRoom(ctx, "spinning"); !errors.Is(err, ErrRoomInUse) {
+			t.Errorf("expected room in use, got %v", err)
+		}
+		if err := service.DeleteRoom(ctx, "yoga"); err != nil || rooms.deleted != "yoga" {
+			t.Errorf("expected yoga to be deleted, got %v", err)
+		}
+	})
+}
+
+type mockCalendarTokens struct {
+	tokens map[string]string // usuario -> token
+}
+
+func (m *mockCalendarTokens) GetByUser(ctx context.Context, userID string) (string, error) {
+	token, ok := m.tokens[userID]
+	if !ok {
+		return "", ErrCalendarTokenNotFound
+	}
+	return token, nil
+}
+
+func (m *mockCalendarTokens) GetUserID(ctx context.Context, token string) (string, error) {
+	for userID, t := range m.tokens {
+		if t == token {
+			return userID, nil
+		}
+	}
+	return "", ErrCalendarTokenNotFound
+}
+
+func (m *mockCalendarTokens) Set(ctx context.Context, userID, token string) error {
+	m.tokens[userID] = token
+	return nil
+}
+
+// TestRecordAttendance tests the attendance of a class
+func TestRecordAttendance(t *testing.T) {
+	ctx := context.Background()
+	// el último lunes ya terminado
+	lastMonday := today().AddDate(0, 0, -7)
+	for lastMonday.Weekday() != time.Monday {
+		lastMonday = lastMonday.AddDate(0, 0, 1)
+	}
+	fecha := lastMonday.Format(dto.FechaLayout)
+	nextMonday := lastMonday.AddDate(0, 0, 14).Format(dto.FechaLayout)
+
+	repo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return dto.ActivityAdministration{
+				Activity:       dto.Activity{ID: id, Nombre: "Yoga", DiaSemana: "Lunes", HoraInicio: "08:00", HoraFin: "09:00", CapacidadMax: 10},
+				UsersInscribed: []int{1, 2},
+				Activa:         true,
+			}, nil
+		},
+	}
+
+	t.Run("absent are the remaining enrolled users", func(t *testing.T) {
+		occurrences := &mockOccurrences{records: []dto.OccurrenceRecord{{ActivityID: "1", Fecha: fecha, UsersInscribed: []int{3}}}}
+		history := &mockHistory{}
+		service := newTestService(testDeps{repo: repo, occurrences: occurrences, history: history})
+
+		attendance, err := service.RecordAttendance(ctx, "1", fecha, []int{1, 3, 3})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !slices.Equal(attendance.Asistentes, []int{1, 3}) || !slices.Equal(attendance.Ausentes, []int{2}) {
+			t.Errorf("expected present [1 3] and absent [2], got %+v", attendance)
+		}
+		if !slices.Equal(occurrences.records[0].Ausentes, []int{2}) {
+			t.Errorf("expected attendance to be saved, got %+v", occurrences.records[0])
+		}
+		if len(history.entries) != 1 || history.entries[0].Action != HistoryActionAttendance {
+			t.Errorf("expected attendance history entry, got %+v", history.entries)
+		}
+	})
+
+	t.Run("user not enrolled", func(t *testing.T) {
+		service := newTestService(testDeps{repo: repo})
+		_, err := service.RecordAttendance(ctx, "1", fecha, []int{9})
+		if !errors.Is(err, ErrValidation) || !errors.Is(err, ErrInvalidAttendance) {
+			t.Errorf("expected invalid attendance, got %v", err)
+		}
+	})
+
+	t.Run("class not started", func(t *testing.T) {
+		service := newTestService(testDeps{repo: repo})
+		if _, err := service.RecordAttendance(ctx, "1", nextMonday, []int{1}); !errors.Is(err, ErrOccurrenceNotStarted) {
+			t.Errorf("expected class not started, got %v", err)
+		}
+	})
+
+	t.Run("cancelled class", func(t *testing.T) {
+		occurrences := &mockOccurrences{records: []dto.OccurrenceRecord{{ActivityID: "1", Fecha: fecha, Estado: dto.OccurrenceCancelled}}}
+		service := newTestService(testDeps{repo: repo, occurrences: occurrences})
+		if _, err := service.RecordAttendance(ctx, "1", fecha, []int{1}); !errors.Is(err, ErrOccurrenceCancelled) {
+			t.Errorf("expected cancelled class, got %v", err)
+		}
+	})
+}
+
+// TestEnrollmentHistory tests the enrollment history of a user
+func TestEnrollmentHistory(t *testing.T) {
+	ctx := context.Background()
+	at := func(day int) time.Time { return time.Date(2026, 3, day, 10, 0, 0, 0, time.UTC) }
+	// el repositorio devuelve el historial más reciente primero
+	history := &mockHistory{entries: []dto.HistoryEntry{
+		{ActivityID: "2", Action: HistoryActionEnroll, UserID: "9", Actor: dto.Actor{ID: "7"}, Timestamp: at(6)},
+		{ActivityID: "1", Action: HistoryActionUnenroll, UserID: "7", Timestamp: at(5)},
+		{ActivityID: "2", Action: HistoryActionEnroll, UserID: "7", Changes: map[string]dto.FieldChange{"fecha": {After: "2026-03-09"}}, Timestamp: at(3)},
+		{ActivityID: "1", Action: HistoryActionEnroll, UserID: "7", Timestamp: at(1)},
+	}}
+	occurrences := &mockOccurrences{records: []dto.OccurrenceRecord{
+		{ActivityID: "1", Fecha: "2026-03-02", Asistentes: []int{7}},
+		{ActivityID: "2", Fecha: "2026-03-09", UsersInscribed: []int{7}, Ausentes: []int{7}},
+	}}
+	mockRepo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			if id == "2" {
+				return dto.ActivityAdministration{}, ErrActivityNotFound
+			}
+			return dto.ActivityAdministration{Activity: dto.Activity{ID: id, Nombre: "Yoga"}}, nil
+		},
+	}
+	service := newTestService(testDeps{repo: mockRepo, history: history, occurrences: occurrences})
+
+	result, err := service.GetEnrollmentHistory(ctx, "7")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Inscripciones) != 2 {
+		t.Fatalf("expected 2 enrollment periods, got %+v", result.Inscripciones)
+	}
+	class, series := result.Inscripciones[0], result.Inscripciones[1]
+	if class.ActivityID != "2" || class.Fecha != "2026-03-09" || class.Hasta != nil || class.Titulo != "" {
+		t.Errorf("expected open class enrollment without title, got %+v", class)
+	}
+	if series.ActivityID != "1" || series.Titulo != "Yoga" || !series.Desde.Equal(at(1)) || series.Hasta == nil || !series.Hasta.Equal(at(5)) {
+		t.Errorf("expected closed series enrollment, got %+v", series)
+	}
+
+	if result.Presentes != 1 || result.Ausentes != 1 || len(result.Asistencias) != 2 {
+		t.Fatalf("expected one present and one absent, got %+v", result)
+	}
+	if result.Asistencias[0].Fecha != "2026-03-09" || result.Asistencias[0].Asistencia != dto.AttendanceAbsent {
+		t.Errorf("expected most recent attendance first, got %+v", result.Asistencias)
+	}
+
+	if _, err := service.GetEnrollmentHistory(ctx, "abc"); !errors.Is(err, ErrInvalidUserID) {
+		t.Errorf("expected invalid user id, got %v", err)
+	}
+}
+
+// TestCalendar tests the iCalendar feed of a user
+func TestCalendar(t *testing.T) {
+	ctx := context.Background()
+	activities := map[string]dto.ActivityAdministration{
+		"1": {
+			Activity: dto.Activity{
+				ID: "1", Nombre: "Yoga, nivel 1", Profesor: "Ana", DiaSemana: "Lunes", HoraInicio: "08:00", HoraFin: "09:00",
+				Recurrencia: &dto.Recurrence{Dias: []string{"Lunes", "Jueves"}, FechaInicio: "2026-03-02", FechaFin: "2026-04-09", Excepciones: []string{"2026-03-23"}},
+			},
+			Activa: true,
+		},
+		"2": {Activity: dto.Activity{ID: "2", Nombre: "Spinning", Profesor: "Luis", DiaSemana: "Martes", HoraInicio: "19:00", HoraFin: "20:00"}, Activa: true},
+		"3": {Activity: dto.Activity{ID: "3", Nombre: "Baja", DiaSemana: "Martes", HoraInicio: "19:00", HoraFin: "20:00"}},
+	}
+	mockRepo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return activities[id], nil
+		},
+		getInscripcionesByUserIDFunc: func(ctx context.Context, userID string) ([]string, error) {
+			return []string{"1", "3"}, nil
+		},
+	}
+	occurrences := &mockOccurrences{records: []dto.OccurrenceRecord{
+		{ActivityID: "1", Fecha: "2026-03-05", Estado: dto.OccurrenceCancelled, Motivo: "Feriado"},
+		{ActivityID: "1", Fecha: "2026-03-09", Estado: dto.OccurrenceRescheduled, NuevaFecha: "2026-03-10", HoraInicio: "18:00", HoraFin: "19:00"},
+		{ActivityID: "2", Fecha: "2026-03-17", UsersInscribed: []int{7}},
+	}}
+	occurrences.listFunc = func(activityID string) []dto.OccurrenceRecord {
+		var records []dto.OccurrenceRecord
+		for _, r := range occurrences.records {
+			if r.ActivityID == activityID {
+				records = append(records, r)
+			}
+		}
+		return records
+	}
+	tokens := &mockCalendarTokens{tokens: map[string]string{"7": "secret"}}
+	service := newTestService(testDeps{repo: mockRepo, occurrences: occurrences, tokens: tokens})
+
+	calendar, err := service.Calendar(ctx, "secret")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, expected := range []string{
+		"UID:1@activities-api",
+		"SUMMARY:Yoga\\, nivel 1",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TH;UNTIL=",
+		"EXDATE" + icsTime("", occurrenceStart("08:00", time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local))),
+		"EXDATE" + icsTime("", occurrenceStart("08:00", time.Date(2026, 3, 9, 0, 0, 0, 0, time.Local))),
+		"EXDATE" + icsTime("", occurrenceStart("08:00", time.Date(2026, 3, 23, 0, 0, 0, 0, time.Local))),
+		"UID:1-2026-03-09@activities-api",
+		"DTSTART" + icsTime("", occurrenceStart("18:00", time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local))),
+		"UID:2-2026-03-17@activities-api",
+	} {
+		if !strings.Contains(calendar, expected) {
+			t.Errorf("expected calendar to contain %q:\n%s", expected, calendar)
+		}
+	}
+	if !strings.Contains(calendar, "DTSTART"+icsTime("", occurrenceStart("08:00", time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)))+"\r\n") {
+		t.Errorf("expected series to start on the first class:\n%s", calendar)
+	}
+	if strings.Contains(calendar, "Baja") {
+		t.Error("expected inactive activities to be left out")
+	}
+	for _, line := range strings.Split(calendar, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("expected folded lines, got %q", line)
+		}
+	}
+
+	if _, err := service.Calendar(ctx, "wrong"); !errors.Is(err, ErrCalendarTokenNotFound) {
+		t.Errorf("expected calendar token not found, got %v", err)
+	}
+
+	t.Run("token is kept until rotated", func(t *testing.T) {
+		first, err := service.CalendarToken(ctx, "8", false)
+		if err != nil || first == "" {
+			t.Fatalf("expected a new token, got %q %v", first, err)
+		}
+		if again, _ := service.CalendarToken(ctx, "8", false); again != first {
+			t.Errorf("expected the same token, got %q and %q", first, again)
+		}
+		if rotated, _ := service.CalendarToken(ctx, "8", true); rotated == first {
+			t.Error("expected a new token after rotating")
+		}
+	})
+}
+
+// TestParseWeek tests the parsing of the week parameter
+func TestParseWeek(t *testing.T) {
+	cases := map[string]string{
+		"2026-W10":   "2026-03-02",
+		"2026-W01":   "2025-12-29",
+		"2026-03-05": "2026-03-02",
+		"2026-03-08": "2026-03-02",
+	}
+	for week, expected := range cases {
+		monday, err := parseWeek(week)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", week, err)
+			continue
+		}
+		if got := monday.Format(dto.FechaLayout); got != expected {
+			t.Errorf("%s: expected %s, got %s", week, expected, got)
+		}
+	}
+
+	for _, week := range []string{"2026-W54", "2026-W00", "2026-13-01", "semana"} {
+		if _, err := parseWeek(week); !errors.Is(err, ErrInvalidWeek) {
+			t.Errorf("%s: expected invalid week, got %v", week, err)
+		}
+	}
+}
+
+// TestWeeklySchedule tests the weekly schedule grouped by day
+func TestWeeklySchedule(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := &mockRepo{
+		listAllForAdminFunc: func(ctx context.Context) ([]dto.ActivityAdministration, error) {
+			return []dto.ActivityAdministration{
+				{Activity: dto.Activity{ID: "1", Nombre: "Yoga", Profesor: "Ana", DiaSemana: "Lunes", HoraInicio: "18:00", HoraFin: "19:00", CapacidadMax: 10}, UsersInscribed: []int{1, 2}, Activa: true},
+				{Activity: dto.Activity{ID: "2", Nombre: "Spinning", Profesor: "Luis", DiaSemana: "Lunes", HoraInicio: "08:00", HoraFin: "09:00", CapacidadMax: 15}, Activa: true},
+				{Activity: dto.Activity{ID: "3", Nombre: "Pilates", DiaSemana: "Lunes", HoraInicio: "07:00", HoraFin: "08:00", CapacidadMax: 5}},
+			}, nil
+		},
+	}
+	occurrences := &mockOccurrences{records: []dto.OccurrenceRecord{
+		{ActivityID: "1", Fecha: "2026-03-02", UsersInscribed: []int{3}},
+		{ActivityID: "2", Fecha: "2026-03-02", Estado: dto.OccurrenceRescheduled, NuevaFecha: "2026-03-04"},
+	}}
+	occurrences.listFunc = func(activityID string) []dto.OccurrenceRecord {
+		var records []dto.OccurrenceRecord
+		for _, r := range occurrences.records {
+			if r.ActivityID == activityID {
+				records = append(records, r)
+			}
+		}
+		return records
+	}
+	service := newTestService(testDeps{repo: mockRepo, occurrences: occurrences})
+
+	schedule, err := service.WeeklySchedule(ctx, "2026-W10")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if schedule.Semana != "2026-W10" || schedule.Desde != "2026-03-02" || schedule.Hasta != "2026-03-08" || len(schedule.Dias) != 7 {
+		t.Fatalf("unexpected week %+v", schedule)
+	}
+
+	lunes := schedule.Dias[0]
+	if lunes.Dia != "Lunes" || len(lunes.Clases) != 1 || lunes.Clases[0].ActivityID != "1" {
+		t.Fatalf("expected only Yoga on Monday, got %+v", lunes)
+	}
+	if lunes.Clases[0].LugaresDisponibles != 7 || lunes.Clases[0].Instructor != "Ana" || lunes.Clases[0].UsersInscribed != nil {
+		t.Errorf("expected 7 spots left without users, got %+v", lunes.Clases[0])
+	}
+
+	miercoles := schedule.Dias[2]
+	if len(miercoles.Clases) != 1 || miercoles.Clases[0].Fecha != "2026-03-02" || miercoles.Clases[0].Estado != dto.OccurrenceRescheduled {
+		t.Errorf("expected rescheduled Spinning on Wednesday, got %+v", miercoles)
+	}
+
+	if _, err := service.WeeklySchedule(ctx, "next"); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
+
+// TestParseActivities tests the parsing of CSV and JSON catalog files
+func TestParseActivities(t *testing.T) {
+	t.Run("CSV with lists and row errors", func(t *testing.T) {
+		data := "clave_externa,titulo,dia,hora_inicio,hora_fin,cupo,dias,excepciones,cancelacion_horas\n" +
+			"yoga-1,Yoga,Lunes,18:00,19:00,10,Lunes|Miércoles,2026-03-02,12\n" +
+			"spin-1,Spinning,Martes,08:00,09:00,diez,,,\n"
+		rows, err := ParseActivities(dto.CatalogFormatCSV, strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(rows) != 2 || rows[0].Fila != 2 || rows[1].Fila != 3 {
+			t.Fatalf("expected rows 2 and 3, got %+v", rows)
+		}
+		yoga := rows[0].Activity
+		if yoga.ClaveExterna != "yoga-1" || yoga.CapacidadMax != 10 || yoga.Recurrencia == nil ||
+			!slices.Equal(yoga.Recurrencia.Dias, []string{"Lunes", "Miércoles"}) || !slices.Equal(yoga.Recurrencia.Excepciones, []string{"2026-03-02"}) ||
+			yoga.Politica == nil || *yoga.Politica != (dto.Policy{CancelacionHoras: 12}) {
+			t.Errorf("unexpected activity %+v", yoga)
+		}
+		if len(rows[1].Errores) != 1 || rows[1].Activity.Recurrencia != nil || rows[1].Activity.Politica != nil {
+			t.Errorf("expected cupo error without recurrence, got %+v", rows[1])
+		}
+	})
+
+	t.Run("CSV with unknown column", func(t *testing.T) {
+		_, err := ParseActivities(dto.CatalogFormatCSV, strings.NewReader("clave_externa,nombre\nyoga-1,Yoga\n"))
+		if !errors.Is(err, ErrInvalidImport) {
+			t.Errorf("expected ErrInvalidImport, got %v", err)
+		}
+	})
+
+	t.Run("JSON with unknown field", func(t *testing.T) {
+		data := `[{"clave_externa":"yoga-1","titulo":"Yoga"},{"clave_externa":"x","color":"rojo"}]`
+		rows, err := ParseActivities(dto.CatalogFormatJSON, strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(rows) != 2 || len(rows[0].Errores) != 0 || rows[0].Activity.Nombre != "Yoga" || len(rows[1].Errores) != 1 {
+			t.Errorf("unexpected rows %+v", rows)
+		}
+		if _, err := ParseActivities(dto.CatalogFormatJSON, strings.NewReader(`{"titulo":"Yoga"}`)); !errors.Is(err, ErrInvalidImport) {
+			t.Errorf("expected ErrInvalidImport for a non array body, got %v", err)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if _, err := ParseCatalogFormat("xml"); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("expected ErrInvalidFormat, got %v", err)
+		}
+	})
+}
+
+// TestImportActivities tests the catalog import (dry run, upsert and validation)
+func TestImportActivities(t *testing.T) {
+	ctx := context.Background()
+	existingID := "65f000000000000000000001"
+	newRepo := func() (*mockRepo, *[]string) {
+		calls := &[]string{}
+		existing := dto.ActivityAdministration{
+			Activity:       dto.Activity{ID: existingID, Nombre: "Yoga", Profesor: "Ana", DiaSemana: "Lunes", HoraInicio: "18:00", HoraFin: "19:00", CapacidadMax: 10},
+			UsersInscribed: []int{1, 2, 3},
+			Activa:         true,
+			Version:        4,
+		}
+		unchanged := dto.ActivityAdministration{
+			Activity:     dto.Activity{ID: "65f000000000000000000002", Nombre: "Pilates", Profesor: "Ana", DiaSemana: "Martes", HoraInicio: "07:00", HoraFin: "08:00", CapacidadMax: 5},
+			ClaveExterna: "pilates-1",
+			Activa:       true,
+		}
+		return &mockRepo{
+			getByExternalKeyFunc: func(ctx context.Context, key string) (dto.ActivityAdministration, error) {
+				if key == "pilates-1" {
+					return unchanged, nil
+				}
+				return dto.ActivityAdministration{}, ErrActivityNotFound
+			},
+			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+				if id == existingID {
+					return existing, nil
+				}
+				return dto.ActivityAdministration{}, ErrActivityNotFound
+			},
+			createFunc: func(ctx context.Context, activity dto.ActivityAdministration) (dto.ActivityAdministration, error) {
+				*calls = append(*calls, "create "+activity.ClaveExterna)
+				activity.ID = "65f000000000000000000009"
+				return activity, nil
+			},
+			updateFunc: func(ctx context.Context, id string, activity dto.ActivityAdministration) (dto.ActivityAdministration, error) {
+				if activity.Version != existing.Version || activity.UsersInscribed != nil {
+					t.Errorf("expected version guard without users, got %+v", activity)
+				}
+				*calls = append(*calls, "update "+id)
+				return activity, nil
+			},
+		}, calls
+	}
+	rows := []dto.ImportRow{
+		{Fila: 1, Activity: dto.ActivityAdministration{Activity: dto.Activity{Nombre: "Spinning", Profesor: "Ana", DiaSemana: "Miércoles", HoraInicio: "08:00", HoraFin: "09:00", CapacidadMax: 15}, ClaveExterna: "spin-1"}},
+		{Fila: 2, Activity: dto.ActivityAdministration{Activity: dto.Activity{Nombre: "Yoga", Profesor: "Ana", DiaSemana: "Lunes", HoraInicio: "18:00", HoraFin: "19:30", CapacidadMax: 10}, ClaveExterna: existingID}},
+		{Fila: 3, Activity: dto.ActivityAdministration{Activity: dto.Activity{Nombre: "Pilates", Profesor: "Ana", DiaSemana: "Martes", HoraInicio: "07:00", HoraFin: "08:00", CapacidadMax: 5}, ClaveExterna: "pilates-1"}},
+	}
+
+	t.Run("dry run reports without applying", func(t *testing.T) {
+		repo, calls := newRepo()
+		service := newTestService(testDeps{repo: repo})
+
+		report, err := service.ImportActivities(ctx, rows, true)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if report.Aplicado || report.Creadas != 1 || report.Actualizadas != 1 || report.SinCambios != 1 || report.ConErrores != 0 {
+			t.Errorf("unexpected report %+v", report)
+		}
+		if yoga := report.Filas[1]; yoga.Accion != dto.ImportActionUpdate || yoga.ActivityID != existingID || !slices.Equal(yoga.Cambios, []string{"hora_fin"}) {
+			t.Errorf("expected hora_fin update by ID, got %+v", yoga)
+		}
+		if len(*calls) != 0 {
+			t.Errorf("expected no writes in a dry run, got %v", *calls)
+		}
+	})
+
+	t.Run("apply creates and updates publishing events", func(t *testing.T) {
+		repo, calls := newRepo()
+		var published []string
+		rabbit := &mockRabbit{publishFunc: func(ctx context.Context, action string, id string) error {
+			published = append(published, action)
+			return nil
+		}}
+		service := newTestService(testDeps{repo: repo, rabbit: rabbit})
+
+		report, err := service.ImportActivities(ctx, rows, false)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !report.Aplicado || report.ConErrores != 0 || report.Filas[0].ActivityID != "65f000000000000000000009" {
+			t.Errorf("unexpected report %+v", report)
+		}
+		if !slices.Equal(*calls, []string{"create spin-1", "update " + existingID}) || !slices.Equal(published, []string{"create", "update"}) {
+			t.Errorf("unexpected writes %v / events %v", *calls, published)
+		}
+	})
+
+	t.Run("invalid rows block the whole import", func(t *testing.T) {
+		repo, calls := newRepo()
+		service := newTestService(testDeps{repo: repo})
+		invalid := append(slices.Clone(rows),
+			dto.ImportRow{Fila: 4, Activity: dto.ActivityAdministration{Activity: dto.Activity{Nombre: "Box", Profesor: "Ana", DiaSemana: "Funday", HoraInicio: "10:00", HoraFin: "11:00", CapacidadMax: 5}, ClaveExterna: "box-1"}},
+			dto.ImportRow{Fila: 5, Activity: rows[0].Activity},
+			dto.ImportRow{Fila: 6, Activity: dto.ActivityAdministration{Activity: rows[0].Activity.Activity}},
+			dto.ImportRow{Fila: 7, Activity: dto.ActivityAdministration{ClaveExterna: "x-1"}, Errores: []string{"cupo must be a number"}},
+		)
+		invalid[1].Activity.CapacidadMax = 2
+
+		report, err := service.ImportActivities(ctx, invalid, false)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if report.Aplicado || report.ConErrores != 5 || len(*calls) != 0 {
+			t.Fatalf("expected 5 invalid rows and no writes, got %+v / %v", report, *calls)
+		}
+		if !strings.Contains(report.Filas[4].Errores[0], ErrDuplicateExternalKey.Error()) {
+			t.Errorf("expected duplicate key error, got %v", report.Filas[4].Errores)
+		}
+		if report.Filas[5].Errores[0] != ErrExternalKeyRequired.Error() {
+			t.Errorf("expected missing key error, got %v", report.Filas[5].Errores)
+		}
+		if !strings.Contains(report.Filas[1].Errores[0], ErrCapacityLessThanInscribed.Error()) {
+			t.Errorf("expected capacity error, got %v", report.Filas[1].Errores)
+		}
+	})
+
+	t.Run("rows in the same file cannot overlap in a room", func(t *testing.T) {
+		repo, calls := newRepo()
+		rooms := &mockRooms{rooms: map[string]dto.Room{"sala-1": {ID: "sala-1", Nombre: "Sala 1", Capacidad: 20}}}
+		service := newTestService(testDeps{repo: repo, rooms: rooms})
+		inRoom := func(fila int, key, dia, inicio, fin string) dto.ImportRow {
+			return dto.ImportRow{Fila: fila, Activity: dto.ActivityAdministration{Activity: dto.Activity{Nombre: key, Profesor: "Ana", DiaSemana: dia,
+				HoraInicio: inicio, HoraFin: fin, CapacidadMax: 10, SalaID: "sala-1"}, ClaveExterna: key}}
+		}
+		overlapping := []dto.ImportRow{
+			inRoom(1, "box-1", "Lunes", "10:00", "11:00"),
+			inRoom(2, "box-2", "Lunes", "10:30", "11:30"),
+			inRoom(3, "box-3", "Martes", "10:00", "11:00"),
+		}
+
+		for _, dryRun := range []bool{true, false} {
+			report, err := service.ImportActivities(ctx, overlapping, dryRun)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if report.Aplicado || report.ConErrores != 1 || len(*calls) != 0 {
+				t.Fatalf("expected the overlap to block the import, got %+v / %v", report, *calls)
+			}
+			if errs := report.Filas[1].Errores; len(errs) != 1 || !strings.Contains(errs[0], ErrRoomConflict.Error()) || !strings.Contains(errs[0], "row 1 (box-1)") {
+				t.Errorf("expected room conflict with row 1, got %v", errs)
+			}
+		}
+	})
+}
+
+// TestExportActivities tests the catalog export
+func TestExportActivities(t *testing.T) {
+	repo := &mockRepo{
+		listAllForAdminFunc: func(ctx context.Context) ([]dto.ActivityAdministration, error) {
+			return []dto.ActivityAdministration{
+				{Activity: dto.Activity{ID: "3", Nombre: "Box", DiaSemana: "Martes", HoraInicio: "07:00", HoraFin: "08:00", CapacidadMax: 5}, Activa: true},
+				{Activity: dto.Activity{ID: "2", Nombre: "Spinning", DiaSemana: "Lunes", HoraInicio: "08:00", HoraFin: "09:00", CapacidadMax: 15}, Activa: false},
+				{Activity: dto.Activity{ID: "1", Nombre: "Yoga", DiaSemana: "Lunes", HoraInicio: "18:00", HoraFin: "19:00", CapacidadMax: 10,
+					Recurrencia: &dto.Recurrence{Dias: []string{"Lunes", "Jueves"}}}, ClaveExterna: "yoga-1", UsersInscribed: []int{1}, Activa: true},
+			}, nil
+		},
+	}
+	service := newTestService(testDeps{repo: repo})
+
+	catalog, err := service.ExportActivities(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(catalog) != 2 || catalog[0].ClaveExterna != "yoga-1" || catalog[1].ClaveExterna != "3" {
+		t.Fatalf("expected active activities by day with ID as default key, got %+v", catalog)
+	}
+
+	for _, format := range []string{dto.CatalogFormatCSV, dto.CatalogFormatJSON} {
+		var buf strings.Builder
+		if err := WriteActivities(format, &buf, catalog); err != nil {
+			t.Fatalf("%s: expected no error, got %v", format, err)
+		}
+		if strings.Contains(buf.String(), "usuarios_inscritos") {
+			t.Errorf("%s: export must not include enrolled users", format)
+		}
+		rows, err := ParseActivities(format, strings.NewReader(buf.String()))
+		if err != nil {
+			t.Fatalf("%s: expected no error reading the export, got %v", format, err)
+		}
+		if len(rows) != 2 || len(rows[0].Errores) != 0 || rows[0].Activity.ClaveExterna != "yoga-1" ||
+			rows[0].Activity.CapacidadMax != 10 || !slices.Equal(rows[0].Activity.Recurrencia.Dias, []string{"Lunes", "Jueves"}) {
+			t.Errorf("%s: export does not round trip, got %+v", format, rows)
+		}
+	}
+}
+
+// TestGetStatistics tests the GetStatistics method
+func TestGetStatistics(t *testing.T) {
+	ctx := context.Background()
+	var limits []int
+	repo := &mockRepo{
+		statisticsFunc: func(ctx context.Context, limit int) (dto.ActivityStatistics, error) {
+			limits = append(limits, limit)
+			return dto.ActivityStatistics{
+				TotalActivities:     4,
+				TotalEnrollments:    30,
+				TotalCapacity:       40,
+				FullActivitiesCount: 1,
+				ByDay: []dto.StatisticsBreakdown{
+					{Clave: "Lunes", Actividades: 3, Inscripciones: 25, Capacidad: 30},
+					{Clave: "Jueves", Actividades: 1, Inscripciones: 5, Capacidad: 10},
+				},
+				ByInstructor: []dto.StatisticsBreakdown{{Clave: "Ana", Actividades: 4, Inscripciones: 30, Capacidad: 40}},
+				ByTimeSlot:   []dto.StatisticsBreakdown{{Clave: "08:00", Actividades: 4, Inscripciones: 30, Capacidad: 0}},
+			}, nil
+		},
+	}
+	history := &mockHistory{weekCounts: []dto.HistoryWeekCount{
+		{Year: 2026, Week: 10, Action: HistoryActionEnroll, Count: 7},
+		{Year: 2026, Week: 10, Action: HistoryActionUnenroll, Count: 2},
+		{Year: 2026, Week: 12, Action: HistoryActionEnroll, Count: 3},
+	}}
+	service := newTestService(testDeps{repo: repo, history: history})
+
+	stats, err := service.GetStatistics(ctx, dto.StatisticsFilters{Desde: "2026-03-04", Hasta: "2026-03-20"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stats.CapacityUtilization != 75 || stats.AverageEnrollmentRate != 7.5 || stats.AvailableActivities != 3 {
+		t.Errorf("unexpected totals %+v", stats)
+	}
+	if stats.ByInstructor[0].Utilizacion != 75 || stats.ByTimeSlot[0].Utilizacion != 0 {
+		t.Errorf("unexpected utilization %+v / %+v", stats.ByInstructor, stats.ByTimeSlot)
+	}
+	wantDays := []dto.DayDistribution{
+		{Dia: "Lunes", Count: 3}, {Dia: "Martes"}, {Dia: "Miércoles"}, {Dia: "Jueves", Count: 1},
+		{Dia: "Viernes"}, {Dia: "Sábado"}, {Dia: "Domingo"},
+	}
+	if !slices.Equal(stats.ActivitiesByDay, wantDays) {
+		t.Errorf("expected days in calendar order with zeros %+v, got %+v", wantDays, stats.ActivitiesByDay)
+	}
+	if len(stats.ByDay) != 7 || stats.ByDay[3].Clave != "Jueves" || stats.ByDay[3].Utilizacion != 50 {
+		t.Errorf("unexpected breakdown by day %+v", stats.ByDay)
+	}
+	if stats.MostPopularActivity != nil || stats.MostPopular == nil || stats.LeastPopular == nil {
+		t.Errorf("expected empty rankings without a most popular activity, got %+v / %+v / %+v", stats.MostPopularActivity, stats.MostPopular, stats.LeastPopular)
+	}
+
+	if !history.from.Equal(time.Date(2026, 3, 4, 0, 0, 0, 0, time.Local)) || !history.to.Equal(time.Date(2026, 3, 21, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("expected history between from and the end of to, got %v - %v", history.from, history.to)
+	}
+	want := []dto.EnrollmentTrend{
+		{Semana: "2026-W10", Desde: "2026-03-02", Inscripciones: 7, Desinscripciones: 2, Neto: 5},
+		{Semana: "2026-W11", Desde: "2026-03-09"},
+		{Semana: "2026-W12", Desde: "2026-03-16", Inscripciones: 3, Neto: 3},
+	}
+	if !slices.Equal(stats.WeeklyEnrollmentsTrend, want) {
+		t.Errorf("expected trend %+v, got %+v", want, stats.WeeklyEnrollmentsTrend)
+	}
+	if stats.PeriodEnrollments != 10 || stats.PeriodUnenrollments != 2 || stats.Desde != "2026-03-04" || stats.Hasta != "2026-03-20" {
+		t.Errorf("unexpected period totals %+v", stats)
+	}
+
+	t.Run("default period is the last 12 weeks", func(t *testing.T) {
+		stats, err := service.GetStatistics(ctx, dto.StatisticsFilters{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(stats.WeeklyEnrollmentsTrend) != 12 || stats.Hasta != today().Format(dto.FechaLayout) {
+			t.Errorf("expected 12 weeks until today, got %d weeks until %s", len(stats.WeeklyEnrollmentsTrend), stats.Hasta)
+		}
+	})
+
+	t.Run("ranking limit", func(t *testing.T) {
+		limits = nil
+		for _, limit := range []int{0, 3, 500} {
+			if _, err := service.GetStatistics(ctx, dto.StatisticsFilters{Limit: limit}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if !slices.Equal(limits, []int{5, 3, 50}) {
+			t.Errorf("expected limits 5, 3 and 50, got %v", limits)
+		}
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		for _, filters := range []dto.StatisticsFilters{
+			{Desde: "2026-03-20", Hasta: "2026-03-04"},
+			{Desde: "2024-01-01", Hasta: "2026-01-01"},
+			{Desde: "ayer"},
+			{Limit: -1},
+		} {
+			if _, err := service.GetStatistics(ctx, filters); !errors.Is(err, ErrValidation) {
+				t.Errorf("%+v: expected validation error, got %v", filters, err)
+			}
+		}
+	})
+}
+
+// TestOccupancyHeatmap tests the occupancy by day and hour
+func TestOccupancyHeatmap(t *testing.T) {
+	repo := &mockRepo{
+		listAllForAdminFunc: func(ctx context.Context) ([]dto.ActivityAdministration, error) {
+			return []dto.ActivityAdministration{
+				{Activity: dto.Activity{ID: "1", DiaSemana: "Lunes", HoraInicio: "18:30", HoraFin: "19:30", CapacidadMax: 10,
+					Recurrencia: &dto.Recurrence{Dias: []string{"Lunes", "Miércoles"}}}, UsersInscribed: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, Activa: true},
+				{Activity: dto.Activity{ID: "2", DiaSemana: "Lunes", HoraInicio: "19:00", HoraFin: "20:00", CapacidadMax: 10}, UsersInscribed: []int{1, 2, 3, 4, 5}, Activa: true},
+				{Activity: dto.Activity{ID: "3", DiaSemana: "Lunes", HoraInicio: "19:00", HoraFin: "20:00", CapacidadMax: 10}, UsersInscribed: []int{1}},
+				{Activity: dto.Activity{ID: "4", DiaSemana: "Martes", HoraInicio: "08:00", HoraFin: "09:00", CapacidadMax: 10,
+					Recurrencia: &dto.Recurrence{Dias: []string{"Martes"}, FechaFin: "2020-01-01"}}, Activa: true},
+			}, nil
+		},
+	}
+	service := newTestService(testDeps{repo: repo})
+
+	heatmap, err := service.OccupancyHeatmap(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(heatmap.Dias) != 7 || heatmap.Dias[0].Dia != "Lunes" || len(heatmap.Dias[0].Horas) != 24 {
+		t.Fatalf("expected a 7 x 24 matrix, got %+v", heatmap.Dias)
+	}
+
+	lunes := heatmap.Dias[0].Horas
+	if lunes[18] != (dto.OccupancyCell{Hora: 18, Clases: 1, Inscriptos: 10, Capacidad: 10, Ocupacion: 100, Llenas: 1}) {
+		t.Errorf("unexpected 18h cell %+v", lunes[18])
+	}
+	if lunes[19] != (dto.OccupancyCell{Hora: 19, Clases: 2, Inscriptos: 15, Capacidad: 20, Ocupacion: 75, Llenas: 1}) {
+		t.Errorf("unexpected 19h cell %+v", lunes[19])
+	}
+	if lunes[20].Clases != 0 || heatmap.Dias[2].Horas[18].Clases != 1 || heatmap.Dias[1].Horas[8].Clases != 0 {
+		t.Errorf("unexpected cells: lunes 20h %+v, miércoles 18h %+v, martes 8h %+v", lunes[20], heatmap.Dias[2].Horas[18], heatmap.Dias[1].Horas[8])
+	}
+
+	want := []dto.OccupancySlot{
+		{Dia: "Lunes", Hora: 18, Ocupacion: 100, Llenas: 1},
+		{Dia: "Miércoles", Hora: 18, Ocupacion: 100, Llenas: 1},
+		{Dia: "Miércoles", Hora: 19, Ocupacion: 100, Llenas: 1},
+	}
+	if !slices.Equal(heatmap.SobreDemanda, want) {
+		t.Errorf("expected over-subscribed slots %+v, got %+v", want, heatmap.SobreDemanda)
+	}
+
+	var buf strings.Builder
+	if err := WriteOccupancyCSV(&buf, heatmap); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1+7*24 || lines[20] != "Lunes,19,2,15,20,75.00,1" {
+		t.Errorf("unexpected CSV: %d lines, 19h row %q", len(lines), lines[20])
+	}
+}
+
+// TestNoShowRates tests the no-show rates by activity
+func TestNoShowRates(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepo{
+		listAllForAdminFunc: func(ctx context.Context) ([]dto.ActivityAdministration, error) {
+			return []dto.ActivityAdministration{
+				{Activity: dto.Activity{ID: "1", Nombre: "Yoga", Profesor: "Ana"}},
+				{Activity: dto.Activity{ID: "2", Nombre: "Spinning", Profesor: "Luis"}},
+			}, nil
+		},
+	}
+	occurrences := &mockOccurrences{attendance: []dto.ActivityAttendance{
+		{ActivityID: "1", Clases: 2, Presentes: 15, Ausentes: 5},
+		{ActivityID: "2", Clases: 1, Presentes: 5, Ausentes: 5, CancelacionesTardias: 2},
+		{ActivityID: "9", Clases: 1, Presentes: 4},
+	}}
+	service := newTestService(testDeps{repo: repo, occurrences: occurrences})
+
+	report, err := service.NoShowRates(ctx, dto.StatisticsFilters{Desde: "2026-03-01", Hasta: "2026-03-31"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.Clases != 4 || report.Presentes != 24 || report.Ausentes != 10 || report.CancelacionesTardias != 2 || report.Desde != "2026-03-01" {
+		t.Errorf("unexpected totals %+v", report)
+	}
+	if len(report.Actividades) != 3 || report.Actividades[0].Titulo != "Spinning" || report.Actividades[0].TasaAusentismo != 50 ||
+		report.Actividades[1].TasaAusentismo != 25 || report.Actividades[2].ActivityID != "9" {
+		t.Errorf("expected activities by no-show rate, got %+v", report.Actividades)
+	}
+
+	var buf strings.Builder
+	if err := WriteNoShowsCSV(&buf, report); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 4 || lines[1] != "2,Spinning,Luis,1,5,5,50.00,2" {
+		t.Errorf("unexpected CSV %q", buf.String())
+	}
+
+	if _, err := service.NoShowRates(ctx, dto.StatisticsFilters{Desde: "2026-04-01", Hasta: "2026-03-01"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
+
+// TestRoster tests the admin roster management
+func TestRoster(t *testing.T) {
+	ctx := context.Background()
+	users := &mockUsers{users: map[int]dto.User{
+		100: {ID: 100}, 200: {ID: 200}, 300: {ID: 300}, 900: {ID: 900, IsAdmin: true},
+	}}
+	// repositorio con estado: las inscripciones cambian la lista de inscriptos
+	newRepo := func() (*mockRepo, map[string]*dto.ActivityAdministration) {
+		activities := map[string]*dto.ActivityAdministration{
+			"1": {Activity: dto.Activity{ID: "1", Nombre: "Yoga", CapacidadMax: 3}, UsersInscribed: []int{100}, Activa: true},
+			"2": {Activity: dto.Activity{ID: "2", Nombre: "Spinning", CapacidadMax: 1}, UsersInscribed: []int{}, Activa: true},
+		}
+		repo := &mockRepo{
+			getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+				activity, ok := activities[id]
+				if !ok {
+					return dto.ActivityAdministration{}, ErrActivityNotFound
+				}
+				copied := *activity
+				copied.UsersInscribed = slices.Clone(activity.UsersInscribed)
+				return copied, nil
+			},
+			inscribirFunc: func(ctx context.Context, id string, userID string) (string, error) {
+				uid, _ := strconv.Atoi(userID)
+				activities[id].UsersInscribed = append(activities[id].UsersInscribed, uid)
+				return id, nil
+			},
+			desinscribirFunc: func(ctx context.Context, id string, userID string) (string, error) {
+				uid, _ := strconv.Atoi(userID)
+				activities[id].UsersInscribed = slices.DeleteFunc(activities[id].UsersInscribed, func(u int) bool { return u == uid })
+				return id, nil
+			},
+		}
+		return repo, activities
+	}
+
+	t.Run("add users", func(t *testing.T) {
+		repo, activities := newRepo()
+		history, notifier := &mockHistory{}, &mockNotifier{}
+		service := newTestService(testDeps{repo: repo, history: history, notifier: notifier, users: users})
+
+		result, err := service.AddToRoster(ctx, "1", []int{200, 300, 200})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !slices.Equal(result.Agregados, []int{200, 300}) || result.Inscriptos != 3 || !slices.Equal(activities["1"].UsersInscribed, []int{100, 200, 300}) {
+			t.Errorf("unexpected result %+v, roster %v", result, activities["1"].UsersInscribed)
+		}
+		if len(history.entries) != 2 || len(notifier.events) != 2 || notifier.events[0].Type != dto.EventEnrollmentCreated {
+			t.Errorf("expected history and events for each user, got %+v %+v", history.entries, notifier.events)
+		}
+	})
+
+	t.Run("already inscribed", func(t *testing.T) {
+		repo, activities := newRepo()
+		service := newTestService(testDeps{repo: repo, users: users})
+
+		if _, err := service.AddToRoster(ctx, "1", []int{200, 100}); !errors.Is(err, ErrUserAlreadyInscribed) {
+			t.Errorf("expected ErrUserAlreadyInscribed, got %v", err)
+		}
+		if len(activities["1"].UsersInscribed) != 1 {
+			t.Errorf("expected no changes, got %v", activities["1"].UsersInscribed)
+		}
+	})
+
+	t.Run("unknown and admin users", func(t *testing.T) {
+		repo, activities := newRepo()
+		service := newTestService(testDeps{repo: repo, users: users})
+
+		_, err := service.AddToRoster(ctx, "1", []int{200, 404, 900})
+		if !errors.Is(err, ErrValidation) || !errors.Is(err, ErrInvalidRosterUsers) ||
+			!strings.Contains(err.Error(), "404: user not found") || !strings.Contains(err.Error(), "900: admin users cannot enroll") {
+			t.Errorf("expected invalid users error, got %v", err)
+		}
+		if len(activities["1"].UsersInscribed) != 1 {
+			t.Errorf("expected no changes, got %v", activities["1"].UsersInscribed)
+		}
+	})
+
+	t.Run("over capacity", func(t *testing.T) {
+		repo, _ := newRepo()
+		service := newTestService(testDeps{repo: repo, users: users})
+
+		if _, err := service.AddToRoster(ctx, "2", []int{200, 300}); !errors.Is(err, ErrActivityFull) {
+			t.Errorf("expected ErrActivityFull, got %v", err)
+		}
+	})
+
+	t.Run("users service down", func(t *testing.T) {
+		repo, _ := newRepo()
+		down := &mockUsers{err: errors.Join(ErrUsersUnavailable, errors.New("connection refused"))}
+		service := newTestService(testDeps{repo: repo, users: down})
+
+		if _, err := service.AddToRoster(ctx, "1", []int{200}); !errors.Is(err, ErrUsersUnavailable) {
+			t.Errorf("expected ErrUsersUnavailable, got %v", err)
+		}
+	})
+
+	t.Run("remove user", func(t *testing.T) {
+		repo, activities := newRepo()
+		notifier := &mockNotifier{}
+		service := newTestService(testDeps{repo: repo, notifier: notifier, users: users})
+
+		result, err := service.RemoveFromRoster(ctx, "1", 100)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !slices.Equal(result.Quitados, []int{100}) || len(activities["1"].UsersInscribed) != 0 ||
+			len(notifier.events) != 1 || notifier.events[0].Type != dto.EventEnrollmentCancelled {
+			t.Errorf("unexpected result %+v, events %+v", result, notifier.events)
+		}
+		if _, err := service.RemoveFromRoster(ctx, "1", 100); !errors.Is(err, ErrUserNotInscribed) {
+			t.Errorf("expected ErrUserNotInscribed, got %v", err)
+		}
+	})
+
+	t.Run("import with replace", func(t *testing.T) {
+		repo, activities := newRepo()
+		service := newTestService(testDeps{repo: repo, users: users})
+
+		ids, err := ParseRoster(dto.CatalogFormatCSV, strings.NewReader("nombre,id_usuario\nAna,200\nLuis,300\n"))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		result, err := service.ImportRoster(ctx, "1", ids, true)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !slices.Equal(result.Agregados, []int{200, 300}) || !slices.Equal(result.Quitados, []int{100}) ||
+			!slices.Equal(activities["1"].UsersInscribed, []int{200, 300}) {
+			t.Errorf("unexpected result %+v, roster %v", result, activities["1"].UsersInscribed)
+		}
+	})
+
+	t.Run("move user", func(t *testing.T) {
+		repo, activities := newRepo()
+		history, notifier := &mockHistory{}, &mockNotifier{}
+		service := newTestService(testDeps{repo: repo, history: history, notifier: notifier, users: users})
+
+		if err := service.MoveEnrollment(ctx, "1", "2", 100); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(activities["1"].UsersInscribed) != 0 || !slices.Equal(activities["2"].UsersInscribed, []int{100}) {
+			t.Errorf("expected user moved, got %v and %v", activities["1"].UsersInscribed, activities["2"].UsersInscribed)
+		}
+		if len(history.entries) != 2 || len(notifier.events) != 2 ||
+			notifier.events[0].ActivityID != "1" || notifier.events[1].Type != dto.EventEnrollmentCreated {
+			t.Errorf("expected unenroll and enroll records, got %+v %+v", history.entries, notifier.events)
+		}
+		if err := service.MoveEnrollment(ctx, "2", "2", 100); !errors.Is(err, ErrSameActivity) {
+			t.Errorf("expected ErrSameActivity, got %v", err)
+		}
+		if err := service.MoveEnrollment(ctx, "1", "2", 100); !errors.Is(err, ErrUserNotInscribed) {
+			t.Errorf("expected ErrUserNotInscribed, got %v", err)
+		}
+	})
+}
+
+// TestGetRoster tests the roster detail with the users resolved in batch
+func TestGetRoster(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return dto.ActivityAdministration{
+				Activity:       dto.Activity{ID: id, Nombre: "Yoga", DiaSemana: "Lunes", HoraInicio: "09:00", HoraFin: "10:00", CapacidadMax: 10},
+				UsersInscribed: []int{1, 2, 7},
+				Activa:         true,
+			}, nil
+		},
+	}
+	occurrences := &mockOccurrences{records: []dto.OccurrenceRecord{
+		{ActivityID: "1", Fecha: "2026-03-02", HoraInicio: "11:00", UsersInscribed: []int{3, 1}},
+	}}
+	users := &mockUsers{users: map[int]dto.User{
+		1: {ID: 1, Nombre: "Ana", Apellido: "Pérez", Email: "ana@mail.com"},
+		2: {ID: 2, Nombre: "Luis", Apellido: "Gómez", Email: "luis@mail.com"},
+		3: {ID: 3, Nombre: "Eva", Apellido: "Gómez", Email: "eva@mail.com"},
+	}}
+	service := newTestService(testDeps{repo: repo, occurrences: occurrences, users: users})
+
+	t.Run("series", func(t *testing.T) {
+		roster, err := service.GetRoster(ctx, "1", "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(roster.Inscritos) != 2 || roster.Inscritos[0].ID != 2 || roster.Inscritos[1].Email != "ana@mail.com" {
+			t.Errorf("expected users sorted by surname, got %+v", roster.Inscritos)
+		}
+		if !slices.Equal(roster.Desconocidos, []int{7}) {
+			t.Errorf("expected unknown user 7, got %v", roster.Desconocidos)
+		}
+		if users.batches != 1 {
+			t.Errorf("expected a single users lookup, got %d", users.batches)
+		}
+
+		var buf strings.Builder
+		if err := WriteRosterCSV(&buf, roster); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := "id_usuario,apellido,nombre,email,firma\n2,Gómez,Luis,luis@mail.com,\n1,Pérez,Ana,ana@mail.com,\n7,,,,\n"
+		if buf.String() != expected {
+			t.Errorf("unexpected csv:\n%s", buf.String())
+		}
+	})
+
+	t.Run("class date", func(t *testing.T) {
+		roster, err := service.GetRoster(ctx, "1", "2026-03-02")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		ids := []int{}
+		for _, user := range roster.Inscritos {
+			ids = append(ids, user.ID)
+		}
+		if !slices.Equal(ids, []int{3, 2, 1}) || roster.HoraInicio != "11:00" || roster.LugaresDisponibles != 5 {
+			t.Errorf("unexpected class roster %+v", roster)
+		}
+		if _, err := service.GetRoster(ctx, "1", "2026-03-03"); !errors.Is(err, ErrOccurrenceNotFound) {
+			t.Errorf("expected ErrOccurrenceNotFound, got %v", err)
+		}
+	})
+
+	t.Run("users service down", func(t *testing.T) {
+		down := newTestService(testDeps{repo: repo, occurrences: occurrences, users: &mockUsers{err: ErrUsersUnavailable}})
+		if _, err := down.GetRoster(ctx, "1", ""); !errors.Is(err, ErrUsersUnavailable) {
+			t.Errorf("expected ErrUsersUnavailable, got %v", err)
+		}
+	})
+}
+
+// TestEnrollmentChecksUser tests that enrollments are rejected for users unknown to users-api
+func TestEnrollmentChecksUser(t *testing.T) {
+	ctx := context.Background()
+	fecha := today().AddDate(0, 0, 7)
+	inscribed := false
+	repo := &mockRepo{
+		getByIDFunc: func(ctx context.Context, id string) (dto.ActivityAdministration, error) {
+			return dto.ActivityAdministration{
+				Activity: dto.Activity{ID: id, DiaSemana: dto.DiasSemana[(int(fecha.Weekday())+6)%7], HoraInicio: "09:00", HoraFin: "10:00", CapacidadMax: 10},
+				Activa:   true,
+			}, nil
+		},
+		inscribirFunc: func(ctx context.Context, id string, userID string) (string, error) {
+			inscribed = true
+			return id, nil
+		},
+	}
+	occurrences := &mockOccurrences{}
+	users := &mockUsers{users: map[int]dto.User{100: {ID: 100}}, entitlement: dto.Entitlement{Vigente: true}}
+	service := newTestService(testDeps{repo: repo, occurrences: occurrences, users: users})
+
+	if _, err := service.Inscribir(ctx, "1", "404"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+	if inscribed {
+		t.Error("expected unknown user not to be inscribed")
+	}
+	if err := service.InscribirOccurrence(ctx, "1", fecha.Format(dto.FechaLayout), "404"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound for a class, got %v", err)
+	}
+
+	if _, err := service.Inscribir(ctx, "1", "100"); err != nil || !inscribed {
+		t.Errorf("expected known user to be inscribed, got %v", err)
+	}
+
+	down := newTestService(testDeps{repo: repo, occurrences: occurrences, users: &mockUsers{err: ErrUsersUnavailable}})
+	if _, err := down.Inscribir(ctx, "1", "100"); !errors.Is(err, ErrUsersUnavailable) {
+		t.Errorf("expected ErrUsersUnavailable, got %v", err)
+	}
+}
+
+// TestRemoveDeletedUser tests that a user.deleted event removes the user from every roster
+func TestRemoveDeletedUser(t *testing.T) {
+	ctx := context.Background()
+	var removed []string
+	repo := &mockRepo{
+		getInscripcionesByUserIDFunc: func(ctx context.Context, userID string) ([]string, error) {
+			return []string{"1", "2"}, nil
+		},
+		desinscribirFunc: func(ctx context.Context, id string, userID string) (string, error) {
+			if id == "2" {
+				// ya quitado en un procesamiento anterior del evento
+				return "", ErrUserNotInscribed
+			}
+			removed = append(removed, id+"/"+userID)
+			return id, nil
+		},
+	}
+	past := today().AddDate(0, 0, -7).Format(dto.FechaLayout)
+	future := today().AddDate(0, 0, 7).Format(dto.FechaLayout)
+	occurrences := &mockOccurrences{records: []dto.OccurrenceRecord{
+		{ActivityID: "3", Fecha: past, UsersInscribed: []int{5}},
+		{ActivityID: "3", Fecha: future, UsersInscribed: []int{5, 6}},
+		{ActivityID: "4", Fecha: future, Asistentes: []int{5}},
+	}}
+	history, notifier, users := &mockHistory{}, &mockNotifier{}, &mockUsers{}
+	service := newTestService(testDeps{repo: repo, history: history, occurrences: occurrences, notifier: notifier, users: users})
+
+	if err := service.HandleUserEvent(ctx, dto.UserEvent{Type: dto.EventUserDeleted, UserID: 5}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !slices.Equal(removed, []string{"1/5"}) {
+		t.Errorf("expected user removed from series 1, got %v", removed)
+	}
+	if !slices.Equal(occurrences.unenrolled, []string{"3/" + future + "/5"}) {
+		t.Errorf("expected user removed only from the future class, got %v", occurrences.unenrolled)
+	}
+	if !slices.Equal(users.forgotten, []int{5}) {
+		t.Errorf("expected cached user to be forgotten, got %v", users.forgotten)
+	}
+	// la serie 2 no se registra: el usuario ya no estaba inscripto
+	if len(history.entries) != 2 || history.entries[0].ActivityID != "1" || history.entries[1].ActivityID != "3" ||
+		history.entries[0].Action != HistoryActionUserDeleted || history.entries[0].Actor.Username != "users-api" {
+		t.Errorf("unexpected history %+v", history.entries)
+	}
+	if len(notifier.events) != 0 {
+		t.Errorf("expected no notifications for a deleted user, got %+v", notifier.events)
+	}
+
+	if err := service.HandleUserEvent(ctx, dto.UserEvent{Type: "user.created", UserID: 7}); err != nil {
+		t.Errorf("expected unknown events to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// overSubscribedThreshold es la ocupación (porcentaje) desde la que una franja tiene sobredemanda
+const overSubscribedThreshold = 90
+
+// OccupancyHeatmap arma la matriz día de la semana × hora con la ocupación (inscriptos a la serie
+// sobre cupo) de las actividades activas vigentes. Una clase ocupa cada hora en la que se dicta,
+// aunque sea en parte (una clase de 18:30 a 19:30 cuenta en las 18 y en las 19).
+func (s *ActivitiesServiceImpl) OccupancyHeatmap(ctx context.Context) (dto.OccupancyHeatmap, error) {
+	activities, err := s.repository.ListAllForAdmin(ctx)
+	if err != nil {
+		return dto.OccupancyHeatmap{}, err
+	}
+
+	heatmap := dto.OccupancyHeatmap{
+		Dias:         make([]dto.OccupancyDay, len(dto.DiasSemana)),
+		Umbral:       overSubscribedThreshold,
+		SobreDemanda: []dto.OccupancySlot{},
+	}
+	for i, dia := range dto.DiasSemana {
+		heatmap.Dias[i] = dto.OccupancyDay{Dia: dia, Horas: make([]dto.OccupancyCell, 24)}
+		for hora := range heatmap.Dias[i].Horas {
+			heatmap.Dias[i].Horas[hora].Hora = hora
+		}
+	}
+
+	hoy := today().Format(dto.FechaLayout)
+	for _, activity := range activities {
+		rec := effectiveRecurrence(activity.Activity)
+		if !activity.Activa || (rec.FechaFin != "" && rec.FechaFin < hoy) {
+			continue
+		}
+		start, errStart := time.Parse("15:04", activity.HoraInicio)
+		end, errEnd := time.Parse("15:04", activity.HoraFin)
+		if errStart != nil || errEnd != nil || !end.After(start) {
+			log.Warnf("Activity %s skipped in occupancy heatmap: invalid schedule %s-%s", activity.ID, activity.HoraInicio, activity.HoraFin)
+			continue
+		}
+
+		inscriptos := len(activity.UsersInscribed)
+		for _, dia := range rec.Dias {
+			d := slices.Index(dto.DiasSemana, dia)
+			if d < 0 {
+				continue
+			}
+			for hora := start.Hour(); hora <= end.Add(-time.Minute).Hour(); hora++ {
+				cell := &heatmap.Dias[d].Horas[hora]
+				cell.Clases++
+				cell.Inscriptos += inscriptos
+				cell.Capacidad += activity.CapacidadMax
+				if inscriptos >= activity.CapacidadMax {
+					cell.Llenas++
+				}
+			}
+		}
+	}
+
+	for d := range heatmap.Dias {
+		for h := range heatmap.Dias[d].Horas {
+			cell := &heatmap.Dias[d].Horas[h]
+			cell.Ocupacion = utilization(cell.Inscriptos, cell.Capacidad)
+			if cell.Clases > 0 && cell.Ocupacion >= overSubscribedThreshold {
+				heatmap.SobreDemanda = append(heatmap.SobreDemanda, dto.OccupancySlot{
+					Dia: heatmap.Dias[d].Dia, Hora: cell.Hora, Ocupacion: cell.Ocupacion, Llenas: cell.Llenas,
+				})
+			}
+		}
+	}
+	// de mayor a menor ocupación; a igual ocupación queda el orden de la semana
+	slices.SortStableFunc(heatmap.SobreDemanda, func(a, b dto.OccupancySlot) int {
+		return cmp.Compare(b.Ocupacion, a.Ocupacion)
+	})
+	return heatmap, nil
+}
+
+// NoShowRates calcula el ausentismo de cada actividad en las clases del período con la asistencia
+// tomada, de mayor a menor, junto con sus cancelaciones tardías. El período se valida como el de
+// las estadísticas.
+func (s *ActivitiesServiceImpl) NoShowRates(ctx context.Context, filters dto.StatisticsFilters) (dto.NoShowReport, error) {
+	from, to, err := statisticsPeriod(filters)
+	if err != nil {
+		return dto.NoShowReport{}, err
+	}
+
+	attendance, err := s.occurrences.AttendanceByActivity(ctx, from.Format(dto.FechaLayout), to.Format(dto.FechaLayout))
+	if err != nil {
+		return dto.NoShowReport{}, err
+	}
+	activities, err := s.repository.ListAllForAdmin(ctx)
+	if err != nil {
+		return dto.NoShowReport{}, err
+	}
+	byID := map[string]dto.ActivityAdministration{}
+	for _, activity := range activities {
+		byID[activity.ID] = activity
+	}
+
+	report := dto.NoShowReport{
+		Desde:       from.Format(dto.FechaLayout),
+		Hasta:       to.Format(dto.FechaLayout),
+		Actividades: []dto.NoShowRate{},
+	}
+	for _, a := range attendance {
+		activity := byID[a.ActivityID] // las actividades eliminadas quedan sin título
+		report.Actividades = append(report.Actividades, dto.NoShowRate{
+			ActivityID:           a.ActivityID,
+			Titulo:               activity.Nombre,
+			Instructor:           activity.Profesor,
+			Clases:               a.Clases,
+			Presentes:            a.Presentes,
+			Ausentes:             a.Ausentes,
+			TasaAusentismo:       utilization(a.Ausentes, a.Presentes+a.Ausentes),
+			CancelacionesTardias: a.CancelacionesTardias,
+		})
+		report.Clases += a.Clases
+		report.Presentes += a.Presentes
+		report.Ausentes += a.Ausentes
+		report.CancelacionesTardias += a.CancelacionesTardias
+	}
+	report.TasaAusentismo = utilization(report.Ausentes, report.Presentes+report.Ausentes)
+
+	slices.SortFunc(report.Actividades, func(a, b dto.NoShowRate) int {
+		return cmp.Or(
+			cmp.Compare(b.TasaAusentismo, a.TasaAusentismo),
+			cmp.Compare(b.Ausentes, a.Ausentes),
+			cmp.Compare(a.Titulo, b.Titulo),
+			cmp.Compare(a.ActivityID, b.ActivityID),
+		)
+	})
+	return report, nil
+}
+
+// WriteOccupancyCSV escribe la matriz de ocupación con una fila por día y hora
+func WriteOccupancyCSV(w io.Writer, heatmap dto.OccupancyHeatmap) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"dia", "hora", "clases", "inscriptos", "capacidad", "ocupacion", "llenas"}); err != nil {
+		return err
+	}
+	for _, day := range heatmap.Dias {
+		for _, cell := range day.Horas {
+			record := []string{
+				day.Dia, strconv.Itoa(cell.Hora), strconv.Itoa(cell.Clases), strconv.Itoa(cell.Inscriptos),
+				strconv.Itoa(cell.Capacidad), formatPercent(cell.Ocupacion), strconv.Itoa(cell.Llenas),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteNoShowsCSV escribe el ausentismo con una fila por actividad
+func WriteNoShowsCSV(w io.Writer, report dto.NoShowReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"id_actividad", "titulo", "instructor", "clases", "presentes", "ausentes", "tasa_ausentismo", "cancelaciones_tardias"}); err != nil {
+		return err
+	}
+	for _, rate := range report.Actividades {
+		record := []string{
+			rate.ActivityID, rate.Titulo, rate.Instructor, strconv.Itoa(rate.Clases),
+			strconv.Itoa(rate.Presentes), strconv.Itoa(rate.Ausentes), formatPercent(rate.TasaAusentismo),
+			strconv.Itoa(rate.CancelacionesTardias),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatPercent(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
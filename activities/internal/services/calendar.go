@@ -0,0 +1,323 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	icsLocalLayout = "20060102T150405"
+	icsUTCLayout   = "20060102T150405Z"
+	icsUIDDomain   = "activities-api"
+)
+
+var icsWeekdays = map[string]string{
+	"Lunes":     "MO",
+	"Martes":    "TU",
+	"Miércoles": "WE",
+	"Jueves":    "TH",
+	"Viernes":   "FR",
+	"Sábado":    "SA",
+	"Domingo":   "SU",
+}
+
+// calendarSeries es una actividad a la que el usuario está inscripto con lo guardado de sus clases
+type calendarSeries struct {
+	activity dto.ActivityAdministration
+	records  []dto.OccurrenceRecord
+	sala     string
+}
+
+// calendarClass es una clase suelta a la que el usuario se inscribió
+type calendarClass struct {
+	activity   dto.ActivityAdministration
+	occurrence dto.Occurrence
+	sala       string
+}
+
+// Calendar devuelve el calendario iCalendar (.ics) del dueño del token: un evento semanal por
+// cada actividad activa a la que está inscripto (sin las clases canceladas y con las
+// reprogramadas en su nueva fecha) y un evento por cada clase a la que se inscribió suelta
+func (s *ActivitiesServiceImpl) Calendar(ctx context.Context, token string) (string, error) {
+	if s.calendarTokens == nil || token == "" {
+		return "", ErrCalendarTokenNotFound
+	}
+	userID, err := s.calendarTokens.GetUserID(ctx, token)
+	if err != nil {
+		return "", err
+	}
+
+	activities := map[string]dto.ActivityAdministration{}
+	getActivity := func(id string) (dto.ActivityAdministration, bool, error) {
+		if activity, ok := activities[id]; ok {
+			return activity, activity.Activa, nil
+		}
+		activity, err := s.repository.GetByID(ctx, id)
+		if errors.Is(err, ErrActivityNotFound) {
+			return activity, false, nil
+		}
+		if err != nil {
+			return activity, false, err
+		}
+		activities[id] = activity
+		return activity, activity.Activa, nil
+	}
+
+	ids, err := s.repository.GetInscripcionesByUserID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	var series []calendarSeries
+	for _, id := range ids {
+		activity, ok, err := getActivity(id)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			continue
+		}
+		records, err := s.occurrences.List(ctx, id, "0000-01-01", "9999-12-31")
+		if err != nil {
+			return "", err
+		}
+		series = append(series, calendarSeries{activity: activity, records: records, sala: s.roomName(ctx, activity.SalaID)})
+	}
+
+	uid, _ := strconv.Atoi(userID)
+	userRecords, err := s.occurrences.ListByUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	var classes []calendarClass
+	for _, record := range userRecords {
+		if !slices.Contains(record.UsersInscribed, uid) || slices.Contains(ids, record.ActivityID) {
+			continue
+		}
+		activity, ok, err := getActivity(record.ActivityID)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			continue
+		}
+		classes = append(classes, calendarClass{
+			activity:   activity,
+			occurrence: buildOccurrence(activity, record.Fecha, record),
+			sala:       s.roomName(ctx, activity.SalaID),
+		})
+	}
+
+	return buildCalendar("Mis clases", series, classes, time.Now()), nil
+}
+
+// roomName devuelve el nombre de la sala para la ubicación de los eventos ("" si no se conoce)
+func (s *ActivitiesServiceImpl) roomName(ctx context.Context, salaID string) string {
+	if salaID == "" || s.rooms == nil {
+		return ""
+	}
+	room, err := s.rooms.GetByID(ctx, salaID)
+	if err != nil {
+		return ""
+	}
+	return room.Nombre
+}
+
+// seriesStart devuelve la primera clase de la actividad: desde el inicio de la recurrencia o, si
+// no tiene, desde que se creó la actividad
+func seriesStart(activity dto.ActivityAdministration, rec dto.Recurrence) (time.Time, bool) {
+	from := activity.FechaCreacion.In(time.Local)
+	if activity.FechaCreacion.IsZero() {
+		from = today()
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local)
+	if rec.FechaInicio != "" {
+		if inicio, err := parseFecha(rec.FechaInicio); err == nil {
+			from = inicio
+		}
+	}
+
+	// la primera semana sin excepciones: DTSTART tiene que ser una clase de la regla
+	rec.Excepciones = nil
+	dates := occurrenceDates(rec, from, from.AddDate(0, 0, 6))
+	if len(dates) == 0 {
+		return time.Time{}, false
+	}
+	return dates[0], true
+}
+
+// buildCalendar arma el iCalendar con una serie semanal por actividad y un evento por cada clase
+// suelta o reprogramada. stamp es el DTSTAMP de los eventos.
+func buildCalendar(name string, series []calendarSeries, classes []calendarClass, stamp time.Time) string {
+	w := &icsWriter{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//gimnasio//activities-api//ES")
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	w.line("X-WR-CALNAME:" + icsText(name))
+	if tz := icsTimezone(); tz != "" {
+		w.line("X-WR-TIMEZONE:" + tz)
+	}
+	dtstamp := "DTSTAMP:" + stamp.UTC().Format(icsUTCLayout)
+
+	for _, item := range series {
+		activity := item.activity
+		rec := effectiveRecurrence(activity.Activity)
+		first, ok := seriesStart(activity, rec)
+		if !ok {
+			continue
+		}
+
+		var days []string
+		for _, dia := range rec.Dias {
+			days = append(days, icsWeekdays[dia])
+		}
+		rrule := "RRULE:FREQ=WEEKLY;BYDAY=" + strings.Join(days, ",")
+		if rec.FechaFin != "" {
+			fin, err := parseFecha(rec.FechaFin)
+			if err != nil || fin.Before(first) {
+				continue
+			}
+			rrule += ";UNTIL=" + icsUntil(occurrenceStart("23:59", fin))
+		}
+
+		w.line("BEGIN:VEVENT")
+		w.line("UID:" + activity.ID + "@" + icsUIDDomain)
+		w.line(dtstamp)
+		w.line(icsTime("DTSTART", occurrenceStart(activity.HoraInicio, first)))
+		w.line(icsTime("DTEND", occurrenceStart(activity.HoraFin, first)))
+		w.line(rrule)
+		excluded := slices.Clone(rec.Excepciones)
+		for _, record := range item.records {
+			if record.Estado == dto.OccurrenceCancelled || record.Estado == dto.OccurrenceRescheduled {
+				excluded = append(excluded, record.Fecha)
+			}
+		}
+		slices.Sort(excluded)
+		for _, fecha := range slices.Compact(excluded) {
+			if date, err := parseFecha(fecha); err == nil {
+				w.line(icsTime("EXDATE", occurrenceStart(activity.HoraInicio, date)))
+			}
+		}
+		w.event(activity, item.sala, "")
+		w.line("END:VEVENT")
+
+		// las clases reprogramadas se publican como eventos sueltos en su nueva fecha
+		for _, record := range item.records {
+			if record.Estado != dto.OccurrenceRescheduled {
+				continue
+			}
+			w.class(activity, buildOccurrence(activity, record.Fecha, record), item.sala, dtstamp)
+		}
+	}
+
+	for _, class := range classes {
+		w.class(class.activity, class.occurrence, class.sala, dtstamp)
+	}
+
+	w.line("END:VCALENDAR")
+	return w.String()
+}
+
+type icsWriter struct {
+	strings.Builder
+}
+
+// line escribe una línea terminada en CRLF, partida en líneas de hasta 75 bytes (RFC 5545 3.1)
+func (w *icsWriter) line(text string) {
+	limit := 75
+	for len(text) > limit {
+		cut := limit
+		// no partir un carácter UTF-8 al medio
+		for cut > 0 && text[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(text[:cut] + "\r\n ")
+		text = text[cut:]
+		limit = 74 // el espacio inicial de la continuación cuenta
+	}
+	w.WriteString(text + "\r\n")
+}
+
+// event escribe el título, la descripción y la ubicación del evento
+func (w *icsWriter) event(activity dto.ActivityAdministration, sala, nota string) {
+	w.line("SUMMARY:" + icsText(activity.Nombre))
+	description := "Instructor: " + activity.Profesor
+	if activity.Descripcion != "" {
+		description += "\n" + activity.Descripcion
+	}
+	if nota != "" {
+		description = nota + "\n" + description
+	}
+	w.line("DESCRIPTION:" + icsText(description))
+	if sala != "" {
+		w.line("LOCATION:" + icsText(sala))
+	}
+}
+
+// class escribe el evento de una clase suelta o reprogramada
+func (w *icsWriter) class(activity dto.ActivityAdministration, occurrence dto.Occurrence, sala, stamp string) {
+	date, err := parseFecha(occurrenceDate(occurrence))
+	if err != nil {
+		return
+	}
+
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + activity.ID + "-" + occurrence.Fecha + "@" + icsUIDDomain)
+	w.line(stamp)
+	w.line(icsTime("DTSTART", occurrenceStart(occurrence.HoraInicio, date)))
+	w.line(icsTime("DTEND", occurrenceStart(occurrence.HoraFin, date)))
+	nota := ""
+	switch occurrence.Estado {
+	case dto.OccurrenceCancelled:
+		w.line("STATUS:CANCELLED")
+		nota = "Clase cancelada"
+	case dto.OccurrenceRescheduled:
+		nota = fmt.Sprintf("Clase del %s reprogramada", occurrence.Fecha)
+	}
+	if nota != "" && occurrence.Motivo != "" {
+		nota += ": " + occurrence.Motivo
+	}
+	w.event(activity, sala, nota)
+	w.line("END:VEVENT")
+}
+
+// icsTimezone devuelve la zona horaria de los eventos ("" si es la hora local sin nombre, en ese
+// caso los eventos usan hora flotante)
+func icsTimezone() string {
+	if tz := time.Local.String(); tz != "Local" {
+		return tz
+	}
+	return ""
+}
+
+func icsTime(property string, t time.Time) string {
+	switch tz := icsTimezone(); tz {
+	case "":
+		return property + ":" + t.Format(icsLocalLayout)
+	case "UTC":
+		return property + ":" + t.UTC().Format(icsUTCLayout)
+	default:
+		return property + ";TZID=" + tz + ":" + t.Format(icsLocalLayout)
+	}
+}
+
+// icsUntil formatea el fin de la regla: en UTC si los eventos tienen zona horaria
+func icsUntil(t time.Time) string {
+	if icsTimezone() == "" {
+		return t.Format(icsLocalLayout)
+	}
+	return t.UTC().Format(icsUTCLayout)
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func icsText(text string) string {
+	return icsEscaper.Replace(text)
+}
@@ -0,0 +1,456 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// catalogColumns son las columnas del CSV del catálogo, con los mismos nombres que el JSON. Las
+// listas (dias, excepciones) se separan con "|".
+var catalogColumns = []string{
+	"clave_externa", "titulo", "descripcion", "instructor", "dia", "hora_inicio", "hora_fin", "cupo",
+	"foto_url", "id_sala", "recurso", "dias", "fecha_inicio", "fecha_fin", "excepciones",
+	"apertura_dias", "cierre_minutos", "cancelacion_horas",
+}
+
+const catalogListSeparator = "|"
+
+// ParseCatalogFormat normaliza el formato del catálogo (csv o json)
+func ParseCatalogFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case dto.CatalogFormatCSV:
+		return dto.CatalogFormatCSV, nil
+	case dto.CatalogFormatJSON:
+		return dto.CatalogFormatJSON, nil
+	default:
+		return "", errors.Join(ErrValidation, fmt.Errorf("%w: %q", ErrInvalidFormat, format))
+	}
+}
+
+// ParseActivities lee las actividades a importar. Los errores de una fila (ej. un cupo que no es
+// un número) quedan en la fila; solo se devuelve error si el archivo no se puede leer.
+func ParseActivities(format string, r io.Reader) ([]dto.ImportRow, error) {
+	switch format {
+	case dto.CatalogFormatCSV:
+		return parseActivitiesCSV(r)
+	case dto.CatalogFormatJSON:
+		return parseActivitiesJSON(r)
+	default:
+		return nil, errors.Join(ErrValidation, fmt.Errorf("%w: %q", ErrInvalidFormat, format))
+	}
+}
+
+func parseActivitiesCSV(r io.Reader) ([]dto.ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Join(ErrValidation, fmt.Errorf("%w: missing header: %v", ErrInvalidImport, err))
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(catalogColumns, name) {
+			return nil, errors.Join(ErrValidation, fmt.Errorf("%w: unknown column %q", ErrInvalidImport, name))
+		}
+		columns[name] = i
+	}
+
+	var rows []dto.ImportRow
+	for fila := 2; ; fila++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Join(ErrValidation, fmt.Errorf("%w: line %d: %v", ErrInvalidImport, fila, err))
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		list := func(column string) []string {
+			var values []string
+			for _, v := range strings.Split(value(column), catalogListSeparator) {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, v)
+				}
+			}
+			return values
+		}
+
+		row := dto.ImportRow{Fila: fila}
+		number := func(column string) int {
+			v := value(column)
+			if v == "" {
+				return 0
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				row.Errores = append(row.Errores, fmt.Sprintf("%s must be a number: %q", column, v))
+			}
+			return n
+		}
+
+		row.Activity = dto.ActivityAdministration{
+			Activity: dto.Activity{
+				Nombre:      value("titulo"),
+				Descripcion: value("descripcion"),
+				Profesor:    value("instructor"),
+				DiaSemana:   value("dia"),
+				HoraInicio:  value("hora_inicio"),
+				HoraFin:     value("hora_fin"),
+				FotoUrl:     value("foto_url"),
+				SalaID:      value("id_sala"),
+				Recurso:     value("recurso"),
+			},
+			ClaveExterna: value("clave_externa"),
+		}
+		row.Activity.CapacidadMax = number("cupo")
+		if dias, excepciones := list("dias"), list("excepciones"); len(dias) > 0 || len(excepciones) > 0 || value("fecha_inicio") != "" || value("fecha_fin") != "" {
+			row.Activity.Recurrencia = &dto.Recurrence{
+				Dias:        dias,
+				FechaInicio: value("fecha_inicio"),
+				FechaFin:    value("fecha_fin"),
+				Excepciones: excepciones,
+			}
+		}
+		if policy := (dto.Policy{
+			AperturaDias:     number("apertura_dias"),
+			CierreMinutos:    number("cierre_minutos"),
+			CancelacionHoras: number("cancelacion_horas"),
+		}); policy != (dto.Policy{}) {
+			row.Activity.Politica = &policy
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseActivitiesJSON(r io.Reader) ([]dto.ImportRow, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, errors.Join(ErrValidation, fmt.Errorf("%w: body must be a JSON array of activities: %v", ErrInvalidImport, err))
+	}
+
+	rows := make([]dto.ImportRow, len(items))
+	for i, item := range items {
+		rows[i].Fila = i + 1
+		decoder := json.NewDecoder(bytes.NewReader(item))
+		decoder.DisallowUnknownFields()
+		var activity dto.ActivityAdministration
+		if err := decoder.Decode(&activity); err != nil {
+			rows[i].Errores = append(rows[i].Errores, err.Error())
+			continue
+		}
+		// los datos de administración no se importan
+		rows[i].Activity = dto.ActivityAdministration{Activity: activity.Activity, ClaveExterna: strings.TrimSpace(activity.ClaveExterna)}
+		rows[i].Activity.ID = ""
+	}
+	return rows, nil
+}
+
+// WriteActivities escribe el catálogo en el mismo formato que lee ParseActivities
+func WriteActivities(format string, w io.Writer, activities []dto.ActivityAdministration) error {
+	switch format {
+	case dto.CatalogFormatJSON:
+		items := make([]catalogItem, len(activities))
+		for i, activity := range activities {
+			items[i] = catalogItem{Activity: activity.Activity, ClaveExterna: activity.ClaveExterna}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(items)
+	case dto.CatalogFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(catalogColumns); err != nil {
+			return err
+		}
+		for _, a := range activities {
+			rec := dto.Recurrence{}
+			if a.Recurrencia != nil {
+				rec = *a.Recurrencia
+			}
+			policy := dto.Policy{}
+			if a.Politica != nil {
+				policy = *a.Politica
+			}
+			record := []string{
+				a.ClaveExterna, a.Nombre, a.Descripcion, a.Profesor, a.DiaSemana, a.HoraInicio, a.HoraFin,
+				strconv.Itoa(a.CapacidadMax), a.FotoUrl, a.SalaID, a.Recurso,
+				strings.Join(rec.Dias, catalogListSeparator), rec.FechaInicio, rec.FechaFin,
+				strings.Join(rec.Excepciones, catalogListSeparator),
+				optionalInt(policy.AperturaDias), optionalInt(policy.CierreMinutos), optionalInt(policy.CancelacionHoras),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return errors.Join(ErrValidation, fmt.Errorf("%w: %q", ErrInvalidFormat, format))
+	}
+}
+
+// catalogItem es una actividad del catálogo exportado en JSON: sin los datos de administración
+// ni los campos calculados
+type catalogItem struct {
+	dto.Activity
+	ClaveExterna       string `json:"clave_externa"`
+	ID                 string `json:"id_actividad,omitempty"`
+	LugaresDisponibles int    `json:"lugares_disponibles,omitempty"`
+}
+
+// ExportActivities devuelve el catálogo de actividades activas ordenado por día y horario. Las
+// actividades sin clave externa se exportan con su ID como clave, que la importación también reconoce.
+func (s *ActivitiesServiceImpl) ExportActivities(ctx context.Context) ([]dto.ActivityAdministration, error) {
+	activities, err := s.repository.ListAllForAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := []dto.ActivityAdministration{}
+	for _, activity := range activities {
+		if !activity.Activa {
+			continue
+		}
+		if activity.ClaveExterna == "" {
+			activity.ClaveExterna = activity.ID
+		}
+		catalog = append(catalog, activity)
+	}
+	sort.SliceStable(catalog, func(i, j int) bool {
+		di, dj := slices.Index(dto.DiasSemana, catalog[i].DiaSemana), slices.Index(dto.DiasSemana, catalog[j].DiaSemana)
+		if di != dj {
+			return di < dj
+		}
+		return catalog[i].HoraInicio < catalog[j].HoraInicio
+	})
+	return catalog, nil
+}
+
+// importPlan es lo que la importación hará con una fila válida
+type importPlan struct {
+	result   *dto.ImportRowResult
+	activity dto.ActivityAdministration
+	existing dto.ActivityAdministration
+}
+
+// overlappingRow busca una fila anterior del mismo archivo que ocupe la sala en un horario que se
+// superpone con activity. checkRoom solo compara contra las actividades ya guardadas.
+func overlappingRow(plans []importPlan, activity dto.Activity) *importPlan {
+	if activity.SalaID == "" {
+		return nil
+	}
+	for i := range plans {
+		other := plans[i].activity.Activity
+		if other.SalaID == activity.SalaID && schedulesOverlap(activity, other) {
+			return &plans[i]
+		}
+	}
+	return nil
+}
+
+// ImportActivities crea o actualiza (upsert por clave_externa) las actividades de las filas.
+// Primero se validan todas las filas con las mismas reglas que el alta, y las salas también entre
+// las filas del archivo; si alguna es inválida no se aplica ninguna. Con dryRun solo se informa qué se haría. Cada alta o modificación pasa por
+// Create / Update, que publican el evento y registran el historial; si una falla se informa en
+// su fila y se sigue con las demás.
+func (s *ActivitiesServiceImpl) ImportActivities(ctx context.Context, rows []dto.ImportRow, dryRun bool) (dto.ImportReport, error) {
+	report := dto.ImportReport{DryRun: dryRun, Total: len(rows), Filas: make([]dto.ImportRowResult, len(rows))}
+
+	seen := map[string]int{}
+	plans := []importPlan{}
+	for i, row := range rows {
+		result := &report.Filas[i]
+		result.Fila = row.Fila
+		result.ClaveExterna = row.Activity.ClaveExterna
+		result.Errores = append(result.Errores, row.Errores...)
+
+		key := row.Activity.ClaveExterna
+		if key == "" {
+			result.Errores = append(result.Errores, ErrExternalKeyRequired.Error())
+		} else if first, ok := seen[key]; ok {
+			result.Errores = append(result.Errores, fmt.Sprintf("%s (row %d)", ErrDuplicateExternalKey, first))
+		} else {
+			seen[key] = row.Fila
+		}
+		if len(result.Errores) > 0 {
+			continue
+		}
+
+		activity := row.Activity
+		normalizeRecurrence(&activity)
+		if err := s.validateActivity(activity); err != nil {
+			result.Errores = append(result.Errores, err.Error())
+			continue
+		}
+
+		existing, found, err := s.findByImportKey(ctx, key)
+		if err != nil {
+			return dto.ImportReport{}, err
+		}
+		if err := s.checkRoom(ctx, activity.Activity, existing.ID); err != nil {
+			if !errors.Is(err, ErrValidation) && !errors.Is(err, ErrRoomConflict) {
+				return dto.ImportReport{}, err
+			}
+			result.Errores = append(result.Errores, importError(err))
+			continue
+		}
+		if other := overlappingRow(plans, activity.Activity); other != nil {
+			result.Errores = append(result.Errores, fmt.Sprintf("%s: row %d (%s) %s-%s",
+				ErrRoomConflict, other.result.Fila, other.result.ClaveExterna, other.activity.HoraInicio, other.activity.HoraFin))
+			continue
+		}
+
+		if !found {
+			result.Accion = dto.ImportActionCreate
+		} else {
+			result.ActivityID = existing.ID
+			merged := existing
+			merged.Activity = activity.Activity
+			merged.ID = existing.ID
+			if key != existing.ID {
+				merged.ClaveExterna = key
+			}
+			activity = merged
+
+			changes := diffActivities(existing, merged)
+			if len(changes) == 0 {
+				result.Accion = dto.ImportActionUnchanged
+			} else {
+				result.Accion = dto.ImportActionUpdate
+				for field := range changes {
+					result.Cambios = append(result.Cambios, field)
+				}
+				sort.Strings(result.Cambios)
+			}
+			if activity.CapacidadMax < len(existing.UsersInscribed) {
+				result.Errores = append(result.Errores, fmt.Sprintf("%s (%d)", ErrCapacityLessThanInscribed, len(existing.UsersInscribed)))
+				result.Accion = ""
+				continue
+			}
+		}
+		plans = append(plans, importPlan{result: result, activity: activity, existing: existing})
+	}
+
+	for _, result := range report.Filas {
+		if len(result.Errores) > 0 {
+			report.ConErrores++
+		}
+	}
+	if report.ConErrores > 0 || dryRun {
+		countImportActions(&report)
+		return report, nil
+	}
+
+	report.Aplicado = true
+	for _, plan := range plans {
+		switch plan.result.Accion {
+		case dto.ImportActionCreate:
+			created, err := s.Create(ctx, plan.activity)
+			if err != nil {
+				plan.result.Errores = append(plan.result.Errores, importError(err))
+				continue
+			}
+			plan.result.ActivityID = created.ID
+		case dto.ImportActionUpdate:
+			update := plan.activity
+			update.Version = plan.existing.Version
+			update.UsersInscribed = nil // las inscripciones no se importan
+			if _, err := s.Update(ctx, plan.existing.ID, update); err != nil {
+				plan.result.Errores = append(plan.result.Errores, importError(err))
+			}
+		}
+	}
+
+	report.ConErrores = 0
+	for _, result := range report.Filas {
+		if len(result.Errores) > 0 {
+			report.ConErrores++
+		}
+	}
+	countImportActions(&report)
+	log.Infof("Catalog import applied: %d created, %d updated, %d unchanged, %d failed",
+		report.Creadas, report.Actualizadas, report.SinCambios, report.ConErrores)
+	return report, nil
+}
+
+// findByImportKey busca la actividad por clave externa o, si la clave es un ID (catálogo
+// exportado de actividades sin clave), por ID
+func (s *ActivitiesServiceImpl) findByImportKey(ctx context.Context, key string) (dto.ActivityAdministration, bool, error) {
+	activity, err := s.repository.GetByExternalKey(ctx, key)
+	if err == nil {
+		return activity, true, nil
+	}
+	if !errors.Is(err, ErrActivityNotFound) {
+		return dto.ActivityAdministration{}, false, err
+	}
+	if !primitive.IsValidObjectID(key) {
+		return dto.ActivityAdministration{}, false, nil
+	}
+
+	activity, err = s.repository.GetByID(ctx, key)
+	if errors.Is(err, ErrActivityNotFound) {
+		return dto.ActivityAdministration{}, false, nil
+	}
+	if err != nil {
+		return dto.ActivityAdministration{}, false, err
+	}
+	if activity.ClaveExterna != "" && activity.ClaveExterna != key {
+		// la actividad ya tiene otra clave: el ID no la identifica en la importación
+		return dto.ActivityAdministration{}, false, nil
+	}
+	return activity, true, nil
+}
+
+func countImportActions(report *dto.ImportReport) {
+	report.Creadas, report.Actualizadas, report.SinCambios = 0, 0, 0
+	for _, result := range report.Filas {
+		if len(result.Errores) > 0 {
+			continue
+		}
+		switch result.Accion {
+		case dto.ImportActionCreate:
+			report.Creadas++
+		case dto.ImportActionUpdate:
+			report.Actualizadas++
+		case dto.ImportActionUnchanged:
+			report.SinCambios++
+		}
+	}
+}
+
+// importError quita el prefijo genérico de validación del mensaje de error de una fila
+func importError(err error) string {
+	message := err.Error()
+	return strings.TrimPrefix(message, ErrValidation.Error()+"\n")
+}
+
+// optionalInt deja vacía la celda de un valor de la política que no está configurado
+func optionalInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
@@ -15,8 +15,61 @@ var (
 	ErrDayRequired                   = errors.ErrDayRequired
 	ErrInvalidDay                    = errors.ErrInvalidDay
 	ErrActivityDoesNotExist          = errors.ErrActivityDoesNotExist
+	ErrActivityInactive              = errors.ErrActivityInactive
+	ErrActivityNotFound              = errors.ErrActivityNotFound
+	ErrActivityFull                  = errors.ErrActivityFull
+	ErrUserAlreadyInscribed          = errors.ErrUserAlreadyInscribed
+	ErrUserNotInscribed              = errors.ErrUserNotInscribed
+	ErrVersionConflict               = errors.ErrVersionConflict
 	ErrCapacityLessThanInscribed     = errors.ErrCapacityLessThanInscribed
 	ErrInscritosExceedCapacity       = errors.ErrInscritosExceedCapacity
+	ErrInvalidSort                   = errors.ErrInvalidSort
+	ErrInvalidTimeFormat             = errors.ErrInvalidTimeFormat
+	ErrInvalidPagination             = errors.ErrInvalidPagination
+	ErrInvalidPatch                  = errors.ErrInvalidPatch
+	ErrReadOnlyField                 = errors.ErrReadOnlyField
+	ErrRosterNotEditable             = errors.ErrRosterNotEditable
+	ErrNoFieldsToUpdate              = errors.ErrNoFieldsToUpdate
+	ErrInvalidDate                   = errors.ErrInvalidDate
+	ErrInvalidDateRange              = errors.ErrInvalidDateRange
+	ErrInvalidRecurrence             = errors.ErrInvalidRecurrence
+	ErrOccurrenceNotFound            = errors.ErrOccurrenceNotFound
+	ErrOccurrencePast                = errors.ErrOccurrencePast
+	ErrOccurrenceCancelled           = errors.ErrOccurrenceCancelled
+	ErrReasonRequired                = errors.ErrReasonRequired
+	ErrRoomNameRequired              = errors.ErrRoomNameRequired
+	ErrRoomCapacityRequired          = errors.ErrRoomCapacityRequired
+	ErrInvalidResource               = errors.ErrInvalidResource
+	ErrUnknownRoom                   = errors.ErrUnknownRoom
+	ErrResourceNotInRoom             = errors.ErrResourceNotInRoom
+	ErrCapacityExceedsRoom           = errors.ErrCapacityExceedsRoom
+	ErrRoomCapacityTooSmall          = errors.ErrRoomCapacityTooSmall
+	ErrRoomConflict                  = errors.ErrRoomConflict
+	ErrRoomInUse                     = errors.ErrRoomInUse
+	ErrOccurrenceNotStarted          = errors.ErrOccurrenceNotStarted
+	ErrInvalidAttendance             = errors.ErrInvalidAttendance
+	ErrInvalidWeek                   = errors.ErrInvalidWeek
+	ErrInvalidImport                 = errors.ErrInvalidImport
+	ErrInvalidFormat                 = errors.ErrInvalidFormat
+	ErrExternalKeyRequired           = errors.ErrExternalKeyRequired
+	ErrDuplicateExternalKey          = errors.ErrDuplicateExternalKey
+	ErrInvalidPolicy                 = errors.ErrInvalidPolicy
+	ErrEnrollmentNotOpen             = errors.ErrEnrollmentNotOpen
+	ErrEnrollmentClosed              = errors.ErrEnrollmentClosed
+	ErrInvalidRoster                 = errors.ErrInvalidRoster
+	ErrInvalidRosterUsers            = errors.ErrInvalidRosterUsers
+	ErrSameActivity                  = errors.ErrSameActivity
+	ErrInvalidUserID                 = errors.ErrInvalidUserID
+	ErrUserNotFound                  = errors.ErrUserNotFound
+	ErrUsersUnavailable              = errors.ErrUsersUnavailable
+	ErrMembershipRequired            = errors.ErrMembershipRequired
+	ErrMembershipNotValid            = errors.ErrMembershipNotValid
+	ErrWeeklyQuotaExceeded           = errors.ErrWeeklyQuotaExceeded
+	ErrMembershipChecksDisabled      = errors.ErrMembershipChecksDisabled
+	ErrCalendarTokenNotFound         = errors.ErrCalendarTokenNotFound
+	ErrRoomNotFound                  = errors.ErrRoomNotFound
+	ErrRoomAlreadyExists             = errors.ErrRoomAlreadyExists
+	ErrInvalidIDFormat               = errors.ErrInvalidIDFormat
 	ErrPublishEventFailed            = errors.ErrPublishEventFailed
 	ErrRollbackFailed                = errors.ErrRollbackFailed
 	ErrCreatingActivityInRepository  = errors.ErrCreatingActivityInRepository
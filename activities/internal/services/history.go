@@ -0,0 +1,130 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Acciones registradas en el historial de actividades
+const (
+	HistoryActionCreate      = "create"
+	HistoryActionUpdate      = "update"
+	HistoryActionDeactivate  = "deactivate"
+	HistoryActionReactivate  = "reactivate"
+	HistoryActionPurge       = "purge"
+	HistoryActionEnroll      = "enroll"
+	HistoryActionUnenroll    = "unenroll"
+	HistoryActionCancel      = "cancel"
+	HistoryActionReschedule  = "reschedule"
+	HistoryActionAttendance  = "attendance"
+	HistoryActionUserDeleted = "user_deleted"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
+type HistoryRepository interface {
+	Append(ctx context.Context, entry dto.HistoryEntry) error
+	List(ctx context.Context, filters dto.HistoryFilters) (dto.HistoryPage, error)
+	CountByWeek(ctx context.Context, actions []string, from, to time.Time) ([]dto.HistoryWeekCount, error)
+}
+
+type actorKey struct{}
+
+// WithActor guarda en el contexto quién realiza la operación, para registrarlo en el historial
+func WithActor(ctx context.Context, actor dto.Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func actorFromContext(ctx context.Context) dto.Actor {
+	actor, _ := ctx.Value(actorKey{}).(dto.Actor)
+	return actor
+}
+
+// historyIgnoredFields son campos derivados o de auditoría que no se comparan en los diffs
+var historyIgnoredFields = map[string]bool{
+	"id_actividad":        true,
+	"lugares_disponibles": true,
+	"fecha_creacion":      true,
+	"fecha_actualizacion": true,
+	"version":             true,
+}
+
+// diffActivities devuelve los campos (con su nombre JSON) que cambiaron entre before y after
+func diffActivities(before, after dto.ActivityAdministration) map[string]dto.FieldChange {
+	beforeFields := activityFields(before)
+	afterFields := activityFields(after)
+
+	changes := map[string]dto.FieldChange{}
+	for field, afterValue := range afterFields {
+		beforeValue := beforeFields[field]
+		if !reflect.DeepEqual(beforeValue, afterValue) {
+			changes[field] = dto.FieldChange{Before: beforeValue, After: afterValue}
+		}
+	}
+	for field, beforeValue := range beforeFields {
+		if _, ok := afterFields[field]; !ok {
+			changes[field] = dto.FieldChange{Before: beforeValue, After: nil}
+		}
+	}
+	return changes
+}
+
+func activityFields(a dto.ActivityAdministration) map[string]any {
+	fields := map[string]any{}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return fields
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fields
+	}
+	for field := range historyIgnoredFields {
+		delete(fields, field)
+	}
+	return fields
+}
+
+// recordHistory agrega un registro al historial. Un fallo al registrar no revierte la operación
+// (que ya se aplicó y publicó), solo se loguea.
+func (s *ActivitiesServiceImpl) recordHistory(ctx context.Context, entry dto.HistoryEntry) {
+	if s.history == nil {
+		return
+	}
+
+	entry.Actor = actorFromContext(ctx)
+	entry.Timestamp = time.Now().UTC()
+
+	if err := s.history.Append(ctx, entry); err != nil {
+		log.Errorf("Failed to record %s history for activity %s: %v", entry.Action, entry.ActivityID, err)
+	}
+}
+
+// ListHistory obtiene el historial de cambios filtrado por actividad y/o usuario, más reciente primero
+func (s *ActivitiesServiceImpl) ListHistory(ctx context.Context, filters dto.HistoryFilters) (dto.HistoryPage, error) {
+	if filters.Page < 0 || filters.Limit < 0 {
+		return dto.HistoryPage{}, errors.Join(ErrValidation, ErrInvalidPagination)
+	}
+	if filters.Page == 0 {
+		filters.Page = 1
+	}
+	if filters.Limit == 0 {
+		filters.Limit = defaultHistoryLimit
+	}
+	if filters.Limit > maxHistoryLimit {
+		filters.Limit = maxHistoryLimit
+	}
+
+	if s.history == nil {
+		return dto.HistoryPage{Entries: []dto.HistoryEntry{}, Page: filters.Page, Limit: filters.Limit}, nil
+	}
+	return s.history.List(ctx, filters)
+}
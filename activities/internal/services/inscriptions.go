@@ -0,0 +1,228 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type CalendarTokensRepository interface {
+	GetByUser(ctx context.Context, userID string) (string, error)
+	GetUserID(ctx context.Context, token string) (string, error)
+	Set(ctx context.Context, userID, token string) error
+}
+
+// RecordAttendance guarda la asistencia de la clase de una fecha: los usuarios presentes. Los
+// demás inscriptos (a la serie o a la fecha) quedan como ausentes. Volver a tomarla reemplaza la
+// anterior.
+func (s *ActivitiesServiceImpl) RecordAttendance(ctx context.Context, activityID, fecha string, presentes []int) (dto.Attendance, error) {
+	activity, record, start, err := s.findOccurrence(ctx, activityID, fecha)
+	if err != nil {
+		return dto.Attendance{}, err
+	}
+	if record.Estado == dto.OccurrenceCancelled {
+		return dto.Attendance{}, ErrOccurrenceCancelled
+	}
+	if start.After(time.Now()) {
+		return dto.Attendance{}, ErrOccurrenceNotStarted
+	}
+
+	enrolled := enrolledInOccurrence(activity, record)
+	asistentes := []int{}
+	for _, uid := range presentes {
+		if !slices.Contains(enrolled, uid) {
+			return dto.Attendance{}, errors.Join(ErrValidation, fmt.Errorf("%w: %d", ErrInvalidAttendance, uid))
+		}
+		if !slices.Contains(asistentes, uid) {
+			asistentes = append(asistentes, uid)
+		}
+	}
+	ausentes := []int{}
+	for _, uid := range enrolled {
+		if !slices.Contains(asistentes, uid) {
+			ausentes = append(ausentes, uid)
+		}
+	}
+
+	if err := s.occurrences.SetAsistencia(ctx, activityID, fecha, asistentes, ausentes); err != nil {
+		return dto.Attendance{}, err
+	}
+
+	log.Infof("Attendance for activity %s on %s: %d present, %d absent", activityID, fecha, len(asistentes), len(ausentes))
+	s.recordHistory(ctx, dto.HistoryEntry{
+		ActivityID: activityID,
+		Action:     HistoryActionAttendance,
+		Changes: map[string]dto.FieldChange{
+			"fecha":      {After: fecha},
+			"asistentes": {Before: record.Asistentes, After: asistentes},
+		},
+	})
+	return dto.Attendance{ActivityID: activityID, Fecha: fecha, Asistentes: asistentes, Ausentes: ausentes}, nil
+}
+
+// GetEnrollmentHistory arma el historial de inscripciones del usuario a partir del historial de
+// actividades (incluye las ya terminadas) y su asistencia a las clases
+func (s *ActivitiesServiceImpl) GetEnrollmentHistory(ctx context.Context, userID string) (dto.EnrollmentHistory, error) {
+	if _, err := strconv.Atoi(userID); err != nil {
+		return dto.EnrollmentHistory{}, ErrInvalidUserID
+	}
+
+	entries, err := s.userEnrollmentEntries(ctx, userID)
+	if err != nil {
+		return dto.EnrollmentHistory{}, err
+	}
+
+	type periodKey struct{ activityID, fecha string }
+	open := map[periodKey]int{}
+	periods := []dto.EnrollmentPeriod{}
+	for _, entry := range entries {
+		switch entry.Action {
+		case HistoryActionEnroll:
+			key := periodKey{entry.ActivityID, changedFecha(entry, false)}
+			if _, ok := open[key]; ok {
+				continue
+			}
+			periods = append(periods, dto.EnrollmentPeriod{ActivityID: entry.ActivityID, Fecha: key.fecha, Desde: entry.Timestamp})
+			open[key] = len(periods) - 1
+		case HistoryActionUnenroll:
+			key := periodKey{entry.ActivityID, changedFecha(entry, true)}
+			if i, ok := open[key]; ok {
+				hasta := entry.Timestamp
+				periods[i].Hasta = &hasta
+				delete(open, key)
+			}
+		}
+	}
+
+	records, err := s.occurrences.ListByUser(ctx, userID)
+	if err != nil {
+		return dto.EnrollmentHistory{}, err
+	}
+	uid, _ := strconv.Atoi(userID)
+	history := dto.EnrollmentHistory{UserID: userID, Asistencias: []dto.AttendanceEntry{}}
+	for _, record := range records {
+		entry := dto.AttendanceEntry{ActivityID: record.ActivityID, Fecha: record.Fecha}
+		switch {
+		case slices.Contains(record.Asistentes, uid):
+			entry.Asistencia = dto.AttendancePresent
+			history.Presentes++
+		case slices.Contains(record.Ausentes, uid):
+			entry.Asistencia = dto.AttendanceAbsent
+			history.Ausentes++
+		default:
+			continue
+		}
+		history.Asistencias = append(history.Asistencias, entry)
+	}
+
+	// títulos: las actividades eliminadas definitivamente quedan sin título
+	titles := map[string]string{}
+	title := func(id string) string {
+		if t, ok := titles[id]; ok {
+			return t
+		}
+		activity, err := s.repository.GetByID(ctx, id)
+		if err != nil {
+			log.Debugf("Activity %s not found for enrollment history: %v", id, err)
+		}
+		titles[id] = activity.Nombre
+		return titles[id]
+	}
+	for i := range periods {
+		periods[i].Titulo = title(periods[i].ActivityID)
+	}
+	for i := range history.Asistencias {
+		history.Asistencias[i].Titulo = title(history.Asistencias[i].ActivityID)
+	}
+
+	slices.Reverse(periods)
+	sort.SliceStable(history.Asistencias, func(i, j int) bool {
+		return history.Asistencias[i].Fecha > history.Asistencias[j].Fecha
+	})
+	history.Inscripciones = periods
+	return history, nil
+}
+
+// userEnrollmentEntries devuelve las inscripciones y desinscripciones del usuario en orden cronológico
+func (s *ActivitiesServiceImpl) userEnrollmentEntries(ctx context.Context, userID string) ([]dto.HistoryEntry, error) {
+	var entries []dto.HistoryEntry
+	if s.history == nil {
+		return entries, nil
+	}
+
+	filters := dto.HistoryFilters{UserID: userID, Page: 1, Limit: maxHistoryLimit}
+	for {
+		page, err := s.history.List(ctx, filters)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range page.Entries {
+			// el filtro también trae lo que el usuario hizo como actor
+			if entry.UserID == userID && (entry.Action == HistoryActionEnroll || entry.Action == HistoryActionUnenroll) {
+				entries = append(entries, entry)
+			}
+		}
+		if !page.HasNext {
+			break
+		}
+		filters.Page++
+	}
+
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+// changedFecha devuelve la fecha de la clase de una inscripción a una sola fecha ("" si fue a la serie)
+func changedFecha(entry dto.HistoryEntry, before bool) string {
+	change, ok := entry.Changes["fecha"]
+	if !ok {
+		return ""
+	}
+	value := change.After
+	if before {
+		value = change.Before
+	}
+	fecha, _ := value.(string)
+	return fecha
+}
+
+// CalendarToken devuelve el token del calendario del usuario, generándolo la primera vez. Con
+// rotate genera uno nuevo e invalida el anterior.
+func (s *ActivitiesServiceImpl) CalendarToken(ctx context.Context, userID string, rotate bool) (string, error) {
+	if _, err := strconv.Atoi(userID); err != nil {
+		return "", ErrInvalidUserID
+	}
+	if s.calendarTokens == nil {
+		return "", errors.New("calendar feed is not configured")
+	}
+
+	if !rotate {
+		token, err := s.calendarTokens.GetByUser(ctx, userID)
+		if err == nil {
+			return token, nil
+		}
+		if !errors.Is(err, ErrCalendarTokenNotFound) {
+			return "", err
+		}
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	if err := s.calendarTokens.Set(ctx, userID, token); err != nil {
+		return "", err
+	}
+	log.Infof("Calendar token generated for user %s", userID)
+	return token, nil
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// UsersDirectory consulta los datos de los usuarios en users-api
+type UsersDirectory interface {
+	GetUser(ctx context.Context, userID int) (dto.User, error)
+	GetUsers(ctx context.Context, userIDs []int) ([]dto.User, error) // omite los que no existen
+	Forget(userID int)                                               // descarta lo guardado del usuario
+	Entitlement(ctx context.Context, userID, fecha string) (dto.Entitlement, error)
+}
+
+// checkEntitlement verifica que el usuario tenga un plan vigente el día de la clase y que sumarle
+// classes clases a esa semana no supere la cuota semanal del plan. Las clases de skipActivity no
+// se cuentan (son las que se están reservando). Si users-api no informa planes no se verifica nada.
+func (s *ActivitiesServiceImpl) checkEntitlement(ctx context.Context, userID string, fecha time.Time, classes int, skipActivity string) error {
+	if s.users == nil {
+		return nil
+	}
+
+	entitlement, err := s.users.Entitlement(ctx, userID, fecha.Format(dto.FechaLayout))
+	if errors.Is(err, ErrMembershipChecksDisabled) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !entitlement.Vigente {
+		if entitlement.Motivo == dto.EntitlementNoPlan {
+			return ErrMembershipRequired
+		}
+		return fmt.Errorf("%w: plan valid from %s to %s", ErrMembershipNotValid, entitlement.Desde, entitlement.Hasta)
+	}
+	if entitlement.ClasesSemanales <= 0 {
+		return nil
+	}
+
+	monday := weekStart(fecha)
+	booked, err := s.weeklyClasses(ctx, userID, monday, skipActivity)
+	if err != nil {
+		return err
+	}
+	if booked+classes > entitlement.ClasesSemanales {
+		return fmt.Errorf("%w: %d of %d classes already booked for the week of %s",
+			ErrWeeklyQuotaExceeded, booked, entitlement.ClasesSemanales, monday.Format(dto.FechaLayout))
+	}
+	return nil
+}
+
+// checkSeriesEntitlement aplica checkEntitlement a la inscripción a la serie: el plan tiene que
+// estar vigente en la próxima clase y todas las clases de esa semana cuentan para la cuota
+func (s *ActivitiesServiceImpl) checkSeriesEntitlement(ctx context.Context, activity dto.ActivityAdministration, userID string) error {
+	if s.users == nil {
+		return nil
+	}
+
+	fecha := today()
+	next, _, ok, err := s.nextOccurrence(ctx, activity)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if date, err := parseFecha(occurrenceDate(next)); err == nil {
+			fecha = date
+		}
+	}
+
+	monday := weekStart(fecha)
+	occurrences, err := s.occurrencesBetween(ctx, activity, monday, monday.AddDate(0, 0, 6))
+	if err != nil {
+		return err
+	}
+	classes := 0
+	for _, occurrence := range occurrences {
+		if occurrence.Estado != dto.OccurrenceCancelled {
+			classes++
+		}
+	}
+	return s.checkEntitlement(ctx, userID, fecha, max(classes, 1), activity.ID)
+}
+
+// weeklyClasses cuenta las clases no canceladas de la semana que empieza en monday a las que el
+// usuario va: las de las series en las que está inscripto y las fechas sueltas
+func (s *ActivitiesServiceImpl) weeklyClasses(ctx context.Context, userID string, monday time.Time, skipActivity string) (int, error) {
+	sunday := monday.AddDate(0, 0, 6)
+
+	ids, err := s.repository.GetInscripcionesByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, id := range ids {
+		if id == skipActivity {
+			continue
+		}
+		activity, err := s.repository.GetByID(ctx, id)
+		if errors.Is(err, ErrActivityNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if !activity.Activa {
+			continue
+		}
+		occurrences, err := s.occurrencesBetween(ctx, activity, monday, sunday)
+		if err != nil {
+			return 0, err
+		}
+		for _, occurrence := range occurrences {
+			if occurrence.Estado != dto.OccurrenceCancelled {
+				count++
+			}
+		}
+	}
+
+	uid, _ := strconv.Atoi(userID)
+	records, err := s.occurrences.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	from, to := monday.Format(dto.FechaLayout), sunday.Format(dto.FechaLayout)
+	for _, record := range records {
+		if record.ActivityID == skipActivity || slices.Contains(ids, record.ActivityID) ||
+			!slices.Contains(record.UsersInscribed, uid) || record.Estado == dto.OccurrenceCancelled {
+			continue
+		}
+		fecha := record.Fecha
+		if record.NuevaFecha != "" {
+			fecha = record.NuevaFecha
+		}
+		if fecha >= from && fecha <= to {
+			count++
+		}
+	}
+
+	log.Debugf("User %s has %d classes in the week of %s", userID, count, from)
+	return count, nil
+}
@@ -0,0 +1,674 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultOccurrencesRange = 28 * 24 * time.Hour
+	maxOccurrencesRange     = 90 * 24 * time.Hour
+	maxUpcomingRange        = 7 * 24 * time.Hour
+)
+
+type OccurrencesRepository interface {
+	List(ctx context.Context, activityID, from, to string) ([]dto.OccurrenceRecord, error)
+	MaxEnrolled(ctx context.Context, activityID, from string) (int, error)
+	Inscribir(ctx context.Context, activityID, fecha, userID string, maxEnrolled int) error
+	Desinscribir(ctx context.Context, activityID, fecha, userID string) error
+	SetEstado(ctx context.Context, record dto.OccurrenceRecord) (dto.OccurrenceRecord, error)
+	SetAsistencia(ctx context.Context, activityID, fecha string, asistentes, ausentes []int) error
+	ListByUser(ctx context.Context, userID string) ([]dto.OccurrenceRecord, error)
+	AttendanceByActivity(ctx context.Context, from, to string) ([]dto.ActivityAttendance, error)
+	AddLateCancellation(ctx context.Context, activityID, fecha, userID string) error
+	DeleteByActivity(ctx context.Context, activityID string) error
+}
+
+var weekdays = map[string]time.Weekday{
+	"Lunes":     time.Monday,
+	"Martes":    time.Tuesday,
+	"Miércoles": time.Wednesday,
+	"Jueves":    time.Thursday,
+	"Viernes":   time.Friday,
+	"Sábado":    time.Saturday,
+	"Domingo":   time.Sunday,
+}
+
+// parseFecha interpreta una fecha YYYY-MM-DD en la zona horaria local
+func parseFecha(fecha string) (time.Time, error) {
+	t, err := time.ParseInLocation(dto.FechaLayout, fecha, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidDate, fecha)
+	}
+	return t, nil
+}
+
+// normalizeRecurrence completa la regla con el día de la actividad (y viceversa) para que
+// DiaSemana siga teniendo sentido para los clientes que no conocen las recurrencias
+func normalizeRecurrence(a *dto.ActivityAdministration) {
+	if a.Recurrencia == nil {
+		return
+	}
+	if len(a.Recurrencia.Dias) == 0 && a.DiaSemana != "" {
+		a.Recurrencia.Dias = []string{a.DiaSemana}
+	}
+	if a.DiaSemana == "" && len(a.Recurrencia.Dias) > 0 {
+		a.DiaSemana = a.Recurrencia.Dias[0]
+	}
+}
+
+func validateRecurrence(rec *dto.Recurrence) error {
+	if rec == nil {
+		return nil
+	}
+	if len(rec.Dias) == 0 {
+		return fmt.Errorf("%w: dias is required", ErrInvalidRecurrence)
+	}
+	for _, dia := range rec.Dias {
+		if _, ok := weekdays[dia]; !ok {
+			return fmt.Errorf("%w: %s", ErrInvalidDay, dia)
+		}
+	}
+
+	var inicio, fin time.Time
+	var err error
+	if rec.FechaInicio != "" {
+		if inicio, err = parseFecha(rec.FechaInicio); err != nil {
+			return err
+		}
+	}
+	if rec.FechaFin != "" {
+		if fin, err = parseFecha(rec.FechaFin); err != nil {
+			return err
+		}
+	}
+	if !inicio.IsZero() && !fin.IsZero() && fin.Before(inicio) {
+		return fmt.Errorf("%w: fecha_fin is before fecha_inicio", ErrInvalidRecurrence)
+	}
+	for _, excepcion := range rec.Excepciones {
+		if _, err := parseFecha(excepcion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// effectiveRecurrence devuelve la regla de la actividad; las actividades sin regla se repiten
+// todas las semanas el día DiaSemana
+func effectiveRecurrence(a dto.Activity) dto.Recurrence {
+	if a.Recurrencia != nil {
+		return *a.Recurrencia
+	}
+	return dto.Recurrence{Dias: []string{a.DiaSemana}}
+}
+
+// occurrenceDates genera las fechas de clase de la regla entre from y to (inclusive)
+func occurrenceDates(rec dto.Recurrence, from, to time.Time) []time.Time {
+	if rec.FechaInicio != "" {
+		if inicio, err := parseFecha(rec.FechaInicio); err == nil && inicio.After(from) {
+			from = inicio
+		}
+	}
+	if rec.FechaFin != "" {
+		if fin, err := parseFecha(rec.FechaFin); err == nil && fin.Before(to) {
+			to = fin
+		}
+	}
+
+	dias := map[time.Weekday]bool{}
+	for _, dia := range rec.Dias {
+		if weekday, ok := weekdays[dia]; ok {
+			dias[weekday] = true
+		}
+	}
+
+	var dates []time.Time
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if dias[day.Weekday()] && !slices.Contains(rec.Excepciones, day.Format(dto.FechaLayout)) {
+			dates = append(dates, day)
+		}
+	}
+	return dates
+}
+
+// isOccurrenceDate indica si la regla tiene clase en la fecha indicada
+func isOccurrenceDate(rec dto.Recurrence, fecha time.Time) bool {
+	return len(occurrenceDates(rec, fecha, fecha)) == 1
+}
+
+// occurrenceStart devuelve el momento de inicio de la clase de esa fecha
+func occurrenceStart(horaInicio string, fecha time.Time) time.Time {
+	start, err := time.ParseInLocation("15:04", horaInicio, time.Local)
+	if err != nil {
+		return fecha
+	}
+	return time.Date(fecha.Year(), fecha.Month(), fecha.Day(), start.Hour(), start.Minute(), 0, 0, time.Local)
+}
+
+// buildOccurrence arma la clase de una fecha combinando la actividad con lo guardado de esa fecha
+// (estado, reprogramación e inscriptos)
+func buildOccurrence(activity dto.ActivityAdministration, fecha string, record dto.OccurrenceRecord) dto.Occurrence {
+	occurrence := dto.Occurrence{
+		ActivityID:         activity.ID,
+		Titulo:             activity.Nombre,
+		Fecha:              fecha,
+		NuevaFecha:         record.NuevaFecha,
+		HoraInicio:         activity.HoraInicio,
+		HoraFin:            activity.HoraFin,
+		CapacidadMax:       activity.CapacidadMax,
+		LugaresDisponibles: activity.CapacidadMax - len(activity.UsersInscribed) - len(record.UsersInscribed),
+		Estado:             dto.OccurrenceScheduled,
+		Motivo:             record.Motivo,
+		UsersInscribed:     record.UsersInscribed,
+	}
+	if record.Estado != "" {
+		occurrence.Estado = record.Estado
+	}
+	if record.HoraInicio != "" {
+		occurrence.HoraInicio = record.HoraInicio
+	}
+	if record.HoraFin != "" {
+		occurrence.HoraFin = record.HoraFin
+	}
+	if date, err := parseFecha(occurrenceDate(occurrence)); err == nil {
+		occurrence.DiaSemana = dto.DiasSemana[(int(date.Weekday())+6)%7]
+	}
+	return occurrence
+}
+
+// occurrenceDate devuelve la fecha en la que se dicta la clase (la nueva si se reprogramó)
+func occurrenceDate(o dto.Occurrence) string {
+	if o.NuevaFecha != "" {
+		return o.NuevaFecha
+	}
+	return o.Fecha
+}
+
+// enrolledInOccurrence devuelve los usuarios que asisten a la clase: los inscriptos a la serie y
+// los inscriptos solo a esa fecha
+func enrolledInOccurrence(activity dto.ActivityAdministration, record dto.OccurrenceRecord) []int {
+	users := make([]int, 0, len(activity.UsersInscribed)+len(record.UsersInscribed))
+	users = append(users, activity.UsersInscribed...)
+	for _, uid := range record.UsersInscribed {
+		if !slices.Contains(users, uid) {
+			users = append(users, uid)
+		}
+	}
+	return users
+}
+
+func today() time.Time {
+	now := time.Now().In(time.Local)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// ListOccurrences devuelve las clases de la actividad entre desde y hasta (YYYY-MM-DD). Sin
+// fechas devuelve las próximas cuatro semanas; el rango máximo es de 90 días. Las clases
+// reprogramadas aparecen en el rango de su nueva fecha.
+func (s *ActivitiesServiceImpl) ListOccurrences(ctx context.Context, activityID, desde, hasta string) ([]dto.Occurrence, error) {
+	from := today()
+	if desde != "" {
+		var err error
+		if from, err = parseFecha(desde); err != nil {
+			return nil, errors.Join(ErrValidation, err)
+		}
+	}
+	to := from.Add(defaultOccurrencesRange)
+	if hasta != "" {
+		var err error
+		if to, err = parseFecha(hasta); err != nil {
+			return nil, errors.Join(ErrValidation, err)
+		}
+	}
+	if to.Before(from) || to.Sub(from) > maxOccurrencesRange {
+		return nil, errors.Join(ErrValidation, fmt.Errorf("%w: hasta must be after desde and at most 90 days later", ErrInvalidDateRange))
+	}
+
+	activity, err := s.repository.GetByID(ctx, activityID)
+	if err != nil {
+		return nil, errors.Join(ErrActivityDoesNotExist, err)
+	}
+	if !activity.Activa {
+		return nil, ErrActivityInactive
+	}
+	return s.occurrencesBetween(ctx, activity, from, to)
+}
+
+// occurrencesBetween arma las clases de la actividad que se dictan entre from y to (días, inclusive),
+// con las reprogramadas en su nueva fecha, ordenadas por fecha y horario
+func (s *ActivitiesServiceImpl) occurrencesBetween(ctx context.Context, activity dto.ActivityAdministration, from, to time.Time) ([]dto.Occurrence, error) {
+	fromFecha, toFecha := from.Format(dto.FechaLayout), to.Format(dto.FechaLayout)
+	records, err := s.occurrences.List(ctx, activity.ID, fromFecha, toFecha)
+	if err != nil {
+		return nil, err
+	}
+	stored := map[string]dto.OccurrenceRecord{}
+	for _, record := range records {
+		stored[record.Fecha] = record
+	}
+
+	occurrences := []dto.Occurrence{}
+	inRange := func(o dto.Occurrence) bool {
+		fecha := occurrenceDate(o)
+		return fecha >= fromFecha && fecha <= toFecha
+	}
+	rec := effectiveRecurrence(activity.Activity)
+	for _, date := range occurrenceDates(rec, from, to) {
+		fecha := date.Format(dto.FechaLayout)
+		if occurrence := buildOccurrence(activity, fecha, stored[fecha]); inRange(occurrence) {
+			occurrences = append(occurrences, occurrence)
+		}
+		delete(stored, fecha)
+	}
+	// clases de otras fechas reprogramadas dentro del rango
+	for fecha, record := range stored {
+		date, err := parseFecha(fecha)
+		if err != nil || !isOccurrenceDate(rec, date) {
+			continue
+		}
+		if occurrence := buildOccurrence(activity, fecha, record); inRange(occurrence) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	slices.SortFunc(occurrences, func(a, b dto.Occurrence) int {
+		if c := strings.Compare(occurrenceDate(a), occurrenceDate(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.HoraInicio, b.HoraInicio)
+	})
+	return occurrences, nil
+}
+
+// occurrenceFor valida que la actividad esté activa, tenga clase en la fecha y que la clase no
+// haya empezado. Devuelve la actividad, lo guardado de esa fecha y cuándo empieza la clase.
+func (s *ActivitiesServiceImpl) occurrenceFor(ctx context.Context, activityID, fecha string) (dto.ActivityAdministration, dto.OccurrenceRecord, time.Time, error) {
+	activity, record, start, err := s.findOccurrence(ctx, activityID, fecha)
+	if err != nil {
+		return dto.ActivityAdministration{}, dto.OccurrenceRecord{}, time.Time{}, err
+	}
+	if !activity.Activa {
+		return dto.ActivityAdministration{}, dto.OccurrenceRecord{}, time.Time{}, ErrActivityInactive
+	}
+	if !start.After(time.Now()) {
+		return dto.ActivityAdministration{}, dto.OccurrenceRecord{}, time.Time{}, ErrOccurrencePast
+	}
+	return activity, record, start, nil
+}
+
+// findOccurrence busca la clase de una fecha de la actividad (esté activa o no) y devuelve lo
+// guardado de esa fecha y cuándo empieza
+func (s *ActivitiesServiceImpl) findOccurrence(ctx context.Context, activityID, fecha string) (dto.ActivityAdministration, dto.OccurrenceRecord, time.Time, error) {
+	date, err := parseFecha(fecha)
+	if err != nil {
+		return dto.ActivityAdministration{}, dto.OccurrenceRecord{}, time.Time{}, errors.Join(ErrValidation, err)
+	}
+
+	activity, err := s.repository.GetByID(ctx, activityID)
+	if err != nil {
+		return dto.ActivityAdministration{}, dto.OccurrenceRecord{}, time.Time{}, err
+	}
+	if !isOccurrenceDate(effectiveRecurrence(activity.Activity), date) {
+		return dto.ActivityAdministration{}, dto.OccurrenceRecord{}, time.Time{}, ErrOccurrenceNotFound
+	}
+
+	records, err := s.occurrences.List(ctx, activityID, fecha, fecha)
+	if err != nil {
+		return dto.ActivityAdministration{}, dto.OccurrenceRecord{}, time.Time{}, err
+	}
+	record := dto.OccurrenceRecord{ActivityID: activityID, Fecha: fecha}
+	for _, r := range records {
+		if r.Fecha == fecha {
+			record = r
+		}
+	}
+
+	occurrence := buildOccurrence(activity, fecha, record)
+	start, err := parseFecha(occurrenceDate(occurrence))
+	if err != nil {
+		start = date
+	}
+	return activity, record, occurrenceStart(occurrence.HoraInicio, start), nil
+}
+
+// InscribirOccurrence inscribe al usuario solo en la clase de una fecha
+func (s *ActivitiesServiceImpl) InscribirOccurrence(ctx context.Context, activityID, fecha, userID string) error {
+	activity, record, start, err := s.occurrenceFor(ctx, activityID, fecha)
+	if err != nil {
+		return err
+	}
+	if record.Estado == dto.OccurrenceCancelled {
+		return ErrOccurrenceCancelled
+	}
+	if err := s.checkUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := checkEnrollmentWindow(activity.Politica, start, time.Now()); err != nil {
+		return err
+	}
+	if err := s.checkEntitlement(ctx, userID, start, 1, ""); err != nil {
+		return err
+	}
+
+	for _, uid := range activity.UsersInscribed {
+		if fmt.Sprint(uid) == userID {
+			// ya tiene lugar en todas las clases de la serie
+			return ErrUserAlreadyInscribed
+		}
+	}
+
+	if err := s.occurrences.Inscribir(ctx, activityID, fecha, userID, activity.CapacidadMax-len(activity.UsersInscribed)); err != nil {
+		return err
+	}
+
+	log.Infof("User %s enrolled in activity %s on %s", userID, activityID, fecha)
+	s.recordHistory(ctx, dto.HistoryEntry{
+		ActivityID: activityID,
+		Action:     HistoryActionEnroll,
+		UserID:     userID,
+		Changes:    map[string]dto.FieldChange{"fecha": {After: fecha}},
+	})
+	s.notifyEnrollment(ctx, dto.EventEnrollmentCreated, activity, buildOccurrence(activity, fecha, record), userID)
+	return nil
+}
+
+// DesinscribirOccurrence quita al usuario de la clase de una fecha. Fuera de la ventana de
+// cancelación de la actividad se permite igual, pero queda registrada como cancelación tardía.
+func (s *ActivitiesServiceImpl) DesinscribirOccurrence(ctx context.Context, activityID, fecha, userID string) error {
+	activity, record, start, err := s.occurrenceFor(ctx, activityID, fecha)
+	if err != nil {
+		return err
+	}
+
+	if err := s.occurrences.Desinscribir(ctx, activityID, fecha, userID); err != nil {
+		return err
+	}
+
+	late := isLateCancellation(activity.Politica, start, time.Now())
+	if late {
+		s.recordLateCancellation(ctx, activityID, fecha, userID)
+	}
+
+	log.Infof("User %s unenrolled from activity %s on %s", userID, activityID, fecha)
+	s.recordHistory(ctx, dto.HistoryEntry{
+		ActivityID:       activityID,
+		Action:           HistoryActionUnenroll,
+		UserID:           userID,
+		Changes:          map[string]dto.FieldChange{"fecha": {Before: fecha}},
+		LateCancellation: late,
+	})
+	s.notifyEnrollment(ctx, dto.EventEnrollmentCancelled, activity, buildOccurrence(activity, fecha, record), userID)
+	return nil
+}
+
+// CancelOccurrence cancela la clase de una fecha. Los inscriptos se conservan (por si la clase se
+// reprograma) y se les avisa con un evento activity.cancelled.
+func (s *ActivitiesServiceImpl) CancelOccurrence(ctx context.Context, activityID, fecha, motivo string) (dto.Occurrence, error) {
+	motivo = strings.TrimSpace(motivo)
+	if motivo == "" {
+		return dto.Occurrence{}, errors.Join(ErrValidation, ErrReasonRequired)
+	}
+
+	activity, record, _, err := s.occurrenceFor(ctx, activityID, fecha)
+	if err != nil {
+		return dto.Occurrence{}, err
+	}
+	if record.Estado == dto.OccurrenceCancelled {
+		return dto.Occurrence{}, ErrOccurrenceCancelled
+	}
+
+	next := record
+	next.Estado = dto.OccurrenceCancelled
+	next.Motivo = motivo
+
+	return s.changeOccurrence(ctx, activity, record, next, dto.EventActivityCancelled, HistoryActionCancel)
+}
+
+// RescheduleOccurrence mueve la clase de una fecha a otro día y/o horario conservando sus
+// inscriptos, y les avisa con un evento activity.rescheduled. También sirve para volver a
+// programar una clase cancelada.
+func (s *ActivitiesServiceImpl) RescheduleOccurrence(ctx context.Context, activityID, fecha string, req dto.OccurrenceReschedule) (dto.Occurrence, error) {
+	req.Motivo = strings.TrimSpace(req.Motivo)
+	if req.Motivo == "" {
+		return dto.Occurrence{}, errors.Join(ErrValidation, ErrReasonRequired)
+	}
+	if req.NuevaFecha == "" && req.HoraInicio == "" && req.HoraFin == "" {
+		return dto.Occurrence{}, errors.Join(ErrValidation, fmt.Errorf("%w: nueva_fecha, hora_inicio or hora_fin is required", ErrInvalidDate))
+	}
+	if req.NuevaFecha != "" {
+		if _, err := parseFecha(req.NuevaFecha); err != nil {
+			return dto.Occurrence{}, errors.Join(ErrValidation, err)
+		}
+	}
+	for _, hora := range []string{req.HoraInicio, req.HoraFin} {
+		if hora != "" && !timeFormat.MatchString(hora) {
+			return dto.Occurrence{}, errors.Join(ErrValidation, fmt.Errorf("%w: %s", ErrInvalidTimeFormat, hora))
+		}
+	}
+
+	activity, record, _, err := s.occurrenceFor(ctx, activityID, fecha)
+	if err != nil {
+		return dto.Occurrence{}, err
+	}
+
+	next := record
+	next.Estado = dto.OccurrenceRescheduled
+	next.Motivo = req.Motivo
+	if req.NuevaFecha != "" {
+		next.NuevaFecha = req.NuevaFecha
+		if req.NuevaFecha == fecha {
+			next.NuevaFecha = ""
+		}
+	}
+	if req.HoraInicio != "" {
+		next.HoraInicio = req.HoraInicio
+	}
+	if req.HoraFin != "" {
+		next.HoraFin = req.HoraFin
+	}
+
+	rescheduled := buildOccurrence(activity, fecha, next)
+	if rescheduled.HoraFin <= rescheduled.HoraInicio {
+		return dto.Occurrence{}, errors.Join(ErrValidation, fmt.Errorf("%w: hora_fin must be after hora_inicio", ErrInvalidTimeFormat))
+	}
+	newDate, _ := parseFecha(occurrenceDate(rescheduled))
+	if !occurrenceStart(rescheduled.HoraInicio, newDate).After(time.Now()) {
+		return dto.Occurrence{}, errors.Join(ErrValidation, fmt.Errorf("%w: the class cannot be moved to the past", ErrInvalidDateRange))
+	}
+	if err := s.checkOccurrenceRoom(ctx, activity, rescheduled); err != nil {
+		return dto.Occurrence{}, err
+	}
+
+	return s.changeOccurrence(ctx, activity, record, next, dto.EventActivityRescheduled, HistoryActionReschedule)
+}
+
+// changeOccurrence guarda el nuevo estado de la clase y publica el evento para los inscriptos.
+// Si no se puede publicar, se restaura el estado anterior.
+func (s *ActivitiesServiceImpl) changeOccurrence(ctx context.Context, activity dto.ActivityAdministration, previous, next dto.OccurrenceRecord, eventType, historyAction string) (dto.Occurrence, error) {
+	if _, err := s.occurrences.SetEstado(ctx, next); err != nil {
+		return dto.Occurrence{}, err
+	}
+
+	before := buildOccurrence(activity, previous.Fecha, previous)
+	after := buildOccurrence(activity, next.Fecha, next)
+	event := dto.OccurrenceEvent{
+		Type:       eventType,
+		ActivityID: activity.ID,
+		Titulo:     activity.Nombre,
+		Fecha:      occurrenceDate(before),
+		HoraInicio: before.HoraInicio,
+		HoraFin:    before.HoraFin,
+		Motivo:     next.Motivo,
+		Usuarios:   enrolledInOccurrence(activity, next),
+		Actor:      actorFromContext(ctx),
+		Timestamp:  time.Now().UTC(),
+	}
+	if eventType == dto.EventActivityRescheduled {
+		event.NuevaFecha = occurrenceDate(after)
+		event.NuevaHoraInicio = after.HoraInicio
+		event.NuevaHoraFin = after.HoraFin
+	}
+
+	if s.notifier != nil {
+		if err := s.notifier.PublishEvent(ctx, event); err != nil {
+			log.Errorf("Failed to publish %s event for activity %s on %s: %v", eventType, activity.ID, next.Fecha, err)
+
+			// Rollback: restore the previous state of the occurrence
+			if _, restoreErr := s.occurrences.SetEstado(ctx, previous); restoreErr != nil {
+				log.Errorf("CRITICAL: Failed to rollback occurrence %s %s after RabbitMQ publish failure: %v", activity.ID, next.Fecha, restoreErr)
+				return dto.Occurrence{}, errors.Join(ErrPublishEventFailed, ErrRollbackFailed, err, restoreErr)
+			}
+
+			log.Warnf("Successfully rolled back occurrence %s %s after RabbitMQ publish failure", activity.ID, next.Fecha)
+			return dto.Occurrence{}, errors.Join(ErrPublishEventFailed, err)
+		}
+	}
+
+	log.Infof("Occurrence %s %s set to %s and %s event published", activity.ID, next.Fecha, next.Estado, eventType)
+	s.recordHistory(ctx, dto.HistoryEntry{
+		ActivityID: activity.ID,
+		Action:     historyAction,
+		Changes:    diffOccurrences(before, after),
+	})
+	return after, nil
+}
+
+// diffOccurrences devuelve los datos de la clase que cambiaron; siempre incluye la fecha para
+// identificarla en el historial
+func diffOccurrences(before, after dto.Occurrence) map[string]dto.FieldChange {
+	changes := map[string]dto.FieldChange{"fecha": {Before: before.Fecha, After: after.Fecha}}
+	fields := []struct {
+		name          string
+		before, after string
+	}{
+		{"estado", before.Estado, after.Estado},
+		{"motivo", before.Motivo, after.Motivo},
+		{"nueva_fecha", before.NuevaFecha, after.NuevaFecha},
+		{"hora_inicio", before.HoraInicio, after.HoraInicio},
+		{"hora_fin", before.HoraFin, after.HoraFin},
+	}
+	for _, field := range fields {
+		if field.before != field.after {
+			changes[field.name] = dto.FieldChange{Before: field.before, After: field.after}
+		}
+	}
+	return changes
+}
+
+// notifyEnrollment avisa al usuario que se inscribió o desinscribió. Es best-effort: la
+// inscripción ya quedó registrada y no se revierte si no se puede publicar el aviso. Para las
+// inscripciones a la serie occurrence es la zero value.
+func (s *ActivitiesServiceImpl) notifyEnrollment(ctx context.Context, eventType string, activity dto.ActivityAdministration, occurrence dto.Occurrence, userID string) {
+	if s.notifier == nil {
+		return
+	}
+	uid, err := strconv.Atoi(userID)
+	if err != nil {
+		return
+	}
+
+	event := dto.OccurrenceEvent{
+		Type:       eventType,
+		ActivityID: activity.ID,
+		Titulo:     activity.Nombre,
+		Dia:        activity.DiaSemana,
+		HoraInicio: activity.HoraInicio,
+		HoraFin:    activity.HoraFin,
+		Usuarios:   []int{uid},
+		Actor:      actorFromContext(ctx),
+		Timestamp:  time.Now().UTC(),
+	}
+	if occurrence.Fecha != "" {
+		event.Dia = occurrence.DiaSemana
+		event.Fecha = occurrenceDate(occurrence)
+		event.HoraInicio = occurrence.HoraInicio
+		event.HoraFin = occurrence.HoraFin
+	}
+
+	if err := s.notifier.PublishEvent(ctx, event); err != nil {
+		log.Warnf("Failed to publish %s event for user %s in activity %s: %v", eventType, userID, activity.ID, err)
+	}
+}
+
+// UpcomingOccurrences devuelve las clases no canceladas de las actividades activas que empiezan
+// entre from (inclusive) y to (exclusive), con todos sus asistentes. Lo usa el servicio de
+// notificaciones para los recordatorios.
+func (s *ActivitiesServiceImpl) UpcomingOccurrences(ctx context.Context, from, to time.Time) ([]dto.Occurrence, error) {
+	if !to.After(from) || to.Sub(from) > maxUpcomingRange {
+		return nil, errors.Join(ErrValidation, fmt.Errorf("%w: hasta must be after desde and at most 7 days later", ErrInvalidDateRange))
+	}
+
+	activities, err := s.repository.ListAllForAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to = from.In(time.Local), to.In(time.Local)
+	firstDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local)
+	lastDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.Local)
+
+	upcoming := []dto.Occurrence{}
+	for _, activity := range activities {
+		if !activity.Activa {
+			continue
+		}
+		// incluye las clases de otras fechas reprogramadas dentro del rango
+		records, err := s.occurrences.List(ctx, activity.ID, firstDay.Format(dto.FechaLayout), lastDay.Format(dto.FechaLayout))
+		if err != nil {
+			return nil, err
+		}
+		stored := map[string]dto.OccurrenceRecord{}
+		for _, record := range records {
+			stored[record.Fecha] = record
+		}
+
+		rec := effectiveRecurrence(activity.Activity)
+		candidates := occurrenceDates(rec, firstDay, lastDay)
+		for fecha := range stored {
+			if date, err := parseFecha(fecha); err == nil && isOccurrenceDate(rec, date) {
+				candidates = append(candidates, date)
+			}
+		}
+
+		seen := map[string]bool{}
+		for _, date := range candidates {
+			fecha := date.Format(dto.FechaLayout)
+			if seen[fecha] {
+				continue
+			}
+			seen[fecha] = true
+
+			record := stored[fecha]
+			if record.Estado == dto.OccurrenceCancelled {
+				continue
+			}
+			occurrence := buildOccurrence(activity, fecha, record)
+			day, err := parseFecha(occurrenceDate(occurrence))
+			if err != nil {
+				continue
+			}
+			start := occurrenceStart(occurrence.HoraInicio, day)
+			if start.Before(from) || !start.Before(to) {
+				continue
+			}
+			occurrence.UsersInscribed = enrolledInOccurrence(activity, record)
+			upcoming = append(upcoming, occurrence)
+		}
+	}
+
+	slices.SortFunc(upcoming, func(a, b dto.Occurrence) int {
+		if c := strings.Compare(occurrenceDate(a), occurrenceDate(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.HoraInicio, b.HoraInicio)
+	})
+	return upcoming, nil
+}
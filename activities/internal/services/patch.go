@@ -0,0 +1,103 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// patchableFields son los campos (nombre JSON) que se pueden modificar con PATCH
+var patchableFields = map[string]bool{
+	"titulo":      true,
+	"descripcion": true,
+	"instructor":  true,
+	"dia":         true,
+	"hora_inicio": true,
+	"hora_fin":    true,
+	"cupo":        true,
+	"foto_url":    true,
+	"recurrencia": true,
+	"politica":    true,
+	"id_sala":     true,
+	"recurso":     true,
+}
+
+// mergePatch aplica un JSON Merge Patch (RFC 7386) sobre target: los null eliminan el campo,
+// los objetos se combinan recursivamente y cualquier otro valor reemplaza al anterior
+func mergePatch(target any, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergePatch(targetObj[key], value)
+	}
+	return targetObj
+}
+
+// Patch aplica un JSON Merge Patch sobre la actividad. La validación se hace sobre el resultado
+// combinado, igual que en Update; version es la versión que el cliente leyó (If-Match).
+func (s *ActivitiesServiceImpl) Patch(ctx context.Context, id string, patch []byte, version int64) (dto.ActivityAdministration, error) {
+	var patchObj map[string]any
+	if err := json.Unmarshal(patch, &patchObj); err != nil || patchObj == nil {
+		return dto.ActivityAdministration{}, errors.Join(ErrValidation, ErrInvalidPatch)
+	}
+	if len(patchObj) == 0 {
+		return dto.ActivityAdministration{}, errors.Join(ErrValidation, ErrNoFieldsToUpdate)
+	}
+
+	var readOnly []string
+	for field := range patchObj {
+		if !patchableFields[field] {
+			readOnly = append(readOnly, field)
+		}
+	}
+	if len(readOnly) > 0 {
+		sort.Strings(readOnly)
+		return dto.ActivityAdministration{}, errors.Join(ErrValidation, fmt.Errorf("%w: %s", ErrReadOnlyField, strings.Join(readOnly, ", ")))
+	}
+
+	current, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return dto.ActivityAdministration{}, errors.Join(ErrActivityDoesNotExist, err)
+	}
+
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return dto.ActivityAdministration{}, err
+	}
+	var currentObj map[string]any
+	if err := json.Unmarshal(currentJSON, &currentObj); err != nil {
+		return dto.ActivityAdministration{}, err
+	}
+
+	mergedJSON, err := json.Marshal(mergePatch(currentObj, patchObj))
+	if err != nil {
+		return dto.ActivityAdministration{}, err
+	}
+	var merged dto.ActivityAdministration
+	if err := json.Unmarshal(mergedJSON, &merged); err != nil {
+		// p.ej. un string en cupo
+		return dto.ActivityAdministration{}, errors.Join(ErrValidation, ErrInvalidPatch, err)
+	}
+
+	// los inscriptos no se editan con PATCH: se conservan los actuales
+	merged.UsersInscribed = nil
+
+	merged.Version = version
+	return s.Update(ctx, id, merged)
+}
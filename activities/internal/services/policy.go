@@ -0,0 +1,110 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func validatePolicy(policy *dto.Policy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.AperturaDias < 0 || policy.CierreMinutos < 0 || policy.CancelacionHoras < 0 {
+		return ErrInvalidPolicy
+	}
+	return nil
+}
+
+// checkEnrollmentWindow verifica que a la hora now la inscripción a la clase que empieza en start
+// esté abierta según la política
+func checkEnrollmentWindow(policy *dto.Policy, start, now time.Time) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.AperturaDias > 0 {
+		if opens := start.AddDate(0, 0, -policy.AperturaDias); now.Before(opens) {
+			return fmt.Errorf("%w: opens at %s", ErrEnrollmentNotOpen, opens.Format(time.RFC3339))
+		}
+	}
+	if policy.CierreMinutos > 0 {
+		if closes := start.Add(-time.Duration(policy.CierreMinutos) * time.Minute); !now.Before(closes) {
+			return fmt.Errorf("%w: closed at %s", ErrEnrollmentClosed, closes.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// isLateCancellation indica si a la hora now ya pasó la cancelación sin cargo de la clase que
+// empieza en start
+func isLateCancellation(policy *dto.Policy, start, now time.Time) bool {
+	if policy == nil || policy.CancelacionHoras <= 0 {
+		return false
+	}
+	return !now.Before(start.Add(-time.Duration(policy.CancelacionHoras) * time.Hour))
+}
+
+// checkSeriesEnrollmentWindow aplica la política a la inscripción a la serie: la apertura se
+// cuenta desde la primera clase de la serie (si todavía no empezó) y el cierre, desde la próxima
+func (s *ActivitiesServiceImpl) checkSeriesEnrollmentWindow(ctx context.Context, activity dto.ActivityAdministration) error {
+	policy := activity.Politica
+	if policy == nil || (policy.AperturaDias <= 0 && policy.CierreMinutos <= 0) {
+		return nil
+	}
+	now := time.Now()
+
+	if policy.AperturaDias > 0 {
+		if first, ok := seriesStart(activity, effectiveRecurrence(activity.Activity)); ok {
+			opening := &dto.Policy{AperturaDias: policy.AperturaDias}
+			if err := checkEnrollmentWindow(opening, occurrenceStart(activity.HoraInicio, first), now); err != nil {
+				return err
+			}
+		}
+	}
+	if policy.CierreMinutos > 0 {
+		_, start, ok, err := s.nextOccurrence(ctx, activity)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return checkEnrollmentWindow(&dto.Policy{CierreMinutos: policy.CierreMinutos}, start, now)
+		}
+	}
+	return nil
+}
+
+// nextOccurrence devuelve la próxima clase de la actividad que todavía no empezó (sin contar las
+// canceladas) dentro de las próximas dos semanas, y cuándo empieza
+func (s *ActivitiesServiceImpl) nextOccurrence(ctx context.Context, activity dto.ActivityAdministration) (dto.Occurrence, time.Time, bool, error) {
+	from := today()
+	occurrences, err := s.occurrencesBetween(ctx, activity, from, from.AddDate(0, 0, 14))
+	if err != nil {
+		return dto.Occurrence{}, time.Time{}, false, err
+	}
+	now := time.Now()
+	for _, occurrence := range occurrences {
+		if occurrence.Estado == dto.OccurrenceCancelled {
+			continue
+		}
+		date, err := parseFecha(occurrenceDate(occurrence))
+		if err != nil {
+			continue
+		}
+		if start := occurrenceStart(occurrence.HoraInicio, date); start.After(now) {
+			return occurrence, start, true, nil
+		}
+	}
+	return dto.Occurrence{}, time.Time{}, false, nil
+}
+
+// recordLateCancellation guarda la cancelación tardía en la clase para los reportes de ausentismo
+func (s *ActivitiesServiceImpl) recordLateCancellation(ctx context.Context, activityID, fecha, userID string) {
+	if err := s.occurrences.AddLateCancellation(ctx, activityID, fecha, userID); err != nil {
+		log.Errorf("Failed to record late cancellation of user %s in activity %s on %s: %v", userID, activityID, fecha, err)
+		return
+	}
+	log.Infof("Late cancellation of user %s in activity %s on %s", userID, activityID, fecha)
+}
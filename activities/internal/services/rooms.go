@@ -0,0 +1,226 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type RoomsRepository interface {
+	List(ctx context.Context) ([]dto.Room, error)
+	GetByID(ctx context.Context, id string) (dto.Room, error)
+	Create(ctx context.Context, room dto.Room) (dto.Room, error)
+	Update(ctx context.Context, id string, room dto.Room) (dto.Room, error)
+	Delete(ctx context.Context, id string) error
+}
+
+func validateRoom(room dto.Room) error {
+	if strings.TrimSpace(room.Nombre) == "" {
+		return ErrRoomNameRequired
+	}
+	if room.Capacidad <= 0 {
+		return ErrRoomCapacityRequired
+	}
+	for name, quantity := range room.Recursos {
+		if strings.TrimSpace(name) == "" || quantity <= 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidResource, name)
+		}
+	}
+	return nil
+}
+
+// ListRooms devuelve todas las salas
+func (s *ActivitiesServiceImpl) ListRooms(ctx context.Context) ([]dto.Room, error) {
+	return s.rooms.List(ctx)
+}
+
+// GetRoom obtiene una sala por ID
+func (s *ActivitiesServiceImpl) GetRoom(ctx context.Context, id string) (dto.Room, error) {
+	return s.rooms.GetByID(ctx, id)
+}
+
+// CreateRoom crea una sala
+func (s *ActivitiesServiceImpl) CreateRoom(ctx context.Context, room dto.Room) (dto.Room, error) {
+	room.Nombre = strings.TrimSpace(room.Nombre)
+	if err := validateRoom(room); err != nil {
+		return dto.Room{}, errors.Join(ErrValidation, err)
+	}
+	return s.rooms.Create(ctx, room)
+}
+
+// UpdateRoom reemplaza los datos de una sala. La capacidad y el equipamiento no pueden quedar
+// por debajo del cupo de las actividades activas asignadas a la sala.
+func (s *ActivitiesServiceImpl) UpdateRoom(ctx context.Context, id string, room dto.Room) (dto.Room, error) {
+	room.Nombre = strings.TrimSpace(room.Nombre)
+	if err := validateRoom(room); err != nil {
+		return dto.Room{}, errors.Join(ErrValidation, err)
+	}
+	if _, err := s.rooms.GetByID(ctx, id); err != nil {
+		return dto.Room{}, err
+	}
+
+	activities, err := s.repository.ListByRoom(ctx, id)
+	if err != nil {
+		return dto.Room{}, err
+	}
+	for _, activity := range activities {
+		if !activity.Activa {
+			continue
+		}
+		if err := checkRoomCapacity(room, activity.Activity); err != nil {
+			return dto.Room{}, errors.Join(ErrValidation, fmt.Errorf("%w: %s (cupo %d)", ErrRoomCapacityTooSmall, activity.Nombre, activity.CapacidadMax), err)
+		}
+	}
+
+	return s.rooms.Update(ctx, id, room)
+}
+
+// DeleteRoom elimina una sala que no tenga actividades asignadas (aunque estén dadas de baja)
+func (s *ActivitiesServiceImpl) DeleteRoom(ctx context.Context, id string) error {
+	activities, err := s.repository.ListByRoom(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(activities) > 0 {
+		return fmt.Errorf("%w: %d activities", ErrRoomInUse, len(activities))
+	}
+	return s.rooms.Delete(ctx, id)
+}
+
+// checkRoomCapacity valida el cupo de la actividad contra la capacidad de la sala y, si la
+// actividad usa equipamiento, contra la cantidad disponible en la sala
+func checkRoomCapacity(room dto.Room, activity dto.Activity) error {
+	if activity.CapacidadMax > room.Capacidad {
+		return fmt.Errorf("%w: %s admits %d", ErrCapacityExceedsRoom, room.Nombre, room.Capacidad)
+	}
+	if activity.Recurso == "" {
+		return nil
+	}
+	available, ok := room.Recursos[activity.Recurso]
+	if !ok {
+		return fmt.Errorf("%w: %s has no %s", ErrResourceNotInRoom, room.Nombre, activity.Recurso)
+	}
+	if activity.CapacidadMax > available {
+		return fmt.Errorf("%w: %s has %d %s", ErrCapacityExceedsRoom, room.Nombre, available, activity.Recurso)
+	}
+	return nil
+}
+
+// checkRoom valida la sala asignada a la actividad: que exista, que alcance para el cupo y que no
+// esté ocupada en el mismo horario por otra actividad activa (distinta de excludeID). Los errores
+// de datos se devuelven unidos a ErrValidation; la superposición, como ErrRoomConflict.
+func (s *ActivitiesServiceImpl) checkRoom(ctx context.Context, activity dto.Activity, excludeID string) error {
+	if activity.SalaID == "" {
+		if activity.Recurso != "" {
+			return errors.Join(ErrValidation, fmt.Errorf("%w: recurso requires id_sala", ErrResourceNotInRoom))
+		}
+		return nil
+	}
+	if s.rooms == nil {
+		return errors.Join(ErrValidation, ErrUnknownRoom)
+	}
+
+	room, err := s.rooms.GetByID(ctx, activity.SalaID)
+	if err != nil {
+		if errors.Is(err, ErrRoomNotFound) || errors.Is(err, ErrInvalidIDFormat) {
+			return errors.Join(ErrValidation, fmt.Errorf("%w: %s", ErrUnknownRoom, activity.SalaID))
+		}
+		return err
+	}
+	if err := checkRoomCapacity(room, activity); err != nil {
+		return errors.Join(ErrValidation, err)
+	}
+
+	others, err := s.repository.ListByRoom(ctx, activity.SalaID)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID == excludeID || !other.Activa {
+			continue
+		}
+		if schedulesOverlap(activity, other.Activity) {
+			log.Warnf("Room %s conflict between activity %s and %s", room.Nombre, activity.Nombre, other.ID)
+			return fmt.Errorf("%w: %s has %s (%s) %s-%s", ErrRoomConflict, room.Nombre, other.Nombre, other.ID, other.HoraInicio, other.HoraFin)
+		}
+	}
+	return nil
+}
+
+// checkOccurrenceRoom valida que la sala de la actividad esté libre para la clase reprogramada: que
+// ninguna otra clase de la sala (de otra actividad activa o de la misma) se dicte ese día en un
+// horario superpuesto. A diferencia de checkRoom compara las clases concretas de esa fecha, con
+// sus cancelaciones y reprogramaciones.
+func (s *ActivitiesServiceImpl) checkOccurrenceRoom(ctx context.Context, activity dto.ActivityAdministration, occurrence dto.Occurrence) error {
+	if activity.SalaID == "" {
+		return nil
+	}
+	date, err := parseFecha(occurrenceDate(occurrence))
+	if err != nil {
+		return errors.Join(ErrValidation, err)
+	}
+
+	others, err := s.repository.ListByRoom(ctx, activity.SalaID)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if !other.Activa {
+			continue
+		}
+		classes, err := s.occurrencesBetween(ctx, other, date, date)
+		if err != nil {
+			return err
+		}
+		for _, class := range classes {
+			// la clase que se está moviendo no choca consigo misma
+			if class.ActivityID == activity.ID && class.Fecha == occurrence.Fecha {
+				continue
+			}
+			if class.Estado == dto.OccurrenceCancelled {
+				continue
+			}
+			if occurrence.HoraInicio < class.HoraFin && class.HoraInicio < occurrence.HoraFin {
+				log.Warnf("Room %s conflict between class %s %s and activity %s", activity.SalaID, activity.ID, occurrence.Fecha, other.ID)
+				return fmt.Errorf("%w: %s (%s) has a class on %s %s-%s", ErrRoomConflict, other.Nombre, other.ID, occurrenceDate(class), class.HoraInicio, class.HoraFin)
+			}
+		}
+	}
+	return nil
+}
+
+// schedulesOverlap indica si dos actividades pueden coincidir: comparten algún día de la semana
+// dentro de su período de vigencia y sus horarios se superponen. Las excepciones y las clases
+// reprogramadas no se tienen en cuenta.
+func schedulesOverlap(a, b dto.Activity) bool {
+	if !(a.HoraInicio < b.HoraFin && b.HoraInicio < a.HoraFin) {
+		return false
+	}
+
+	recA, recB := effectiveRecurrence(a), effectiveRecurrence(b)
+	sharedDay := false
+	for _, dia := range recA.Dias {
+		for _, other := range recB.Dias {
+			if dia == other {
+				sharedDay = true
+			}
+		}
+	}
+	if !sharedDay {
+		return false
+	}
+
+	// fechas YYYY-MM-DD: se comparan como texto; sin fin la regla no termina
+	endA, endB := recA.FechaFin, recB.FechaFin
+	if endA == "" {
+		endA = "9999-12-31"
+	}
+	if endB == "" {
+		endB = "9999-12-31"
+	}
+	return recA.FechaInicio <= endB && recB.FechaInicio <= endA
+}
@@ -0,0 +1,399 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ParseRoster lee los usuarios de una importación de inscriptos: en CSV una columna id_usuario,
+// en JSON {"usuarios": [1, 2]}
+func ParseRoster(format string, r io.Reader) ([]int, error) {
+	switch format {
+	case dto.CatalogFormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		header, err := reader.Read()
+		if err != nil {
+			return nil, errors.Join(ErrValidation, fmt.Errorf("%w: missing header: %v", ErrInvalidImport, err))
+		}
+		column := slices.IndexFunc(header, func(name string) bool {
+			return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) == "id_usuario"
+		})
+		if column < 0 {
+			return nil, errors.Join(ErrValidation, fmt.Errorf("%w: missing column id_usuario", ErrInvalidImport))
+		}
+
+		var ids []int
+		for fila := 2; ; fila++ {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, errors.Join(ErrValidation, fmt.Errorf("%w: line %d: %v", ErrInvalidImport, fila, err))
+			}
+			if column >= len(record) || strings.TrimSpace(record[column]) == "" {
+				continue
+			}
+			id, err := strconv.Atoi(strings.TrimSpace(record[column]))
+			if err != nil {
+				return nil, errors.Join(ErrValidation, fmt.Errorf("%w: line %d: id_usuario must be a number", ErrInvalidRoster, fila))
+			}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	case dto.CatalogFormatJSON:
+		var req dto.RosterRequest
+		if err := json.NewDecoder(r).Decode(&req); err != nil {
+			return nil, errors.Join(ErrValidation, fmt.Errorf("%w: body must be {\"usuarios\": [...]}: %v", ErrInvalidImport, err))
+		}
+		return req.Usuarios, nil
+	default:
+		return nil, errors.Join(ErrValidation, fmt.Errorf("%w: %q", ErrInvalidFormat, format))
+	}
+}
+
+// normalizeRoster valida los IDs y quita los repetidos, conservando el orden
+func normalizeRoster(ids []int) ([]int, error) {
+	unique := []int{}
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, errors.Join(ErrValidation, fmt.Errorf("%w: %d", ErrInvalidRoster, id))
+		}
+		if !slices.Contains(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
+
+// validateRosterUsers verifica en users-api que los usuarios existan y no sean admins
+func (s *ActivitiesServiceImpl) validateRosterUsers(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if s.users == nil {
+		return fmt.Errorf("%w: users-api is not configured", ErrUsersUnavailable)
+	}
+
+	var problems []string
+	for _, id := range ids {
+		user, err := s.users.GetUser(ctx, id)
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			problems = append(problems, fmt.Sprintf("%d: user not found", id))
+		case err != nil:
+			return err
+		case user.IsAdmin:
+			problems = append(problems, fmt.Sprintf("%d: admin users cannot enroll", id))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.Join(ErrValidation, fmt.Errorf("%w: %s", ErrInvalidRosterUsers, strings.Join(problems, "; ")))
+	}
+	return nil
+}
+
+// checkRosterCapacity verifica que a la serie le entren size inscriptos, contando la fecha futura
+// con más inscripciones sueltas (como Inscribir)
+func (s *ActivitiesServiceImpl) checkRosterCapacity(ctx context.Context, activity dto.ActivityAdministration, size int) error {
+	maxOccurrence, err := s.occurrences.MaxEnrolled(ctx, activity.ID, today().Format(dto.FechaLayout))
+	if err != nil {
+		return err
+	}
+	if size+maxOccurrence > activity.CapacidadMax {
+		return fmt.Errorf("%w: %d places needed, capacity is %d", ErrActivityFull, size+maxOccurrence, activity.CapacidadMax)
+	}
+	return nil
+}
+
+// AddToRoster inscribe a la serie a los usuarios indicados por un admin. No se aplican las
+// ventanas de inscripción ni las cuotas de los planes, pero sí el cupo. Si alguno ya está
+// inscripto o no es válido no se inscribe a ninguno.
+func (s *ActivitiesServiceImpl) AddToRoster(ctx context.Context, id string, userIDs []int) (dto.RosterResult, error) {
+	ids, err := normalizeRoster(userIDs)
+	if err != nil {
+		return dto.RosterResult{}, err
+	}
+	if len(ids) == 0 {
+		return dto.RosterResult{}, errors.Join(ErrValidation, ErrInvalidRoster)
+	}
+
+	activity, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return dto.RosterResult{}, err
+	}
+	if !activity.Activa {
+		return dto.RosterResult{}, ErrActivityInactive
+	}
+	var already []string
+	for _, uid := range ids {
+		if slices.Contains(activity.UsersInscribed, uid) {
+			already = append(already, strconv.Itoa(uid))
+		}
+	}
+	if len(already) > 0 {
+		return dto.RosterResult{}, fmt.Errorf("%w: %s", ErrUserAlreadyInscribed, strings.Join(already, ", "))
+	}
+
+	return s.applyRoster(ctx, activity, ids, nil)
+}
+
+// RemoveFromRoster quita a un usuario de la serie por pedido de un admin. No se valida contra
+// users-api para poder quitar usuarios que ya no existen, y no cuenta como cancelación tardía.
+func (s *ActivitiesServiceImpl) RemoveFromRoster(ctx context.Context, id string, userID int) (dto.RosterResult, error) {
+	activity, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return dto.RosterResult{}, err
+	}
+	if !slices.Contains(activity.UsersInscribed, userID) {
+		return dto.RosterResult{}, ErrUserNotInscribed
+	}
+	return s.applyRoster(ctx, activity, nil, []int{userID})
+}
+
+// ImportRoster carga la lista de inscriptos de la serie: inscribe a los que faltan y, con replace,
+// quita a los que no están en la lista. Se valida todo antes de aplicar ningún cambio.
+func (s *ActivitiesServiceImpl) ImportRoster(ctx context.Context, id string, userIDs []int, replace bool) (dto.RosterResult, error) {
+	ids, err := normalizeRoster(userIDs)
+	if err != nil {
+		return dto.RosterResult{}, err
+	}
+
+	activity, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return dto.RosterResult{}, err
+	}
+	if !activity.Activa {
+		return dto.RosterResult{}, ErrActivityInactive
+	}
+
+	var add, remove []int
+	for _, uid := range ids {
+		if !slices.Contains(activity.UsersInscribed, uid) {
+			add = append(add, uid)
+		}
+	}
+	if replace {
+		for _, uid := range activity.UsersInscribed {
+			if !slices.Contains(ids, uid) {
+				remove = append(remove, uid)
+			}
+		}
+	}
+	return s.applyRoster(ctx, activity, add, remove)
+}
+
+// applyRoster valida a los usuarios a inscribir y el cupo, y después quita e inscribe de a uno,
+// con su historial y evento de inscripción
+func (s *ActivitiesServiceImpl) applyRoster(ctx context.Context, activity dto.ActivityAdministration, add, remove []int) (dto.RosterResult, error) {
+	if err := s.validateRosterUsers(ctx, add); err != nil {
+		return dto.RosterResult{}, err
+	}
+	size := len(activity.UsersInscribed) - len(remove) + len(add)
+	if len(add) > 0 {
+		if err := s.checkRosterCapacity(ctx, activity, size); err != nil {
+			return dto.RosterResult{}, err
+		}
+	}
+
+	result := dto.RosterResult{
+		ActivityID: activity.ID,
+		Agregados:  []int{},
+		Quitados:   []int{},
+		SinCambios: []int{},
+		Cupo:       activity.CapacidadMax,
+	}
+	for _, uid := range activity.UsersInscribed {
+		if !slices.Contains(remove, uid) {
+			result.SinCambios = append(result.SinCambios, uid)
+		}
+	}
+
+	for _, uid := range remove {
+		userID := strconv.Itoa(uid)
+		if _, err := s.repository.Desinscribir(ctx, activity.ID, userID); err != nil {
+			return result, fmt.Errorf("failed to remove user %d: %w", uid, err)
+		}
+		result.Quitados = append(result.Quitados, uid)
+		s.recordHistory(ctx, dto.HistoryEntry{ActivityID: activity.ID, Action: HistoryActionUnenroll, UserID: userID})
+		s.notifyEnrollment(ctx, dto.EventEnrollmentCancelled, activity, dto.Occurrence{}, userID)
+	}
+	for _, uid := range add {
+		userID := strconv.Itoa(uid)
+		if _, err := s.repository.Inscribir(ctx, activity.ID, userID); err != nil {
+			return result, fmt.Errorf("failed to enroll user %d: %w", uid, err)
+		}
+		result.Agregados = append(result.Agregados, uid)
+		s.recordHistory(ctx, dto.HistoryEntry{ActivityID: activity.ID, Action: HistoryActionEnroll, UserID: userID})
+		s.notifyEnrollment(ctx, dto.EventEnrollmentCreated, activity, dto.Occurrence{}, userID)
+	}
+
+	result.Inscriptos = len(result.SinCambios) + len(result.Agregados)
+	log.Infof("Roster of activity %s updated by admin: %d added, %d removed", activity.ID, len(result.Agregados), len(result.Quitados))
+	return result, nil
+}
+
+// MoveEnrollment pasa al usuario de la serie de la actividad from a la de to. Primero lo inscribe
+// en el destino y, si no se lo puede quitar del origen, deshace la inscripción.
+func (s *ActivitiesServiceImpl) MoveEnrollment(ctx context.Context, from, to string, userID int) error {
+	if to == "" || to == from {
+		return errors.Join(ErrValidation, ErrSameActivity)
+	}
+	if userID <= 0 {
+		return errors.Join(ErrValidation, fmt.Errorf("%w: %d", ErrInvalidRoster, userID))
+	}
+
+	source, err := s.repository.GetByID(ctx, from)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(source.UsersInscribed, userID) {
+		return ErrUserNotInscribed
+	}
+	destination, err := s.repository.GetByID(ctx, to)
+	if err != nil {
+		return err
+	}
+	if !destination.Activa {
+		return ErrActivityInactive
+	}
+	if slices.Contains(destination.UsersInscribed, userID) {
+		return ErrUserAlreadyInscribed
+	}
+	if err := s.validateRosterUsers(ctx, []int{userID}); err != nil {
+		return err
+	}
+	if err := s.checkRosterCapacity(ctx, destination, len(destination.UsersInscribed)+1); err != nil {
+		return err
+	}
+
+	uid := strconv.Itoa(userID)
+	if _, err := s.repository.Inscribir(ctx, to, uid); err != nil {
+		return err
+	}
+	if _, err := s.repository.Desinscribir(ctx, from, uid); err != nil {
+		log.Errorf("Failed to remove user %s from activity %s while moving to %s: %v", uid, from, to, err)
+		if _, rollbackErr := s.repository.Desinscribir(ctx, to, uid); rollbackErr != nil {
+			log.Errorf("Failed to roll back enrollment of user %s in activity %s: %v", uid, to, rollbackErr)
+			return fmt.Errorf("%w: %v", ErrRollbackFailed, rollbackErr)
+		}
+		return err
+	}
+
+	log.Infof("User %s moved from activity %s to %s", uid, from, to)
+	s.recordHistory(ctx, dto.HistoryEntry{
+		ActivityID: from,
+		Action:     HistoryActionUnenroll,
+		UserID:     uid,
+		Changes:    map[string]dto.FieldChange{"actividad": {Before: from, After: to}},
+	})
+	s.recordHistory(ctx, dto.HistoryEntry{
+		ActivityID: to,
+		Action:     HistoryActionEnroll,
+		UserID:     uid,
+		Changes:    map[string]dto.FieldChange{"actividad": {Before: from, After: to}},
+	})
+	s.notifyEnrollment(ctx, dto.EventEnrollmentCancelled, source, dto.Occurrence{}, uid)
+	s.notifyEnrollment(ctx, dto.EventEnrollmentCreated, destination, dto.Occurrence{}, uid)
+	return nil
+}
+
+// GetRoster devuelve la actividad con los datos de sus inscriptos, resueltos en una sola consulta a
+// users-api y ordenados por apellido y nombre. Con fecha devuelve la lista de esa clase: los
+// inscriptos a la serie y los inscriptos solo a esa fecha, con su horario.
+func (s *ActivitiesServiceImpl) GetRoster(ctx context.Context, id, fecha string) (dto.ActivityRoster, error) {
+	var (
+		activity dto.ActivityAdministration
+		enrolled []int
+		err      error
+	)
+	if fecha == "" {
+		activity, err = s.repository.GetByID(ctx, id)
+		if err != nil {
+			return dto.ActivityRoster{}, err
+		}
+		enrolled = activity.UsersInscribed
+	} else {
+		var record dto.OccurrenceRecord
+		activity, record, _, err = s.findOccurrence(ctx, id, fecha)
+		if err != nil {
+			return dto.ActivityRoster{}, err
+		}
+		enrolled = enrolledInOccurrence(activity, record)
+		occurrence := buildOccurrence(activity, fecha, record)
+		activity.DiaSemana = occurrence.DiaSemana
+		activity.HoraInicio = occurrence.HoraInicio
+		activity.HoraFin = occurrence.HoraFin
+		activity.LugaresDisponibles = occurrence.LugaresDisponibles
+	}
+
+	roster := dto.ActivityRoster{Activity: activity.Activity, Fecha: fecha, Inscritos: []dto.UserPublic{}}
+	if len(enrolled) == 0 {
+		return roster, nil
+	}
+	if s.users == nil {
+		return dto.ActivityRoster{}, fmt.Errorf("%w: users-api is not configured", ErrUsersUnavailable)
+	}
+	users, err := s.users.GetUsers(ctx, enrolled)
+	if err != nil {
+		return dto.ActivityRoster{}, err
+	}
+
+	found := make(map[int]dto.User, len(users))
+	for _, user := range users {
+		found[user.ID] = user
+	}
+	for _, uid := range enrolled {
+		user, ok := found[uid]
+		if !ok {
+			roster.Desconocidos = append(roster.Desconocidos, uid)
+			continue
+		}
+		roster.Inscritos = append(roster.Inscritos, dto.UserPublic{ID: user.ID, Nombre: user.Nombre, Apellido: user.Apellido, Email: user.Email})
+	}
+	if len(roster.Desconocidos) > 0 {
+		log.Warnf("actividad %s tiene inscriptos que no existen en users-api: %v", id, roster.Desconocidos)
+	}
+	slices.SortFunc(roster.Inscritos, func(a, b dto.UserPublic) int {
+		return cmp.Or(
+			cmp.Compare(strings.ToLower(a.Apellido), strings.ToLower(b.Apellido)),
+			cmp.Compare(strings.ToLower(a.Nombre), strings.ToLower(b.Nombre)),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	return roster, nil
+}
+
+// WriteRosterCSV escribe la planilla de asistencia de la clase: una fila por inscripto con la
+// columna firma vacía para imprimir
+func WriteRosterCSV(w io.Writer, roster dto.ActivityRoster) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"id_usuario", "apellido", "nombre", "email", "firma"}); err != nil {
+		return err
+	}
+	for _, user := range roster.Inscritos {
+		if err := writer.Write([]string{strconv.Itoa(user.ID), user.Apellido, user.Nombre, user.Email, ""}); err != nil {
+			return err
+		}
+	}
+	for _, uid := range roster.Desconocidos {
+		if err := writer.Write([]string{strconv.Itoa(uid), "", "", "", ""}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
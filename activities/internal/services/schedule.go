@@ -0,0 +1,146 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseWeek devuelve el lunes de la semana indicada como semana ISO (2026-W10) o como cualquier
+// fecha de la semana (YYYY-MM-DD). Sin semana devuelve la actual.
+func parseWeek(week string) (time.Time, error) {
+	var day time.Time
+	switch {
+	case week == "":
+		day = today()
+	case strings.Contains(week, "-W"):
+		year, number, _ := strings.Cut(week, "-W")
+		y, errYear := strconv.Atoi(year)
+		w, errWeek := strconv.Atoi(number)
+		if errYear != nil || errWeek != nil || len(year) != 4 || w < 1 || w > 53 {
+			return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidWeek, week)
+		}
+		// el 4 de enero siempre está en la semana 1
+		jan4 := time.Date(y, time.January, 4, 0, 0, 0, 0, time.Local)
+		day = jan4.AddDate(0, 0, (w-1)*7)
+		if isoYear, isoWeek := day.ISOWeek(); isoYear != y || isoWeek != w {
+			return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidWeek, week)
+		}
+	default:
+		var err error
+		if day, err = parseFecha(week); err != nil {
+			return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidWeek, week)
+		}
+	}
+	return weekStart(day), nil
+}
+
+// weekStart devuelve el lunes de la semana del día
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// weekLabel devuelve la semana ISO del día (YYYY-Www)
+func weekLabel(day time.Time) string {
+	isoYear, isoWeek := day.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", isoYear, isoWeek)
+}
+
+// WeeklySchedule arma la grilla de la semana con las clases de todas las actividades activas,
+// agrupadas por día y ordenadas por horario, con los lugares disponibles de cada clase. Las
+// clases canceladas figuran con su estado; las reprogramadas, en el día en que se dictan.
+func (s *ActivitiesServiceImpl) WeeklySchedule(ctx context.Context, week string) (dto.WeeklySchedule, error) {
+	monday, err := parseWeek(week)
+	if err != nil {
+		return dto.WeeklySchedule{}, errors.Join(ErrValidation, err)
+	}
+	sunday := monday.AddDate(0, 0, 6)
+
+	activities, err := s.repository.ListAllForAdmin(ctx)
+	if err != nil {
+		return dto.WeeklySchedule{}, err
+	}
+
+	schedule := dto.WeeklySchedule{
+		Semana: weekLabel(monday),
+		Desde:  monday.Format(dto.FechaLayout),
+		Hasta:  sunday.Format(dto.FechaLayout),
+		Dias:   make([]dto.ScheduleDay, 7),
+	}
+	for i := range schedule.Dias {
+		schedule.Dias[i] = dto.ScheduleDay{
+			Dia:    dto.DiasSemana[i],
+			Fecha:  monday.AddDate(0, 0, i).Format(dto.FechaLayout),
+			Clases: []dto.ScheduleClass{},
+		}
+	}
+
+	for _, activity := range activities {
+		if !activity.Activa {
+			continue
+		}
+		occurrences, err := s.occurrencesBetween(ctx, activity, monday, sunday)
+		if err != nil {
+			return dto.WeeklySchedule{}, err
+		}
+		for _, occurrence := range occurrences {
+			date, err := parseFecha(occurrenceDate(occurrence))
+			if err != nil {
+				continue
+			}
+			occurrence.UsersInscribed = nil
+			day := &schedule.Dias[(int(date.Weekday())+6)%7]
+			day.Clases = append(day.Clases, dto.ScheduleClass{
+				Occurrence: occurrence,
+				Instructor: activity.Profesor,
+				SalaID:     activity.SalaID,
+			})
+		}
+	}
+
+	for _, day := range schedule.Dias {
+		slices.SortStableFunc(day.Clases, func(a, b dto.ScheduleClass) int {
+			if c := strings.Compare(a.HoraInicio, b.HoraInicio); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Titulo, b.Titulo)
+		})
+	}
+	return schedule, nil
+}
+
+// ScheduleCalendar devuelve el iCalendar público con todas las actividades activas como eventos
+// semanales. El DTSTAMP es la última modificación de las actividades para que el contenido no
+// cambie entre pedidos si no cambió la grilla.
+func (s *ActivitiesServiceImpl) ScheduleCalendar(ctx context.Context) (string, error) {
+	activities, err := s.repository.ListAllForAdmin(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var series []calendarSeries
+	var stamp time.Time
+	for _, activity := range activities {
+		if !activity.Activa {
+			continue
+		}
+		records, err := s.occurrences.List(ctx, activity.ID, "0000-01-01", "9999-12-31")
+		if err != nil {
+			return "", err
+		}
+		series = append(series, calendarSeries{activity: activity, records: records, sala: s.roomName(ctx, activity.SalaID)})
+		if activity.FechaActualizacion.After(stamp) {
+			stamp = activity.FechaActualizacion
+		}
+	}
+	if stamp.IsZero() {
+		stamp = time.Unix(0, 0)
+	}
+
+	return buildCalendar("Horarios", series, nil, stamp), nil
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultStatisticsWeeks = 12
+	maxStatisticsRange     = 366 * 24 * time.Hour
+	defaultRankingLimit    = 5
+	maxRankingLimit        = 50
+)
+
+// GetStatistics calcula las estadísticas de las actividades activas (con un pipeline de agregación
+// en el repositorio) y la tendencia semanal de inscripciones del período, tomada del historial.
+// Limit es el largo de los rankings de popularidad: cero toma el valor por defecto y se acota a
+// maxRankingLimit.
+func (s *ActivitiesServiceImpl) GetStatistics(ctx context.Context, filters dto.StatisticsFilters) (dto.ActivityStatistics, error) {
+	from, to, err := statisticsPeriod(filters)
+	if err != nil {
+		return dto.ActivityStatistics{}, err
+	}
+	if filters.Limit < 0 {
+		return dto.ActivityStatistics{}, errors.Join(ErrValidation, ErrInvalidPagination)
+	}
+	limit := min(cmp.Or(filters.Limit, defaultRankingLimit), maxRankingLimit)
+
+	stats, err := s.repository.Statistics(ctx, limit)
+	if err != nil {
+		log.WithError(err).Error("Error aggregating activity statistics")
+		return dto.ActivityStatistics{}, err
+	}
+
+	stats.CapacityUtilization = utilization(stats.TotalEnrollments, stats.TotalCapacity)
+	if stats.TotalActivities > 0 {
+		stats.AverageEnrollmentRate = float64(stats.TotalEnrollments) / float64(stats.TotalActivities)
+	}
+	stats.AvailableActivities = stats.TotalActivities - stats.FullActivitiesCount
+	stats.ByDay = calendarDays(stats.ByDay)
+	for _, breakdowns := range [][]dto.StatisticsBreakdown{stats.ByInstructor, stats.ByDay, stats.ByTimeSlot} {
+		for i := range breakdowns {
+			breakdowns[i].Utilizacion = utilization(breakdowns[i].Inscripciones, breakdowns[i].Capacidad)
+		}
+	}
+	stats.ActivitiesByDay = make([]dto.DayDistribution, len(stats.ByDay))
+	for i, day := range stats.ByDay {
+		stats.ActivitiesByDay[i] = dto.DayDistribution{Dia: day.Clave, Count: day.Actividades}
+	}
+	if stats.MostPopular == nil {
+		stats.MostPopular = []dto.RankedActivity{}
+	}
+	if stats.LeastPopular == nil {
+		stats.LeastPopular = []dto.RankedActivity{}
+	}
+
+	stats.Desde = from.Format(dto.FechaLayout)
+	stats.Hasta = to.Format(dto.FechaLayout)
+	if stats.WeeklyEnrollmentsTrend, err = s.enrollmentTrend(ctx, from, to); err != nil {
+		log.WithError(err).Error("Error aggregating enrollment history for statistics")
+		return dto.ActivityStatistics{}, err
+	}
+	for _, week := range stats.WeeklyEnrollmentsTrend {
+		stats.PeriodEnrollments += week.Inscripciones
+		stats.PeriodUnenrollments += week.Desinscripciones
+	}
+
+	log.WithFields(log.Fields{
+		"total_activities":   stats.TotalActivities,
+		"total_enrollments":  stats.TotalEnrollments,
+		"full_activities":    stats.FullActivitiesCount,
+		"period_enrollments": stats.PeriodEnrollments,
+	}).Info("Statistics calculated successfully")
+
+	return stats, nil
+}
+
+// calendarDays ordena el desglose por día de lunes a domingo, con los días sin actividades en cero
+func calendarDays(byDay []dto.StatisticsBreakdown) []dto.StatisticsBreakdown {
+	days := make([]dto.StatisticsBreakdown, len(dto.DiasSemana))
+	for i, dia := range dto.DiasSemana {
+		days[i] = dto.StatisticsBreakdown{Clave: dia}
+	}
+	for _, day := range byDay {
+		if i := slices.Index(dto.DiasSemana, day.Clave); i >= 0 {
+			days[i] = day
+		}
+	}
+	return days
+}
+
+// statisticsPeriod valida el período de las estadísticas. Sin fechas son las últimas 12 semanas
+// hasta hoy, empezando un lunes; el máximo es de un año.
+func statisticsPeriod(filters dto.StatisticsFilters) (time.Time, time.Time, error) {
+	to := today()
+	if filters.Hasta != "" {
+		var err error
+		if to, err = parseFecha(filters.Hasta); err != nil {
+			return time.Time{}, time.Time{}, errors.Join(ErrValidation, err)
+		}
+	}
+	from := to.AddDate(0, 0, -7*defaultStatisticsWeeks+1)
+	from = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+	if filters.Desde != "" {
+		var err error
+		if from, err = parseFecha(filters.Desde); err != nil {
+			return time.Time{}, time.Time{}, errors.Join(ErrValidation, err)
+		}
+	}
+	if to.Before(from) || to.Sub(from) > maxStatisticsRange {
+		return time.Time{}, time.Time{}, errors.Join(ErrValidation, fmt.Errorf("%w: to must be after from and at most one year later", ErrInvalidDateRange))
+	}
+	return from, to, nil
+}
+
+// enrollmentTrend devuelve las inscripciones y desinscripciones (a la serie o a una clase) de
+// cada semana ISO entre from y to, inclusive, con las semanas sin movimientos en cero
+func (s *ActivitiesServiceImpl) enrollmentTrend(ctx context.Context, from, to time.Time) ([]dto.EnrollmentTrend, error) {
+	trend := []dto.EnrollmentTrend{}
+	weeks := map[string]int{}
+	monday := from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+	for day := monday; !day.After(to); day = day.AddDate(0, 0, 7) {
+		weeks[weekLabel(day)] = len(trend)
+		trend = append(trend, dto.EnrollmentTrend{Semana: weekLabel(day), Desde: day.Format(dto.FechaLayout)})
+	}
+	if s.history == nil {
+		return trend, nil
+	}
+
+	counts, err := s.history.CountByWeek(ctx, []string{HistoryActionEnroll, HistoryActionUnenroll}, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	for _, count := range counts {
+		i, ok := weeks[fmt.Sprintf("%04d-W%02d", count.Year, count.Week)]
+		if !ok {
+			continue
+		}
+		switch count.Action {
+		case HistoryActionEnroll:
+			trend[i].Inscripciones += count.Count
+		case HistoryActionUnenroll:
+			trend[i].Desinscripciones += count.Count
+		}
+	}
+	for i := range trend {
+		trend[i].Neto = trend[i].Inscripciones - trend[i].Desinscripciones
+	}
+	return trend, nil
+}
+
+// utilization es el porcentaje de la capacidad ocupada
+func utilization(enrolled, capacity int) float64 {
+	if capacity <= 0 {
+		return 0
+	}
+	return float64(enrolled) / float64(capacity) * 100
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"activities/internal/dto"
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// usersAPIActor es quien figura en el historial de los cambios hechos por eventos de users-api
+var usersAPIActor = dto.Actor{Username: "users-api"}
+
+// checkUser verifica en users-api que el usuario exista antes de inscribirlo
+func (s *ActivitiesServiceImpl) checkUser(ctx context.Context, userID string) error {
+	if s.users == nil {
+		return nil
+	}
+	uid, err := strconv.Atoi(userID)
+	if err != nil {
+		return ErrInvalidUserID
+	}
+	_, err = s.users.GetUser(ctx, uid)
+	return err
+}
+
+// HandleUserEvent procesa un evento de la cola de usuarios de users-api
+func (s *ActivitiesServiceImpl) HandleUserEvent(ctx context.Context, event dto.UserEvent) error {
+	switch event.Type {
+	case dto.EventUserDeleted:
+		if s.users != nil {
+			s.users.Forget(event.UserID)
+		}
+		return s.RemoveDeletedUser(ctx, event.UserID)
+	default:
+		log.Warnf("evento de usuario desconocido: %s", event.Type)
+		return nil
+	}
+}
+
+// RemoveDeletedUser quita a un usuario eliminado de todas las series (activas o no) y de las
+// clases que todavía no pasaron. Las clases pasadas se conservan para las estadísticas. No se
+// publican eventos de notificación porque el usuario ya no existe.
+func (s *ActivitiesServiceImpl) RemoveDeletedUser(ctx context.Context, userID int) error {
+	ctx = WithActor(ctx, usersAPIActor)
+	uid := strconv.Itoa(userID)
+
+	activityIDs, err := s.repository.GetInscripcionesByUserID(ctx, uid)
+	if err != nil {
+		return err
+	}
+	series := 0
+	for _, id := range activityIDs {
+		// si el evento se reprocesa, los que ya se quitaron no son un error ni se registran de nuevo
+		if _, err := s.repository.Desinscribir(ctx, id, uid); err != nil {
+			if errors.Is(err, ErrUserNotInscribed) {
+				continue
+			}
+			return err
+		}
+		series++
+		s.recordHistory(ctx, dto.HistoryEntry{ActivityID: id, Action: HistoryActionUserDeleted, UserID: uid})
+	}
+
+	records, err := s.occurrences.ListByUser(ctx, uid)
+	if err != nil {
+		return err
+	}
+	desde := today().Format(dto.FechaLayout)
+	removed := 0
+	for _, record := range records {
+		if record.Fecha < desde || !slices.Contains(record.UsersInscribed, userID) {
+			continue
+		}
+		if err := s.occurrences.Desinscribir(ctx, record.ActivityID, record.Fecha, uid); err != nil {
+			if errors.Is(err, ErrUserNotInscribed) {
+				continue
+			}
+			return err
+		}
+		removed++
+		s.recordHistory(ctx, dto.HistoryEntry{
+			ActivityID: record.ActivityID,
+			Action:     HistoryActionUserDeleted,
+			UserID:     uid,
+			Changes:    map[string]dto.FieldChange{"fecha": {Before: record.Fecha}},
+		})
+	}
+
+	log.Infof("usuario eliminado %d quitado de %d actividades y %d clases", userID, series, removed)
+	return nil
+}
@@ -40,7 +40,8 @@ const ActivityFormModal = ({ mode = 'create', actividad = null, onClose, onSave,
                 hora_fin: actividad.hora_fin || '',
                 foto_url: actividad.foto_url || '',
                 instructor: actividad.instructor || '',
-                usuarios_inscritos: usuariosInscritos
+                usuarios_inscritos: usuariosInscritos,
+                version: actividad.version
             };
 
             setFormData(actividadData);
@@ -115,7 +116,21 @@ const ActivityFormModal = ({ mode = 'create', actividad = null, onClose, onSave,
             if (mode === 'create') {
                 await actividadService.createActividad(dataToSend);
             } else {
-                await actividadService.updateActividad(formData.id_actividad, dataToSend);
+                // los inscriptos no se editan con PUT: se aplican las altas y bajas con el roster
+                const { usuarios_inscritos: usuariosInscritos = [], ...actividadData } = dataToSend;
+                await actividadService.updateActividad(formData.id_actividad, actividadData);
+
+                if (inscriptionsEdit) {
+                    const anteriores = actividad.usuarios_inscritos || [];
+                    const agregados = usuariosInscritos.filter(id => !anteriores.includes(id));
+                    const quitados = anteriores.filter(id => !usuariosInscritos.includes(id));
+                    for (const usuarioId of quitados) {
+                        await actividadService.removeFromRoster(formData.id_actividad, usuarioId);
+                    }
+                    if (agregados.length > 0) {
+                        await actividadService.addToRoster(formData.id_actividad, agregados);
+                    }
+                }
             }
 
             onSave();
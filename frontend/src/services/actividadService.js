@@ -117,7 +117,7 @@ export const actividadService = {
   async getActividades() {
     try {
       logger.logActivityFetch(ACTIVITIES_URL);
-      const response = await fetch(`${ACTIVITIES_URL}/activities`);
+      const response = await fetch(`${ACTIVITIES_URL}/activities?all=true`);
 
       if (!response.ok) {
         logger.logApiError('/activities', response.status, 'Failed to fetch actividades');
@@ -252,15 +252,25 @@ export const actividadService = {
     try {
       logger.logActivityAction('UPDATE', actividadId, actividadData);
       const token = localStorage.getItem('access_token');
+      const headers = {
+        'Content-Type': 'application/json',
+        'Authorization': `Bearer ${token}`
+      };
+      // la API exige la versión leída (ETag) para no pisar cambios de otro admin
+      if (actividadData.version !== undefined) {
+        headers['If-Match'] = `"${actividadData.version}"`;
+      }
       const response = await fetch(`${ACTIVITIES_URL}/activities/${actividadId}`, {
         method: 'PUT',
-        headers: {
-          'Content-Type': 'application/json',
-          'Authorization': `Bearer ${token}`
-        },
+        headers,
         body: JSON.stringify(actividadData),
       });
 
+      if (response.status === 412) {
+        logger.logApiError(`/activities/${actividadId}`, response.status, 'Version conflict');
+        throw new Error('La actividad fue modificada por otra persona. Cierre el formulario y vuelva a abrirlo para ver los cambios.');
+      }
+
       if (!response.ok) {
         const errorMessage = await extractErrorMessage(response, 'Error al actualizar la actividad');
         logger.logApiError(`/activities/${actividadId}`, response.status, errorMessage);
@@ -304,6 +314,62 @@ export const actividadService = {
     }
   },
 
+  /**
+   * Inscribir usuarios en la serie de una actividad (solo admin)
+   */
+  async addToRoster(actividadId, usuarios) {
+    try {
+      logger.logActivityAction('ROSTER_ADD', actividadId, { usuarios });
+      const token = localStorage.getItem('access_token');
+      const response = await fetch(`${ACTIVITIES_URL}/activities/${actividadId}/roster`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          'Authorization': `Bearer ${token}`
+        },
+        body: JSON.stringify({ usuarios }),
+      });
+
+      if (!response.ok) {
+        const errorMessage = await extractErrorMessage(response, 'Error al inscribir a los usuarios');
+        logger.logApiError(`/activities/${actividadId}/roster`, response.status, errorMessage);
+        throw new Error(errorMessage);
+      }
+
+      return await response.json();
+    } catch (error) {
+      logger.error(`Error al inscribir usuarios en actividad ${actividadId}`, error);
+      throw error;
+    }
+  },
+
+  /**
+   * Quitar a un usuario de la serie de una actividad (solo admin)
+   */
+  async removeFromRoster(actividadId, usuarioId) {
+    try {
+      logger.logActivityAction('ROSTER_REMOVE', actividadId, { usuarioId });
+      const token = localStorage.getItem('access_token');
+      const response = await fetch(`${ACTIVITIES_URL}/activities/${actividadId}/roster/${usuarioId}`, {
+        method: 'DELETE',
+        headers: {
+          'Authorization': `Bearer ${token}`
+        }
+      });
+
+      if (!response.ok) {
+        const errorMessage = await extractErrorMessage(response, 'Error al desinscribir al usuario');
+        logger.logApiError(`/activities/${actividadId}/roster/${usuarioId}`, response.status, errorMessage);
+        throw new Error(errorMessage);
+      }
+
+      return await response.json();
+    } catch (error) {
+      logger.error(`Error al desinscribir usuario ${usuarioId} de actividad ${actividadId}`, error);
+      throw error;
+    }
+  },
+
   /**
    * Inscribir un usuario en una actividad
    */
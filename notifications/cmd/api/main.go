@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"notifications/internal/clients"
+	"notifications/internal/config"
+	"notifications/internal/controllers"
+	"notifications/internal/senders"
+	"notifications/internal/services"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	cfg := config.Load()
+	ctx := context.Background()
+
+	templates, err := services.LoadTemplates(cfg.TemplatesDir)
+	if err != nil {
+		log.Fatalf("error loading templates: %v", err)
+	}
+
+	usersClient := clients.NewUsersClient(cfg.UsersAPIURL)
+	activitiesClient := clients.NewActivitiesClient(cfg.ActivitiesAPIURL, cfg.JwtSecret)
+
+	notificationsService := services.NewNotificationsService(usersClient, newSender(cfg.Sender), templates)
+
+	notificationsQueue := clients.NewRabbitMQClient(
+		cfg.RabbitMQ.Username,
+		cfg.RabbitMQ.Password,
+		cfg.RabbitMQ.QueueName,
+		cfg.RabbitMQ.Host,
+		cfg.RabbitMQ.Port,
+	)
+	defer notificationsQueue.Close()
+
+	go func() {
+		if err := notificationsQueue.Consume(ctx, notificationsService.HandleEvent); err != nil {
+			log.Fatalf("consumer stopped: %v", err)
+		}
+	}()
+
+	if cfg.Reminders.LeadHours > 0 {
+		reminders := services.NewReminderScheduler(
+			activitiesClient,
+			notificationsService,
+			time.Duration(cfg.Reminders.LeadHours)*time.Hour,
+			time.Duration(cfg.Reminders.IntervalMinutes)*time.Minute,
+		)
+		go reminders.Run(ctx)
+	}
+
+	notificationsController := controllers.NewNotificationsController(notificationsService, cfg.Sender.Type)
+	router := gin.Default()
+
+	router.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	router.GET("/notifications/stats", notificationsController.Stats)
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("🚀 API listening on port %s", cfg.Port)
+	log.Printf("📊 Health check: http://localhost:%s/healthz", cfg.Port)
+
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("server error: %v", err)
+	}
+}
+
+// newSender crea el sender configurado en NOTIFICATIONS_SENDER
+func newSender(cfg config.SenderConfig) services.Sender {
+	switch cfg.Type {
+	case "smtp":
+		return senders.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPass, cfg.SMTPFrom)
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			log.Fatalf("NOTIFICATIONS_SENDER=webhook requiere WEBHOOK_URL")
+		}
+		return senders.NewWebhookSender(cfg.WebhookURL)
+	case "file":
+		return senders.NewFileSender(cfg.FilePath)
+	default:
+		log.Fatalf("NOTIFICATIONS_SENDER desconocido: %s (smtp, webhook o file)", cfg.Type)
+		return nil
+	}
+}
@@ -0,0 +1,80 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"notifications/internal/dto"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ActivitiesClient consulta a activities-api las clases próximas para los recordatorios. Se
+// autentica con un token de servicio firmado con el JWT_SECRET compartido.
+type ActivitiesClient struct {
+	baseURL   string
+	jwtSecret string
+	client    *http.Client
+}
+
+func NewActivitiesClient(baseURL, jwtSecret string) *ActivitiesClient {
+	return &ActivitiesClient{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		jwtSecret: jwtSecret,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Upcoming devuelve las clases que empiezan entre from (inclusive) y to (exclusive)
+func (c *ActivitiesClient) Upcoming(ctx context.Context, from, to time.Time) ([]dto.Occurrence, error) {
+	token, err := c.serviceToken()
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("desde", from.UTC().Format(time.RFC3339))
+	query.Set("hasta", to.UTC().Format(time.RFC3339))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/occurrences/upcoming?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling activities-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("activities-api returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Occurrences []dto.Occurrence `json:"occurrences"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("error decoding upcoming classes: %w", err)
+	}
+	return body.Occurrences, nil
+}
+
+// serviceToken firma un token de corta duración que activities-api reconoce por el claim "service"
+func (c *ActivitiesClient) serviceToken() (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":      "notifications",
+		"service":  "notifications",
+		"username": "notifications",
+		"is_admin": false,
+		"iat":      now.Unix(),
+		"exp":      now.Add(5 * time.Minute).Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(c.jwtSecret))
+}
@@ -0,0 +1,140 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"notifications/internal/dto"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+type RabbitMQClient struct {
+	connection *amqp091.Connection
+	channel    *amqp091.Channel
+	queue      *amqp091.Queue
+}
+
+// NewRabbitMQClient conecta con RabbitMQ y declara la cola de notificaciones
+func NewRabbitMQClient(user, password, queueName, host, port string) *RabbitMQClient {
+	connStr := fmt.Sprintf("amqp://%s:%s@%s:%s/", user, password, host, port)
+
+	var connection *amqp091.Connection
+	var err error
+
+	// Retry connection up to 10 times with exponential backoff
+	maxRetries := 10
+	for i := 0; i < maxRetries; i++ {
+		connection, err = amqp091.Dial(connStr)
+		if err == nil {
+			break
+		}
+
+		waitTime := time.Duration(i+1) * time.Second
+		log.Warnf("Failed to connect to RabbitMQ (attempt %d/%d): %v. Retrying in %v...", i+1, maxRetries, err, waitTime)
+		time.Sleep(waitTime)
+	}
+
+	if err != nil {
+		log.Fatalf("failed to connect to RabbitMQ after %d attempts: %v", maxRetries, err)
+	}
+
+	channel, err := connection.Channel()
+	if err != nil {
+		log.Fatalf("failed to open a channel: %v", err)
+	}
+
+	// Declare queue with same settings as activities-api (durable: true)
+	queue, err := channel.QueueDeclare(
+		queueName, // name
+		true,      // durable - survives broker restart
+		false,     // delete when unused
+		false,     // exclusive
+		false,     // no-wait
+		nil,       // arguments
+	)
+	if err != nil {
+		log.Fatalf("failed to declare a queue: %v", err)
+	}
+
+	// los mensajes se procesan de a uno: cada uno puede implicar varios envíos
+	if err := channel.Qos(10, 0, false); err != nil {
+		log.Fatalf("failed to set channel QoS: %v", err)
+	}
+
+	log.Infof("Successfully connected to RabbitMQ at %s:%s", host, port)
+	return &RabbitMQClient{
+		connection: connection,
+		channel:    channel,
+		queue:      &queue,
+	}
+}
+
+// Consume invoca al handler con cada evento de la cola. Si el handler falla el mensaje se
+// reencola una sola vez; los mensajes que no se pueden deserializar se descartan.
+func (r *RabbitMQClient) Consume(ctx context.Context, handler func(context.Context, dto.ActivityEvent) error) error {
+	msgs, err := r.channel.Consume(
+		r.queue.Name, // queue
+		"",           // consumer
+		false,        // auto-ack
+		false,        // exclusive
+		false,        // no-local
+		false,        // no-wait
+		nil,          // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	log.Printf("🎯 Consumer registered for queue: %s", r.queue.Name)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Consumer context cancelled")
+			return ctx.Err()
+
+		case msg, ok := <-msgs:
+			if !ok {
+				return fmt.Errorf("consumer channel closed")
+			}
+
+			var event dto.ActivityEvent
+			if err := json.Unmarshal(msg.Body, &event); err != nil {
+				log.Printf("❌ Error unmarshalling message: %v", err)
+				if err := msg.Nack(false, false); err != nil {
+					log.Printf("❌ Error rejecting message: %v", err)
+				}
+				continue
+			}
+
+			if err := handler(ctx, event); err != nil {
+				log.Printf("❌ Error handling %s event for activity %s: %v", event.Type, event.ActivityID, err)
+				if err := msg.Nack(false, !msg.Redelivered); err != nil {
+					log.Printf("❌ Error rejecting message: %v", err)
+				}
+				continue
+			}
+
+			if err := msg.Ack(false); err != nil {
+				log.Printf("❌ Error acknowledging message: %v", err)
+			}
+		}
+	}
+}
+
+// Close cierra canal y conexión
+func (r *RabbitMQClient) Close() error {
+	if r.channel != nil {
+		if err := r.channel.Close(); err != nil {
+			return err
+		}
+	}
+	if r.connection != nil {
+		return r.connection.Close()
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"notifications/internal/dto"
+	"strings"
+	"time"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+// UsersClient obtiene los datos de contacto de los usuarios desde users-api
+type UsersClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewUsersClient(baseURL string) *UsersClient {
+	return &UsersClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// GetByID busca un usuario por su ID
+func (c *UsersClient) GetByID(ctx context.Context, id int) (dto.User, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/users/%d", c.baseURL, id), nil)
+	if err != nil {
+		return dto.User{}, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return dto.User{}, fmt.Errorf("error calling users-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return dto.User{}, ErrUserNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return dto.User{}, fmt.Errorf("users-api returned status %d", resp.StatusCode)
+	}
+
+	var user dto.User
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return dto.User{}, fmt.Errorf("error decoding user: %w", err)
+	}
+	return user, nil
+}
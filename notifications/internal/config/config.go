@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	log "github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	Port             string
+	RabbitMQ         RabbitMQConfig
+	UsersAPIURL      string
+	ActivitiesAPIURL string
+	JwtSecret        string
+	Sender           SenderConfig
+	Reminders        RemindersConfig
+	TemplatesDir     string
+}
+
+type RabbitMQConfig struct {
+	Username  string
+	Password  string
+	QueueName string
+	Host      string
+	Port      string
+}
+
+// SenderConfig elige cómo se entregan las notificaciones: smtp, webhook o file (por defecto)
+type SenderConfig struct {
+	Type       string
+	SMTPHost   string
+	SMTPPort   string
+	SMTPUser   string
+	SMTPPass   string
+	SMTPFrom   string
+	WebhookURL string
+	// archivo donde el sender file agrega una línea JSON por mensaje; vacío solo los loguea
+	FilePath string
+}
+
+type RemindersConfig struct {
+	// horas antes de la clase en las que se envía el recordatorio; 0 los desactiva
+	LeadHours       int
+	IntervalMinutes int
+}
+
+var config *Config
+
+func Load() *Config {
+	if config != nil {
+		return config
+	}
+
+	log.SetOutput(os.Stderr)
+	// log.SetFormatter(&log.JSONFormatter{})
+	log.SetFormatter(&log.TextFormatter{
+		ForceColors:     true,
+		FullTimestamp:   true,
+		TimestampFormat: "02/01/2006-15:04:05.000",
+	})
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found or error loading .env file")
+	}
+
+	leadHours, err := strconv.Atoi(getEnv("REMINDER_HOURS_BEFORE", "24"))
+	if err != nil {
+		leadHours = 24
+	}
+
+	intervalMinutes, err := strconv.Atoi(getEnv("REMINDER_INTERVAL_MINUTES", "10"))
+	if err != nil || intervalMinutes <= 0 {
+		intervalMinutes = 10
+	}
+
+	secret := getEnv("JWT_SECRET", "")
+	if secret == "" {
+		log.Fatalf("no se pudo iniciar la aplicación, se debe especificar la variable de entorno JWT_SECRET")
+	}
+
+	config = &Config{
+		Port: getEnv("PORT", "8080"),
+		RabbitMQ: RabbitMQConfig{
+			Username:  getEnv("RABBITMQ_USER", "admin"),
+			Password:  getEnv("RABBITMQ_PASS", "admin"),
+			QueueName: getEnv("RABBITMQ_NOTIFICATIONS_QUEUE", "notifications"),
+			Host:      getEnv("RABBITMQ_HOST", "localhost"),
+			Port:      getEnv("RABBITMQ_PORT", "5672"),
+		},
+		UsersAPIURL:      getEnv("USERS_API_URL", "http://users-api:8080"),
+		ActivitiesAPIURL: getEnv("ACTIVITIES_API_URL", "http://activities-api:8080"),
+		JwtSecret:        secret,
+		Sender: SenderConfig{
+			Type:       getEnv("NOTIFICATIONS_SENDER", "file"),
+			SMTPHost:   getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:   getEnv("SMTP_PORT", "25"),
+			SMTPUser:   getEnv("SMTP_USER", ""),
+			SMTPPass:   getEnv("SMTP_PASS", ""),
+			SMTPFrom:   getEnv("SMTP_FROM", "no-reply@gimnasio.local"),
+			WebhookURL: getEnv("WEBHOOK_URL", ""),
+			FilePath:   getEnv("NOTIFICATIONS_FILE", ""),
+		},
+		Reminders: RemindersConfig{
+			LeadHours:       leadHours,
+			IntervalMinutes: intervalMinutes,
+		},
+		TemplatesDir: getEnv("TEMPLATES_DIR", ""),
+	}
+
+	log.Infoln("========== CONFIGURACIÓN ==========")
+	log.Infoln("PORT:", config.Port)
+	log.Infoln("RABBITMQ_USER:", config.RabbitMQ.Username)
+	log.Infoln("RABBITMQ_NOTIFICATIONS_QUEUE:", config.RabbitMQ.QueueName)
+	log.Infoln("RABBITMQ_HOST:", config.RabbitMQ.Host)
+	log.Infoln("RABBITMQ_PORT:", config.RabbitMQ.Port)
+	log.Infoln("USERS_API_URL:", config.UsersAPIURL)
+	log.Infoln("ACTIVITIES_API_URL:", config.ActivitiesAPIURL)
+	log.Infoln("NOTIFICATIONS_SENDER:", config.Sender.Type)
+	log.Infoln("SMTP_HOST:", config.Sender.SMTPHost)
+	log.Infoln("SMTP_PORT:", config.Sender.SMTPPort)
+	log.Infoln("SMTP_FROM:", config.Sender.SMTPFrom)
+	log.Infoln("WEBHOOK_URL:", config.Sender.WebhookURL)
+	log.Infoln("NOTIFICATIONS_FILE:", config.Sender.FilePath)
+	log.Infoln("REMINDER_HOURS_BEFORE:", config.Reminders.LeadHours)
+	log.Infoln("REMINDER_INTERVAL_MINUTES:", config.Reminders.IntervalMinutes)
+	log.Infoln("TEMPLATES_DIR:", config.TemplatesDir)
+	log.Infoln("===================================")
+
+	return config
+}
+
+func getEnv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
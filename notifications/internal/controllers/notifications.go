@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationsService interface {
+	Stats() (sent, failed int64)
+}
+
+type NotificationsController struct {
+	service    NotificationsService
+	senderType string
+}
+
+func NewNotificationsController(service NotificationsService, senderType string) *NotificationsController {
+	return &NotificationsController{service: service, senderType: senderType}
+}
+
+// Stats maneja GET /notifications/stats: sender configurado y cantidad de envíos desde el arranque
+func (c *NotificationsController) Stats(ctx *gin.Context) {
+	sent, failed := c.service.Stats()
+	ctx.JSON(http.StatusOK, gin.H{
+		"sender": c.senderType,
+		"sent":   sent,
+		"failed": failed,
+	})
+}
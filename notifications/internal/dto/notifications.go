@@ -0,0 +1,62 @@
+package dto
+
+import "time"
+
+// Tipos de eventos que se notifican. Los de clases e inscripciones los publica activities-api;
+// los recordatorios los genera este servicio.
+const (
+	EventActivityCancelled   = "activity.cancelled"
+	EventActivityRescheduled = "activity.rescheduled"
+	EventEnrollmentCreated   = "enrollment.created"
+	EventEnrollmentCancelled = "enrollment.cancelled"
+	EventActivityReminder    = "activity.reminder"
+)
+
+// ActivityEvent es el evento que publica activities-api en la cola de notificaciones. En las
+// inscripciones a la serie Fecha viene vacío y Dia indica el día de la semana.
+type ActivityEvent struct {
+	Type            string    `json:"type"`
+	ActivityID      string    `json:"id_actividad"`
+	Titulo          string    `json:"titulo"`
+	Dia             string    `json:"dia,omitempty"`
+	Fecha           string    `json:"fecha,omitempty"`
+	HoraInicio      string    `json:"hora_inicio"`
+	HoraFin         string    `json:"hora_fin"`
+	NuevaFecha      string    `json:"nueva_fecha,omitempty"`
+	NuevaHoraInicio string    `json:"nueva_hora_inicio,omitempty"`
+	NuevaHoraFin    string    `json:"nueva_hora_fin,omitempty"`
+	Motivo          string    `json:"motivo,omitempty"`
+	Usuarios        []int     `json:"usuarios"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// Occurrence es una clase próxima devuelta por activities-api con todos sus asistentes
+type Occurrence struct {
+	ActivityID     string `json:"id_actividad"`
+	Titulo         string `json:"titulo"`
+	Fecha          string `json:"fecha"`
+	NuevaFecha     string `json:"nueva_fecha,omitempty"`
+	DiaSemana      string `json:"dia"`
+	HoraInicio     string `json:"hora_inicio"`
+	HoraFin        string `json:"hora_fin"`
+	UsersInscribed []int  `json:"usuarios_inscritos"`
+}
+
+// User son los datos de contacto que devuelve users-api
+type User struct {
+	ID       int    `json:"id_usuario"`
+	Nombre   string `json:"nombre"`
+	Apellido string `json:"apellido"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// Message es una notificación lista para entregar a un usuario
+type Message struct {
+	Type    string `json:"type"`
+	UserID  int    `json:"id_usuario"`
+	To      string `json:"to"`
+	Nombre  string `json:"nombre"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
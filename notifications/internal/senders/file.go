@@ -0,0 +1,46 @@
+package senders
+
+import (
+	"context"
+	"encoding/json"
+	"notifications/internal/dto"
+	"os"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// FileSender es para pruebas locales: agrega cada notificación como una línea JSON al archivo
+// indicado o, sin archivo, solo la loguea
+type FileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSender(path string) *FileSender {
+	return &FileSender{path: path}
+}
+
+func (s *FileSender) Send(ctx context.Context, msg dto.Message) error {
+	if s.path == "" {
+		log.Infof("📧 [%s] para %s <%s>: %s\n%s", msg.Type, msg.Nombre, msg.To, msg.Subject, msg.Body)
+		return nil
+	}
+
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
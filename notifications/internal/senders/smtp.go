@@ -0,0 +1,48 @@
+package senders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"notifications/internal/dto"
+	"strings"
+)
+
+// SMTPSender envía las notificaciones por mail
+type SMTPSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPSender(host, port, user, pass, from string) *SMTPSender {
+	var auth smtp.Auth
+	if user != "" {
+		auth = smtp.PlainAuth("", user, pass, host)
+	}
+	return &SMTPSender{addr: net.JoinHostPort(host, port), auth: auth, from: from}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg dto.Message) error {
+	if msg.To == "" {
+		return errors.New("recipient has no email")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	// net/smtp no recibe contexto: se respeta al menos una cancelación previa al envío
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, []byte(b.String()))
+}
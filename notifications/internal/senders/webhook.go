@@ -0,0 +1,46 @@
+package senders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"notifications/internal/dto"
+	"time"
+)
+
+// WebhookSender publica cada notificación como JSON en una URL (por ejemplo un bot de chat o un
+// proveedor de SMS/push)
+type WebhookSender struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSender(url string) *WebhookSender {
+	return &WebhookSender{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *WebhookSender) Send(ctx context.Context, msg dto.Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"notifications/internal/dto"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrUnknownEvent = errors.New("no template for event type")
+
+type UsersClient interface {
+	GetByID(ctx context.Context, id int) (dto.User, error)
+}
+
+// Sender entrega un mensaje ya renderizado (SMTP, webhook, archivo, ...)
+type Sender interface {
+	Send(ctx context.Context, msg dto.Message) error
+}
+
+type NotificationsService struct {
+	users     UsersClient
+	sender    Sender
+	templates *Templates
+
+	sent   atomic.Int64
+	failed atomic.Int64
+}
+
+func NewNotificationsService(users UsersClient, sender Sender, templates *Templates) *NotificationsService {
+	return &NotificationsService{
+		users:     users,
+		sender:    sender,
+		templates: templates,
+	}
+}
+
+// HandleEvent notifica a cada usuario del evento. Los fallos de un usuario se loguean y no
+// impiden notificar al resto; solo se devuelve error si no se pudo notificar a ninguno (así el
+// mensaje se puede reintentar sin duplicar avisos).
+func (s *NotificationsService) HandleEvent(ctx context.Context, event dto.ActivityEvent) error {
+	if len(event.Usuarios) == 0 {
+		log.Debugf("evento %s de actividad %s sin usuarios para notificar", event.Type, event.ActivityID)
+		return nil
+	}
+
+	var errs []error
+	delivered := 0
+	for _, userID := range event.Usuarios {
+		if err := s.notifyUser(ctx, event, userID); err != nil {
+			if errors.Is(err, ErrUnknownEvent) {
+				// ningún usuario puede recibir este evento: no tiene sentido reintentarlo
+				log.Warnf("evento %s ignorado: %v", event.Type, err)
+				return nil
+			}
+			s.failed.Add(1)
+			log.Errorf("no se pudo notificar %s al usuario %d: %v", event.Type, userID, err)
+			errs = append(errs, err)
+			continue
+		}
+		s.sent.Add(1)
+		delivered++
+	}
+
+	if delivered == 0 {
+		return errors.Join(errs...)
+	}
+	log.Infof("evento %s de actividad %s notificado a %d/%d usuarios", event.Type, event.ActivityID, delivered, len(event.Usuarios))
+	return nil
+}
+
+func (s *NotificationsService) notifyUser(ctx context.Context, event dto.ActivityEvent, userID int) error {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error getting user: %w", err)
+	}
+
+	subject, body, err := s.templates.Render(event.Type, TemplateData{User: user, Event: event})
+	if err != nil {
+		return err
+	}
+
+	return s.sender.Send(ctx, dto.Message{
+		Type:    event.Type,
+		UserID:  user.ID,
+		To:      user.Email,
+		Nombre:  user.Nombre,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// Stats devuelve la cantidad de notificaciones enviadas y fallidas desde que arrancó el servicio
+func (s *NotificationsService) Stats() (sent, failed int64) {
+	return s.sent.Load(), s.failed.Load()
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"notifications/internal/dto"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockUsers struct {
+	users map[int]dto.User
+}
+
+func (m *mockUsers) GetByID(ctx context.Context, id int) (dto.User, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return dto.User{}, errors.New("user not found")
+	}
+	return user, nil
+}
+
+type mockSender struct {
+	messages []dto.Message
+	err      error
+}
+
+func (m *mockSender) Send(ctx context.Context, msg dto.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+type mockActivities struct {
+	from, to    time.Time
+	occurrences []dto.Occurrence
+}
+
+func (m *mockActivities) Upcoming(ctx context.Context, from, to time.Time) ([]dto.Occurrence, error) {
+	m.from, m.to = from, to
+	return m.occurrences, nil
+}
+
+var testUsers = &mockUsers{users: map[int]dto.User{
+	1: {ID: 1, Nombre: "Ana", Email: "ana@example.com"},
+	2: {ID: 2, Nombre: "Juan", Email: "juan@example.com"},
+}}
+
+func loadTestTemplates(t *testing.T) *Templates {
+	t.Helper()
+	templates, err := LoadTemplates("")
+	if err != nil {
+		t.Fatalf("error loading templates: %v", err)
+	}
+	return templates
+}
+
+// TestTemplates checks that every event type has a template that renders
+func TestTemplates(t *testing.T) {
+	templates := loadTestTemplates(t)
+	event := dto.ActivityEvent{
+		ActivityID:      "1",
+		Titulo:          "Yoga",
+		Dia:             "Lunes",
+		Fecha:           "2026-03-02",
+		HoraInicio:      "10:00",
+		HoraFin:         "11:00",
+		NuevaFecha:      "2026-03-03",
+		NuevaHoraInicio: "18:00",
+		NuevaHoraFin:    "19:00",
+		Motivo:          "Instructor enfermo",
+	}
+
+	for _, eventType := range []string{
+		dto.EventActivityCancelled,
+		dto.EventActivityRescheduled,
+		dto.EventEnrollmentCreated,
+		dto.EventEnrollmentCancelled,
+		dto.EventActivityReminder,
+	} {
+		t.Run(eventType, func(t *testing.T) {
+			event.Type = eventType
+			subject, body, err := templates.Render(eventType, TemplateData{User: testUsers.users[1], Event: event})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !strings.Contains(subject, "Yoga") || !strings.Contains(body, "Ana") {
+				t.Errorf("unexpected message %q / %q", subject, body)
+			}
+		})
+	}
+
+	if _, _, err := templates.Render("unknown", TemplateData{}); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("expected ErrUnknownEvent, got %v", err)
+	}
+}
+
+// TestHandleEvent tests the delivery of an event to its users
+func TestHandleEvent(t *testing.T) {
+	ctx := context.Background()
+	event := dto.ActivityEvent{
+		Type:       dto.EventActivityCancelled,
+		ActivityID: "1",
+		Titulo:     "Yoga",
+		Fecha:      "2026-03-02",
+		HoraInicio: "10:00",
+		Motivo:     "Feriado",
+		Usuarios:   []int{1, 2, 3},
+	}
+
+	t.Run("partial failure", func(t *testing.T) {
+		sender := &mockSender{}
+		service := NewNotificationsService(testUsers, sender, loadTestTemplates(t))
+
+		// el usuario 3 no existe: no impide notificar al resto
+		if err := service.HandleEvent(ctx, event); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(sender.messages) != 2 {
+			t.Fatalf("expected 2 messages, got %d", len(sender.messages))
+		}
+		if sender.messages[0].To != "ana@example.com" || !strings.Contains(sender.messages[0].Body, "Feriado") {
+			t.Errorf("unexpected message %+v", sender.messages[0])
+		}
+		if sent, failed := service.Stats(); sent != 2 || failed != 1 {
+			t.Errorf("expected 2 sent and 1 failed, got %d and %d", sent, failed)
+		}
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		service := NewNotificationsService(testUsers, &mockSender{err: errors.New("smtp down")}, loadTestTemplates(t))
+
+		if err := service.HandleEvent(ctx, event); err == nil {
+			t.Error("expected error when no user could be notified")
+		}
+	})
+
+	t.Run("unknown event", func(t *testing.T) {
+		sender := &mockSender{}
+		service := NewNotificationsService(testUsers, sender, loadTestTemplates(t))
+
+		unknown := event
+		unknown.Type = "activity.unknown"
+		if err := service.HandleEvent(ctx, unknown); err != nil {
+			t.Errorf("expected unknown events to be dropped, got %v", err)
+		}
+		if len(sender.messages) != 0 {
+			t.Errorf("expected no messages, got %d", len(sender.messages))
+		}
+	})
+}
+
+// TestReminders tests the reminder window of each cycle
+func TestReminders(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	activities := &mockActivities{occurrences: []dto.Occurrence{{
+		ActivityID:     "1",
+		Titulo:         "Yoga",
+		Fecha:          "2026-03-02",
+		NuevaFecha:     "2026-03-03",
+		HoraInicio:     "12:05",
+		UsersInscribed: []int{1},
+	}}}
+	sender := &mockSender{}
+	scheduler := NewReminderScheduler(activities, NewNotificationsService(testUsers, sender, loadTestTemplates(t)), 24*time.Hour, 10*time.Minute)
+	scheduler.now = func() time.Time { return now }
+
+	last := scheduler.runOnce(ctx, now.Add(-10*time.Minute))
+
+	if !last.Equal(now) {
+		t.Errorf("expected next cycle to start at %v, got %v", now, last)
+	}
+	if !activities.from.Equal(now.Add(24*time.Hour-10*time.Minute)) || !activities.to.Equal(now.Add(24*time.Hour)) {
+		t.Errorf("unexpected window %v - %v", activities.from, activities.to)
+	}
+	if len(sender.messages) != 1 || sender.messages[0].Type != dto.EventActivityReminder || !strings.Contains(sender.messages[0].Subject, "2026-03-03") {
+		t.Errorf("unexpected messages %+v", sender.messages)
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"notifications/internal/dto"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxReminderWindow limita cuánto se recupera si activities-api estuvo caído varios ciclos
+const maxReminderWindow = 24 * time.Hour
+
+type ActivitiesClient interface {
+	Upcoming(ctx context.Context, from, to time.Time) ([]dto.Occurrence, error)
+}
+
+// ReminderScheduler envía los recordatorios de las clases que empiezan dentro de lead. En cada
+// ciclo consulta las clases que empiezan en [último ciclo + lead, ahora + lead), de modo que cada
+// clase cae en un único ciclo.
+type ReminderScheduler struct {
+	activities    ActivitiesClient
+	notifications *NotificationsService
+	lead          time.Duration
+	interval      time.Duration
+	now           func() time.Time
+}
+
+func NewReminderScheduler(activities ActivitiesClient, notifications *NotificationsService, lead, interval time.Duration) *ReminderScheduler {
+	return &ReminderScheduler{
+		activities:    activities,
+		notifications: notifications,
+		lead:          lead,
+		interval:      interval,
+		now:           time.Now,
+	}
+}
+
+// Run ejecuta los ciclos hasta que se cancele el contexto
+func (r *ReminderScheduler) Run(ctx context.Context) {
+	log.Infof("⏰ recordatorios %v antes de cada clase, revisando cada %v", r.lead, r.interval)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	last := r.now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			last = r.runOnce(ctx, last)
+		}
+	}
+}
+
+// runOnce envía los recordatorios pendientes desde last y devuelve desde dónde seguir en el
+// próximo ciclo (last si la consulta falló, para reintentarla)
+func (r *ReminderScheduler) runOnce(ctx context.Context, last time.Time) time.Time {
+	now := r.now()
+	if now.Sub(last) > maxReminderWindow {
+		last = now.Add(-maxReminderWindow)
+	}
+
+	occurrences, err := r.activities.Upcoming(ctx, last.Add(r.lead), now.Add(r.lead))
+	if err != nil {
+		log.Errorf("error al obtener clases próximas para recordatorios: %v", err)
+		return last
+	}
+
+	for _, occurrence := range occurrences {
+		fecha := occurrence.Fecha
+		if occurrence.NuevaFecha != "" {
+			fecha = occurrence.NuevaFecha
+		}
+		event := dto.ActivityEvent{
+			Type:       dto.EventActivityReminder,
+			ActivityID: occurrence.ActivityID,
+			Titulo:     occurrence.Titulo,
+			Dia:        occurrence.DiaSemana,
+			Fecha:      fecha,
+			HoraInicio: occurrence.HoraInicio,
+			HoraFin:    occurrence.HoraFin,
+			Usuarios:   occurrence.UsersInscribed,
+			Timestamp:  now.UTC(),
+		}
+		if err := r.notifications.HandleEvent(ctx, event); err != nil {
+			log.Errorf("error al enviar recordatorios de %s %s: %v", occurrence.ActivityID, fecha, err)
+		}
+	}
+	return now
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"embed"
+	"fmt"
+	"notifications/internal/dto"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// TemplateData es lo que recibe cada plantilla
+type TemplateData struct {
+	User  dto.User
+	Event dto.ActivityEvent
+}
+
+// Templates tiene una plantilla por tipo de evento (archivo <tipo>.tmpl) con los bloques
+// "subject" y "body"
+type Templates struct {
+	byType map[string]*template.Template
+}
+
+// LoadTemplates carga las plantillas embebidas; si dir no está vacío, los archivos <tipo>.tmpl
+// de ese directorio reemplazan a los de por defecto
+func LoadTemplates(dir string) (*Templates, error) {
+	t := &Templates{byType: map[string]*template.Template{}}
+
+	entries, err := defaultTemplates.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		content, err := defaultTemplates.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if err := t.add(entry.Name(), string(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir == "" {
+		return t, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.add(filepath.Base(file), string(content)); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (t *Templates) add(fileName, content string) error {
+	eventType := strings.TrimSuffix(fileName, ".tmpl")
+	tmpl, err := template.New(eventType).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return fmt.Errorf("invalid template %s: %w", fileName, err)
+	}
+	for _, block := range []string{"subject", "body"} {
+		if tmpl.Lookup(block) == nil {
+			return fmt.Errorf("template %s must define %q", fileName, block)
+		}
+	}
+	t.byType[eventType] = tmpl
+	return nil
+}
+
+// Render devuelve el asunto y el cuerpo del mensaje para el tipo de evento
+func (t *Templates) Render(eventType string, data TemplateData) (string, string, error) {
+	tmpl, ok := t.byType[eventType]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrUnknownEvent, eventType)
+	}
+
+	var subject, body strings.Builder
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(subject.String()), strings.TrimSpace(body.String()) + "\n", nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"search/internal/clients"
 	"search/internal/config"
@@ -20,18 +21,37 @@ func main() {
 	cfg := config.Load()
 	ctx := context.Background()
 
-	activitiesLocalCacheRepo := repository.NewActivitysLocalCacheRepository(1 * time.Hour)
+	staleTTL := time.Duration(cfg.Memcached.StaleTTLSeconds) * time.Second
+
+	activitiesLocalCacheRepo := repository.NewActivitysLocalCacheRepository(1*time.Hour, staleTTL)
 
 	activiesMemcachedRepo := repository.NewMemcachedActivitiesRepository(
 		cfg.Memcached.Host,
 		cfg.Memcached.Port,
 		time.Duration(cfg.Memcached.TTLSeconds)*time.Second,
+		staleTTL,
 	)
 
+	// El core configurado es un alias de SolrCloud; si todavía no existe se crea vacío y se
+	// llena en segundo plano (primer arranque o volumen de Solr nuevo)
+	solrAdmin := clients.NewSolrAdminClient(cfg.Solr.Host, cfg.Solr.Port)
+	indexerFor := func(collection string) services.CollectionIndexer {
+		return clients.NewSolrClient(cfg.Solr.Host, cfg.Solr.Port, collection, time.Duration(cfg.Solr.CommitWithinMs)*time.Millisecond, nil)
+	}
+	rebuildService := services.NewIndexRebuildService(solrAdmin, indexerFor, cfg.Solr.Core, cfg.Solr.ConfigSet, cfg.Solr.KeepCollections)
+	created, err := rebuildService.EnsureAlias(ctx)
+	if err != nil {
+		log.Warnf("no se pudo verificar el alias de Solr %s: %v", cfg.Solr.Core, err)
+	} else if created {
+		go initialRebuild(ctx, rebuildService)
+	}
+
 	activitiesSolrRepo := repository.NewSolrActivitysRepository(
 		cfg.Solr.Host,
 		cfg.Solr.Port,
 		cfg.Solr.Core,
+		time.Duration(cfg.Solr.CommitWithinMs)*time.Millisecond,
+		clients.NewCircuitBreaker("solr", cfg.Solr.BreakerMaxFailures, time.Duration(cfg.Solr.BreakerOpenSeconds)*time.Second),
 	)
 
 	activiesQueue := clients.NewRabbitMQClient(
@@ -40,6 +60,8 @@ func main() {
 		cfg.RabbitMQ.QueueName,
 		cfg.RabbitMQ.Host,
 		cfg.RabbitMQ.Port,
+		cfg.RabbitMQ.BatchSize,
+		time.Duration(cfg.RabbitMQ.BatchWindowMs)*time.Millisecond,
 	)
 
 	activityService := services.NewActivitiesService(activitiesLocalCacheRepo, activiesMemcachedRepo, activitiesSolrRepo, activiesQueue)
@@ -70,3 +92,26 @@ func main() {
 		log.Fatalf("server error: %v", err)
 	}
 }
+
+// initialRebuild llena el índice recién creado con las actividades existentes. Reintenta mientras
+// el servicio de actividades no responda; si no lo logra hay que correr /bin/rebuild a mano.
+func initialRebuild(ctx context.Context, rebuildService services.IndexRebuildService) {
+	const attempts = 10
+	for i := 1; i <= attempts; i++ {
+		result, err := rebuildService.Rebuild(ctx)
+		if err == nil {
+			log.Infof("indice inicial construido en %s con %d actividades", result.Collection, result.Documents)
+			return
+		}
+		log.Warnf("no se pudo construir el indice inicial (intento %d/%d): %v", i, attempts, err)
+		if errors.Is(err, services.ErrReconcileFailed) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(15 * time.Second):
+		}
+	}
+	log.Errorf("indice inicial vacio: correr /bin/rebuild cuando el servicio de actividades este disponible")
+}
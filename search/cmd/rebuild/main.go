@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"search/internal/clients"
+	"search/internal/config"
+	"search/internal/repository"
+	"search/internal/services"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// rebuild reconstruye el índice de Solr en una colección nueva y mueve el alias SOLR_CORE
+// hacia ella. Con -rollback vuelve a apuntar el alias a la colección anterior.
+func main() {
+	rollback := flag.Bool("rollback", false, "volver a apuntar el alias a la coleccion anterior")
+	flag.Parse()
+
+	cfg := config.Load()
+	ctx := context.Background()
+
+	admin := clients.NewSolrAdminClient(cfg.Solr.Host, cfg.Solr.Port)
+	indexerFor := func(collection string) services.CollectionIndexer {
+		return clients.NewSolrClient(cfg.Solr.Host, cfg.Solr.Port, collection, time.Duration(cfg.Solr.CommitWithinMs)*time.Millisecond, nil)
+	}
+	rebuildService := services.NewIndexRebuildService(admin, indexerFor, cfg.Solr.Core, cfg.Solr.ConfigSet, cfg.Solr.KeepCollections)
+
+	var result services.RebuildResult
+	var err error
+	if *rollback {
+		log.Infof("Rolling back alias %s...", cfg.Solr.Core)
+		result, err = rebuildService.Rollback(ctx)
+	} else {
+		log.Infof("Rebuilding index for alias %s...", cfg.Solr.Core)
+		result, err = rebuildService.Rebuild(ctx)
+	}
+	if errors.Is(err, services.ErrReconcileFailed) {
+		// el alias ya se movió: se sigue para limpiar la caché
+		log.Errorf("Index rebuilt but %v; run the activities reindex to apply them", err)
+	} else if err != nil {
+		log.Errorf("Index rebuild failed: %v", err)
+		os.Exit(1)
+	}
+
+	// la caché distribuida puede tener resultados de la colección anterior
+	memcached := repository.NewMemcachedActivitiesRepository(
+		cfg.Memcached.Host,
+		cfg.Memcached.Port,
+		time.Duration(cfg.Memcached.TTLSeconds)*time.Second,
+		time.Duration(cfg.Memcached.StaleTTLSeconds)*time.Second,
+	)
+	if err := memcached.FlushAll(); err != nil {
+		log.Warnf("Failed to flush memcached: %v", err)
+	}
+
+	log.Info("=== Index Rebuild Summary ===")
+	log.Infof("Alias: %s", result.Alias)
+	log.Infof("Active collection: %s", result.Collection)
+	log.Infof("Previous collection: %s", result.Previous)
+	log.Infof("Documents: %d", result.Documents)
+	log.Infof("Reconciled: %d", result.Reconciled)
+	log.Info("=============================")
+
+	if err != nil {
+		os.Exit(1)
+	}
+}
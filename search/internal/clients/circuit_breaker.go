@@ -0,0 +1,136 @@
+package clients
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// CircuitBreaker corta las llamadas a un servicio externo después de maxFailures fallos
+// consecutivos. Pasado openTimeout deja pasar una única llamada de prueba (half-open):
+// si funciona se vuelve a cerrar, si falla se abre otra vez.
+// Un *CircuitBreaker nil deja pasar todas las llamadas.
+type CircuitBreaker struct {
+	name        string
+	maxFailures int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+func NewCircuitBreaker(name string, maxFailures int, openTimeout time.Duration) *CircuitBreaker {
+	if maxFailures <= 0 {
+		maxFailures = 5
+	}
+	if openTimeout <= 0 {
+		openTimeout = 30 * time.Second
+	}
+	return &CircuitBreaker{
+		name:        name,
+		maxFailures: maxFailures,
+		openTimeout: openTimeout,
+	}
+}
+
+// Allow indica si se puede realizar la llamada. Devuelve ErrCircuitOpen si el circuito está abierto.
+func (cb *CircuitBreaker) Allow() error {
+	if cb == nil {
+		return nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if time.Since(cb.openedAt) < cb.openTimeout {
+			return ErrCircuitOpen
+		}
+		cb.state = circuitHalfOpen
+		log.Infof("circuit breaker %s half-open: permitiendo llamada de prueba", cb.name)
+		return nil
+	case circuitHalfOpen:
+		// ya hay una llamada de prueba en curso
+		return ErrCircuitOpen
+	default:
+		return nil
+	}
+}
+
+// Record registra el resultado de una llamada permitida por Allow
+func (cb *CircuitBreaker) Record(success bool) {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if success {
+		if cb.state != circuitClosed {
+			log.Infof("circuit breaker %s cerrado", cb.name)
+		}
+		cb.state = circuitClosed
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	if cb.state == circuitHalfOpen || cb.failures >= cb.maxFailures {
+		if cb.state != circuitOpen {
+			log.Warnf("circuit breaker %s abierto tras %d fallos", cb.name, cb.failures)
+		}
+		cb.state = circuitOpen
+		cb.openedAt = time.Now()
+	}
+}
+
+// Release libera una llamada permitida por Allow sin contarla como éxito ni como fallo,
+// por ejemplo cuando la cancela el propio cliente. Si era la llamada de prueba, el circuito
+// vuelve a quedar abierto y el próximo Allow deja pasar otra prueba.
+func (cb *CircuitBreaker) Release() {
+	if cb == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == circuitHalfOpen {
+		cb.state = circuitOpen
+	}
+}
+
+// RetryAfter devuelve cuánto falta para que el circuito deje pasar una llamada de prueba
+func (cb *CircuitBreaker) RetryAfter() time.Duration {
+	if cb == nil {
+		return 0
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == circuitClosed {
+		return 0
+	}
+	remaining := cb.openTimeout - time.Since(cb.openedAt)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
@@ -0,0 +1,93 @@
+package clients
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestCircuitBreaker tests the transitions between the closed, open and half-open states
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("opens after max failures", func(t *testing.T) {
+		cb := NewCircuitBreaker("test", 2, time.Minute)
+		cb.Record(false)
+		if err := cb.Allow(); err != nil {
+			t.Fatalf("expected closed circuit after 1 failure, got %v", err)
+		}
+		cb.Record(false)
+		if err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected ErrCircuitOpen, got %v", err)
+		}
+		if retry := cb.RetryAfter(); retry <= 0 || retry > time.Minute {
+			t.Errorf("expected retry after within the open timeout, got %v", retry)
+		}
+	})
+
+	t.Run("success resets the failures", func(t *testing.T) {
+		cb := NewCircuitBreaker("test", 2, time.Minute)
+		cb.Record(false)
+		cb.Record(true)
+		cb.Record(false)
+		if err := cb.Allow(); err != nil {
+			t.Errorf("expected closed circuit, got %v", err)
+		}
+		if retry := cb.RetryAfter(); retry != 0 {
+			t.Errorf("expected no retry after on a closed circuit, got %v", retry)
+		}
+	})
+
+	t.Run("half-open allows a single trial that closes the circuit", func(t *testing.T) {
+		cb := NewCircuitBreaker("test", 1, 10*time.Millisecond)
+		cb.Record(false)
+		time.Sleep(20 * time.Millisecond)
+
+		if err := cb.Allow(); err != nil {
+			t.Fatalf("expected the trial call to be allowed, got %v", err)
+		}
+		if err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected a second call during the trial to be rejected, got %v", err)
+		}
+		cb.Record(true)
+		if err := cb.Allow(); err != nil {
+			t.Errorf("expected closed circuit after a successful trial, got %v", err)
+		}
+	})
+
+	t.Run("failed trial opens the circuit again", func(t *testing.T) {
+		cb := NewCircuitBreaker("test", 3, 10*time.Millisecond)
+		for i := 0; i < 3; i++ {
+			cb.Record(false)
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		if err := cb.Allow(); err != nil {
+			t.Fatalf("expected the trial call to be allowed, got %v", err)
+		}
+		cb.Record(false)
+		if err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("expected the circuit to open again, got %v", err)
+		}
+	})
+
+	t.Run("released trial allows another trial", func(t *testing.T) {
+		cb := NewCircuitBreaker("test", 1, 10*time.Millisecond)
+		cb.Record(false)
+		time.Sleep(20 * time.Millisecond)
+
+		if err := cb.Allow(); err != nil {
+			t.Fatalf("expected the trial call to be allowed, got %v", err)
+		}
+		cb.Release()
+		if err := cb.Allow(); err != nil {
+			t.Errorf("expected a new trial after the release, got %v", err)
+		}
+	})
+
+	t.Run("nil breaker allows every call", func(t *testing.T) {
+		var cb *CircuitBreaker
+		cb.Record(false)
+		if err := cb.Allow(); err != nil || cb.RetryAfter() != 0 {
+			t.Errorf("expected nil breaker to allow calls, got %v", err)
+		}
+	})
+}
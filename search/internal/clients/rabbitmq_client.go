@@ -13,12 +13,37 @@ import (
 )
 
 type RabbitMQClient struct {
-	connection *amqp091.Connection
-	channel    *amqp091.Channel
-	queue      *amqp091.Queue
+	connection  *amqp091.Connection
+	channel     *amqp091.Channel
+	queue       *amqp091.Queue
+	batchSize   int
+	batchWindow time.Duration
+	retryDelay  time.Duration // espera después de un lote fallido
+
+	// deadLetter guarda un mensaje que no se pudo procesar ni al reintentarlo. Si es nil
+	// esos mensajes se reencolan siempre.
+	deadLetter func(ctx context.Context, msg amqp091.Delivery) error
 }
 
-func NewRabbitMQClient(user, password, queueName, host, port string) *RabbitMQClient {
+const (
+	defaultBatchSize   = 100
+	defaultBatchWindow = 500 * time.Millisecond
+	defaultRetryDelay  = 5 * time.Second
+
+	// deadLetterSuffix se agrega al nombre de la cola para la cola de mensajes descartados
+	deadLetterSuffix = ".dlq"
+)
+
+// NewRabbitMQClient conecta con RabbitMQ. batchSize y batchWindow controlan cuántos eventos
+// acumula ConsumeBatch (y durante cuánto tiempo como máximo) antes de invocar al handler.
+func NewRabbitMQClient(user, password, queueName, host, port string, batchSize int, batchWindow time.Duration) *RabbitMQClient {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if batchWindow <= 0 {
+		batchWindow = defaultBatchWindow
+	}
+
 	connStr := fmt.Sprintf("amqp://%s:%s@%s:%s/", user, password, host, port)
 
 	var connection *amqp091.Connection
@@ -59,16 +84,50 @@ func NewRabbitMQClient(user, password, queueName, host, port string) *RabbitMQCl
 		log.Fatalf("failed to declare a queue: %v", err)
 	}
 
+	// Cola donde quedan los mensajes que fallan aun procesándolos de a uno, para revisarlos
+	// o volver a publicarlos a mano sin que bloqueen la cola principal
+	deadLetterQueue, err := channel.QueueDeclare(queueName+deadLetterSuffix, true, false, false, false, nil)
+	if err != nil {
+		log.Fatalf("failed to declare the dead letter queue: %v", err)
+	}
+
+	// Prefetch suficiente para poder completar un lote sin esperar a que se confirme el anterior
+	if err := channel.Qos(batchSize*2, 0, false); err != nil {
+		log.Fatalf("failed to set channel QoS: %v", err)
+	}
+
 	log.Infof("Successfully connected to RabbitMQ at %s:%s", host, port)
-	return &RabbitMQClient{connection: connection, channel: channel, queue: &queue}
+	client := &RabbitMQClient{
+		connection:  connection,
+		channel:     channel,
+		queue:       &queue,
+		batchSize:   batchSize,
+		batchWindow: batchWindow,
+		retryDelay:  defaultRetryDelay,
+	}
+	client.deadLetter = func(ctx context.Context, msg amqp091.Delivery) error {
+		pubCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		return channel.PublishWithContext(pubCtx, "", deadLetterQueue.Name, false, false, amqp091.Publishing{
+			ContentType:  msg.ContentType,
+			Headers:      msg.Headers,
+			Body:         msg.Body,
+			DeliveryMode: amqp091.Persistent,
+		})
+	}
+	return client
 }
 
-func (r *RabbitMQClient) Consume(ctx context.Context, handler func(context.Context, services.ActivityEvent) error) error {
+// ConsumeBatch acumula eventos hasta alcanzar batchSize o hasta que pase batchWindow desde
+// el primer evento del lote, y entonces invoca al handler con el lote completo. Los mensajes
+// se confirman (ack) una vez procesado el lote; si el handler falla se procesan de a uno
+// (ver handleOneByOne).
+func (r *RabbitMQClient) ConsumeBatch(ctx context.Context, handler func(context.Context, []services.ActivityEvent) error) error {
 	// Configurar el consumer
 	msgs, err := r.channel.Consume(
 		r.queue.Name, // queue
 		"",           // consumer
-		true,         // auto-ack
+		false,        // auto-ack
 		false,        // exclusive
 		false,        // no-local
 		false,        // no-wait
@@ -78,7 +137,62 @@ func (r *RabbitMQClient) Consume(ctx context.Context, handler func(context.Conte
 		return fmt.Errorf("failed to register consumer: %w", err)
 	}
 
-	log.Printf("🎯 Consumer registered for queue: %s", r.queue.Name)
+	log.Printf("🎯 Consumer registered for queue: %s (batch size: %d, window: %v)", r.queue.Name, r.batchSize, r.batchWindow)
+	return r.consumeBatches(ctx, msgs, handler)
+}
+
+// consumeBatches arma los lotes con los mensajes de msgs. Si un lote falla y ningún evento se
+// puede procesar ni siquiera de a uno, se reencola entero y se espera retryDelay antes de seguir,
+// para no reintentar en un ciclo mientras el servicio externo esté caído.
+func (r *RabbitMQClient) consumeBatches(ctx context.Context, msgs <-chan amqp091.Delivery, handler func(context.Context, []services.ActivityEvent) error) error {
+	batch := make([]services.ActivityEvent, 0, r.batchSize)
+	deliveries := make([]amqp091.Delivery, 0, r.batchSize) // mensaje de cada evento de batch
+	var invalid []amqp091.Delivery
+	var last amqp091.Delivery
+	var pending bool
+	var window <-chan time.Time
+
+	flush := func() {
+		var failed error
+		if len(batch) > 0 {
+			// Procesar lote
+			if err := handler(ctx, batch); err != nil {
+				log.Printf("❌ Error handling batch of %d messages: %v", len(batch), err)
+				failed = err
+			}
+		}
+		if pending {
+			if failed != nil {
+				if !r.handleOneByOne(ctx, batch, deliveries, failed, handler) {
+					if err := last.Nack(true, true); err != nil {
+						log.Printf("❌ Error requeueing messages: %v", err)
+					}
+				} else {
+					// los inválidos no se pueden procesar nunca: se confirman igual que con el lote
+					for _, msg := range invalid {
+						if err := msg.Ack(false); err != nil {
+							log.Printf("❌ Error acknowledging message: %v", err)
+						}
+					}
+					failed = nil
+				}
+			} else if err := last.Ack(true); err != nil {
+				log.Printf("❌ Error acknowledging messages: %v", err)
+			}
+		}
+		batch = batch[:0]
+		deliveries = deliveries[:0]
+		invalid = invalid[:0]
+		pending = false
+		window = nil
+
+		if failed != nil && r.retryDelay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(r.retryDelay):
+			}
+		}
+	}
 
 	// Loop infinito para consumir mensajes
 	for {
@@ -87,18 +201,83 @@ func (r *RabbitMQClient) Consume(ctx context.Context, handler func(context.Conte
 			log.Println("🛑 Consumer context cancelled")
 			return ctx.Err()
 
-		case msg := <-msgs:
+		case <-window:
+			flush()
+
+		case msg, ok := <-msgs:
+			if !ok {
+				flush()
+				return fmt.Errorf("consumer channel closed")
+			}
+
+			last = msg
+			pending = true
+
 			// Deserializar mensaje
 			var event services.ActivityEvent
 			if err := json.Unmarshal(msg.Body, &event); err != nil {
 				log.Printf("❌ Error unmarshalling message: %v", err)
-				continue
+				invalid = append(invalid, msg)
+			} else {
+				batch = append(batch, event)
+				deliveries = append(deliveries, msg)
 			}
 
-			// Procesar mensaje
-			if err := handler(ctx, event); err != nil {
-				log.Printf("❌ Error handling message: %v", err)
+			if window == nil {
+				window = time.After(r.batchWindow)
+			}
+			if len(batch) >= r.batchSize {
+				flush()
+			}
+		}
+	}
+}
+
+// handleOneByOne procesa de a uno los eventos de un lote fallido, para que un mensaje que no se
+// puede procesar (poison message) no haga reencolar el lote entero una y otra vez. Los eventos
+// que se procesan se confirman; los que fallan se reencolan y, si ya venían de un reintento, se
+// mueven a la cola de descartados. Si no se pudo procesar ninguno se asume que el servicio
+// externo está caído: no se toca ningún mensaje y devuelve false para que se reencole el lote.
+func (r *RabbitMQClient) handleOneByOne(ctx context.Context, events []services.ActivityEvent, deliveries []amqp091.Delivery, batchErr error, handler func(context.Context, []services.ActivityEvent) error) bool {
+	errs := make([]error, len(events))
+	processed := 0
+	for i, event := range events {
+		if len(events) == 1 {
+			// el lote ya era este único evento
+			errs[i] = batchErr
+		} else {
+			errs[i] = handler(ctx, []services.ActivityEvent{event})
+		}
+		if errs[i] == nil {
+			processed++
+		}
+	}
+	if processed == 0 {
+		return false
+	}
+
+	for i, msg := range deliveries {
+		switch {
+		case errs[i] == nil:
+			if err := msg.Ack(false); err != nil {
+				log.Printf("❌ Error acknowledging message: %v", err)
+			}
+		case msg.Redelivered && r.deadLetter != nil:
+			log.Printf("☠️ Moving message %s %s to the dead letter queue: %v", events[i].Action, events[i].ID, errs[i])
+			if err := r.deadLetter(ctx, msg); err != nil {
+				log.Printf("❌ Error moving message to the dead letter queue: %v", err)
+				if err := msg.Nack(false, true); err != nil {
+					log.Printf("❌ Error requeueing message: %v", err)
+				}
+			} else if err := msg.Ack(false); err != nil {
+				log.Printf("❌ Error acknowledging message: %v", err)
+			}
+		default:
+			log.Printf("❌ Error handling message %s %s, requeueing: %v", events[i].Action, events[i].ID, errs[i])
+			if err := msg.Nack(false, true); err != nil {
+				log.Printf("❌ Error requeueing message: %v", err)
 			}
 		}
 	}
+	return true
 }
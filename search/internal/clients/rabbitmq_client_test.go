@@ -0,0 +1,196 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"search/internal/services"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// mockAcknowledger registra los ack y nack de los mensajes
+type mockAcknowledger struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (m *mockAcknowledger) Ack(tag uint64, multiple bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, fmt.Sprintf("ack %d multiple=%t", tag, multiple))
+	return nil
+}
+
+func (m *mockAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, fmt.Sprintf("nack %d multiple=%t requeue=%t", tag, multiple, requeue))
+	return nil
+}
+
+func (m *mockAcknowledger) Reject(tag uint64, requeue bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, fmt.Sprintf("reject %d requeue=%t", tag, requeue))
+	return nil
+}
+
+func (m *mockAcknowledger) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+func delivery(ack amqp091.Acknowledger, tag uint64, body string) amqp091.Delivery {
+	return amqp091.Delivery{Acknowledger: ack, DeliveryTag: tag, Body: []byte(body)}
+}
+
+// runConsumer procesa los mensajes hasta que se cierra el canal y devuelve los lotes recibidos
+func runConsumer(t *testing.T, client *RabbitMQClient, messages []amqp091.Delivery, handlerErr error) [][]services.ActivityEvent {
+	t.Helper()
+	msgs := make(chan amqp091.Delivery, len(messages))
+	for _, msg := range messages {
+		msgs <- msg
+	}
+	close(msgs)
+
+	var batches [][]services.ActivityEvent
+	handler := func(ctx context.Context, events []services.ActivityEvent) error {
+		batches = append(batches, append([]services.ActivityEvent(nil), events...))
+		return handlerErr
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.consumeBatches(ctx, msgs, handler); err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected consumer channel closed error, got %v", err)
+	}
+	return batches
+}
+
+// TestConsumeBatches tests the batching, ack and requeue of the activities events
+func TestConsumeBatches(t *testing.T) {
+	t.Run("flush when the batch is full", func(t *testing.T) {
+		ack := &mockAcknowledger{}
+		client := &RabbitMQClient{batchSize: 2, batchWindow: time.Minute}
+
+		batches := runConsumer(t, client, []amqp091.Delivery{
+			delivery(ack, 1, `{"action":"create","id":"a"}`),
+			delivery(ack, 2, `{"action":"update","id":"b"}`),
+			delivery(ack, 3, `{"action":"delete","id":"c"}`),
+		}, nil)
+
+		if len(batches) != 2 || len(batches[0]) != 2 || batches[1][0].ID != "c" {
+			t.Errorf("expected batches of 2 and 1 events, got %+v", batches)
+		}
+		calls := ack.Calls()
+		if len(calls) != 2 || calls[0] != "ack 2 multiple=true" || calls[1] != "ack 3 multiple=true" {
+			t.Errorf("unexpected acks %v", calls)
+		}
+	})
+
+	t.Run("flush when the window ends", func(t *testing.T) {
+		ack := &mockAcknowledger{}
+		client := &RabbitMQClient{batchSize: 10, batchWindow: 10 * time.Millisecond}
+		msgs := make(chan amqp091.Delivery, 1)
+		flushed := make(chan []services.ActivityEvent, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go client.consumeBatches(ctx, msgs, func(ctx context.Context, events []services.ActivityEvent) error {
+			flushed <- events
+			return nil
+		})
+		msgs <- delivery(ack, 1, `{"action":"create","id":"a"}`)
+
+		select {
+		case events := <-flushed:
+			if len(events) != 1 || events[0].ID != "a" {
+				t.Errorf("unexpected batch %+v", events)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected the batch to be flushed after the window")
+		}
+	})
+
+	t.Run("requeue the batch when the handler fails", func(t *testing.T) {
+		ack := &mockAcknowledger{}
+		client := &RabbitMQClient{batchSize: 2, batchWindow: time.Minute}
+
+		runConsumer(t, client, []amqp091.Delivery{
+			delivery(ack, 1, `{"action":"create","id":"a"}`),
+			delivery(ack, 2, `{"action":"update","id":"b"}`),
+		}, errors.New("solr unavailable"))
+
+		calls := ack.Calls()
+		if len(calls) != 1 || calls[0] != "nack 2 multiple=true requeue=true" {
+			t.Errorf("expected the batch to be requeued, got %v", calls)
+		}
+	})
+
+	t.Run("a failing message does not block the rest of the batch", func(t *testing.T) {
+		ack := &mockAcknowledger{}
+		var deadLetters []string
+		client := &RabbitMQClient{batchSize: 4, batchWindow: time.Minute}
+		client.deadLetter = func(ctx context.Context, msg amqp091.Delivery) error {
+			deadLetters = append(deadLetters, string(msg.Body))
+			return nil
+		}
+		poison := delivery(ack, 2, `{"action":"create","id":"bad"}`)
+		poison.Redelivered = true
+
+		msgs := make(chan amqp091.Delivery, 4)
+		msgs <- delivery(ack, 1, `{"action":"create","id":"a"}`)
+		msgs <- poison
+		msgs <- delivery(ack, 3, `{"action":"create","id":"bad2"}`)
+		msgs <- delivery(ack, 4, `not json`)
+		close(msgs)
+
+		var handled []string
+		handler := func(ctx context.Context, events []services.ActivityEvent) error {
+			for _, event := range events {
+				if event.ID != "a" {
+					return errors.New("invalid document")
+				}
+			}
+			handled = append(handled, events[0].ID)
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := client.consumeBatches(ctx, msgs, handler); err == nil || errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected consumer channel closed error, got %v", err)
+		}
+
+		if len(handled) != 1 || handled[0] != "a" {
+			t.Errorf("expected the valid event to be handled alone, got %v", handled)
+		}
+		if len(deadLetters) != 1 || deadLetters[0] != `{"action":"create","id":"bad"}` {
+			t.Errorf("expected only the redelivered message in the dead letter queue, got %v", deadLetters)
+		}
+		expected := []string{"ack 1 multiple=false", "ack 2 multiple=false", "nack 3 multiple=false requeue=true", "ack 4 multiple=false"}
+		if calls := ack.Calls(); fmt.Sprint(calls) != fmt.Sprint(expected) {
+			t.Errorf("expected %v, got %v", expected, calls)
+		}
+	})
+
+	t.Run("invalid messages are acknowledged with the batch", func(t *testing.T) {
+		ack := &mockAcknowledger{}
+		client := &RabbitMQClient{batchSize: 2, batchWindow: time.Minute}
+
+		batches := runConsumer(t, client, []amqp091.Delivery{
+			delivery(ack, 1, `not json`),
+			delivery(ack, 2, `{"action":"create","id":"a"}`),
+		}, nil)
+
+		if len(batches) != 1 || len(batches[0]) != 1 {
+			t.Errorf("expected only the valid event, got %+v", batches)
+		}
+		if calls := ack.Calls(); len(calls) != 1 || calls[0] != "ack 2 multiple=true" {
+			t.Errorf("unexpected acks %v", calls)
+		}
+	})
+}
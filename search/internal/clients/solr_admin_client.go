@@ -0,0 +1,134 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// SolrAdminClient usa la Collections API de Solr (modo SolrCloud) para administrar
+// colecciones y alias. Se usa para reconstruir el índice sin afectar a la colección en uso.
+type SolrAdminClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+type solrAdminResponse struct {
+	ResponseHeader struct {
+		Status int `json:"status"`
+		QTime  int `json:"QTime"`
+	} `json:"responseHeader"`
+	Error *struct {
+		Msg string `json:"msg"`
+	} `json:"error,omitempty"`
+	Collections []string          `json:"collections,omitempty"`
+	Aliases     map[string]string `json:"aliases,omitempty"`
+}
+
+func NewSolrAdminClient(host, port string) *SolrAdminClient {
+	return &SolrAdminClient{
+		baseURL: fmt.Sprintf("http://%s:%s/solr/admin/collections", host, port),
+		// crear una colección puede tardar bastante más que una búsqueda
+		client: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// CreateCollection crea una colección de un shard y una réplica usando el configset indicado
+func (s *SolrAdminClient) CreateCollection(ctx context.Context, name, configSet string) error {
+	params := url.Values{}
+	params.Set("action", "CREATE")
+	params.Set("name", name)
+	params.Set("numShards", "1")
+	params.Set("replicationFactor", "1")
+	params.Set("collection.configName", configSet)
+
+	if _, err := s.do(ctx, params); err != nil {
+		return fmt.Errorf("error creating collection %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteCollection elimina una colección y todos sus documentos
+func (s *SolrAdminClient) DeleteCollection(ctx context.Context, name string) error {
+	params := url.Values{}
+	params.Set("action", "DELETE")
+	params.Set("name", name)
+
+	if _, err := s.do(ctx, params); err != nil {
+		return fmt.Errorf("error deleting collection %s: %w", name, err)
+	}
+	return nil
+}
+
+// ListCollections devuelve los nombres de todas las colecciones existentes
+func (s *SolrAdminClient) ListCollections(ctx context.Context) ([]string, error) {
+	params := url.Values{}
+	params.Set("action", "LIST")
+
+	resp, err := s.do(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("error listing collections: %w", err)
+	}
+	return resp.Collections, nil
+}
+
+// GetAliases devuelve el mapa alias -> colección
+func (s *SolrAdminClient) GetAliases(ctx context.Context) (map[string]string, error) {
+	params := url.Values{}
+	params.Set("action", "LISTALIASES")
+
+	resp, err := s.do(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("error listing aliases: %w", err)
+	}
+	if resp.Aliases == nil {
+		return map[string]string{}, nil
+	}
+	return resp.Aliases, nil
+}
+
+// CreateAlias crea el alias o lo reasigna a otra colección. Solr realiza el cambio de forma atómica.
+func (s *SolrAdminClient) CreateAlias(ctx context.Context, alias, collection string) error {
+	params := url.Values{}
+	params.Set("action", "CREATEALIAS")
+	params.Set("name", alias)
+	params.Set("collections", collection)
+
+	if _, err := s.do(ctx, params); err != nil {
+		return fmt.Errorf("error pointing alias %s to %s: %w", alias, collection, err)
+	}
+	return nil
+}
+
+func (s *SolrAdminClient) do(ctx context.Context, params url.Values) (solrAdminResponse, error) {
+	params.Set("wt", "json")
+
+	url := fmt.Sprintf("%s?%s", s.baseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return solrAdminResponse{}, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return solrAdminResponse{}, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var adminResp solrAdminResponse
+	if err := json.NewDecoder(resp.Body).Decode(&adminResp); err != nil {
+		return solrAdminResponse{}, fmt.Errorf("error decoding response (status %d): %w", resp.StatusCode, err)
+	}
+
+	if resp.StatusCode != http.StatusOK || adminResp.ResponseHeader.Status != 0 {
+		if adminResp.Error != nil && adminResp.Error.Msg != "" {
+			return solrAdminResponse{}, fmt.Errorf("solr returned status %d: %s", resp.StatusCode, adminResp.Error.Msg)
+		}
+		return solrAdminResponse{}, fmt.Errorf("solr returned status %d", resp.StatusCode)
+	}
+
+	return adminResp, nil
+}
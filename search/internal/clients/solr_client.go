@@ -1,8 +1,10 @@
 package clients
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -12,9 +14,11 @@ import (
 )
 
 type SolrClient struct {
-	baseURL string
-	core    string
-	client  *http.Client
+	baseURL      string
+	core         string
+	client       *http.Client
+	commitWithin time.Duration
+	breaker      *CircuitBreaker
 }
 
 type SolrDocument struct {
@@ -30,6 +34,7 @@ type SolrResponse struct {
 		Start    int            `json:"start"`
 		Docs     []SolrDocument `json:"docs"`
 	} `json:"response"`
+	NextCursorMark string `json:"nextCursorMark,omitempty"`
 }
 
 type SolrUpdateResponse struct {
@@ -41,96 +46,126 @@ type SolrUpdateResponse struct {
 
 const (
 	defaultCount = 10
+
+	// maxBatchSize limita la cantidad de documentos enviados en un único request de update
+	maxBatchSize = 500
+
+	defaultCommitWithin = 1 * time.Second
 )
 
-func NewSolrClient(host, port, core string) *SolrClient {
+// NewSolrClient crea un cliente de Solr. commitWithin indica el plazo máximo en el que
+// Solr debe hacer visibles los cambios (soft commit); si es <= 0 se usa el valor por defecto.
+// Si breaker no es nil, todas las llamadas a Solr pasan por el circuit breaker.
+func NewSolrClient(host, port, core string, commitWithin time.Duration, breaker *CircuitBreaker) *SolrClient {
+	if commitWithin <= 0 {
+		commitWithin = defaultCommitWithin
+	}
+
 	baseURL := fmt.Sprintf("http://%s:%s/solr/%s", host, port, core)
 	return &SolrClient{
-		baseURL: baseURL,
-		core:    core,
-		client:  &http.Client{Timeout: 10 * time.Second},
+		baseURL:      baseURL,
+		core:         core,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		commitWithin: commitWithin,
+		breaker:      breaker,
 	}
 }
 
-func (s *SolrClient) Index(ctx context.Context, activity dto.Activity) error {
-	doc := SolrDocument{
-		ID:          activity.ID,
-		Titulo:      []string{activity.Titulo},
-		Descripcion: []string{activity.Descripcion},
-		DiaSemana:   []string{activity.DiaSemana},
-	}
+// RetryAfter indica cuánto falta para volver a intentar llamar a Solr si el circuit breaker está abierto
+func (s *SolrClient) RetryAfter() time.Duration {
+	return s.breaker.RetryAfter()
+}
 
-	data, err := json.Marshal([]SolrDocument{doc})
-	if err != nil {
-		return fmt.Errorf("error marshalling document: %w", err)
+// do ejecuta el request a través del circuit breaker. Los errores de red y las respuestas 5xx
+// cuentan como fallos; los 4xx no, ya que indican un problema del request y no de Solr.
+// Tampoco cuenta la cancelación o el vencimiento del contexto del request: la corta el cliente.
+func (s *SolrClient) do(req *http.Request) (*http.Response, error) {
+	if err := s.breaker.Allow(); err != nil {
+		return nil, err
 	}
-
-	url := fmt.Sprintf("%s/update?commit=true", s.baseURL)
-	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(data)))
-	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := s.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("error executing request: %w", err)
+	if err != nil && req.Context().Err() != nil && errors.Is(err, req.Context().Err()) {
+		s.breaker.Release()
+		return resp, err
 	}
-	defer resp.Body.Close()
+	s.breaker.Record(err == nil && resp.StatusCode < http.StatusInternalServerError)
+	return resp, err
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("solr returned status %d", resp.StatusCode)
-	}
+func (s *SolrClient) Index(ctx context.Context, activity dto.Activity) error {
+	return s.IndexMany(ctx, []dto.Activity{activity})
+}
 
-	var updateResp SolrUpdateResponse
-	if err := json.NewDecoder(resp.Body).Decode(&updateResp); err != nil {
-		return fmt.Errorf("error decoding response: %w", err)
-	}
+// IndexMany indexa varias actividades en lotes de hasta maxBatchSize documentos por request.
+// Los cambios se hacen visibles mediante commitWithin en lugar de un hard commit por request.
+func (s *SolrClient) IndexMany(ctx context.Context, activities []dto.Activity) error {
+	for start := 0; start < len(activities); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(activities))
+
+		docs := make([]SolrDocument, 0, end-start)
+		for _, activity := range activities[start:end] {
+			docs = append(docs, SolrDocument{
+				ID:          activity.ID,
+				Titulo:      []string{activity.Titulo},
+				Descripcion: []string{activity.Descripcion},
+				DiaSemana:   []string{activity.DiaSemana},
+			})
+		}
 
-	if updateResp.ResponseHeader.Status != 0 {
-		return fmt.Errorf("solr update failed with status %d", updateResp.ResponseHeader.Status)
+		data, err := json.Marshal(docs)
+		if err != nil {
+			return fmt.Errorf("error marshalling documents: %w", err)
+		}
+
+		if err := s.update(ctx, data); err != nil {
+			return fmt.Errorf("error indexing documents %d-%d: %w", start, end, err)
+		}
 	}
 
 	return nil
 }
 
-func (s *SolrClient) Search(ctx context.Context, query string, page int, count int) (dto.PaginatedResponse, error) {
-	if page < 1 {
-		page = 1
+// Search busca documentos a partir de start. Si cursorMark no es vacío se usa la paginación
+// por cursor de Solr (start se ignora) y se devuelve el nextCursorMark. En ambos casos se ordena
+// por relevancia y luego por id para que el orden sea estable entre páginas.
+func (s *SolrClient) Search(ctx context.Context, query string, start int, count int, cursorMark string) (dto.PaginatedResponse, string, error) {
+	if start < 0 {
+		start = 0
 	}
 	if count <= 0 {
 		count = defaultCount
 	}
 
-	// calcular offset
-	start := (page - 1) * count
-
 	params := url.Values{}
 	params.Set("q", query)
 	params.Set("wt", "json")
-	params.Set("start", fmt.Sprintf("%d", start))
 	params.Set("rows", fmt.Sprintf("%d", count))
+	params.Set("sort", "score desc,id asc")
+	if cursorMark != "" {
+		params.Set("cursorMark", cursorMark)
+	} else {
+		params.Set("start", fmt.Sprintf("%d", start))
+	}
 
 	url := fmt.Sprintf("%s/select?%s", s.baseURL, params.Encode())
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return dto.PaginatedResponse{}, fmt.Errorf("error creating request: %w", err)
+		return dto.PaginatedResponse{}, "", fmt.Errorf("error creating request: %w", err)
 	}
 
-	resp, err := s.client.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
-		return dto.PaginatedResponse{}, fmt.Errorf("error executing request: %w", err)
+		return dto.PaginatedResponse{}, "", fmt.Errorf("error executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return dto.PaginatedResponse{}, fmt.Errorf("solr returned status %d", resp.StatusCode)
+		return dto.PaginatedResponse{}, "", fmt.Errorf("solr returned status %d", resp.StatusCode)
 	}
 
 	var solrResp SolrResponse
 	if err := json.NewDecoder(resp.Body).Decode(&solrResp); err != nil {
-		return dto.PaginatedResponse{}, fmt.Errorf("error decoding response: %w", err)
+		return dto.PaginatedResponse{}, "", fmt.Errorf("error decoding response: %w", err)
 	}
 
 	activities := make([]dto.Activity, len(solrResp.Response.Docs))
@@ -144,25 +179,79 @@ func (s *SolrClient) Search(ctx context.Context, query string, page int, count i
 	}
 
 	return dto.PaginatedResponse{
-		Page:    page,
 		Count:   len(activities),
 		Total:   solrResp.Response.NumFound,
 		Results: activities,
-	}, nil
+	}, solrResp.NextCursorMark, nil
+}
+
+// Count devuelve la cantidad total de documentos del core/colección
+func (s *SolrClient) Count(ctx context.Context) (int, error) {
+	params := url.Values{}
+	params.Set("q", "*:*")
+	params.Set("wt", "json")
+	params.Set("rows", "0")
+
+	url := fmt.Sprintf("%s/select?%s", s.baseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("solr returned status %d", resp.StatusCode)
+	}
+
+	var solrResp SolrResponse
+	if err := json.NewDecoder(resp.Body).Decode(&solrResp); err != nil {
+		return 0, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return solrResp.Response.NumFound, nil
 }
 
 func (s *SolrClient) Delete(ctx context.Context, id string) error {
-	data := fmt.Sprintf(`{"delete":{"id":"%s"}}`, id)
-	url := fmt.Sprintf("%s/update?commit=true", s.baseURL)
+	return s.DeleteMany(ctx, []string{id})
+}
+
+// DeleteMany elimina varios documentos por ID en lotes de hasta maxBatchSize IDs por request.
+func (s *SolrClient) DeleteMany(ctx context.Context, ids []string) error {
+	for start := 0; start < len(ids); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(ids))
+
+		data, err := json.Marshal(map[string][]string{"delete": ids[start:end]})
+		if err != nil {
+			return fmt.Errorf("error marshalling delete request: %w", err)
+		}
+
+		if err := s.update(ctx, data); err != nil {
+			return fmt.Errorf("error deleting documents %d-%d: %w", start, end, err)
+		}
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(data))
+	return nil
+}
+
+// update envía un request al handler /update de Solr usando commitWithin (soft commit)
+func (s *SolrClient) update(ctx context.Context, data []byte) error {
+	params := url.Values{}
+	params.Set("commitWithin", fmt.Sprintf("%d", s.commitWithin.Milliseconds()))
+
+	url := fmt.Sprintf("%s/update?%s", s.baseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.client.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return fmt.Errorf("error executing request: %w", err)
 	}
@@ -178,7 +267,7 @@ func (s *SolrClient) Delete(ctx context.Context, id string) error {
 	}
 
 	if updateResp.ResponseHeader.Status != 0 {
-		return fmt.Errorf("solr delete failed with status %d", updateResp.ResponseHeader.Status)
+		return fmt.Errorf("solr update failed with status %d", updateResp.ResponseHeader.Status)
 	}
 
 	return nil
@@ -194,7 +283,7 @@ func (s *SolrClient) Commit(ctx context.Context) error {
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.client.Do(req)
+	resp, err := s.do(req)
 	if err != nil {
 		return fmt.Errorf("error executing request: %w", err)
 	}
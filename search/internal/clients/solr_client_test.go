@@ -0,0 +1,55 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestSolrClientBreaker tests which Solr errors count as failures for the circuit breaker
+func TestSolrClientBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") == "slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(serverURL.Host)
+
+	t.Run("cancelled context is not a failure", func(t *testing.T) {
+		breaker := NewCircuitBreaker("test", 1, time.Minute)
+		client := NewSolrClient(host, port, "demo", 0, breaker)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, _, err := client.Search(ctx, "slow", 0, 10, ""); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+		}
+		if err := breaker.Allow(); err != nil {
+			t.Errorf("expected the circuit to stay closed, got %v", err)
+		}
+	})
+
+	t.Run("server error is a failure", func(t *testing.T) {
+		breaker := NewCircuitBreaker("test", 1, time.Minute)
+		client := NewSolrClient(host, port, "demo", 0, breaker)
+
+		if _, _, err := client.Search(context.Background(), "*:*", 0, 10, ""); err == nil {
+			t.Fatal("expected an error")
+		}
+		if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("expected ErrCircuitOpen, got %v", err)
+		}
+	})
+}
@@ -17,23 +17,32 @@ type Config struct {
 }
 
 type MemcachedConfig struct {
-	Host       string
-	Port       string
-	TTLSeconds int
+	Host            string
+	Port            string
+	TTLSeconds      int
+	StaleTTLSeconds int
 }
 
 type RabbitMQConfig struct {
-	Username  string
-	Password  string
-	QueueName string
-	Host      string
-	Port      string
+	Username      string
+	Password      string
+	QueueName     string
+	Host          string
+	Port          string
+	BatchSize     int
+	BatchWindowMs int
 }
 
 type SolrConfig struct {
-	Host string
-	Port string
-	Core string
+	Host            string
+	Port            string
+	Core            string
+	CommitWithinMs  int
+	ConfigSet       string
+	KeepCollections int
+	// circuit breaker: fallos consecutivos que lo abren y segundos que permanece abierto
+	BreakerMaxFailures int
+	BreakerOpenSeconds int
 }
 
 var config *Config
@@ -60,24 +69,67 @@ func Load() *Config {
 		memcachedTTL = 60
 	}
 
+	batchSize, err := strconv.Atoi(getEnv("RABBITMQ_BATCH_SIZE", "100"))
+	if err != nil {
+		batchSize = 100
+	}
+
+	batchWindowMs, err := strconv.Atoi(getEnv("RABBITMQ_BATCH_WINDOW_MS", "500"))
+	if err != nil {
+		batchWindowMs = 500
+	}
+
+	commitWithinMs, err := strconv.Atoi(getEnv("SOLR_COMMIT_WITHIN_MS", "1000"))
+	if err != nil {
+		commitWithinMs = 1000
+	}
+
+	keepCollections, err := strconv.Atoi(getEnv("SOLR_KEEP_COLLECTIONS", "2"))
+	if err != nil {
+		keepCollections = 2
+	}
+
+	staleTTL, err := strconv.Atoi(getEnv("MEMCACHED_STALE_TTL_SECONDS", "86400"))
+	if err != nil {
+		staleTTL = 86400
+	}
+
+	breakerMaxFailures, err := strconv.Atoi(getEnv("SOLR_BREAKER_MAX_FAILURES", "5"))
+	if err != nil {
+		breakerMaxFailures = 5
+	}
+
+	breakerOpenSeconds, err := strconv.Atoi(getEnv("SOLR_BREAKER_OPEN_SECONDS", "30"))
+	if err != nil {
+		breakerOpenSeconds = 30
+	}
+
 	config = &Config{
 		Port: getEnv("PORT", "8080"),
 		Memcached: MemcachedConfig{
-			Host:       getEnv("MEMCACHED_HOST", "localhost"),
-			Port:       getEnv("MEMCACHED_PORT", "11211"),
-			TTLSeconds: memcachedTTL,
+			Host:            getEnv("MEMCACHED_HOST", "localhost"),
+			Port:            getEnv("MEMCACHED_PORT", "11211"),
+			TTLSeconds:      memcachedTTL,
+			StaleTTLSeconds: staleTTL,
 		},
 		RabbitMQ: RabbitMQConfig{
-			Username:  getEnv("RABBITMQ_USER", "admin"),
-			Password:  getEnv("RABBITMQ_PASS", "admin"),
-			QueueName: getEnv("RABBITMQ_QUEUE_NAME", "items-news"),
-			Host:      getEnv("RABBITMQ_HOST", "localhost"),
-			Port:      getEnv("RABBITMQ_PORT", "5672"),
+			Username:      getEnv("RABBITMQ_USER", "admin"),
+			Password:      getEnv("RABBITMQ_PASS", "admin"),
+			QueueName:     getEnv("RABBITMQ_QUEUE_NAME", "items-news"),
+			Host:          getEnv("RABBITMQ_HOST", "localhost"),
+			Port:          getEnv("RABBITMQ_PORT", "5672"),
+			BatchSize:     batchSize,
+			BatchWindowMs: batchWindowMs,
 		},
 		Solr: SolrConfig{
-			Host: getEnv("SOLR_HOST", "localhost"),
-			Port: getEnv("SOLR_PORT", "8983"),
-			Core: getEnv("SOLR_CORE", "demo"),
+			Host:               getEnv("SOLR_HOST", "localhost"),
+			Port:               getEnv("SOLR_PORT", "8983"),
+			Core:               getEnv("SOLR_CORE", "demo"),
+			CommitWithinMs:     commitWithinMs,
+			ConfigSet:          getEnv("SOLR_CONFIGSET", "_default"),
+			KeepCollections:    keepCollections,
+			BreakerMaxFailures: breakerMaxFailures,
+			BreakerOpenSeconds: breakerOpenSeconds,
 		},
 		ActivitiesAPIURL: getEnv("ACTIVITIES_API_URL", "http://activities-api:8080"),
 	}
@@ -87,14 +139,22 @@ func Load() *Config {
 	log.Infoln("MEMCACHED_HOST:", config.Memcached.Host)
 	log.Infoln("MEMCACHED_PORT:", config.Memcached.Port)
 	log.Infoln("MEMCACHED_TTL_SECONDS:", config.Memcached.TTLSeconds)
+	log.Infoln("MEMCACHED_STALE_TTL_SECONDS:", config.Memcached.StaleTTLSeconds)
 	log.Infoln("RABBITMQ_USER:", config.RabbitMQ.Username)
 	log.Infoln("RABBITMQ_PASS:", config.RabbitMQ.Password)
 	log.Infoln("RABBITMQ_QUEUE_NAME:", config.RabbitMQ.QueueName)
 	log.Infoln("RABBITMQ_HOST:", config.RabbitMQ.Host)
 	log.Infoln("RABBITMQ_PORT:", config.RabbitMQ.Port)
+	log.Infoln("RABBITMQ_BATCH_SIZE:", config.RabbitMQ.BatchSize)
+	log.Infoln("RABBITMQ_BATCH_WINDOW_MS:", config.RabbitMQ.BatchWindowMs)
 	log.Infoln("SOLR_HOST", config.Solr.Host)
 	log.Infoln("SOLR_PORT", config.Solr.Port)
 	log.Infoln("SOLR_CORE", config.Solr.Core)
+	log.Infoln("SOLR_COMMIT_WITHIN_MS", config.Solr.CommitWithinMs)
+	log.Infoln("SOLR_CONFIGSET", config.Solr.ConfigSet)
+	log.Infoln("SOLR_KEEP_COLLECTIONS", config.Solr.KeepCollections)
+	log.Infoln("SOLR_BREAKER_MAX_FAILURES", config.Solr.BreakerMaxFailures)
+	log.Infoln("SOLR_BREAKER_OPEN_SECONDS", config.Solr.BreakerOpenSeconds)
 	log.Infoln("ACTIVITIES_API_URL:", config.ActivitiesAPIURL)
 	log.Infoln("===================================")
 
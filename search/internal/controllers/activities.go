@@ -2,8 +2,12 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"search/internal/dto"
+	"search/internal/services"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -21,6 +25,9 @@ type ItemsController struct {
 const (
 	listDefaultPage  = 1
 	listDefaultCount = 9
+	listMaxCount     = 100
+	// listMaxOffset limita la paginación por número de página; más allá se debe usar cursor
+	listMaxOffset = 1000
 )
 
 func NewActivitiesController(activitiesService ItemsService) *ItemsController {
@@ -46,28 +53,64 @@ func (c *ItemsController) List(ctx *gin.Context) {
 
 	filters.SortBy = ctx.DefaultQuery("sortBy", "fecha_creacion asc")
 
+	filters.Page = listDefaultPage
 	if pageStr := ctx.Query("page"); pageStr != "" {
-		if page, err := strconv.Atoi(pageStr); err == nil {
-			filters.Page = page
+		page, err := strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			log.Warnf("parametro page invalido: %s", pageStr)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive integer"})
+			return
 		}
-	} else {
-		filters.Page = listDefaultPage
+		filters.Page = page
 	}
 
+	filters.Count = listDefaultCount
 	if countStr := ctx.Query("count"); countStr != "" {
-		if count, err := strconv.Atoi(countStr); err == nil {
-			filters.Count = count
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count < 1 || count > listMaxCount {
+			log.Warnf("parametro count invalido: %s", countStr)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("count must be an integer between 1 and %d", listMaxCount)})
+			return
 		}
-	} else {
-		filters.Count = listDefaultCount
+		filters.Count = count
+	}
+
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		decoded, err := dto.DecodeCursor(cursor)
+		if err != nil {
+			log.Warnf("parametro cursor invalido: %s", cursor)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+		// un cursor sin cursorMark pagina por offset: tiene el mismo límite que page
+		if decoded.Mark == "" && decoded.Offset > listMaxOffset {
+			log.Warnf("cursor por offset demasiado profundo: %d", decoded.Offset)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("cursor too deep: at most %d results can be skipped without a search cursor", listMaxOffset)})
+			return
+		}
+		filters.Cursor = cursor
+	} else if (filters.Page-1)*filters.Count > listMaxOffset {
+		log.Warnf("pagina demasiado profunda: page=%d count=%d", filters.Page, filters.Count)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("page too deep: at most %d results can be skipped, use next_cursor to continue", listMaxOffset)})
+		return
 	}
 
 	resp, err := c.service.List(ctx.Request.Context(), filters)
 	if err != nil {
 		log.Errorf("error al realizar busqueda: %s", err.Error())
+
+		var unavailable *services.SearchUnavailableError
+		if errors.As(err, &unavailable) {
+			retryAfter := int(math.Ceil(unavailable.RetryAfter.Seconds()))
+			ctx.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Search is temporarily unavailable",
+			})
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to fetch activities",
-			"details": err.Error(),
+			"error": "Failed to fetch activities",
 		})
 		return
 	}
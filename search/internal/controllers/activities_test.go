@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"search/internal/dto"
+	"search/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockItemsService struct {
+	filters dto.SearchFilters
+	called  bool
+	err     error
+}
+
+func (m *mockItemsService) List(ctx context.Context, filters dto.SearchFilters) (dto.PaginatedResponse, error) {
+	m.filters = filters
+	m.called = true
+	return dto.PaginatedResponse{}, m.err
+}
+
+func search(service *mockItemsService, query string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/activities", NewActivitiesController(service).List)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/activities?"+query, nil))
+	return rec
+}
+
+// TestListPaging tests the validation of the paging parameters and cursors
+func TestListPaging(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"defaults", "", http.StatusOK},
+		{"count too big", "count=101", http.StatusBadRequest},
+		{"page not a number", "page=abc", http.StatusBadRequest},
+		{"page at the offset limit", "page=101&count=10", http.StatusOK},
+		{"page too deep", "page=102&count=10", http.StatusBadRequest},
+		{"invalid cursor", "cursor=%25%25", http.StatusBadRequest},
+		{"solr cursor past the offset limit", "cursor=" + dto.EncodeCursor(dto.Cursor{Mark: "AoE", Offset: 5000}), http.StatusOK},
+		{"offset cursor at the limit", "cursor=" + dto.EncodeCursor(dto.Cursor{Offset: 1000}), http.StatusOK},
+		{"forged offset cursor", "cursor=" + dto.EncodeCursor(dto.Cursor{Offset: 100000000}), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockItemsService{}
+			rec := search(service, tt.query)
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if service.called != (tt.status == http.StatusOK) {
+				t.Errorf("expected the service to be called only for valid requests")
+			}
+		})
+	}
+}
+
+// TestListUnavailable tests the response when the search engine is down
+func TestListUnavailable(t *testing.T) {
+	service := &mockItemsService{err: &services.SearchUnavailableError{RetryAfter: 1500 * time.Millisecond, Cause: errors.New("circuit open")}}
+	rec := search(service, "")
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "2" {
+		t.Errorf("expected 503 with Retry-After 2, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
 type Activity struct {
 	ID          string `json:"id"`
 	Titulo      string `json:"titulo"`
@@ -17,11 +23,56 @@ type SearchFilters struct {
 	SortBy      string `json:"sort_by"`
 	Page        int    `json:"page"`
 	Count       int    `json:"count"`
+	Cursor      string `json:"cursor"`
 }
 
 type PaginatedResponse struct {
-	Page    int        `json:"page"`
-	Count   int        `json:"count"`
-	Total   int        `json:"total"`
-	Results Activities `json:"results"`
+	Page       int        `json:"page"`
+	Count      int        `json:"count"`
+	Total      int        `json:"total"`
+	TotalPages int        `json:"total_pages"`
+	HasNext    bool       `json:"has_next"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+	Results    Activities `json:"results"`
+	// Stale indica que el resultado es una copia vieja de la caché porque Solr no está disponible
+	Stale bool `json:"stale,omitempty"`
+	// Degraded indica que el resultado se armó filtrando en memoria el listado del servicio de actividades
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor es el contenido del cursor opaco (next_cursor) que se entrega al cliente.
+// Mark es el cursorMark de Solr (vacío si la página se generó sin Solr) y Offset la
+// cantidad de resultados anteriores a la página que apunta el cursor.
+type Cursor struct {
+	Mark   string `json:"m,omitempty"`
+	Offset int    `json:"o"`
+}
+
+func EncodeCursor(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Offset < 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// SetPaging completa Page, TotalPages y HasNext a partir del offset de la página y el tamaño pedido
+func (r *PaginatedResponse) SetPaging(offset, count int) {
+	if count <= 0 {
+		count = max(len(r.Results), 1)
+	}
+	r.Page = offset/count + 1
+	r.TotalPages = (r.Total + count - 1) / count
+	r.HasNext = offset+len(r.Results) < r.Total
 }
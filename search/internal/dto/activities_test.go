@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+// TestCursor tests the encoding and decoding of the opaque cursors
+func TestCursor(t *testing.T) {
+	cursor := Cursor{Mark: "AoE/abc", Offset: 40}
+	decoded, err := DecodeCursor(EncodeCursor(cursor))
+	if err != nil || decoded != cursor {
+		t.Errorf("expected %+v, got %+v (%v)", cursor, decoded, err)
+	}
+
+	invalid := []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"o":-1}`)),
+	}
+	for _, value := range invalid {
+		if _, err := DecodeCursor(value); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("expected ErrInvalidCursor for %q, got %v", value, err)
+		}
+	}
+}
+
+// TestSetPaging tests the paging fields computed from the offset
+func TestSetPaging(t *testing.T) {
+	response := PaginatedResponse{Total: 25, Results: make(Activities, 10)}
+	response.SetPaging(10, 10)
+	if response.Page != 2 || response.TotalPages != 3 || !response.HasNext {
+		t.Errorf("unexpected paging %+v", response)
+	}
+
+	response.SetPaging(20, 10)
+	if response.Page != 3 || response.HasNext {
+		t.Errorf("expected last page, got %+v", response)
+	}
+}
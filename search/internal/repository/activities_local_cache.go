@@ -11,20 +11,40 @@ import (
 )
 
 type ActivitiesLocalCacheRepository struct {
-	client *ccache.Cache
-	ttl    time.Duration
+	client   *ccache.Cache
+	stale    *ccache.Cache
+	ttl      time.Duration
+	staleTTL time.Duration
 }
 
-func NewActivitysLocalCacheRepository(ttl time.Duration) *ActivitiesLocalCacheRepository {
+// NewActivitysLocalCacheRepository crea la caché local. Además de la caché principal mantiene
+// una copia de cada página con staleTTL que no se borra con FlushAll, para poder responder
+// con resultados viejos cuando Solr no está disponible.
+func NewActivitysLocalCacheRepository(ttl time.Duration, staleTTL time.Duration) *ActivitiesLocalCacheRepository {
 	return &ActivitiesLocalCacheRepository{
-		client: ccache.New(ccache.Configure()),
-		ttl:    ttl,
+		client:   ccache.New(ccache.Configure()),
+		stale:    ccache.New(ccache.Configure()),
+		ttl:      ttl,
+		staleTTL: staleTTL,
 	}
 }
 
+// cacheKey arma la clave de caché a partir de los filtros de búsqueda
+func cacheKey(filters dto.SearchFilters) string {
+	return fmt.Sprintf("%s:%s:%s:%d:%d:%s", filters.Titulo, filters.Descripcion, filters.DiaSemana, filters.Page, filters.Count, filters.Cursor)
+}
+
 func (r ActivitiesLocalCacheRepository) List(ctx context.Context, filters dto.SearchFilters) (dto.PaginatedResponse, error) {
-	key := fmt.Sprintf("%s:%s:%s:%d:%d", filters.Titulo, filters.Descripcion, filters.DiaSemana, filters.Page, filters.Count)
-	item := r.client.Get(key)
+	return getFromCcache(r.client, cacheKey(filters))
+}
+
+// GetStale obtiene la última copia conocida de la página, aunque la caché principal se haya invalidado
+func (r ActivitiesLocalCacheRepository) GetStale(ctx context.Context, filters dto.SearchFilters) (dto.PaginatedResponse, error) {
+	return getFromCcache(r.stale, cacheKey(filters))
+}
+
+func getFromCcache(cache *ccache.Cache, key string) (dto.PaginatedResponse, error) {
+	item := cache.Get(key)
 	if item == nil {
 		return dto.PaginatedResponse{}, errors.New("cache miss")
 	}
@@ -40,12 +60,13 @@ func (r ActivitiesLocalCacheRepository) List(ctx context.Context, filters dto.Se
 
 // SetPaginatedResult stores a paginated response in cache using search filters as key
 func (r ActivitiesLocalCacheRepository) SetPaginatedResult(filters dto.SearchFilters, result dto.PaginatedResponse) error {
-	key := fmt.Sprintf("%s:%s:%s:%d:%d", filters.Titulo, filters.Descripcion, filters.DiaSemana, filters.Page, filters.Count)
+	key := cacheKey(filters)
 	r.client.Set(key, result, r.ttl)
+	r.stale.Set(key, result, r.staleTTL)
 	return nil
 }
 
-// FlushAll clears all entries from the local cache
+// FlushAll clears all entries from the local cache (stale copies are kept)
 func (r ActivitiesLocalCacheRepository) FlushAll() error {
 	r.client.Clear()
 	return nil
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"search/internal/dto"
 	"time"
@@ -10,22 +11,48 @@ import (
 	"github.com/bradfitz/gomemcache/memcache"
 )
 
+// generationKey guarda la generación actual de la caché. Las claves de las páginas incluyen la
+// generación, de modo que invalidar la caché es incrementar este contador.
+const generationKey = "activities:generation"
+
 type MemcachedActivitiesRepository struct {
-	ttl    time.Duration
-	client *memcache.Client
+	ttl      time.Duration
+	staleTTL time.Duration
+	client   *memcache.Client
 }
 
-func NewMemcachedActivitiesRepository(host string, port string, ttl time.Duration) MemcachedActivitiesRepository {
+// NewMemcachedActivitiesRepository crea la caché distribuida. Cada página se guarda además con
+// staleTTL bajo una clave sin generación, para poder responder con resultados viejos cuando
+// Solr no está disponible.
+func NewMemcachedActivitiesRepository(host string, port string, ttl time.Duration, staleTTL time.Duration) MemcachedActivitiesRepository {
 	client := memcache.New(fmt.Sprintf("%s:%s", host, port))
 
 	return MemcachedActivitiesRepository{
-		client: client,
-		ttl:    ttl,
+		client:   client,
+		ttl:      ttl,
+		staleTTL: staleTTL,
+	}
+}
+
+func (r MemcachedActivitiesRepository) generation() string {
+	item, err := r.client.Get(generationKey)
+	if err != nil {
+		return "0"
 	}
+	return string(item.Value)
 }
 
 func (r MemcachedActivitiesRepository) List(ctx context.Context, filters dto.SearchFilters) (dto.PaginatedResponse, error) {
-	key := fmt.Sprintf("%s:%s:%s:%d:%d", filters.Titulo, filters.Descripcion, filters.DiaSemana, filters.Page, filters.Count)
+	key := fmt.Sprintf("%s:%s", r.generation(), cacheKey(filters))
+	return r.get(key)
+}
+
+// GetStale obtiene la última copia conocida de la página, aunque la caché se haya invalidado
+func (r MemcachedActivitiesRepository) GetStale(ctx context.Context, filters dto.SearchFilters) (dto.PaginatedResponse, error) {
+	return r.get("stale:" + cacheKey(filters))
+}
+
+func (r MemcachedActivitiesRepository) get(key string) (dto.PaginatedResponse, error) {
 	item, err := r.client.Get(key)
 	if err != nil {
 		return dto.PaginatedResponse{}, fmt.Errorf("cache miss: %w", err)
@@ -39,22 +66,39 @@ func (r MemcachedActivitiesRepository) List(ctx context.Context, filters dto.Sea
 
 // SetPaginatedResult stores a paginated response in cache using search filters as key
 func (r MemcachedActivitiesRepository) SetPaginatedResult(filters dto.SearchFilters, result dto.PaginatedResponse) error {
-	key := fmt.Sprintf("%s:%s:%s:%d:%d", filters.Titulo, filters.Descripcion, filters.DiaSemana, filters.Page, filters.Count)
 	bytes, err := json.Marshal(result)
 	if err != nil {
 		return fmt.Errorf("error marshalling paginated response to JSON: %w", err)
 	}
+
+	key := cacheKey(filters)
 	if err := r.client.Set(&memcache.Item{
-		Key:        key,
+		Key:        fmt.Sprintf("%s:%s", r.generation(), key),
 		Value:      bytes,
 		Expiration: int32(r.ttl.Seconds()),
 	}); err != nil {
 		return fmt.Errorf("error setting paginated response in memcached: %w", err)
 	}
+	if err := r.client.Set(&memcache.Item{
+		Key:        "stale:" + key,
+		Value:      bytes,
+		Expiration: int32(r.staleTTL.Seconds()),
+	}); err != nil {
+		return fmt.Errorf("error setting stale paginated response in memcached: %w", err)
+	}
 	return nil
 }
 
-// FlushAll clears all entries from memcached
+// FlushAll invalida todas las páginas cacheadas incrementando la generación. Las entradas de
+// generaciones anteriores expiran solas y las copias stale se conservan.
 func (r MemcachedActivitiesRepository) FlushAll() error {
-	return r.client.FlushAll()
+	_, err := r.client.Increment(generationKey, 1)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		err = r.client.Add(&memcache.Item{Key: generationKey, Value: []byte("1")})
+		if errors.Is(err, memcache.ErrNotStored) {
+			// otra instancia la creó al mismo tiempo
+			_, err = r.client.Increment(generationKey, 1)
+		}
+	}
+	return err
 }
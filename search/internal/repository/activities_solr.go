@@ -6,6 +6,7 @@ import (
 	"search/internal/clients"
 	"search/internal/dto"
 	"strings"
+	"time"
 )
 
 type SolrClient interface {
@@ -15,16 +16,53 @@ type SolrActivitysRepository struct {
 	client *clients.SolrClient
 }
 
-func NewSolrActivitysRepository(host, port, core string) *SolrActivitysRepository {
-	client := clients.NewSolrClient(host, port, core)
+func NewSolrActivitysRepository(host, port, core string, commitWithin time.Duration, breaker *clients.CircuitBreaker) *SolrActivitysRepository {
+	client := clients.NewSolrClient(host, port, core, commitWithin, breaker)
 	return &SolrActivitysRepository{
 		client: client,
 	}
 }
 
+// List busca en Solr. La primera página y las pedidas con cursor usan cursorMark de Solr y
+// devuelven next_cursor; el resto de las páginas usa offset (acotado por el controller).
 func (r *SolrActivitysRepository) List(ctx context.Context, filters dto.SearchFilters) (dto.PaginatedResponse, error) {
 	query := buildQuery(filters)
-	return r.client.Search(ctx, query, filters.Page, filters.Count)
+
+	var cursor dto.Cursor
+	switch {
+	case filters.Cursor != "":
+		c, err := dto.DecodeCursor(filters.Cursor)
+		if err != nil {
+			return dto.PaginatedResponse{}, err
+		}
+		cursor = c
+	case filters.Page <= 1:
+		cursor = dto.Cursor{Mark: "*"}
+	default:
+		cursor = dto.Cursor{Offset: (filters.Page - 1) * filters.Count}
+	}
+
+	result, nextMark, err := r.client.Search(ctx, query, cursor.Offset, filters.Count, cursor.Mark)
+	if err != nil {
+		return dto.PaginatedResponse{}, err
+	}
+
+	result.SetPaging(cursor.Offset, filters.Count)
+	if result.HasNext && (filters.Cursor != "" || filters.Page <= 1) {
+		next := dto.Cursor{Offset: cursor.Offset + len(result.Results)}
+		// si la página se pidió por offset (cursor generado sin Solr) se sigue por offset
+		if cursor.Mark != "" && nextMark != cursor.Mark {
+			next.Mark = nextMark
+		}
+		result.NextCursor = dto.EncodeCursor(next)
+	}
+
+	return result, nil
+}
+
+// RetryAfter indica cuánto falta para que Solr vuelva a recibir llamadas si el circuit breaker está abierto
+func (r *SolrActivitysRepository) RetryAfter() time.Duration {
+	return r.client.RetryAfter()
 }
 
 func (r *SolrActivitysRepository) Create(ctx context.Context, activity dto.Activity) (dto.Activity, error) {
@@ -43,6 +81,28 @@ func (r *SolrActivitysRepository) Update(ctx context.Context, id string, activit
 	return activity, nil
 }
 
+// CreateMany indexa (o reindexa) varias actividades en una sola operación por lote
+func (r *SolrActivitysRepository) CreateMany(ctx context.Context, activities []dto.Activity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+	if err := r.client.IndexMany(ctx, activities); err != nil {
+		return fmt.Errorf("error indexing activities in solr: %w", err)
+	}
+	return nil
+}
+
+// DeleteMany elimina varias actividades del índice en una sola operación por lote
+func (r *SolrActivitysRepository) DeleteMany(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.client.DeleteMany(ctx, ids); err != nil {
+		return fmt.Errorf("error deleting activities from solr: %w", err)
+	}
+	return nil
+}
+
 func (r *SolrActivitysRepository) Delete(ctx context.Context, id string) error {
 	if err := r.client.Delete(ctx, id); err != nil {
 		return fmt.Errorf("error deleting activity from solr: %w", err)
@@ -3,11 +3,14 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"search/internal/config"
 	"search/internal/dto"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -23,16 +26,43 @@ type ActivitiesRepository interface {
 	Create(ctx context.Context, activity dto.Activity) (dto.Activity, error)
 	Update(ctx context.Context, id string, activity dto.Activity) (dto.Activity, error)
 	Delete(ctx context.Context, id string) error
+	CreateMany(ctx context.Context, activities []dto.Activity) error
+	DeleteMany(ctx context.Context, ids []string) error
 }
 
 type ActivitiesCacheRepository interface {
 	List(ctx context.Context, filters dto.SearchFilters) (dto.PaginatedResponse, error)
+	GetStale(ctx context.Context, filters dto.SearchFilters) (dto.PaginatedResponse, error)
 	SetPaginatedResult(filters dto.SearchFilters, result dto.PaginatedResponse) error
 	FlushAll() error
 }
 
+// retryAfterProvider lo implementa el repositorio de búsqueda cuando tiene un circuit breaker
+type retryAfterProvider interface {
+	RetryAfter() time.Duration
+}
+
+var ErrSearchUnavailable = errors.New("search is temporarily unavailable")
+
+// defaultRetryAfter se sugiere al cliente cuando no se sabe cuándo volverá a estar disponible Solr
+const defaultRetryAfter = 30 * time.Second
+
+// SearchUnavailableError se devuelve cuando Solr no responde y no hay ningún resultado alternativo
+type SearchUnavailableError struct {
+	RetryAfter time.Duration
+	Cause      error
+}
+
+func (e *SearchUnavailableError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrSearchUnavailable, e.Cause)
+}
+
+func (e *SearchUnavailableError) Unwrap() []error {
+	return []error{ErrSearchUnavailable, e.Cause}
+}
+
 type ActivitiesConsumer interface {
-	Consume(ctx context.Context, handler func(ctx context.Context, message ActivityEvent) error) error
+	ConsumeBatch(ctx context.Context, handler func(ctx context.Context, messages []ActivityEvent) error) error
 }
 
 type ActiviesServiceImpl struct {
@@ -51,6 +81,9 @@ func NewActivitiesService(localCache ActivitiesCacheRepository, cache Activities
 	}
 }
 
+// List busca en la caché local, luego en memcached y por último en Solr. Si Solr no está
+// disponible responde, en orden, con la última copia cacheada de la página (stale), con una
+// búsqueda degradada sobre el servicio de actividades o con SearchUnavailableError.
 func (s *ActiviesServiceImpl) List(ctx context.Context, filters dto.SearchFilters) (dto.PaginatedResponse, error) {
 	var localCacheMiss, memcacheMiss bool = false, false
 
@@ -94,8 +127,96 @@ func (s *ActiviesServiceImpl) List(ctx context.Context, filters dto.SearchFilter
 		return result, err
 	}
 
-	log.Infof("no se encontro actividad en solr")
-	return dto.PaginatedResponse{}, err
+	log.Errorf("error al buscar en solr: %v", err)
+	return s.listWithoutSolr(ctx, filters, err)
+}
+
+func (s *ActiviesServiceImpl) listWithoutSolr(ctx context.Context, filters dto.SearchFilters, solrErr error) (dto.PaginatedResponse, error) {
+	if result, err := s.localCache.GetStale(ctx, filters); err == nil {
+		log.Warnf("respondiendo con resultado stale de cache local: %v", filters)
+		result.Stale = true
+		return result, nil
+	}
+
+	if result, err := s.memCached.GetStale(ctx, filters); err == nil {
+		log.Warnf("respondiendo con resultado stale de memcached: %v", filters)
+		result.Stale = true
+		return result, nil
+	}
+
+	result, err := s.degradedList(ctx, filters)
+	if err == nil {
+		log.Warnf("respondiendo con busqueda degradada sobre el servicio de actividades: %v", filters)
+		return result, nil
+	}
+	log.Errorf("error en busqueda degradada: %v", err)
+
+	retryAfter := defaultRetryAfter
+	if provider, ok := s.search.(retryAfterProvider); ok {
+		if d := provider.RetryAfter(); d > 0 {
+			retryAfter = d
+		}
+	}
+	return dto.PaginatedResponse{}, &SearchUnavailableError{RetryAfter: retryAfter, Cause: errors.Join(solrErr, err)}
+}
+
+// degradedList obtiene todas las actividades del servicio de actividades y aplica los filtros
+// y la paginación en memoria. No respeta el orden pedido ni la relevancia de Solr.
+func (s *ActiviesServiceImpl) degradedList(ctx context.Context, filters dto.SearchFilters) (dto.PaginatedResponse, error) {
+	activities, err := fetchAllActivities(ctx)
+	if err != nil {
+		return dto.PaginatedResponse{}, err
+	}
+
+	matches := dto.Activities{}
+	for _, activity := range activities {
+		if matchesFilters(activity, filters) {
+			matches = append(matches, activity)
+		}
+	}
+
+	count := filters.Count
+	if count <= 0 {
+		count = max(len(matches), 1)
+	}
+
+	offset := (max(filters.Page, 1) - 1) * count
+	if filters.Cursor != "" {
+		cursor, err := dto.DecodeCursor(filters.Cursor)
+		if err != nil {
+			return dto.PaginatedResponse{}, err
+		}
+		offset = cursor.Offset
+	}
+
+	start := min(offset, len(matches))
+	end := min(start+count, len(matches))
+
+	result := dto.PaginatedResponse{
+		Count:    end - start,
+		Total:    len(matches),
+		Results:  matches[start:end],
+		Degraded: true,
+	}
+	result.SetPaging(offset, count)
+	if result.HasNext && (filters.Cursor != "" || filters.Page <= 1) {
+		// sin Solr no hay cursorMark: el cursor continúa por offset
+		result.NextCursor = dto.EncodeCursor(dto.Cursor{Offset: end})
+	}
+
+	return result, nil
+}
+
+func matchesFilters(activity dto.Activity, filters dto.SearchFilters) bool {
+	if filters.ID != "" && activity.ID != filters.ID {
+		return false
+	}
+	contains := func(value, filter string) bool {
+		return filter == "" || strings.Contains(strings.ToLower(value), strings.ToLower(filter))
+	}
+	return contains(activity.Titulo, filters.Titulo) &&
+		contains(activity.Descripcion, filters.Descripcion) &&
+		contains(activity.DiaSemana, filters.DiaSemana)
 }
 
 // activityFromActivitiesAPI represents the activity structure from activities service API
@@ -106,29 +227,32 @@ type activityFromActivitiesAPI struct {
 	DiaSemana   string `json:"dia"`
 }
 
-// fetchActivityByID makes an HTTP GET request to activities service to fetch activity details
-func (s *ActiviesServiceImpl) fetchActivityByID(ctx context.Context, activityID string) (dto.Activity, error) {
+// fetchActivitiesByIDs makes a single HTTP GET request to activities service to fetch the details
+// of several activities. IDs that no longer exist are silently omitted from the result.
+func (s *ActiviesServiceImpl) fetchActivitiesByIDs(ctx context.Context, activityIDs []string) ([]dto.Activity, error) {
 	activitiesURL := config.Load().ActivitiesAPIURL
 	if activitiesURL == "" {
-		return dto.Activity{}, fmt.Errorf("ACTIVITIES_API_URL not configured")
+		return nil, fmt.Errorf("ACTIVITIES_API_URL not configured")
 	}
 
-	url := fmt.Sprintf("%s/activities/many?ids=%s", activitiesURL, activityID)
+	params := url.Values{}
+	params.Set("ids", strings.Join(activityIDs, ","))
+	url := fmt.Sprintf("%s/activities/many?%s", activitiesURL, params.Encode())
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return dto.Activity{}, fmt.Errorf("error creating request: %w", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	client := &http.Client{Timeout: 5 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return dto.Activity{}, fmt.Errorf("error making HTTP request: %w", err)
+		return nil, fmt.Errorf("error making HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return dto.Activity{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	var response struct {
@@ -137,118 +261,161 @@ func (s *ActiviesServiceImpl) fetchActivityByID(ctx context.Context, activityID
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return dto.Activity{}, fmt.Errorf("error decoding response: %w", err)
+		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
-	if len(response.Activities) == 0 {
-		return dto.Activity{}, fmt.Errorf("activity not found: %s", activityID)
+	// Map from activities API DTO to search DTO
+	activities := make([]dto.Activity, len(response.Activities))
+	for i, apiActivity := range response.Activities {
+		activities[i] = dto.Activity{
+			ID:          apiActivity.ID,
+			Titulo:      apiActivity.Titulo,
+			Descripcion: apiActivity.Descripcion,
+			DiaSemana:   apiActivity.DiaSemana,
+		}
 	}
 
-	// Map from activities API DTO to search DTO
-	apiActivity := response.Activities[0]
-	return dto.Activity{
-		ID:          apiActivity.ID,
-		Titulo:      apiActivity.Titulo,
-		Descripcion: apiActivity.Descripcion,
-		DiaSemana:   apiActivity.DiaSemana,
-	}, nil
+	return activities, nil
 }
 
-func (s *ActiviesServiceImpl) InitConsumer(ctx context.Context) {
-	slog.Info("🐰 Starting RabbitMQ consumer...")
-
-	if err := s.consumer.Consume(ctx, s.handleMessage); err != nil {
-		slog.Error("❌ Error in RabbitMQ consumer: %v", err)
+// fetchAllActivities obtiene todas las actividades publicadas por el servicio de actividades
+func fetchAllActivities(ctx context.Context) ([]dto.Activity, error) {
+	activitiesURL := config.Load().ActivitiesAPIURL
+	if activitiesURL == "" {
+		return nil, fmt.Errorf("ACTIVITIES_API_URL not configured")
 	}
-	slog.Info("🐰 RabbitMQ consumer stopped.")
-}
 
-func (s *ActiviesServiceImpl) handleMessage(ctx context.Context, message ActivityEvent) error {
-	slog.Info("📨 Processing message",
-		slog.String("action", message.Action),
-		slog.String("id", message.ID),
-	)
+	req, err := http.NewRequestWithContext(ctx, "GET", activitiesURL+"/activities?all=true", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
 
-	switch message.Action {
-	case "create":
-		// Fetch activity details from activities service
-		activity, err := s.fetchActivityByID(ctx, message.ID)
-		if err != nil {
-			slog.Error("❌ Error fetching activity from activities service",
-				slog.String("activity_id", message.ID),
-				slog.String("error", err.Error()))
-			return fmt.Errorf("error fetching activity: %w", err)
-		}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
 
-		slog.Info("✅ Activity fetched from activities service", slog.String("activity_id", activity.ID))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
 
-		// Index in SolR
-		if _, err := s.search.Create(ctx, activity); err != nil {
-			slog.Error("❌ Error indexing activity in search",
-				slog.String("activity_id", message.ID),
-				slog.String("error", err.Error()))
-			return fmt.Errorf("error indexing activity: %w", err)
-		}
+	var response struct {
+		Activities []activityFromActivitiesAPI `json:"activities"`
+		Count      int                         `json:"count"`
+	}
 
-		// Invalidate all cache to ensure consistency
-		if err := s.localCache.FlushAll(); err != nil {
-			slog.Warn("⚠️ Error flushing local cache",
-				slog.String("error", err.Error()))
-		}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
 
-		if err := s.memCached.FlushAll(); err != nil {
-			slog.Warn("⚠️ Error flushing memcached",
-				slog.String("error", err.Error()))
+	activities := make([]dto.Activity, len(response.Activities))
+	for i, apiActivity := range response.Activities {
+		activities[i] = dto.Activity{
+			ID:          apiActivity.ID,
+			Titulo:      apiActivity.Titulo,
+			Descripcion: apiActivity.Descripcion,
+			DiaSemana:   apiActivity.DiaSemana,
 		}
+	}
 
-		slog.Info("🔍 Activity indexed in search engine", slog.String("activity_id", message.ID))
+	return activities, nil
+}
 
-	case "update":
-		// Fetch updated activity details from activities service
-		activity, err := s.fetchActivityByID(ctx, message.ID)
-		if err != nil {
-			slog.Error("❌ Error fetching activity from activities service",
-				slog.String("activity_id", message.ID),
-				slog.String("error", err.Error()))
-			return fmt.Errorf("error fetching activity: %w", err)
-		}
+func (s *ActiviesServiceImpl) InitConsumer(ctx context.Context) {
+	slog.Info("🐰 Starting RabbitMQ consumer...")
 
-		slog.Info("✏️ Activity fetched from activities service", slog.String("activity_id", activity.ID))
+	if err := s.consumer.ConsumeBatch(ctx, s.handleBatch); err != nil {
+		slog.Error("❌ Error in RabbitMQ consumer", slog.String("error", err.Error()))
+	}
+	slog.Info("🐰 RabbitMQ consumer stopped.")
+}
 
-		// Reindex in SolR
-		_, err = s.search.Update(ctx, message.ID, activity)
-		if err != nil {
-			slog.Error("❌ Error reindexing activity in search",
-				slog.String("activity_id", message.ID),
-				slog.String("error", err.Error()))
-			return fmt.Errorf("error reindexing activity: %w", err)
+// handleBatch procesa un lote de eventos acumulados por el consumer. Los eventos se
+// consolidan por ID (gana la última acción recibida), las actividades creadas o
+// modificadas se obtienen con un único request al servicio de actividades y se
+// indexan/eliminan en Solr con una operación por lote. La caché se invalida una sola vez.
+func (s *ActiviesServiceImpl) handleBatch(ctx context.Context, messages []ActivityEvent) error {
+	slog.Info("📨 Processing batch", slog.Int("events", len(messages)))
+
+	lastAction := make(map[string]string, len(messages))
+	order := make([]string, 0, len(messages))
+	for _, message := range messages {
+		switch message.Action {
+		case "create", "update", "delete":
+			if _, seen := lastAction[message.ID]; !seen {
+				order = append(order, message.ID)
+			}
+			lastAction[message.ID] = message.Action
+		default:
+			slog.Info("⚠️ Unknown action", slog.String("action", message.Action), slog.String("id", message.ID))
 		}
+	}
 
-		// Invalidate all cache to ensure consistency
-		if err := s.localCache.FlushAll(); err != nil {
-			slog.Warn("⚠️ Error flushing local cache",
-				slog.String("error", err.Error()))
+	var upsertIDs, deleteIDs []string
+	for _, id := range order {
+		if lastAction[id] == "delete" {
+			deleteIDs = append(deleteIDs, id)
+		} else {
+			upsertIDs = append(upsertIDs, id)
 		}
+	}
 
-		if err := s.memCached.FlushAll(); err != nil {
-			slog.Warn("⚠️ Error flushing memcached",
-				slog.String("error", err.Error()))
-		}
+	var errs []error
+	changed := false
 
-		slog.Info("🔍 Activity reindexed in search engine", slog.String("activity_id", message.ID))
+	if len(upsertIDs) > 0 {
+		// Fetch activity details from activities service
+		activities, err := s.fetchActivitiesByIDs(ctx, upsertIDs)
+		if err != nil {
+			slog.Error("❌ Error fetching activities from activities service",
+				slog.Int("activities", len(upsertIDs)),
+				slog.String("error", err.Error()))
+			errs = append(errs, fmt.Errorf("error fetching activities: %w", err))
+		} else {
+			// las actividades dadas de baja no se devuelven: se quitan del índice si estaban
+			found := make(map[string]bool, len(activities))
+			for _, activity := range activities {
+				found[activity.ID] = true
+			}
+			for _, id := range upsertIDs {
+				if !found[id] {
+					deleteIDs = append(deleteIDs, id)
+				}
+			}
+			if missing := len(upsertIDs) - len(activities); missing > 0 {
+				slog.Warn("⚠️ Some activities were not found or are inactive in activities service", slog.Int("missing", missing))
+			}
 
-	case "delete":
-		slog.Info("🗑️ Activity deleted", slog.String("activity_id", message.ID))
+			// Index in SolR
+			if err := s.search.CreateMany(ctx, activities); err != nil {
+				slog.Error("❌ Error indexing activities in search",
+					slog.Int("activities", len(activities)),
+					slog.String("error", err.Error()))
+				errs = append(errs, fmt.Errorf("error indexing activities: %w", err))
+			} else {
+				slog.Info("🔍 Activities indexed in search engine", slog.Int("activities", len(activities)))
+				changed = true
+			}
+		}
+	}
 
+	if len(deleteIDs) > 0 {
 		// Delete from SolR
-		err := s.search.Delete(ctx, message.ID)
-		if err != nil {
-			slog.Error("❌ Error deleting activity in search",
-				slog.String("activity_id", message.ID),
+		if err := s.search.DeleteMany(ctx, deleteIDs); err != nil {
+			slog.Error("❌ Error deleting activities in search",
+				slog.Int("activities", len(deleteIDs)),
 				slog.String("error", err.Error()))
-			return fmt.Errorf("error deleting activity in search: %w", err)
+			errs = append(errs, fmt.Errorf("error deleting activities in search: %w", err))
+		} else {
+			slog.Info("🗑️ Activities deleted from search engine", slog.Int("activities", len(deleteIDs)))
+			changed = true
 		}
+	}
 
+	// Si Solr no se pudo actualizar la caché se conserva: sigue siendo lo más reciente que hay
+	if changed {
 		// Invalidate all cache to ensure consistency
 		if err := s.localCache.FlushAll(); err != nil {
 			slog.Warn("⚠️ Error flushing local cache",
@@ -259,12 +426,7 @@ func (s *ActiviesServiceImpl) handleMessage(ctx context.Context, message Activit
 			slog.Warn("⚠️ Error flushing memcached",
 				slog.String("error", err.Error()))
 		}
-
-		slog.Info("🗑️ Activity deleted from search engine", slog.String("activity_id", message.ID))
-
-	default:
-		slog.Info("⚠️ Unknown action", slog.String("action", message.Action))
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
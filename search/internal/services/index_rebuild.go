@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"search/internal/dto"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrDocumentCountMismatch = errors.New("document count in new collection does not match activities count")
+	ErrAliasNotFound         = errors.New("solr alias does not exist")
+	ErrNoPreviousCollection  = errors.New("there is no previous collection to roll back to")
+	ErrReconcileFailed       = errors.New("changes made during the rebuild could not be applied to the new collection")
+)
+
+type SolrAdmin interface {
+	CreateCollection(ctx context.Context, name, configSet string) error
+	DeleteCollection(ctx context.Context, name string) error
+	ListCollections(ctx context.Context) ([]string, error)
+	GetAliases(ctx context.Context) (map[string]string, error)
+	CreateAlias(ctx context.Context, alias, collection string) error
+}
+
+// CollectionIndexer indexa documentos en una colección concreta (no en el alias)
+type CollectionIndexer interface {
+	IndexMany(ctx context.Context, activities []dto.Activity) error
+	DeleteMany(ctx context.Context, ids []string) error
+	Commit(ctx context.Context) error
+	Count(ctx context.Context) (int, error)
+}
+
+type RebuildResult struct {
+	Alias      string `json:"alias"`
+	Collection string `json:"collection"`
+	Previous   string `json:"previous,omitempty"`
+	Documents  int    `json:"documents"`
+	Reconciled int    `json:"reconciled"`
+}
+
+// IndexRebuildService reconstruye el índice en una colección nueva (blue/green) y, una vez
+// validada, mueve el alias que usa la API hacia ella. Las colecciones anteriores se conservan
+// (hasta keepCollections) para poder volver atrás con Rollback.
+type IndexRebuildService struct {
+	admin           SolrAdmin
+	indexerFor      func(collection string) CollectionIndexer
+	fetchActivities func(ctx context.Context) ([]dto.Activity, error)
+	alias           string
+	configSet       string
+	keepCollections int
+}
+
+func NewIndexRebuildService(admin SolrAdmin, indexerFor func(collection string) CollectionIndexer, alias, configSet string, keepCollections int) IndexRebuildService {
+	if keepCollections < 2 {
+		// se necesita al menos la colección actual y la anterior para poder hacer rollback
+		keepCollections = 2
+	}
+	return IndexRebuildService{
+		admin:           admin,
+		indexerFor:      indexerFor,
+		fetchActivities: fetchAllActivities,
+		alias:           alias,
+		configSet:       configSet,
+		keepCollections: keepCollections,
+	}
+}
+
+// EnsureAlias crea una colección vacía y el alias si este todavía no existe, para que la API
+// y el consumer puedan funcionar antes del primer Rebuild. Devuelve true si creó el alias.
+func (s *IndexRebuildService) EnsureAlias(ctx context.Context) (bool, error) {
+	aliases, err := s.admin.GetAliases(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := aliases[s.alias]; ok {
+		return false, nil
+	}
+
+	collection, err := s.newCollectionName(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := s.admin.CreateCollection(ctx, collection, s.configSet); err != nil {
+		return false, err
+	}
+	if err := s.admin.CreateAlias(ctx, s.alias, collection); err != nil {
+		return false, err
+	}
+
+	log.Infof("alias %s creado apuntando a la coleccion vacia %s", s.alias, collection)
+	return true, nil
+}
+
+// Rebuild crea una colección nueva, la llena con las actividades del servicio de actividades,
+// valida la cantidad de documentos, mueve el alias de forma atómica y aplica sobre la colección
+// nueva los cambios hechos mientras se construía (ver reconcile). Si la conciliación falla el
+// alias ya quedó movido: devuelve el resultado junto con ErrReconcileFailed.
+func (s *IndexRebuildService) Rebuild(ctx context.Context) (RebuildResult, error) {
+	activities, err := s.fetchActivities(ctx)
+	if err != nil {
+		return RebuildResult{}, fmt.Errorf("error fetching activities: %w", err)
+	}
+	log.Infof("%d actividades obtenidas del servicio de actividades", len(activities))
+
+	collection, err := s.newCollectionName(ctx)
+	if err != nil {
+		return RebuildResult{}, err
+	}
+	if err := s.admin.CreateCollection(ctx, collection, s.configSet); err != nil {
+		return RebuildResult{}, err
+	}
+	log.Infof("coleccion %s creada", collection)
+
+	indexer := s.indexerFor(collection)
+	count, err := s.populate(ctx, indexer, activities)
+	if err != nil {
+		s.discard(ctx, collection)
+		return RebuildResult{}, err
+	}
+	if count != len(activities) {
+		s.discard(ctx, collection)
+		return RebuildResult{}, fmt.Errorf("%w: expected %d, got %d", ErrDocumentCountMismatch, len(activities), count)
+	}
+
+	aliases, err := s.admin.GetAliases(ctx)
+	if err != nil {
+		s.discard(ctx, collection)
+		return RebuildResult{}, err
+	}
+	previous := aliases[s.alias]
+
+	if err := s.admin.CreateAlias(ctx, s.alias, collection); err != nil {
+		s.discard(ctx, collection)
+		return RebuildResult{}, err
+	}
+	log.Infof("alias %s movido de %q a %s", s.alias, previous, collection)
+
+	result := RebuildResult{
+		Alias:      s.alias,
+		Collection: collection,
+		Previous:   previous,
+		Documents:  count,
+	}
+	reconciled, err := s.reconcile(ctx, indexer, activities)
+	if err != nil {
+		// la colección anterior se conserva entera para poder volver atrás
+		return result, fmt.Errorf("%w: %w", ErrReconcileFailed, err)
+	}
+	result.Reconciled = reconciled
+
+	s.pruneOldCollections(ctx, collection)
+
+	return result, nil
+}
+
+// reconcile aplica sobre la colección nueva los cambios hechos mientras se construía, que el
+// consumer escribió en la colección anterior (la del alias). Con el alias ya movido vuelve a pedir
+// las actividades y, comparando con snapshot, indexa las nuevas o modificadas y elimina las que ya
+// no están. Lo que cambie después llega por el consumer directamente a la colección nueva.
+func (s *IndexRebuildService) reconcile(ctx context.Context, indexer CollectionIndexer, snapshot []dto.Activity) (int, error) {
+	current, err := s.fetchActivities(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching activities: %w", err)
+	}
+
+	before := make(map[string]dto.Activity, len(snapshot))
+	for _, activity := range snapshot {
+		before[activity.ID] = activity
+	}
+	var changed []dto.Activity
+	for _, activity := range current {
+		if previous, ok := before[activity.ID]; !ok || previous != activity {
+			changed = append(changed, activity)
+		}
+		delete(before, activity.ID)
+	}
+	removed := make([]string, 0, len(before))
+	for id := range before {
+		removed = append(removed, id)
+	}
+	sort.Strings(removed)
+
+	if len(changed) > 0 {
+		if err := indexer.IndexMany(ctx, changed); err != nil {
+			return 0, err
+		}
+	}
+	if len(removed) > 0 {
+		if err := indexer.DeleteMany(ctx, removed); err != nil {
+			return 0, err
+		}
+	}
+	if len(changed)+len(removed) > 0 {
+		log.Infof("%d actividades modificadas y %d eliminadas durante el rebuild aplicadas a la coleccion nueva", len(changed), len(removed))
+	}
+	return len(changed) + len(removed), nil
+}
+
+// Rollback vuelve a apuntar el alias a la colección inmediatamente anterior a la actual
+func (s *IndexRebuildService) Rollback(ctx context.Context) (RebuildResult, error) {
+	aliases, err := s.admin.GetAliases(ctx)
+	if err != nil {
+		return RebuildResult{}, err
+	}
+	current, ok := aliases[s.alias]
+	if !ok {
+		return RebuildResult{}, fmt.Errorf("%w: %s", ErrAliasNotFound, s.alias)
+	}
+
+	collections, err := s.ownCollections(ctx)
+	if err != nil {
+		return RebuildResult{}, err
+	}
+
+	// las colecciones están ordenadas de la más vieja a la más nueva
+	target := ""
+	for _, c := range collections {
+		if c >= current {
+			break
+		}
+		target = c
+	}
+	if target == "" {
+		return RebuildResult{}, ErrNoPreviousCollection
+	}
+
+	if err := s.admin.CreateAlias(ctx, s.alias, target); err != nil {
+		return RebuildResult{}, err
+	}
+	log.Infof("alias %s movido de %s a %s (rollback)", s.alias, current, target)
+
+	count, err := s.indexerFor(target).Count(ctx)
+	if err != nil {
+		log.Warnf("no se pudo contar los documentos de %s: %v", target, err)
+	}
+
+	return RebuildResult{
+		Alias:      s.alias,
+		Collection: target,
+		Previous:   current,
+		Documents:  count,
+	}, nil
+}
+
+func (s *IndexRebuildService) populate(ctx context.Context, indexer CollectionIndexer, activities []dto.Activity) (int, error) {
+	if err := indexer.IndexMany(ctx, activities); err != nil {
+		return 0, err
+	}
+	// hard commit para que el conteo de documentos sea exacto antes de mover el alias
+	if err := indexer.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return indexer.Count(ctx)
+}
+
+func (s *IndexRebuildService) discard(ctx context.Context, collection string) {
+	if err := s.admin.DeleteCollection(ctx, collection); err != nil {
+		log.Errorf("no se pudo eliminar la coleccion descartada %s: %v", collection, err)
+		return
+	}
+	log.Warnf("coleccion %s descartada", collection)
+}
+
+// pruneOldCollections elimina las colecciones más viejas dejando keepCollections (incluida la actual)
+func (s *IndexRebuildService) pruneOldCollections(ctx context.Context, current string) {
+	collections, err := s.ownCollections(ctx)
+	if err != nil {
+		log.Warnf("no se pudieron listar las colecciones para limpiar: %v", err)
+		return
+	}
+
+	for len(collections) > s.keepCollections {
+		oldest := collections[0]
+		collections = collections[1:]
+		if oldest == current {
+			continue
+		}
+		if err := s.admin.DeleteCollection(ctx, oldest); err != nil {
+			log.Warnf("no se pudo eliminar la coleccion vieja %s: %v", oldest, err)
+			continue
+		}
+		log.Infof("coleccion vieja %s eliminada", oldest)
+	}
+}
+
+// ownCollections devuelve, ordenadas de la más vieja a la más nueva, las colecciones creadas para el alias
+func (s *IndexRebuildService) ownCollections(ctx context.Context) ([]string, error) {
+	all, err := s.admin.ListCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := s.alias + "_"
+	var own []string
+	for _, c := range all {
+		if strings.HasPrefix(c, prefix) {
+			own = append(own, c)
+		}
+	}
+	sort.Strings(own)
+	return own, nil
+}
+
+// newCollectionName arma el nombre de la colección nueva con la fecha y hora actual. Si ya hay una
+// colección con ese nombre (dos rebuilds en el mismo segundo) agrega un sufijo que mantiene el
+// orden de creación al ordenar los nombres.
+func (s *IndexRebuildService) newCollectionName(ctx context.Context) (string, error) {
+	existing, err := s.admin.ListCollections(ctx)
+	if err != nil {
+		return "", err
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, c := range existing {
+		taken[c] = true
+	}
+
+	base := fmt.Sprintf("%s_%s", s.alias, time.Now().UTC().Format("20060102150405"))
+	name := base
+	for i := 1; taken[name]; i++ {
+		name = fmt.Sprintf("%s_%03d", base, i)
+	}
+	return name, nil
+}
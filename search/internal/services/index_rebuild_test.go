@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"search/internal/dto"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// mockSolrAdmin guarda las colecciones y alias en memoria
+type mockSolrAdmin struct {
+	collections []string
+	aliases     map[string]string
+}
+
+func (m *mockSolrAdmin) CreateCollection(ctx context.Context, name, configSet string) error {
+	for _, c := range m.collections {
+		if c == name {
+			return errors.New("collection already exists")
+		}
+	}
+	m.collections = append(m.collections, name)
+	return nil
+}
+
+func (m *mockSolrAdmin) DeleteCollection(ctx context.Context, name string) error {
+	for i, c := range m.collections {
+		if c == name {
+			m.collections = append(m.collections[:i], m.collections[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("collection not found")
+}
+
+func (m *mockSolrAdmin) ListCollections(ctx context.Context) ([]string, error) {
+	return append([]string(nil), m.collections...), nil
+}
+
+func (m *mockSolrAdmin) GetAliases(ctx context.Context) (map[string]string, error) {
+	return m.aliases, nil
+}
+
+func (m *mockSolrAdmin) CreateAlias(ctx context.Context, alias, collection string) error {
+	m.aliases[alias] = collection
+	return nil
+}
+
+// mockIndexer guarda los documentos de una colección en memoria
+type mockIndexer struct {
+	docs      map[string]dto.Activity
+	deleteErr error
+}
+
+func (m *mockIndexer) IndexMany(ctx context.Context, activities []dto.Activity) error {
+	for _, activity := range activities {
+		m.docs[activity.ID] = activity
+	}
+	return nil
+}
+
+func (m *mockIndexer) DeleteMany(ctx context.Context, ids []string) error {
+	if m.deleteErr != nil {
+		return m.deleteErr
+	}
+	for _, id := range ids {
+		delete(m.docs, id)
+	}
+	return nil
+}
+
+func (m *mockIndexer) Commit(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockIndexer) Count(ctx context.Context) (int, error) {
+	return len(m.docs), nil
+}
+
+func newRebuildService(admin *mockSolrAdmin, indexers map[string]*mockIndexer, fetches ...[]dto.Activity) IndexRebuildService {
+	service := NewIndexRebuildService(admin, func(collection string) CollectionIndexer {
+		if indexers[collection] == nil {
+			indexers[collection] = &mockIndexer{docs: map[string]dto.Activity{}}
+		}
+		return indexers[collection]
+	}, "demo", "_default", 2)
+	service.fetchActivities = func(ctx context.Context) ([]dto.Activity, error) {
+		activities := fetches[0]
+		if len(fetches) > 1 {
+			fetches = fetches[1:]
+		}
+		return activities, nil
+	}
+	return service
+}
+
+// TestRebuild tests the collection swap and the changes made while the collection was built
+func TestRebuild(t *testing.T) {
+	ctx := context.Background()
+	snapshot := []dto.Activity{
+		{ID: "a", Titulo: "Yoga", DiaSemana: "Lunes"},
+		{ID: "b", Titulo: "Pilates", DiaSemana: "Martes"},
+		{ID: "c", Titulo: "Spinning", DiaSemana: "Jueves"},
+	}
+	current := []dto.Activity{
+		{ID: "a", Titulo: "Yoga", DiaSemana: "Lunes"},
+		{ID: "b", Titulo: "Pilates avanzado", DiaSemana: "Martes"},
+		{ID: "d", Titulo: "Boxeo", DiaSemana: "Viernes"},
+	}
+
+	t.Run("changes during the rebuild reach the new collection", func(t *testing.T) {
+		admin := &mockSolrAdmin{collections: []string{"demo_old"}, aliases: map[string]string{"demo": "demo_old"}}
+		indexers := map[string]*mockIndexer{}
+		service := newRebuildService(admin, indexers, snapshot, current)
+
+		result, err := service.Rebuild(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if admin.aliases["demo"] != result.Collection || result.Previous != "demo_old" || result.Documents != 3 || result.Reconciled != 3 {
+			t.Errorf("unexpected result %+v (aliases %v)", result, admin.aliases)
+		}
+
+		docs := indexers[result.Collection].docs
+		if len(docs) != 3 || docs["b"].Titulo != "Pilates avanzado" || docs["d"].ID != "d" {
+			t.Errorf("expected the new collection to match the current activities, got %+v", docs)
+		}
+		if _, ok := docs["c"]; ok {
+			t.Error("expected the activity removed during the rebuild to be deleted")
+		}
+	})
+
+	t.Run("reconcile failure keeps the previous collection", func(t *testing.T) {
+		admin := &mockSolrAdmin{collections: []string{"demo_1", "demo_2"}, aliases: map[string]string{"demo": "demo_2"}}
+		indexers := map[string]*mockIndexer{}
+		service := newRebuildService(admin, indexers, snapshot, current)
+		indexerFor := service.indexerFor
+		service.indexerFor = func(collection string) CollectionIndexer {
+			indexer := indexerFor(collection).(*mockIndexer)
+			indexer.deleteErr = errors.New("solr unavailable")
+			return indexer
+		}
+
+		result, err := service.Rebuild(ctx)
+		if !errors.Is(err, ErrReconcileFailed) {
+			t.Fatalf("expected ErrReconcileFailed, got %v", err)
+		}
+		if admin.aliases["demo"] != result.Collection || result.Previous != "demo_2" {
+			t.Errorf("expected the alias moved to the new collection, got %+v (aliases %v)", result, admin.aliases)
+		}
+		if len(admin.collections) != 3 {
+			t.Errorf("expected no collection to be pruned, got %v", admin.collections)
+		}
+	})
+}
+
+// TestNewCollectionName tests that collections created in the same second get different names
+func TestNewCollectionName(t *testing.T) {
+	ctx := context.Background()
+	admin := &mockSolrAdmin{aliases: map[string]string{}}
+	service := newRebuildService(admin, map[string]*mockIndexer{}, nil)
+
+	var names []string
+	for i := 0; i < 3; i++ {
+		name, err := service.newCollectionName(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := admin.CreateCollection(ctx, name, "_default"); err != nil {
+			t.Fatalf("expected a free name, got %s: %v", name, err)
+		}
+		names = append(names, name)
+	}
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, "demo_") {
+			t.Errorf("expected the alias prefix, got %s", name)
+		}
+	}
+	// si las tres cayeron en el mismo segundo, los sufijos mantienen el orden de creación
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	if strings.Join(sorted, ",") != strings.Join(names, ",") {
+		t.Errorf("expected names in creation order, got %v", names)
+	}
+}
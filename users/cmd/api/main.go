@@ -3,6 +3,7 @@ package main
 import (
 	"net/http"
 	"time"
+	"users/internal/clients"
 	"users/internal/config"
 	"users/internal/controllers"
 	"users/internal/middleware"
@@ -18,7 +19,19 @@ func main() {
 	cfg := config.Load()
 
 	usersMySQLRepo := repository.NewMySQLUsersRepository(cfg.MySQL)
-	userService := services.NewUsersService(usersMySQLRepo, cfg.JwtSecret)
+	rabbitClient, err := clients.NewRabbitMQClient(
+		cfg.RabbitMQ.Host,
+		cfg.RabbitMQ.Port,
+		cfg.RabbitMQ.User,
+		cfg.RabbitMQ.Pass,
+		cfg.RabbitMQ.QueueName,
+	)
+	if err != nil {
+		log.Fatalf("no se pudo iniciar el cliente de RabbitMQ: %v", err)
+	}
+	defer rabbitClient.Close()
+
+	userService := services.NewUsersService(usersMySQLRepo, cfg.JwtSecret, rabbitClient)
 	userController := controllers.NewUsersController(&userService)
 
 	router := gin.New()
@@ -31,6 +44,8 @@ func main() {
 	})
 
 	router.GET("/users", userController.GetAll)
+	router.GET("/users/many", userController.GetMany)
+	router.POST("/users/many", userController.PostMany)
 	router.GET("/users/:id", userController.GetByID)
 	router.POST("/register", userController.Create)
 	router.POST("/login", userController.Login)
@@ -38,6 +53,16 @@ func main() {
 	router.PUT("/users/:id", middleware.AuthMiddleware(cfg.JwtSecret), userController.Update)
 	router.DELETE("/users/:id", middleware.AuthMiddleware(cfg.JwtSecret), userController.Delete)
 
+	router.GET("/plans", userController.GetPlans)
+	router.POST("/plans", middleware.AuthMiddleware(cfg.JwtSecret), userController.CreatePlan)
+	router.PUT("/plans/:id", middleware.AuthMiddleware(cfg.JwtSecret), userController.UpdatePlan)
+	router.GET("/users/:id/plan", middleware.AuthMiddleware(cfg.JwtSecret), userController.GetMembership)
+	router.PUT("/users/:id/plan", middleware.AuthMiddleware(cfg.JwtSecret), userController.SetMembership)
+
+	// endpoints internos, para los otros servicios
+	internal := router.Group("/internal", middleware.InternalMiddleware(cfg.InternalAPIKey))
+	internal.GET("/users/:id/entitlement", userController.GetEntitlement)
+
 	srv := &http.Server{
 		Addr:              ":" + cfg.Port,
 		Handler:           router,
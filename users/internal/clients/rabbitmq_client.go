@@ -0,0 +1,86 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"users/internal/dto"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// RabbitMQClient publica los eventos de usuarios
+type RabbitMQClient struct {
+	conn      *amqp.Connection
+	channel   *amqp.Channel
+	queueName string
+}
+
+// NewRabbitMQClient intenta conectar con reintentos
+func NewRabbitMQClient(host, port, user, pass, queueName string) (*RabbitMQClient, error) {
+	url := fmt.Sprintf("amqp://%s:%s@%s:%s/", user, pass, host, port)
+
+	var conn *amqp.Connection
+	var err error
+
+	maxRetries := 6
+	for i := 0; i < maxRetries; i++ {
+		conn, err = amqp.Dial(url)
+		if err == nil {
+			break
+		}
+		wait := time.Duration((i+1)*2) * time.Second
+		log.Warnf("no se pudo conectar a RabbitMQ (intento %d/%d): %v - reintentando en %v", i+1, maxRetries, err, wait)
+		time.Sleep(wait)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al conectar a RabbitMQ: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error al abrir el canal: %w", err)
+	}
+
+	if _, err := ch.QueueDeclare(queueName, true, false, false, false, nil); err != nil {
+		ch.Close()
+		conn.Close()
+		return nil, fmt.Errorf("error al declarar la cola: %w", err)
+	}
+
+	log.Infof("conectado a RabbitMQ %s:%s cola=%s", host, port, queueName)
+	return &RabbitMQClient{conn: conn, channel: ch, queueName: queueName}, nil
+}
+
+// PublishEvent publica un evento de usuario
+func (r *RabbitMQClient) PublishEvent(ctx context.Context, event dto.UserEventDTO) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	pubCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return r.channel.PublishWithContext(pubCtx, "", r.queueName, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		Type:         event.Type,
+		Body:         b,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    event.Timestamp,
+	})
+}
+
+// Close cierra canal y conexión
+func (r *RabbitMQClient) Close() error {
+	if r.channel != nil {
+		if err := r.channel.Close(); err != nil {
+			return err
+		}
+	}
+	if r.conn != nil {
+		return r.conn.Close()
+	}
+	return nil
+}
@@ -9,7 +9,19 @@ import (
 type Config struct {
 	Port      string
 	MySQL     MySQLConfig
+	RabbitMQ  RabbitMQConfig
 	JwtSecret string
+	// clave que usan los otros servicios para los endpoints internos
+	InternalAPIKey string
+}
+
+type RabbitMQConfig struct {
+	Host string
+	Port string
+	User string
+	Pass string
+	// cola de eventos de usuarios (user.deleted) que consume activities-api
+	QueueName string
 }
 
 type MySQLConfig struct {
@@ -44,7 +56,15 @@ func Load() Config {
 			DB_PORT:   getEnv("DB_PORT", "3306"),
 			DB_SCHEMA: getEnv("DB_SCHEMA", "users"),
 		},
-		JwtSecret: secret,
+		RabbitMQ: RabbitMQConfig{
+			Host:      getEnv("RABBITMQ_HOST", "rabbit-search-api"),
+			Port:      getEnv("RABBITMQ_PORT", "5672"),
+			User:      getEnv("RABBITMQ_USER", "admin"),
+			Pass:      getEnv("RABBITMQ_PASS", "admin"),
+			QueueName: getEnv("RABBITMQ_USERS_QUEUE", "users"),
+		},
+		JwtSecret:      secret,
+		InternalAPIKey: getEnv("INTERNAL_API_KEY", ""),
 	}
 
 	log.Infoln("=== variables de entorno ===")
@@ -55,10 +75,18 @@ func Load() Config {
 	log.Infoln("DB_HOST:", cfg.MySQL.DB_HOST)
 	log.Infoln("DB_PORT:", cfg.MySQL.DB_PORT)
 	log.Infoln("DB_SCHEMA:", cfg.MySQL.DB_SCHEMA)
+	log.Infoln("RABBITMQ_HOST:", cfg.RabbitMQ.Host)
+	log.Infoln("RABBITMQ_PORT:", cfg.RabbitMQ.Port)
+	log.Infoln("RABBITMQ_USERS_QUEUE:", cfg.RabbitMQ.QueueName)
 	log.Infoln("JWT_SECRET:", cfg.JwtSecret)
+	log.Infoln("INTERNAL_API_KEY:", cfg.InternalAPIKey)
 	log.Infoln()
 	log.Infoln("==================================")
 
+	if cfg.InternalAPIKey == "" {
+		log.Warnln("INTERNAL_API_KEY no configurada: los endpoints internos quedan deshabilitados")
+	}
+
 	return cfg
 }
 
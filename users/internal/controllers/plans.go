@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"users/internal/dto"
+	"users/internal/repository"
+	"users/internal/services"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// requireAdmin responde 401/403 si quien hace la petición no es admin
+func requireAdmin(ctx *gin.Context, action string) bool {
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return false
+	}
+	if !isAdminFromClaims(claims) {
+		log.Warnf("operacion sin privilegios para el usuario: %s@%s", claims["username"], ctx.RemoteIP())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "only admin users can " + action})
+		return false
+	}
+	return true
+}
+
+// paramID lee el ID numérico del parámetro name. Si no es un número responde 400.
+func paramID(ctx *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param(name))
+	if err != nil {
+		ctx.Error(fmt.Errorf("no se pudo obtener el ID del parámetro de la consulta: %s", err.Error()))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID con formato incorrecto. Debe ser un número"})
+		return 0, false
+	}
+	return id, true
+}
+
+func (c *UsersController) GetPlans(ctx *gin.Context) {
+	planes, err := c.service.GetPlans()
+	if err != nil {
+		ctx.Error(fmt.Errorf("error al obtener los planes: %v", err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error al obtener planes"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, planes)
+}
+
+func (c *UsersController) CreatePlan(ctx *gin.Context) {
+	if !requireAdmin(ctx, "create plans") {
+		return
+	}
+
+	var plan dto.PlanDTO
+	if err := ctx.BindJSON(&plan); err != nil {
+		ctx.Error(fmt.Errorf("error al parsear body al crear plan: %v", err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto"})
+		return
+	}
+
+	created, err := c.service.CreatePlan(plan)
+	if err != nil {
+		c.respondPlanError(ctx, err, "crear")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, created)
+}
+
+func (c *UsersController) UpdatePlan(ctx *gin.Context) {
+	if !requireAdmin(ctx, "update plans") {
+		return
+	}
+	id, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var plan dto.PlanDTO
+	if err := ctx.BindJSON(&plan); err != nil {
+		ctx.Error(fmt.Errorf("error al parsear body al actualizar plan: %v", err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto"})
+		return
+	}
+
+	updated, err := c.service.UpdatePlan(id, plan)
+	if err != nil {
+		c.respondPlanError(ctx, err, "actualizar")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
+func (c *UsersController) respondPlanError(ctx *gin.Context, err error, accion string) {
+	ctx.Error(fmt.Errorf("error al %s plan: %v", accion, err))
+	switch {
+	case errors.Is(err, services.ErrInvalidPlan):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrPlanNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "plan no encontrado"})
+	case errors.Is(err, repository.ErrDuplicatePlan):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "ya existe un plan con ese nombre"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error al " + accion + " plan"})
+	}
+}
+
+// GetMembership devuelve el plan del usuario. Cada usuario puede ver el suyo; un admin, el de cualquiera.
+func (c *UsersController) GetMembership(ctx *gin.Context) {
+	id, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+	claims, ok := getClaimsFromContext(ctx)
+	if !ok {
+		log.Warnf("token sin claims")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "missing token claims"})
+		return
+	}
+	if uid, _ := claims["id_usuario"].(float64); int(uid) != id && !isAdminFromClaims(claims) {
+		log.Warnf("operacion sin privilegios para el usuario: %s@%s", claims["username"], ctx.RemoteIP())
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "users can only see their own plan"})
+		return
+	}
+
+	membership, err := c.service.GetMembership(id)
+	if err != nil {
+		ctx.Error(fmt.Errorf("error al obtener el plan del usuario %d: %v", id, err))
+		switch {
+		case errors.Is(err, repository.ErrUserNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "usuario no encontrado"})
+		case errors.Is(err, services.ErrSinPlan):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error al obtener el plan"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, membership)
+}
+
+// SetMembership asigna un plan a un usuario (solo admin)
+func (c *UsersController) SetMembership(ctx *gin.Context) {
+	if !requireAdmin(ctx, "assign plans") {
+		return
+	}
+	id, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var membership dto.MembershipDTO
+	if err := ctx.BindJSON(&membership); err != nil {
+		ctx.Error(fmt.Errorf("error al parsear body al asignar plan: %v", err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto"})
+		return
+	}
+
+	result, err := c.service.SetMembership(id, membership)
+	if err != nil {
+		ctx.Error(fmt.Errorf("error al asignar plan al usuario %d: %v", id, err))
+		switch {
+		case errors.Is(err, services.ErrInvalidMembership):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, repository.ErrPlanNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "plan no encontrado"})
+		case errors.Is(err, repository.ErrUserNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "usuario no encontrado"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error al asignar plan"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetEntitlement maneja GET /internal/users/:id/entitlement?fecha=YYYY-MM-DD: si el usuario tiene
+// un plan vigente ese día y su cuota semanal. Lo usa activities-api al inscribir.
+func (c *UsersController) GetEntitlement(ctx *gin.Context) {
+	id, ok := paramID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	entitlement, err := c.service.GetEntitlement(id, ctx.Query("fecha"))
+	if err != nil {
+		ctx.Error(fmt.Errorf("error al verificar el plan del usuario %d: %v", id, err))
+		switch {
+		case errors.Is(err, services.ErrInvalidFecha):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, repository.ErrUserNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "usuario no encontrado"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error al verificar el plan"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entitlement)
+}
@@ -161,6 +161,64 @@ func (c *UsersController) GetAll(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, usuarios)
 }
 
+// GetMany maneja GET /users/many?ids=1,2,3
+func (c *UsersController) GetMany(ctx *gin.Context) {
+	idsParam := ctx.Query("ids")
+	if idsParam == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "el parámetro ids es obligatorio"})
+		return
+	}
+
+	ids := []int{}
+	for _, idStr := range strings.Split(idsParam, ",") {
+		idStr = strings.TrimSpace(idStr)
+		if idStr == "" {
+			continue
+		}
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			ctx.Error(fmt.Errorf("ID con formato incorrecto en la consulta: %s", idStr))
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID con formato incorrecto. Debe ser un número"})
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	c.respondMany(ctx, ids)
+}
+
+// PostMany maneja POST /users/many con body {"ids": [1, 2, 3]}, para listas que no entran en la URL
+func (c *UsersController) PostMany(ctx *gin.Context) {
+	var datos dto.UsersManyDTO
+	if err := ctx.BindJSON(&datos); err != nil {
+		ctx.Error(fmt.Errorf("no se pudo procesar la lista de IDs: %s", err.Error()))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Datos con formato incorrecto"})
+		return
+	}
+
+	c.respondMany(ctx, datos.Ids)
+}
+
+func (c *UsersController) respondMany(ctx *gin.Context, ids []int) {
+	if len(ids) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "se debe indicar al menos un ID"})
+		return
+	}
+
+	usuarios, err := c.service.GetMany(ids)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidIDs) || errors.Is(err, services.ErrTooManyIDs) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.Error(fmt.Errorf("error al buscar varios usuarios: %v", err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error al buscar usuarios"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"users": usuarios, "count": len(usuarios)})
+}
+
 func (c *UsersController) Update(ctx *gin.Context) {
 	id_str := ctx.Param("id")
 
@@ -235,6 +293,10 @@ func (c *UsersController) Delete(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "usuario no encontrado"})
 			return
 		}
+		if errors.Is(err, services.ErrPublishEvent) && !errors.Is(err, services.ErrRollback) {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "no se pudo notificar la baja a los otros servicios; el usuario no se eliminó, reintente más tarde"})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error al eliminar usuario"})
 		return
 	}
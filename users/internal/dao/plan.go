@@ -0,0 +1,9 @@
+package dao
+
+type Plan struct {
+	Id              int    `gorm:"column:id_plan;primaryKey;autoIncrement"`
+	Nombre          string `gorm:"type:varchar(50);unique;not null"`
+	ClasesSemanales int    `gorm:"column:clases_semanales;default:0;not null"` // 0 = ilimitado
+}
+
+type Plans []Plan
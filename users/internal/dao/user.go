@@ -1,5 +1,7 @@
 package dao
 
+import "time"
+
 type User struct {
 	Id       int    `gorm:"column:id_usuario;primaryKey;autoIncrement"`
 	Nombre   string `gorm:"type:varchar(30);not null"`
@@ -8,6 +10,11 @@ type User struct {
 	Email    string `gorm:"type:varchar(60);unique;not null"`
 	Password string `gorm:"type:varchar(60);collation:utf8mb4_bin;not null"`
 	IsAdmin  bool   `gorm:"column:is_admin;default:false;not null"`
+
+	// plan contratado y su vigencia (inclusive)
+	PlanId    *int       `gorm:"column:id_plan"`
+	PlanDesde *time.Time `gorm:"column:plan_desde;type:date"`
+	PlanHasta *time.Time `gorm:"column:plan_hasta;type:date"`
 }
 
 type Users []User
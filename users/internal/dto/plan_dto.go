@@ -0,0 +1,37 @@
+package dto
+
+const FechaLayout = "2006-01-02"
+
+type PlanDTO struct {
+	Id              int    `json:"id_plan"`
+	Nombre          string `json:"nombre"`
+	ClasesSemanales int    `json:"clases_semanales"` // 0 = ilimitado
+}
+
+type PlansDTO []PlanDTO
+
+// MembershipDTO es el plan asignado a un usuario, vigente entre desde y hasta (inclusive, YYYY-MM-DD)
+type MembershipDTO struct {
+	IdPlan int    `json:"id_plan"`
+	Desde  string `json:"desde"`
+	Hasta  string `json:"hasta"`
+}
+
+// Motivos por los que un usuario no puede inscribirse a clases
+const (
+	MotivoSinPlan        = "sin_plan"
+	MotivoPlanNoIniciado = "plan_no_iniciado"
+	MotivoPlanVencido    = "plan_vencido"
+)
+
+// EntitlementDTO indica si el usuario puede inscribirse a clases en una fecha según su plan
+type EntitlementDTO struct {
+	IdUsuario       int      `json:"id_usuario"`
+	Fecha           string   `json:"fecha"`
+	Vigente         bool     `json:"vigente"`
+	Motivo          string   `json:"motivo,omitempty"`
+	Plan            *PlanDTO `json:"plan,omitempty"`
+	Desde           string   `json:"desde,omitempty"`
+	Hasta           string   `json:"hasta,omitempty"`
+	ClasesSemanales int      `json:"clases_semanales"` // 0 = ilimitado
+}
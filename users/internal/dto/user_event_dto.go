@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+// EventUserDeleted se publica cuando se elimina un usuario, para que los otros servicios quiten sus datos
+const EventUserDeleted = "user.deleted"
+
+// UserEventDTO es el evento que se publica en la cola de usuarios
+type UserEventDTO struct {
+	Type      string    `json:"type"`
+	UserID    int       `json:"id_usuario"`
+	Timestamp time.Time `json:"timestamp"`
+}
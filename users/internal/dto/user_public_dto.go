@@ -0,0 +1,16 @@
+package dto
+
+// UserPublicDTO son los datos de un usuario que se comparten con otros servicios (sin credenciales)
+type UserPublicDTO struct {
+	Id       int    `json:"id_usuario"`
+	Nombre   string `json:"nombre"`
+	Apellido string `json:"apellido"`
+	Email    string `json:"email"`
+}
+
+type UsersPublicDTO []UserPublicDTO
+
+// UsersManyDTO es el body de POST /users/many
+type UsersManyDTO struct {
+	Ids []int `json:"ids"`
+}
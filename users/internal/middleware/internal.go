@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InternalKeyHeader es el header con el que los otros servicios se autentican en los endpoints internos
+const InternalKeyHeader = "X-Internal-Key"
+
+// InternalMiddleware protege los endpoints que solo usan los otros servicios. Sin clave
+// configurada los endpoints quedan deshabilitados.
+func InternalMiddleware(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key == "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "internal endpoints are disabled"})
+			return
+		}
+
+		got := c.GetHeader(InternalKeyHeader)
+		if subtle.ConstantTimeCompare([]byte(got), []byte(key)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid internal key"})
+			return
+		}
+
+		c.Next()
+	}
+}
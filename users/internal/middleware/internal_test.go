@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestInternalMiddleware tests the internal key required by the service endpoints
+func TestInternalMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		key    string
+		header string
+		status int
+	}{
+		{"valid key", "secret", "secret", http.StatusOK},
+		{"missing key", "secret", "", http.StatusUnauthorized},
+		{"wrong key", "secret", "other", http.StatusUnauthorized},
+		{"disabled without configured key", "", "", http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/internal", InternalMiddleware(tt.key), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/internal", nil)
+			if tt.header != "" {
+				req.Header.Set(InternalKeyHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
@@ -7,4 +7,6 @@ var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrDuplicateUsername = errors.New("username already exists")
 	ErrDuplicateEmail    = errors.New("email already exists")
+	ErrPlanNotFound      = errors.New("plan not found")
+	ErrDuplicatePlan     = errors.New("plan name already exists")
 )
@@ -24,8 +24,15 @@ type UsersRepository interface {
 	GetUserByUsername(username string) (dao.User, error)
 	GetUserByEmail(email string) (dao.User, error)
 	GetAll() ([]dao.User, error)
+	GetUsersByIDs(ids []int) ([]dao.User, error)
 	Update(id int, user dao.User) (dao.User, error)
 	Delete(id int) error
+
+	CreatePlan(plan dao.Plan) (dao.Plan, error)
+	GetPlanByID(id int) (dao.Plan, error)
+	GetPlans() ([]dao.Plan, error)
+	UpdatePlan(id int, plan dao.Plan) (dao.Plan, error)
+	SetMembership(id int, planID int, desde, hasta time.Time) (dao.User, error)
 }
 
 type MySQLUsersRepository struct {
@@ -67,7 +74,7 @@ func NewMySQLUsersRepository(cfg config.MySQLConfig) *MySQLUsersRepository {
 	}
 
 	log.Info("conexion a base de datos establecida")
-	conn.AutoMigrate(&dao.User{})
+	conn.AutoMigrate(&dao.Plan{}, &dao.User{})
 
 	repo := &MySQLUsersRepository{
 		db:  conn,
@@ -239,6 +246,22 @@ func (r *MySQLUsersRepository) GetAll() ([]dao.User, error) {
 	return usuarios, nil
 }
 
+// GetUsersByIDs busca los usuarios de la lista en una sola consulta; los que no existen se omiten
+func (r *MySQLUsersRepository) GetUsersByIDs(ids []int) ([]dao.User, error) {
+	var usuarios []dao.User
+
+	err := r.db.Where("id_usuario IN ?", ids).Find(&usuarios).Error
+	if err != nil {
+		if r.isConnectionError(err) {
+			log.Errorf("error al conectar a la BDD: %s", err.Error())
+			go r.reconnect()
+		}
+		return nil, err
+	}
+
+	return usuarios, nil
+}
+
 func (r *MySQLUsersRepository) Update(id int, user dao.User) (dao.User, error) {
 	// Usar Save para asegurar que todos los campos se actualicen, incluyendo booleanos
 	user.Id = id
@@ -268,3 +291,88 @@ func (r *MySQLUsersRepository) Delete(id int) error {
 	}
 	return nil
 }
+
+func (r *MySQLUsersRepository) CreatePlan(plan dao.Plan) (dao.Plan, error) {
+	err := r.db.Create(&plan).Error
+	if err != nil {
+		if mysqlErr, ok := err.(*mysqlerr.MySQLError); ok && mysqlErr.Number == 1062 {
+			return dao.Plan{}, ErrDuplicatePlan
+		}
+		if r.isConnectionError(err) {
+			log.Errorf("error al conectar a la BDD: %s", err.Error())
+			go r.reconnect()
+		}
+		return dao.Plan{}, err
+	}
+
+	return plan, nil
+}
+
+func (r *MySQLUsersRepository) GetPlanByID(id int) (dao.Plan, error) {
+	var plan dao.Plan
+
+	err := r.db.Where("id_plan = ?", id).First(&plan).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dao.Plan{}, ErrPlanNotFound
+		}
+		if r.isConnectionError(err) {
+			log.Errorf("error al conectar a la BDD: %s", err.Error())
+			go r.reconnect()
+		}
+		return dao.Plan{}, err
+	}
+
+	return plan, nil
+}
+
+func (r *MySQLUsersRepository) GetPlans() ([]dao.Plan, error) {
+	var planes []dao.Plan
+
+	err := r.db.Order("id_plan").Find(&planes).Error
+	if err != nil {
+		if r.isConnectionError(err) {
+			log.Errorf("error al conectar a la BDD: %s", err.Error())
+			go r.reconnect()
+		}
+		return nil, err
+	}
+
+	return planes, nil
+}
+
+func (r *MySQLUsersRepository) UpdatePlan(id int, plan dao.Plan) (dao.Plan, error) {
+	plan.Id = id
+	err := r.db.Save(&plan).Error
+	if err != nil {
+		if mysqlErr, ok := err.(*mysqlerr.MySQLError); ok && mysqlErr.Number == 1062 {
+			return dao.Plan{}, ErrDuplicatePlan
+		}
+		if r.isConnectionError(err) {
+			log.Errorf("error al conectar a la BDD: %s", err.Error())
+			go r.reconnect()
+		}
+		return dao.Plan{}, err
+	}
+
+	return plan, nil
+}
+
+// SetMembership asigna el plan al usuario con su vigencia
+func (r *MySQLUsersRepository) SetMembership(id int, planID int, desde, hasta time.Time) (dao.User, error) {
+	result := r.db.Model(&dao.User{}).Where("id_usuario = ?", id).Updates(map[string]any{
+		"id_plan":    planID,
+		"plan_desde": desde,
+		"plan_hasta": hasta,
+	})
+	if result.Error != nil {
+		if r.isConnectionError(result.Error) {
+			log.Errorf("error al conectar a la BDD: %s", result.Error.Error())
+			go r.reconnect()
+		}
+		return dao.User{}, result.Error
+	}
+
+	// RowsAffected es 0 también si no cambió nada: confirmamos que el usuario exista
+	return r.GetUserByID(id)
+}
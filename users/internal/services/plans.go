@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"users/internal/dao"
+	"users/internal/dto"
+)
+
+var (
+	ErrInvalidPlan       error = errors.New("el plan debe tener nombre y una cantidad de clases semanales no negativa")
+	ErrInvalidMembership error = errors.New("la vigencia del plan debe tener fechas desde y hasta (YYYY-MM-DD), con desde anterior o igual a hasta")
+	ErrInvalidFecha      error = errors.New("la fecha debe tener formato YYYY-MM-DD")
+	ErrSinPlan           error = errors.New("el usuario no tiene un plan asignado")
+)
+
+func planToDTO(plan dao.Plan) dto.PlanDTO {
+	return dto.PlanDTO{
+		Id:              plan.Id,
+		Nombre:          plan.Nombre,
+		ClasesSemanales: plan.ClasesSemanales,
+	}
+}
+
+func validatePlan(plan dto.PlanDTO) error {
+	if strings.TrimSpace(plan.Nombre) == "" || plan.ClasesSemanales < 0 {
+		return ErrInvalidPlan
+	}
+	return nil
+}
+
+func (s *UsersServiceImpl) GetPlans() ([]dto.PlanDTO, error) {
+	planes, err := s.repository.GetPlans()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []dto.PlanDTO{}
+	for _, p := range planes {
+		result = append(result, planToDTO(p))
+	}
+	return result, nil
+}
+
+func (s *UsersServiceImpl) CreatePlan(plan dto.PlanDTO) (dto.PlanDTO, error) {
+	if err := validatePlan(plan); err != nil {
+		return dto.PlanDTO{}, err
+	}
+
+	created, err := s.repository.CreatePlan(dao.Plan{
+		Nombre:          strings.TrimSpace(plan.Nombre),
+		ClasesSemanales: plan.ClasesSemanales,
+	})
+	if err != nil {
+		return dto.PlanDTO{}, err
+	}
+	return planToDTO(created), nil
+}
+
+func (s *UsersServiceImpl) UpdatePlan(id int, plan dto.PlanDTO) (dto.PlanDTO, error) {
+	if err := validatePlan(plan); err != nil {
+		return dto.PlanDTO{}, err
+	}
+	if _, err := s.repository.GetPlanByID(id); err != nil {
+		return dto.PlanDTO{}, err
+	}
+
+	updated, err := s.repository.UpdatePlan(id, dao.Plan{
+		Nombre:          strings.TrimSpace(plan.Nombre),
+		ClasesSemanales: plan.ClasesSemanales,
+	})
+	if err != nil {
+		return dto.PlanDTO{}, err
+	}
+	return planToDTO(updated), nil
+}
+
+// GetMembership devuelve el plan asignado al usuario y su vigencia
+func (s *UsersServiceImpl) GetMembership(id int) (dto.MembershipDTO, error) {
+	userData, err := s.repository.GetUserByID(id)
+	if err != nil {
+		return dto.MembershipDTO{}, err
+	}
+	if userData.PlanId == nil || userData.PlanDesde == nil || userData.PlanHasta == nil {
+		return dto.MembershipDTO{}, ErrSinPlan
+	}
+
+	return dto.MembershipDTO{
+		IdPlan: *userData.PlanId,
+		Desde:  userData.PlanDesde.Format(dto.FechaLayout),
+		Hasta:  userData.PlanHasta.Format(dto.FechaLayout),
+	}, nil
+}
+
+// SetMembership asigna un plan al usuario, reemplazando el anterior
+func (s *UsersServiceImpl) SetMembership(id int, membership dto.MembershipDTO) (dto.MembershipDTO, error) {
+	desde, errDesde := time.ParseInLocation(dto.FechaLayout, membership.Desde, time.Local)
+	hasta, errHasta := time.ParseInLocation(dto.FechaLayout, membership.Hasta, time.Local)
+	if errDesde != nil || errHasta != nil || hasta.Before(desde) {
+		return dto.MembershipDTO{}, ErrInvalidMembership
+	}
+	if _, err := s.repository.GetPlanByID(membership.IdPlan); err != nil {
+		return dto.MembershipDTO{}, err
+	}
+
+	if _, err := s.repository.SetMembership(id, membership.IdPlan, desde, hasta); err != nil {
+		return dto.MembershipDTO{}, err
+	}
+	return s.GetMembership(id)
+}
+
+// GetEntitlement indica si el usuario puede inscribirse a clases en la fecha (hoy si es vacía):
+// tiene que tener un plan vigente ese día. Incluye la cuota semanal del plan.
+func (s *UsersServiceImpl) GetEntitlement(id int, fecha string) (dto.EntitlementDTO, error) {
+	if fecha == "" {
+		fecha = time.Now().Format(dto.FechaLayout)
+	} else if _, err := time.ParseInLocation(dto.FechaLayout, fecha, time.Local); err != nil {
+		return dto.EntitlementDTO{}, ErrInvalidFecha
+	}
+
+	membership, err := s.GetMembership(id)
+	if errors.Is(err, ErrSinPlan) {
+		return dto.EntitlementDTO{IdUsuario: id, Fecha: fecha, Motivo: dto.MotivoSinPlan}, nil
+	}
+	if err != nil {
+		return dto.EntitlementDTO{}, err
+	}
+
+	plan, err := s.repository.GetPlanByID(membership.IdPlan)
+	if err != nil {
+		return dto.EntitlementDTO{}, err
+	}
+	planDTO := planToDTO(plan)
+
+	entitlement := dto.EntitlementDTO{
+		IdUsuario:       id,
+		Fecha:           fecha,
+		Plan:            &planDTO,
+		Desde:           membership.Desde,
+		Hasta:           membership.Hasta,
+		ClasesSemanales: plan.ClasesSemanales,
+	}
+	// las fechas YYYY-MM-DD se pueden comparar como texto
+	switch {
+	case fecha < membership.Desde:
+		entitlement.Motivo = dto.MotivoPlanNoIniciado
+	case fecha > membership.Hasta:
+		entitlement.Motivo = dto.MotivoPlanVencido
+	default:
+		entitlement.Vigente = true
+	}
+	return entitlement, nil
+}
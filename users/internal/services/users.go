@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 	"users/internal/dao"
@@ -18,28 +20,50 @@ type UsersService interface {
 	Create(datos dto.UserMinDTO) (dto.UserMinDTO, error)
 	GetByID(id int) (dto.UserDTO, error)
 	GetAll() ([]dto.UserDTO, error)
+	GetMany(ids []int) ([]dto.UserPublicDTO, error)
 	Update(id int, updateDTO dto.UserUpdateDTO) (dto.UserDTO, error)
 	Delete(id int) error
 	IsAdmin(token string) (bool, error)
 	GenerateToken(userdata dao.User) (string, error)
 	GetClaimsFromToken(tokenString string) (jwt.MapClaims, error)
+
+	GetPlans() ([]dto.PlanDTO, error)
+	CreatePlan(plan dto.PlanDTO) (dto.PlanDTO, error)
+	UpdatePlan(id int, plan dto.PlanDTO) (dto.PlanDTO, error)
+	GetMembership(id int) (dto.MembershipDTO, error)
+	SetMembership(id int, membership dto.MembershipDTO) (dto.MembershipDTO, error)
+	GetEntitlement(id int, fecha string) (dto.EntitlementDTO, error)
 }
 
 var (
 	ErrIncorrectCredentials error = errors.New("credenciales incorrectas")
 	ErrLoginFormat          error = errors.New("se debe especificar solo uno de los siguientes: username, email")
 	ErrInvalidTokenClaims   error = errors.New("error al obtener los claims")
+	ErrInvalidIDs           error = errors.New("los IDs deben ser números positivos")
+	ErrTooManyIDs           error = fmt.Errorf("se pueden pedir hasta %d usuarios a la vez", MaxUsersPerRequest)
+	ErrPublishEvent         error = errors.New("no se pudo publicar el evento del usuario")
+	ErrRollback             error = errors.New("no se pudo restaurar el usuario")
 )
 
+// MaxUsersPerRequest es la cantidad máxima de IDs de una búsqueda de varios usuarios
+const MaxUsersPerRequest = 1000
+
+// EventsPublisher publica los eventos de usuarios para los otros servicios
+type EventsPublisher interface {
+	PublishEvent(ctx context.Context, event dto.UserEventDTO) error
+}
+
 type UsersServiceImpl struct {
 	repository repository.UsersRepository
+	events     EventsPublisher
 
 	jwtSecret []byte
 }
 
-func NewUsersService(repository repository.UsersRepository, jwtSecret string) UsersServiceImpl {
+func NewUsersService(repository repository.UsersRepository, jwtSecret string, events EventsPublisher) UsersServiceImpl {
 	return UsersServiceImpl{
 		repository: repository,
+		events:     events,
 		jwtSecret:  []byte(jwtSecret),
 	}
 }
@@ -218,6 +242,53 @@ func (s *UsersServiceImpl) GetAll() ([]dto.UserDTO, error) {
 	return result, nil
 }
 
+// GetMany busca varios usuarios por ID y los devuelve en el orden pedido, sin repetidos. Los que
+// no existen se omiten.
+func (s *UsersServiceImpl) GetMany(ids []int) ([]dto.UserPublicDTO, error) {
+	unicos := make([]int, 0, len(ids))
+	vistos := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, ErrInvalidIDs
+		}
+		if !vistos[id] {
+			vistos[id] = true
+			unicos = append(unicos, id)
+		}
+	}
+	if len(unicos) > MaxUsersPerRequest {
+		return nil, ErrTooManyIDs
+	}
+
+	result := []dto.UserPublicDTO{}
+	if len(unicos) == 0 {
+		return result, nil
+	}
+	usuarios, err := s.repository.GetUsersByIDs(unicos)
+	if err != nil {
+		return nil, err
+	}
+
+	porID := make(map[int]dao.User, len(usuarios))
+	for _, u := range usuarios {
+		porID[u.Id] = u
+	}
+	for _, id := range unicos {
+		u, ok := porID[id]
+		if !ok {
+			continue
+		}
+		result = append(result, dto.UserPublicDTO{
+			Id:       u.Id,
+			Nombre:   u.Nombre,
+			Apellido: u.Apellido,
+			Email:    u.Email,
+		})
+	}
+
+	return result, nil
+}
+
 func (s *UsersServiceImpl) Update(id int, updateDTO dto.UserUpdateDTO) (dto.UserDTO, error) {
 	usuarioActual, err := s.repository.GetUserByID(id)
 	if err != nil {
@@ -254,8 +325,34 @@ func (s *UsersServiceImpl) Update(id int, updateDTO dto.UserUpdateDTO) (dto.User
 	}, nil
 }
 
+// Delete elimina al usuario y publica user.deleted para que activities-api lo quite de las
+// actividades. Si la publicación falla se restaura el usuario (con su mismo ID) y se devuelve
+// el error, para que la baja se pueda reintentar sin dejarlo en las listas de inscriptos.
 func (s *UsersServiceImpl) Delete(id int) error {
-	return s.repository.Delete(id)
+	usuario, err := s.repository.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.repository.Delete(id); err != nil {
+		return err
+	}
+	if s.events == nil {
+		return nil
+	}
+
+	event := dto.UserEventDTO{Type: dto.EventUserDeleted, UserID: id, Timestamp: time.Now().UTC()}
+	if err := s.events.PublishEvent(context.Background(), event); err != nil {
+		log.Errorf("error al publicar %s del usuario %d: %v", event.Type, id, err)
+
+		// rollback: se vuelve a crear el usuario con sus datos originales
+		if _, restoreErr := s.repository.Create(usuario); restoreErr != nil {
+			log.Errorf("CRITICO: no se pudo restaurar el usuario %d tras fallar la publicación: %v", id, restoreErr)
+			return errors.Join(ErrPublishEvent, ErrRollback, err, restoreErr)
+		}
+		log.Warnf("usuario %d restaurado tras fallar la publicación de %s", id, event.Type)
+		return errors.Join(ErrPublishEvent, err)
+	}
+	return nil
 }
 
 func (s *UsersServiceImpl) IsAdmin(token string) (bool, error) {
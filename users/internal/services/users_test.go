@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"users/internal/dao"
+	"users/internal/dto"
+	"users/internal/repository"
+)
+
+// mockRepo guarda los usuarios y planes en memoria
+type mockRepo struct {
+	users       map[int]dao.User
+	plans       map[int]dao.Plan
+	memberships int
+}
+
+func (m *mockRepo) Create(user dao.User) (dao.User, error) {
+	// como en MySQL, si el usuario ya trae ID se inserta con ese ID
+	if user.Id == 0 {
+		user.Id = len(m.users) + 100
+	}
+	m.users[user.Id] = user
+	return user, nil
+}
+
+func (m *mockRepo) GetUserByID(id int) (dao.User, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return dao.User{}, repository.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (m *mockRepo) GetUserByUsername(username string) (dao.User, error) {
+	for _, user := range m.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return dao.User{}, repository.ErrUserNotFound
+}
+
+func (m *mockRepo) GetUserByEmail(email string) (dao.User, error) {
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return dao.User{}, repository.ErrUserNotFound
+}
+
+func (m *mockRepo) GetAll() ([]dao.User, error) {
+	var users []dao.User
+	for _, user := range m.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (m *mockRepo) GetUsersByIDs(ids []int) ([]dao.User, error) {
+	var users []dao.User
+	for _, id := range ids {
+		if user, ok := m.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (m *mockRepo) Update(id int, user dao.User) (dao.User, error) {
+	m.users[id] = user
+	return user, nil
+}
+
+func (m *mockRepo) Delete(id int) error {
+	if _, ok := m.users[id]; !ok {
+		return repository.ErrUserNotFound
+	}
+	delete(m.users, id)
+	return nil
+}
+
+func (m *mockRepo) CreatePlan(plan dao.Plan) (dao.Plan, error) {
+	plan.Id = len(m.plans) + 1
+	m.plans[plan.Id] = plan
+	return plan, nil
+}
+
+func (m *mockRepo) GetPlanByID(id int) (dao.Plan, error) {
+	plan, ok := m.plans[id]
+	if !ok {
+		return dao.Plan{}, repository.ErrPlanNotFound
+	}
+	return plan, nil
+}
+
+func (m *mockRepo) GetPlans() ([]dao.Plan, error) {
+	var plans []dao.Plan
+	for _, plan := range m.plans {
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+func (m *mockRepo) UpdatePlan(id int, plan dao.Plan) (dao.Plan, error) {
+	plan.Id = id
+	m.plans[id] = plan
+	return plan, nil
+}
+
+func (m *mockRepo) SetMembership(id int, planID int, desde, hasta time.Time) (dao.User, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return dao.User{}, repository.ErrUserNotFound
+	}
+	user.PlanId, user.PlanDesde, user.PlanHasta = &planID, &desde, &hasta
+	m.users[id] = user
+	m.memberships++
+	return user, nil
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{
+		users: map[int]dao.User{1: {Id: 1, Nombre: "Ana", Apellido: "Perez", Username: "ana", Email: "ana@mail.com"}},
+		plans: map[int]dao.Plan{1: {Id: 1, Nombre: "3 clases", ClasesSemanales: 3}},
+	}
+}
+
+// TestGetEntitlement tests the membership checks for a date
+func TestGetEntitlement(t *testing.T) {
+	repo := newMockRepo()
+	service := NewUsersService(repo, "secret", nil)
+
+	entitlement, err := service.GetEntitlement(1, "2026-03-02")
+	if err != nil || entitlement.Vigente || entitlement.Motivo != dto.MotivoSinPlan {
+		t.Fatalf("expected sin_plan, got %+v (%v)", entitlement, err)
+	}
+
+	if _, err := service.SetMembership(1, dto.MembershipDTO{IdPlan: 1, Desde: "2026-03-01", Hasta: "2026-03-31"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		fecha   string
+		vigente bool
+		motivo  string
+	}{
+		{"2026-02-28", false, dto.MotivoPlanNoIniciado},
+		{"2026-03-01", true, ""},
+		{"2026-03-31", true, ""},
+		{"2026-04-01", false, dto.MotivoPlanVencido},
+	}
+	for _, tt := range tests {
+		entitlement, err := service.GetEntitlement(1, tt.fecha)
+		if err != nil {
+			t.Fatalf("expected no error for %s, got %v", tt.fecha, err)
+		}
+		if entitlement.Vigente != tt.vigente || entitlement.Motivo != tt.motivo {
+			t.Errorf("%s: expected vigente=%t motivo=%q, got %+v", tt.fecha, tt.vigente, tt.motivo, entitlement)
+		}
+		if entitlement.ClasesSemanales != 3 || entitlement.Plan == nil || entitlement.Plan.Id != 1 {
+			t.Errorf("%s: expected the plan quota, got %+v", tt.fecha, entitlement)
+		}
+	}
+
+	if _, err := service.GetEntitlement(1, "02/03/2026"); !errors.Is(err, ErrInvalidFecha) {
+		t.Errorf("expected ErrInvalidFecha, got %v", err)
+	}
+}
+
+// TestSetMembership tests the validation of the membership dates and plan
+func TestSetMembership(t *testing.T) {
+	repo := newMockRepo()
+	service := NewUsersService(repo, "secret", nil)
+
+	invalid := []dto.MembershipDTO{
+		{IdPlan: 1, Desde: "2026-03-31", Hasta: "2026-03-01"},
+		{IdPlan: 1, Desde: "2026-03-01"},
+		{IdPlan: 1, Desde: "01/03/2026", Hasta: "2026-03-31"},
+		{IdPlan: 1, Desde: "2026-02-30", Hasta: "2026-03-31"},
+	}
+	for _, membership := range invalid {
+		if _, err := service.SetMembership(1, membership); !errors.Is(err, ErrInvalidMembership) {
+			t.Errorf("expected ErrInvalidMembership for %+v, got %v", membership, err)
+		}
+	}
+
+	if _, err := service.SetMembership(1, dto.MembershipDTO{IdPlan: 9, Desde: "2026-03-01", Hasta: "2026-03-31"}); !errors.Is(err, repository.ErrPlanNotFound) {
+		t.Errorf("expected ErrPlanNotFound, got %v", err)
+	}
+	if repo.memberships != 0 {
+		t.Errorf("expected no membership to be saved, got %d", repo.memberships)
+	}
+
+	membership, err := service.SetMembership(1, dto.MembershipDTO{IdPlan: 1, Desde: "2026-03-01", Hasta: "2026-03-01"})
+	if err != nil || membership.Desde != "2026-03-01" || membership.Hasta != "2026-03-01" {
+		t.Errorf("expected a one day membership, got %+v (%v)", membership, err)
+	}
+}
+
+// TestGetMany tests the batch lookup of users
+func TestGetMany(t *testing.T) {
+	repo := newMockRepo()
+	repo.users[2] = dao.User{Id: 2, Nombre: "Juan", Apellido: "Gomez", Username: "juan", Email: "juan@mail.com", Password: "hash"}
+	repo.users[3] = dao.User{Id: 3, Nombre: "Luz", Apellido: "Diaz", Username: "luz", Email: "luz@mail.com"}
+	service := NewUsersService(repo, "secret", nil)
+
+	users, err := service.GetMany([]int{3, 1, 99, 3, 2})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(users) != 3 || users[0].Id != 3 || users[1].Id != 1 || users[2].Id != 2 {
+		t.Errorf("expected users 3, 1, 2 in the requested order without repeats, got %+v", users)
+	}
+	if users[2] != (dto.UserPublicDTO{Id: 2, Nombre: "Juan", Apellido: "Gomez", Email: "juan@mail.com"}) {
+		t.Errorf("unexpected public data %+v", users[2])
+	}
+
+	if users, err := service.GetMany(nil); err != nil || users == nil || len(users) != 0 {
+		t.Errorf("expected an empty list, got %v (%v)", users, err)
+	}
+	if _, err := service.GetMany([]int{1, 0}); !errors.Is(err, ErrInvalidIDs) {
+		t.Errorf("expected ErrInvalidIDs, got %v", err)
+	}
+	if _, err := service.GetMany([]int{-1}); !errors.Is(err, ErrInvalidIDs) {
+		t.Errorf("expected ErrInvalidIDs, got %v", err)
+	}
+
+	tooMany := make([]int, MaxUsersPerRequest+1)
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+	if _, err := service.GetMany(tooMany); !errors.Is(err, ErrTooManyIDs) {
+		t.Errorf("expected ErrTooManyIDs, got %v", err)
+	}
+	// los repetidos no cuentan para el límite
+	if _, err := service.GetMany(append(tooMany[:MaxUsersPerRequest], 1, 2)); err != nil {
+		t.Errorf("expected repeated ids to be ignored for the limit, got %v", err)
+	}
+}
+
+// mockPublisher registra los eventos publicados
+type mockPublisher struct {
+	events []dto.UserEventDTO
+	err    error
+}
+
+func (m *mockPublisher) PublishEvent(ctx context.Context, event dto.UserEventDTO) error {
+	m.events = append(m.events, event)
+	return m.err
+}
+
+// TestDelete tests that deleting a user publishes user.deleted
+func TestDelete(t *testing.T) {
+	t.Run("publishes the event", func(t *testing.T) {
+		events := &mockPublisher{}
+		service := NewUsersService(newMockRepo(), "secret", events)
+
+		if err := service.Delete(1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(events.events) != 1 || events.events[0].Type != dto.EventUserDeleted || events.events[0].UserID != 1 || events.events[0].Timestamp.IsZero() {
+			t.Errorf("expected a user.deleted event for user 1, got %+v", events.events)
+		}
+	})
+
+	t.Run("unknown user publishes nothing", func(t *testing.T) {
+		events := &mockPublisher{}
+		service := NewUsersService(newMockRepo(), "secret", events)
+
+		if err := service.Delete(9); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+		if len(events.events) != 0 {
+			t.Errorf("expected no events, got %+v", events.events)
+		}
+	})
+
+	t.Run("publish failure restores the user", func(t *testing.T) {
+		repo := newMockRepo()
+		original := repo.users[1]
+		service := NewUsersService(repo, "secret", &mockPublisher{err: errors.New("rabbit down")})
+
+		err := service.Delete(1)
+		if !errors.Is(err, ErrPublishEvent) || errors.Is(err, ErrRollback) {
+			t.Errorf("expected ErrPublishEvent without ErrRollback, got %v", err)
+		}
+		if restored, ok := repo.users[1]; !ok || restored != original {
+			t.Errorf("expected user 1 restored with the same ID and data, got %+v", repo.users)
+		}
+	})
+}